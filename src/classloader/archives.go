@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"jacobin/log"
+	"path/filepath"
 	"strings"
 )
 
@@ -70,7 +71,7 @@ func (archive *Archive) scanArchive() error {
 	for _, file := range reader.File {
 		entry := archive.recordFile(file)
 		if entry.Type == Manifest {
-			if archive.parseManifest(file); err != nil {
+			if err := archive.parseManifest(file); err != nil {
 				return err
 			}
 		}
@@ -102,25 +103,37 @@ func (archive *Archive) recordFile(file *zip.File) ResourceEntry {
 	return entry
 }
 
+// parseManifest reads the main attributes of the manifest. Per the JAR file specification,
+// lines end in CR LF, LF, or CR, and a line that begins with a space continues the value
+// on the line before it, which is how long values such as Class-Path are wrapped.
 func (archive *Archive) parseManifest(file *zip.File) error {
 	rc, err := file.Open()
-
-	defer rc.Close()
-
 	if err != nil {
 		return err
 	}
+	defer rc.Close()
 
 	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
 
-	contents := string(data)
-
-	lines := strings.Split(contents, "\r\n")
+	contents := strings.ReplaceAll(string(data), "\r\n", "\n")
+	contents = strings.ReplaceAll(contents, "\r", "\n")
+	lines := strings.Split(contents, "\n")
 
+	var lastKey string
 	for _, line := range lines {
-		parts := strings.Split(line, ":")
+		if strings.HasPrefix(line, " ") && lastKey != "" {
+			archive.manifest[lastKey] += line[1:]
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
 		if len(parts) > 1 {
-			archive.manifest[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+			lastKey = strings.TrimSpace(parts[0])
+			archive.manifest[lastKey] = strings.TrimSpace(parts[1])
+		} else {
+			lastKey = ""
 		}
 	}
 
@@ -137,8 +150,13 @@ func (archive *Archive) hasResource(name string, resourceType ResourceType) bool
 	return item.Type == resourceType
 }
 
+// hasClass reports whether the archive holds a class file for the named class
+func (archive *Archive) hasClass(className string) bool {
+	return archive.hasResource(jarClassName(className), ClassFile)
+}
+
 func (archive *Archive) loadClass(className string) (*LoadResult, error) {
-	item, ok := archive.entryCache[className]
+	item, ok := archive.entryCache[jarClassName(className)]
 
 	if !ok {
 		err := errors.New(fmt.Sprintf("Unable to load class %s in archive %s", className, archive.Filename))
@@ -183,3 +201,26 @@ func (archive *Archive) getMainClass() string {
 		return ""
 	}
 }
+
+// getClassPath returns the jars listed in the Class-Path attribute of the manifest. The
+// entries are separated by spaces and are relative to the location of this archive, so
+// they are returned resolved against the directory holding it.
+func (archive *Archive) getClassPath() []string {
+	var classPath []string
+	for _, entry := range strings.Fields(archive.manifest["Class-Path"]) {
+		path := filepath.FromSlash(entry)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(archive.Filename), path)
+		}
+		classPath = append(classPath, path)
+	}
+	return classPath
+}
+
+// jarClassName converts a class name in either the java/lang/String format or, as on
+// Windows, with platform path separators, into the java.lang.String format used for
+// the class files in the entryCache
+func jarClassName(className string) string {
+	name := strings.ReplaceAll(className, "/", ".")
+	return strings.ReplaceAll(name, "\\", ".")
+}
@@ -7,6 +7,7 @@
 package classloader
 
 import (
+	"archive/zip"
 	"os"
 	"path/filepath"
 	"testing"
@@ -102,3 +103,78 @@ func TestLoadClassDoesNotExist(t *testing.T) {
 		t.Error("Expected error loading class, but didn't get one.")
 	}
 }
+
+// writeJar creates a jar file holding the given files, keyed by their names in the jar
+func writeJar(t *testing.T, jarFileName string, files map[string][]byte) {
+	if err := os.MkdirAll(filepath.Dir(jarFileName), 0755); err != nil {
+		t.Fatal("Unable to create directory for jar file", err)
+	}
+	jarFile, err := os.Create(jarFileName)
+	if err != nil {
+		t.Fatal("Unable to create jar file", err)
+	}
+	defer jarFile.Close()
+
+	writer := zip.NewWriter(jarFile)
+	for name, contents := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal("Unable to add file to jar", err)
+		}
+		_, _ = w.Write(contents)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal("Unable to write jar file", err)
+	}
+}
+
+func TestManifestClassPath(t *testing.T) {
+	dir := t.TempDir()
+	jarFileName := filepath.Join(dir, "app.jar")
+	manifest := "Manifest-Version: 1.0\r\n" +
+		"Main-Class: app.Main\r\n" +
+		"Class-Path: lib/util.jar lib/\r\n" + // a long value is continued on the next line
+		" more.jar\r\n" +
+		"Implementation-URL: http://jacobin.org\r\n" +
+		"\r\n"
+	writeJar(t, jarFileName, map[string][]byte{"META-INF/MANIFEST.MF": []byte(manifest)})
+
+	jar, err := NewJarFile(jarFileName)
+	if err != nil {
+		t.Fatal("Error opening jar file", err)
+	}
+
+	if jar.getMainClass() != "app.Main" {
+		t.Errorf("Expected Main-Class to be 'app.Main', but was '%s'", jar.getMainClass())
+	}
+	if jar.manifest["Implementation-URL"] != "http://jacobin.org" {
+		t.Errorf("Expected a value containing a colon to be kept whole, but got '%s'",
+			jar.manifest["Implementation-URL"])
+	}
+
+	classPath := jar.getClassPath()
+	expected := []string{filepath.Join(dir, "lib", "util.jar"), filepath.Join(dir, "lib", "more.jar")}
+	if len(classPath) != len(expected) {
+		t.Fatalf("Expected Class-Path of %v, but got %v", expected, classPath)
+	}
+	for i := range expected {
+		if classPath[i] != expected[i] {
+			t.Errorf("Expected Class-Path entry %d to be %s, but got %s", i, expected[i], classPath[i])
+		}
+	}
+}
+
+func TestHasClassWithSlashedName(t *testing.T) {
+	jar, err := getJar(GOOD_JAR_NAME, t)
+
+	if err != nil {
+		return
+	}
+
+	if !jar.hasClass("jacobin/HelloWorld") {
+		t.Error("Expected jar to have class jacobin/HelloWorld, but it didn't")
+	}
+	if jar.hasClass("jacobin/GoodbyeWorld") {
+		t.Error("Expected jar not to have class jacobin/GoodbyeWorld, but it did")
+	}
+}
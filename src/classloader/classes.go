@@ -13,6 +13,7 @@ import (
 	"jacobin/shutdown"
 	"jacobin/stringPool"
 	"jacobin/types"
+	"sync/atomic"
 )
 
 // the definition of the class as it's stored in the method area
@@ -36,6 +37,7 @@ type ClData struct {
 	Attributes      []Attr
 	SourceFile      string
 	Bootstraps      []BootstrapMethod
+	InnerClasses    []InnerClass // the nested classes named in the InnerClasses attribute
 	CP              CPool
 	Access          AccessFlags
 	ClInit          byte // 0 = no clinit, 1 = clinit not run, 2 clinit run
@@ -58,6 +60,10 @@ type CPool struct {
 	NameAndTypes   []NameAndTypeEntry
 	//	StringRefs     []uint16 // all StringRefs are converted into utf8Refs
 	Utf8Refs []string
+
+	// what the MethodRef and FieldRef entries resolve to, indexed by CP index (see cpCache.go)
+	resolvedMethods []atomic.Pointer[ResolvedMethodRef]
+	resolvedFields  []atomic.Pointer[ResolvedFieldRef]
 }
 
 type AccessFlags struct {
@@ -133,6 +139,16 @@ type BootstrapMethod struct {
 	Args      []uint16 // arguments: indexes to loadable arguments from the CP
 }
 
+// a nested class, as recorded in the InnerClasses attribute of both the nested class and
+// the classes that refer to it. Consult:
+// https://docs.oracle.com/javase/specs/jvms/se17/html/jvms-4.html#jvms-4.7.6
+type InnerClass struct {
+	Name        string // the nested class in java/lang/String format, e.g., Outer$Inner
+	OuterClass  string // the class it's a member of, or "" if it's a local or anonymous class
+	SimpleName  string // its name in the source code, e.g., Inner, or "" if it's anonymous
+	AccessFlags int    // its access flags as declared in the source code
+}
+
 // ==== Constant Pool structs (in order by their numeric code) ====//
 type CpEntry struct {
 	Type uint16
@@ -220,6 +236,10 @@ func FetchMethodAndCP(className, methName, methType string) (MTentry, error) {
 				// noMainError() calls shutdown.Exit(). However, in test mode, shutdown.Exit() doesn't exit,
 				// so the following error return is needed to cover the test cases.
 				return MTentry{}, errors.New("Error: main() method not found in class " + origClassName + "\n")
+			} else if errors.Is(err, ErrClassNotFound) {
+				// left to the caller to throw a NoClassDefFoundError
+				return MTentry{}, fmt.Errorf("FetchMethodAndCP: LoadClassFromNameOnly for %s failed: %w",
+					className, err)
 			} else {
 				errMsg := fmt.Sprintf("FetchMethodAndCP: LoadClassFromNameOnly for %s failed: %s",
 					className, err.Error())
@@ -328,6 +348,14 @@ func FetchMethodAndCP(className, methName, methType string) (MTentry, error) {
 			shutdown.Exit(shutdown.JVM_EXCEPTION)
 			return MTentry{}, errors.New(errMsg) // dummy return needed for tests
 		}
+
+		// the superclass's method might be a native (G) function, such as the methods of
+		// java/lang/Enum, which are found only in the MTable
+		if gEntry := MTable[className+"."+searchName]; gEntry.Meth != nil && gEntry.MType == 'G' {
+			AddEntry(&MTable, methFQN, gEntry)
+			return gEntry, nil
+		}
+
 		methRef, ok = k.Data.MethodTable[searchName]
 		if ok {
 			m = *methRef
@@ -16,6 +16,7 @@ import (
 	"jacobin/globals"
 	"jacobin/log"
 	"jacobin/shutdown"
+	"jacobin/stringPool"
 	"jacobin/types"
 	"jacobin/util"
 	"os"
@@ -67,6 +68,7 @@ type ParsedClass struct {
 	sourceFile      string
 	bootstrapCount  int // the number of bootstrap methods
 	bootstraps      []bootstrapMethod
+	innerClasses    []innerClass
 
 	deprecated bool
 
@@ -164,6 +166,14 @@ type bootstrapMethod struct {
 	args      []int // arguments: indexes to loadable arguments from the CP
 }
 
+// the nested classes, specified in the InnerClasses class attribute
+type innerClass struct {
+	name        string // the nested class, e.g., Outer$Inner
+	outerClass  string // the class it's a member of, or "" if it's a local or anonymous class
+	simpleName  string // its name in the source code, e.g., Inner, or "" if it's anonymous
+	accessFlags int
+}
+
 var ClassesLock = sync.RWMutex{}
 
 // cfe = class format error, which is the error thrown by the parser for most
@@ -194,6 +204,8 @@ func CFE(msg string) error { return cfe(msg) }
 // the JAVA_HOME/jmods/java.base.jmod zip file.
 // In Java 17.0.7, there are currently a total of 6401 embedded classes in java.base.jmod.
 // Based on the lib/classlist member in java.base.jmod, only 1402 class files are actually loaded by this function.
+// If a required base class (such as java/lang/Object) cannot be loaded, the JVM exits; an optional
+// class that cannot be loaded is reported with a warning and skipped.
 func LoadBaseClasses() {
 	global := globals.GetGlobalRef()
 	jmodFilePath := global.JavaHome + string(os.PathSeparator) + "jmods" + string(os.PathSeparator) + "java.base.jmod"
@@ -203,6 +215,7 @@ func LoadBaseClasses() {
 		_ = log.Log("LoadBaseClasses: Error loading jmod file classes "+jmodFilePath, log.SEVERE)
 		_ = log.Log(err.Error(), log.SEVERE)
 		shutdown.Exit(shutdown.JVM_EXCEPTION)
+		return // needed only in test mode, in which shutdown.Exit() returns
 	}
 
 	msg := fmt.Sprintf("LoadBaseClasses: Bootstrap classes from %s have been loaded", jmodFilePath)
@@ -289,7 +302,13 @@ func LoadFromLoaderChannel(LoaderChannel <-chan string) {
 	globals.LoaderWg.Done()
 }
 
-// Load a class from name in java/lang/Class format
+// ErrClassNotFound is wrapped in the error returned when a class can't be found in the
+// jmods, in the starting jar and its Class-Path, or as a file. While a program runs, the
+// interpreter reports it as a NoClassDefFoundError, which the program can catch.
+var ErrClassNotFound = errors.New("class not found")
+
+// Load a class from name in java/lang/Class format. If the class can't be found, the
+// error returned wraps ErrClassNotFound.
 func LoadClassFromNameOnly(className string) error {
 	var err error
 
@@ -323,11 +342,17 @@ func LoadClassFromNameOnly(className string) error {
 		return err
 	}
 
-	// Load class from a jar file?
+	// Load class from a jar file? The class is looked for in the starting jar and then
+	// in the jars listed in the Class-Path of its manifest.
 	if len(globals.GetGlobalRef().StartingJar) > 0 {
 		validName := util.ConvertToPlatformPathSeparators(className)
-		_ = log.Log("LoadClassFromNameOnly: LoadClassFromJar "+validName, log.CLASS)
-		_, err = LoadClassFromJar(AppCL, validName, globals.GetGlobalRef().StartingJar)
+		jarFileName := findJarWithClass(AppCL, validName, globals.GetGlobalRef().StartingJar, make(map[string]bool))
+		if jarFileName == "" {
+			_ = log.Log("LoadClassFromNameOnly: "+validName+" is not in the jar or its Class-Path", log.CLASS)
+			return fmt.Errorf("%w: %s", ErrClassNotFound, className)
+		}
+		_ = log.Log("LoadClassFromNameOnly: LoadClassFromJar "+validName+" from "+jarFileName, log.CLASS)
+		_, err = LoadClassFromJar(AppCL, validName, jarFileName)
 		if err != nil {
 			_ = log.Log("LoadClassFromNameOnly: LoadClassFromJar "+validName+" failed", log.SEVERE)
 			_ = log.Log(err.Error(), log.SEVERE)
@@ -335,28 +360,133 @@ func LoadClassFromNameOnly(className string) error {
 		return err
 	}
 
-	// Loading from a local file system class
-	// TODO: classpath
+	// Loading from a local file system class, in the directories and jars on the classpath
 	validName := util.ConvertToPlatformPathSeparators(className)
+	if globals.GetGlobalRef().ClassPath != "" {
+		return loadClassFromClassPath(className, validName)
+	}
 	_ = log.Log("LoadClassFromNameOnly: Loaded class from file "+validName, log.CLASS)
-	_, err = LoadClassFromFile(AppCL, validName)
-	if err != nil {
-		errMsg := fmt.Sprintf("LoadClassFromNameOnly for %s failed", className)
-		globals.GetGlobalRef().FuncThrowException(excNames.ClassNotFoundException, errMsg)
-		return errors.New(errMsg) // return for tests only
+	_, err = loadClassFromFile(AppCL, validName)
+	if errors.Is(err, ErrClassNotFound) {
+		return fmt.Errorf("%w: %s", ErrClassNotFound, className)
 	}
 	return err
 }
 
+// ClassPathEntries returns the directories and jar files on the classpath, which are
+// separated by the path separator (the path.separator property). The classpath defaults
+// to the current directory.
+func ClassPathEntries() []string {
+	glob := globals.GetGlobalRef()
+	if glob.ClassPath == "" {
+		return []string{"."}
+	}
+	var entries []string
+	for _, entry := range strings.Split(glob.ClassPath, glob.PathSeparator) {
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// loadClassFromClassPath loads the class from the first directory or jar file on the
+// classpath that contains it. validName is the class name with the platform's separators.
+func loadClassFromClassPath(className, validName string) error {
+	for _, entry := range ClassPathEntries() {
+		if strings.HasSuffix(entry, ".jar") {
+			jarFileName := findJarWithClass(AppCL, validName, entry, make(map[string]bool))
+			if jarFileName == "" {
+				continue
+			}
+			_ = log.Log("LoadClassFromNameOnly: LoadClassFromJar "+validName+" from "+jarFileName, log.CLASS)
+			_, err := LoadClassFromJar(AppCL, validName, jarFileName)
+			return err
+		}
+
+		_, err := loadClassFromFile(AppCL, filepath.Join(entry, validName))
+		if errors.Is(err, ErrClassNotFound) {
+			continue
+		}
+		_ = log.Log("LoadClassFromNameOnly: Loaded class "+validName+" from "+entry, log.CLASS)
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrClassNotFound, className)
+}
+
+// LoadClassHierarchy loads the superclasses and superinterfaces of the named class, and
+// the class itself if need be, so that the whole hierarchy above the class is in the
+// method area before the class is verified or its methods are dispatched. The superclass
+// chain and the interfaces at every level are loaded transitively, each class only once.
+// If a class turns out to be its own superclass or superinterface, a ClassCircularityError
+// is thrown, as the JVM spec requires. Consult:
+// https://docs.oracle.com/javase/specs/jvms/se17/html/jvms-5.html#jvms-5.3.5
+func LoadClassHierarchy(className string) error {
+	return loadClassHierarchy(className, make(map[string]bool), make(map[string]bool))
+}
+
+// loadClassHierarchy does the work of LoadClassHierarchy(). inProgress holds the classes
+// whose hierarchy is being loaded, so that finding one of them again means a cycle, and
+// loaded holds the classes whose hierarchy has been loaded.
+func loadClassHierarchy(className string, inProgress, loaded map[string]bool) error {
+	if loaded[className] {
+		return nil
+	}
+	if inProgress[className] {
+		errMsg := fmt.Sprintf("LoadClassHierarchy: %s is its own superclass or superinterface", className)
+		globals.GetGlobalRef().FuncThrowException(excNames.ClassCircularityError, errMsg)
+		return errors.New(errMsg) // return for tests only
+	}
+	inProgress[className] = true
+
+	klass := MethAreaFetch(className)
+	if klass == nil {
+		if err := LoadClassFromNameOnly(className); err != nil {
+			return err
+		}
+		klass = MethAreaFetch(className)
+		if klass == nil {
+			return fmt.Errorf("LoadClassHierarchy: %s is not in the method area after loading it", className)
+		}
+	}
+
+	supertypes := []uint32{klass.Data.SuperclassIndex}
+	for _, index := range klass.Data.Interfaces {
+		supertypes = append(supertypes, uint32(index))
+	}
+	for _, index := range supertypes {
+		supertype := stringPool.GetStringPointer(index)
+		if supertype == nil || *supertype == "" { // java/lang/Object has no superclass
+			continue
+		}
+		if err := loadClassHierarchy(*supertype, inProgress, loaded); err != nil {
+			return err
+		}
+	}
+
+	delete(inProgress, className)
+	loaded[className] = true
+	return nil
+}
+
 // LoadClassFromFile first canonicalizes the filename, and reads
-// the indicated file, and runs it through the classloader.
+// the indicated file, and runs it through the classloader. It is used to
+// load the starting class, so a ClassNotFoundException is thrown if the
+// file cannot be read.
 func LoadClassFromFile(cl Classloader, fname string) (uint32, error) {
-	var filename string
-	if !strings.HasSuffix(fname, ".class") {
-		filename = fname + ".class"
-	} else {
-		filename = fname
+	index, err := loadClassFromFile(cl, fname)
+	if errors.Is(err, ErrClassNotFound) {
+		errMsg := fmt.Sprintf("LoadClassFromFile for %s failed", classFilename(fname))
+		globals.GetGlobalRef().FuncThrowException(excNames.ClassNotFoundException, errMsg)
 	}
+	return index, err
+}
+
+// loadClassFromFile does the work of LoadClassFromFile(), but leaves it to
+// the caller to report a class file that can't be read, which is returned
+// as an error that wraps ErrClassNotFound.
+func loadClassFromFile(cl Classloader, fname string) (uint32, error) {
+	filename := classFilename(fname)
 	if filename == ".class" || strings.HasSuffix(filename, ";.class") {
 		msg := "LoadClassFromFile: class name" + fname + " is invalid"
 		_ = log.Log(msg, log.SEVERE)
@@ -365,15 +495,22 @@ func LoadClassFromFile(cl Classloader, fname string) (uint32, error) {
 	}
 	rawBytes, err := os.ReadFile(filename)
 	if err != nil {
-		errMsg := fmt.Sprintf("LoadClassFromFile for %s failed", filename)
-		globals.GetGlobalRef().FuncThrowException(excNames.ClassNotFoundException, errMsg)
-		return types.InvalidStringIndex, errors.New(errMsg) // return for tests only
+		return types.InvalidStringIndex, fmt.Errorf("%w: %s", ErrClassNotFound, filename)
 	}
 	_ = log.Log("LoadClassFromFile: File "+fname+" was read", log.CLASS)
 
 	return loadClassFromBytes(cl, filename, rawBytes)
 }
 
+// classFilename returns the name of the class file for the given name, which
+// may or may not already end in .class
+func classFilename(fname string) string {
+	if !strings.HasSuffix(fname, ".class") {
+		return fname + ".class"
+	}
+	return fname
+}
+
 func getJarFile(cl Classloader, jarFileName string) (*Archive, error) {
 	archive, exists := cl.Archives[jarFileName]
 
@@ -402,6 +539,36 @@ func GetMainClassFromJar(cl Classloader, jarFileName string) (string, error) {
 	return jar.getMainClass(), nil
 }
 
+// findJarWithClass returns the name of the jar that holds the class: jarFileName itself or,
+// failing that, one of the jars listed in the Class-Path of its manifest, which are searched
+// in order, each in turn along with the jars on its own Class-Path. Jars that cannot be
+// opened are skipped, as the JDK does. visited guards against Class-Paths that form a cycle.
+// Returns "" if no jar holds the class.
+func findJarWithClass(cl Classloader, className string, jarFileName string, visited map[string]bool) string {
+	if visited[jarFileName] {
+		return ""
+	}
+	visited[jarFileName] = true
+
+	if _, err := os.Stat(jarFileName); err != nil {
+		return "" // a jar on the Class-Path that does not exist is ignored
+	}
+	jar, err := getJarFile(cl, jarFileName)
+	if err != nil {
+		return ""
+	}
+	if jar.hasClass(className) {
+		return jarFileName
+	}
+
+	for _, classPathJar := range jar.getClassPath() {
+		if found := findJarWithClass(cl, className, classPathJar, visited); found != "" {
+			return found
+		}
+	}
+	return ""
+}
+
 func LoadClassFromJar(cl Classloader, filename string, jarFileName string) (uint32, error) {
 	jar, err := getJarFile(cl, jarFileName)
 
@@ -596,6 +763,14 @@ func convertToPostableClass(fullyParsedClass *ParsedClass) ClData {
 			kd.Bootstraps = append(kd.Bootstraps, kdbs)
 		}
 	}
+	for _, inner := range fullyParsedClass.innerClasses {
+		kd.InnerClasses = append(kd.InnerClasses, InnerClass{
+			Name:        inner.name,
+			OuterClass:  inner.outerClass,
+			SimpleName:  inner.simpleName,
+			AccessFlags: inner.accessFlags,
+		})
+	}
 	kd.Access.ClassIsPublic = fullyParsedClass.classIsPublic
 	kd.Access.ClassIsFinal = fullyParsedClass.classIsFinal
 	kd.Access.ClassIsSuper = fullyParsedClass.classIsSuper
@@ -739,6 +914,7 @@ func convertToPostableClass(fullyParsedClass *ParsedClass) ClData {
 			kd.CP.Utf8Refs = append(kd.CP.Utf8Refs, fullyParsedClass.utf8Refs[i].content)
 		}
 	}
+	InitResolvedRefs(&kd.CP)
 
 	if log.Level == log.FINEST {
 		b := new(bytes.Buffer)
@@ -9,10 +9,12 @@ package classloader
 import (
 	"errors"
 	"io"
+	"jacobin/excNames"
 	"jacobin/globals"
 	"jacobin/log"
 	"jacobin/types"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -411,3 +413,307 @@ func TestLoadFullyParsedClass(t *testing.T) {
 		t.Errorf("Invalid number of methods in Hello2.class: %d", len(classToPost.Methods))
 	}
 }
+
+// a class that is not in the starting jar is loaded from a jar listed in the Class-Path
+// of the starting jar's manifest, which is found relative to the starting jar
+func TestLoadClassFromManifestClassPath(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	InitMethodArea()
+	AppCL.Archives = make(map[string]*Archive)
+
+	// the jmod map is consulted first, so it must not be empty
+	savedJmodMap, savedJmodMapSize := JMODMAP, jmodMapSize
+	JMODMAP = map[string]string{"java/lang/Object.class": "java.base.jmod"}
+	jmodMapSize = len(JMODMAP)
+	defer func() { JMODMAP, jmodMapSize = savedJmodMap, savedJmodMapSize }()
+
+	helloBytes, err := os.ReadFile(filepath.Join("..", "..", "testdata", "Hello.class"))
+	if err != nil {
+		t.Skip("Hello.class test data not available")
+	}
+
+	dir := t.TempDir()
+	mainJar := filepath.Join(dir, "app.jar")
+	writeJar(t, mainJar, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\r\nMain-Class: Main\r\n" +
+			"Class-Path: missing.jar lib/hello.jar\r\n\r\n"),
+	})
+	writeJar(t, filepath.Join(dir, "lib", "hello.jar"), map[string][]byte{"Hello.class": helloBytes})
+	globals.GetGlobalRef().StartingJar = mainJar
+
+	if err := LoadClassFromNameOnly("Hello"); err != nil {
+		t.Fatalf("Expected Hello to be loaded from the Class-Path jar, but got: %s", err.Error())
+	}
+	if MethAreaFetch("Hello") == nil {
+		t.Error("Expected Hello to be in the method area after loading it")
+	}
+	if found := findJarWithClass(AppCL, "Hello", mainJar, make(map[string]bool)); found != filepath.Join(dir, "lib", "hello.jar") {
+		t.Errorf("Expected Hello to be found in lib/hello.jar, but found it in '%s'", found)
+	}
+	if found := findJarWithClass(AppCL, "Goodbye", mainJar, make(map[string]bool)); found != "" {
+		t.Errorf("Expected Goodbye not to be found, but found it in '%s'", found)
+	}
+}
+
+// makeNestedClassBytes returns the bytes of a minimal class file for either Outer or its
+// member class Outer$Inner. Both have an InnerClasses attribute that records Outer$Inner
+// as a public static member of Outer whose simple name is Inner.
+func makeNestedClassBytes(inner bool) []byte {
+	utf8 := func(s string) []byte {
+		return append([]byte{UTF8, 0x00, byte(len(s))}, []byte(s)...)
+	}
+
+	bytes := []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x00, 0x00, 0x00, 0x37} // magic number, Java 11
+	bytes = append(bytes, 0x00, 0x09)                               // CP count
+	bytes = append(bytes, ClassRef, 0x00, 0x02)                     // CP[1] -> Outer
+	bytes = append(bytes, utf8("Outer")...)                         // CP[2]
+	bytes = append(bytes, ClassRef, 0x00, 0x04)                     // CP[3] -> Outer$Inner
+	bytes = append(bytes, utf8("Outer$Inner")...)                   // CP[4]
+	bytes = append(bytes, ClassRef, 0x00, 0x06)                     // CP[5] -> java/lang/Object
+	bytes = append(bytes, utf8("java/lang/Object")...)              // CP[6]
+	bytes = append(bytes, utf8("Inner")...)                         // CP[7]
+	bytes = append(bytes, utf8("InnerClasses")...)                  // CP[8]
+
+	if inner {
+		bytes = append(bytes, 0x00, 0x20, 0x00, 0x03) // access flags: super; this class: CP[3]
+	} else {
+		bytes = append(bytes, 0x00, 0x21, 0x00, 0x01) // access flags: public, super; this class: CP[1]
+	}
+	bytes = append(bytes, 0x00, 0x05) // superclass: CP[5]
+	bytes = append(bytes, 0x00, 0x00) // interface count
+	bytes = append(bytes, 0x00, 0x00) // field count
+	bytes = append(bytes, 0x00, 0x00) // method count
+
+	bytes = append(bytes, 0x00, 0x01)             // class attribute count
+	bytes = append(bytes, 0x00, 0x08)             // attribute name: CP[8] -> InnerClasses
+	bytes = append(bytes, 0x00, 0x00, 0x00, 0x0A) // attribute length
+	bytes = append(bytes, 0x00, 0x01)             // number of inner classes
+	bytes = append(bytes, 0x00, 0x03)             // inner class: CP[3] -> Outer$Inner
+	bytes = append(bytes, 0x00, 0x01)             // outer class: CP[1] -> Outer
+	bytes = append(bytes, 0x00, 0x07)             // simple name: CP[7] -> Inner
+	bytes = append(bytes, 0x00, 0x09)             // access flags: public, static
+	return bytes
+}
+
+// an outer class records its nested classes from its InnerClasses attribute, and the
+// nested classes, with their $-names, are loaded just as the outer class is
+func TestLoadOuterAndInnerClasses(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	InitMethodArea()
+	AppCL.Archives = make(map[string]*Archive)
+
+	// the jmod map is consulted first, so it must not be empty
+	savedJmodMap, savedJmodMapSize := JMODMAP, jmodMapSize
+	JMODMAP = map[string]string{"java/lang/Object.class": "java.base.jmod"}
+	jmodMapSize = len(JMODMAP)
+	defer func() { JMODMAP, jmodMapSize = savedJmodMap, savedJmodMapSize }()
+
+	jarFileName := filepath.Join(t.TempDir(), "nested.jar")
+	writeJar(t, jarFileName, map[string][]byte{
+		"Outer.class":       makeNestedClassBytes(false),
+		"Outer$Inner.class": makeNestedClassBytes(true),
+	})
+	globals.GetGlobalRef().StartingJar = jarFileName
+
+	if err := LoadClassFromNameOnly("Outer"); err != nil {
+		t.Fatalf("Unexpected error loading Outer: %s", err.Error())
+	}
+	outer := MethAreaFetch("Outer")
+	if outer == nil {
+		t.Fatal("Expected Outer to be in the method area after loading it")
+	}
+	if len(outer.Data.InnerClasses) != 1 {
+		t.Fatalf("Expected Outer to have 1 inner class, got %d", len(outer.Data.InnerClasses))
+	}
+	expected := InnerClass{Name: "Outer$Inner", OuterClass: "Outer", SimpleName: "Inner", AccessFlags: 0x0009}
+	if outer.Data.InnerClasses[0] != expected {
+		t.Errorf("Expected inner class %+v, got %+v", expected, outer.Data.InnerClasses[0])
+	}
+
+	innerName := outer.Data.InnerClasses[0].Name
+	if err := LoadClassFromNameOnly(innerName); err != nil {
+		t.Fatalf("Unexpected error loading %s: %s", innerName, err.Error())
+	}
+	inner := MethAreaFetch(innerName)
+	if inner == nil {
+		t.Fatalf("Expected %s to be in the method area after loading it", innerName)
+	}
+	if len(inner.Data.InnerClasses) != 1 || inner.Data.InnerClasses[0].OuterClass != "Outer" {
+		t.Errorf("Expected %s to record Outer as its outer class, got %+v", innerName, inner.Data.InnerClasses)
+	}
+}
+
+// the classpath is split on the path separator, whatever it is, and empty entries are skipped
+func TestClassPathEntries(t *testing.T) {
+	globals.InitGlobals("test")
+	glob := globals.GetGlobalRef()
+
+	if entries := ClassPathEntries(); len(entries) != 1 || entries[0] != "." {
+		t.Errorf("Expected the default classpath to be the current directory, got %v", entries)
+	}
+
+	glob.PathSeparator = ";"
+	glob.ClassPath = "classes;;lib/app.jar;other"
+	expected := []string{"classes", "lib/app.jar", "other"}
+	entries := ClassPathEntries()
+	if strings.Join(entries, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected classpath entries %v, got %v", expected, entries)
+	}
+}
+
+// classes are loaded from the directories and jars on the classpath, in the order they appear
+func TestLoadClassFromClassPath(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	InitMethodArea()
+	AppCL.Archives = make(map[string]*Archive)
+
+	// the jmod map is consulted first, so it must not be empty
+	savedJmodMap, savedJmodMapSize := JMODMAP, jmodMapSize
+	JMODMAP = map[string]string{"java/lang/Object.class": "java.base.jmod"}
+	jmodMapSize = len(JMODMAP)
+	defer func() { JMODMAP, jmodMapSize = savedJmodMap, savedJmodMapSize }()
+
+	dir := t.TempDir()
+	classDir := filepath.Join(dir, "classes")
+	if err := os.MkdirAll(classDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	classBytes := makeClassBytes("FromDir", "java/lang/Object", nil, false)
+	if err := os.WriteFile(filepath.Join(classDir, "FromDir.class"), classBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	jarFileName := filepath.Join(dir, "lib.jar")
+	writeJar(t, jarFileName, map[string][]byte{
+		"FromJar.class": makeClassBytes("FromJar", "java/lang/Object", nil, false),
+	})
+
+	glob := globals.GetGlobalRef()
+	glob.PathSeparator = "|"
+	glob.ClassPath = filepath.Join(dir, "missing") + "|" + classDir + "|" + jarFileName
+
+	for _, className := range []string{"FromDir", "FromJar"} {
+		if err := LoadClassFromNameOnly(className); err != nil {
+			t.Fatalf("Expected %s to be loaded from the classpath, but got: %s", className, err.Error())
+		}
+		if MethAreaFetch(className) == nil {
+			t.Errorf("Expected %s to be in the method area after loading it", className)
+		}
+	}
+
+	err := LoadClassFromNameOnly("Missing")
+	if !errors.Is(err, ErrClassNotFound) {
+		t.Errorf("Expected a class not on the classpath not to be found, but got: %v", err)
+	}
+}
+
+// makeClassBytes returns the bytes of a minimal class file, with no fields or methods,
+// for a class or interface with the given superclass and superinterfaces
+func makeClassBytes(className, superclassName string, interfaceNames []string, isInterface bool) []byte {
+	var cp []byte
+	cpCount := 1
+	classRef := func(name string) []byte { // adds a UTF8 entry and a ClassRef to it
+		cp = append(cp, UTF8, byte(len(name)>>8), byte(len(name)))
+		cp = append(cp, name...)
+		cp = append(cp, ClassRef, byte(cpCount>>8), byte(cpCount))
+		cpCount += 2
+		return []byte{byte((cpCount - 1) >> 8), byte(cpCount - 1)}
+	}
+
+	thisClass := classRef(className)
+	superclass := classRef(superclassName)
+	var interfaces []byte
+	for _, name := range interfaceNames {
+		interfaces = append(interfaces, classRef(name)...)
+	}
+
+	bytes := []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x00, 0x00, 0x00, 0x37} // magic number, Java 11
+	bytes = append(bytes, byte(cpCount>>8), byte(cpCount))
+	bytes = append(bytes, cp...)
+	if isInterface {
+		bytes = append(bytes, 0x06, 0x01) // access flags: public, interface, abstract
+	} else {
+		bytes = append(bytes, 0x00, 0x21) // access flags: public, super
+	}
+	bytes = append(bytes, thisClass...)
+	bytes = append(bytes, superclass...)
+	bytes = append(bytes, 0x00, byte(len(interfaceNames)))
+	bytes = append(bytes, interfaces...)
+	bytes = append(bytes, 0x00, 0x00) // field count
+	bytes = append(bytes, 0x00, 0x00) // method count
+	bytes = append(bytes, 0x00, 0x00) // class attribute count
+	return bytes
+}
+
+// setUpJarOfClasses writes the classes to a jar that becomes the starting jar, and makes
+// the method area hold java/lang/Object, so that the classes can be loaded by name
+func setUpJarOfClasses(t *testing.T, classes map[string][]byte) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	InitMethodArea()
+	AppCL.Archives = make(map[string]*Archive)
+
+	savedJmodMap, savedJmodMapSize := JMODMAP, jmodMapSize
+	JMODMAP = map[string]string{"java/lang/Object.class": "java.base.jmod"}
+	jmodMapSize = len(JMODMAP)
+	t.Cleanup(func() { JMODMAP, jmodMapSize = savedJmodMap, savedJmodMapSize })
+
+	MethAreaInsert(types.ObjectClassName, &Klass{Status: 'F', Loader: "bootstrap",
+		Data: &ClData{Name: types.ObjectClassName}})
+
+	jarFileName := filepath.Join(t.TempDir(), "classes.jar")
+	files := make(map[string][]byte)
+	for name, bytes := range classes {
+		files[name+".class"] = bytes
+	}
+	writeJar(t, jarFileName, files)
+	globals.GetGlobalRef().StartingJar = jarFileName
+}
+
+// loading the hierarchy of a class loads its superclasses and, at every level, the
+// interfaces implemented
+func TestLoadClassHierarchy(t *testing.T) {
+	setUpJarOfClasses(t, map[string][]byte{
+		"Animal":  makeClassBytes("Animal", types.ObjectClassName, nil, false),
+		"Mammal":  makeClassBytes("Mammal", "Animal", []string{"Furry"}, false),
+		"Dog":     makeClassBytes("Dog", "Mammal", nil, false),
+		"Furry":   makeClassBytes("Furry", types.ObjectClassName, nil, true),
+		"Unusual": makeClassBytes("Unusual", types.ObjectClassName, nil, false),
+	})
+
+	if err := LoadClassHierarchy("Dog"); err != nil {
+		t.Fatalf("Unexpected error loading the hierarchy of Dog: %s", err.Error())
+	}
+	for _, className := range []string{"Dog", "Mammal", "Animal", "Furry"} {
+		if MethAreaFetch(className) == nil {
+			t.Errorf("Expected %s to be in the method area after loading the hierarchy of Dog", className)
+		}
+	}
+	if MethAreaFetch("Unusual") != nil {
+		t.Error("Expected Unusual, which is not in the hierarchy of Dog, not to be loaded")
+	}
+}
+
+// a class that is its own superclass is rejected with a ClassCircularityError
+func TestLoadClassHierarchyWithCycle(t *testing.T) {
+	setUpJarOfClasses(t, map[string][]byte{
+		"Chicken": makeClassBytes("Chicken", "Egg", nil, false),
+		"Egg":     makeClassBytes("Egg", "Chicken", nil, false),
+	})
+
+	thrown := -1
+	globals.GetGlobalRef().FuncThrowException = func(which int, msg string) { thrown = which }
+
+	if err := LoadClassHierarchy("Chicken"); err == nil {
+		t.Error("Expected an error loading a circular hierarchy, but got none")
+	}
+	if thrown != excNames.ClassCircularityError {
+		t.Errorf("Expected a ClassCircularityError to be thrown, but got exception %d", thrown)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by  the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package classloader
+
+import (
+	"jacobin/stringPool"
+	"sync/atomic"
+)
+
+// This file contains the cache of resolved CP entries. Resolving a MethodRef or a FieldRef
+// to the names it refers to takes a chain of CP look-ups, which the bytecodes that use
+// these entries (INVOKE*, GETFIELD, PUTSTATIC, etc.) would otherwise repeat every time they
+// execute. So once an entry is resolved, the result is cached in the CP itself, in a slot
+// indexed by the entry's CP index. As each class has its own CP, the cache is in effect
+// per class. Classes can't be redefined, so cached entries never need to be invalidated.
+// Reading a cached entry takes no lock: each slot is an atomic pointer, which a thread that
+// resolves the entry sets. Two threads can resolve the same entry at the same time,
+// but as they resolve it to the same names, it doesn't matter which result is kept.
+
+// ResolvedMethodRef is what a MethodRef CP entry resolves to: the names of the class, the
+// method, and the method type. For a static method, Method holds the method itself, once
+// it has been found and cached by CacheResolvedMethod(); until then, Method.Meth is nil.
+type ResolvedMethodRef struct {
+	ClassName  string
+	MethodName string
+	MethodType string
+	Method     MTentry
+}
+
+// ResolvedFieldRef is what a FieldRef CP entry resolves to: the names of the class, the
+// field, and the field's type
+type ResolvedFieldRef struct {
+	ClassName string
+	FieldName string
+	FieldType string
+}
+
+// InitResolvedRefs makes room in CP for caching its resolved entries, one slot for each
+// entry. It's called once the CP is loaded. Entries of a CP without the slots, such as one
+// assembled by hand, are still resolved, but every time they're used.
+func InitResolvedRefs(CP *CPool) {
+	CP.resolvedMethods = make([]atomic.Pointer[ResolvedMethodRef], len(CP.CpIndex))
+	CP.resolvedFields = make([]atomic.Pointer[ResolvedFieldRef], len(CP.CpIndex))
+}
+
+// cacheSlot returns the slot in cache for the entry at cpIndex, or nil if there's none
+func cacheSlot[T any](cache []atomic.Pointer[T], cpIndex int) *atomic.Pointer[T] {
+	if cpIndex < 0 || cpIndex >= len(cache) {
+		return nil
+	}
+	return &cache[cpIndex]
+}
+
+// ResolveMethodRef returns what the MethodRef at cpIndex in CP resolves to, resolving
+// and caching it on its first use. Returns false if the entry is not a MethodRef.
+// The result can be the cached entry, so it must not be modified.
+func ResolveMethodRef(CP *CPool, cpIndex int) (*ResolvedMethodRef, bool) {
+	slot := cacheSlot(CP.resolvedMethods, cpIndex)
+	if slot != nil {
+		if cached := slot.Load(); cached != nil {
+			return cached, true
+		}
+	}
+
+	className, methName, methType := GetMethInfoFromCPmethref(CP, cpIndex)
+	if className == "" {
+		return nil, false
+	}
+	ref := &ResolvedMethodRef{ClassName: className, MethodName: methName, MethodType: methType}
+	if slot != nil {
+		slot.Store(ref)
+	}
+	return ref, true
+}
+
+// CacheResolvedMethod records the method that the MethodRef at cpIndex in CP refers to,
+// so that later calls of the method needn't look it up. Used only for static methods,
+// as which method a virtual call runs depends on the object it's called on.
+func CacheResolvedMethod(CP *CPool, cpIndex int, mte MTentry) {
+	slot := cacheSlot(CP.resolvedMethods, cpIndex)
+	if slot == nil {
+		return
+	}
+	if cached := slot.Load(); cached != nil {
+		ref := *cached
+		ref.Method = mte
+		slot.Store(&ref)
+	}
+}
+
+// ResolveFieldRef returns what the FieldRef at cpIndex in CP resolves to, resolving
+// and caching it on its first use. Returns false if the entry is not a FieldRef.
+// The result can be the cached entry, so it must not be modified.
+func ResolveFieldRef(CP *CPool, cpIndex int) (*ResolvedFieldRef, bool) {
+	slot := cacheSlot(CP.resolvedFields, cpIndex)
+	if slot != nil {
+		if cached := slot.Load(); cached != nil {
+			return cached, true
+		}
+	}
+
+	if cpIndex < 1 || cpIndex >= len(CP.CpIndex) || CP.CpIndex[cpIndex].Type != FieldRef {
+		return nil, false
+	}
+	field := CP.FieldRefs[CP.CpIndex[cpIndex].Slot]
+	nAndT := CP.NameAndTypes[CP.CpIndex[field.NameAndType].Slot]
+	ref := &ResolvedFieldRef{
+		FieldName: utf8StringAt(CP, nAndT.NameIndex),
+		FieldType: utf8StringAt(CP, nAndT.DescIndex),
+	}
+	if classRef := CP.CpIndex[field.ClassIndex]; int(classRef.Slot) < len(CP.ClassRefs) {
+		ref.ClassName = *stringPool.GetStringPointer(CP.ClassRefs[classRef.Slot])
+	}
+	if slot != nil {
+		slot.Store(ref)
+	}
+	return ref, true
+}
+
+// utf8StringAt returns the string held by the UTF8 entry at cpIndex in CP, or an empty
+// string if there's no such entry
+func utf8StringAt(CP *CPool, cpIndex uint16) string {
+	if int(cpIndex) >= len(CP.CpIndex) || int(CP.CpIndex[cpIndex].Slot) >= len(CP.Utf8Refs) {
+		return ""
+	}
+	return CP.Utf8Refs[CP.CpIndex[cpIndex].Slot]
+}
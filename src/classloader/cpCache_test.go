@@ -0,0 +1,113 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package classloader
+
+import (
+	"jacobin/globals"
+	"jacobin/stringPool"
+	"testing"
+)
+
+// creates a CP with a MethodRef to Counter.inc()V at entry 1 and a FieldRef to
+// Counter.count:I at entry 2
+func makeRefsCP() *CPool {
+	globals.InitGlobals("test")
+	className := "Counter"
+	CP := CPool{}
+	CP.CpIndex = []CpEntry{
+		{Type: 0, Slot: 0},
+		{Type: MethodRef, Slot: 0},   // 1 Counter.inc()V
+		{Type: FieldRef, Slot: 0},    // 2 Counter.count:I
+		{Type: ClassRef, Slot: 0},    // 3 Counter
+		{Type: NameAndType, Slot: 0}, // 4 inc()V
+		{Type: NameAndType, Slot: 1}, // 5 count:I
+		{Type: UTF8, Slot: 0},        // 6 inc
+		{Type: UTF8, Slot: 1},        // 7 ()V
+		{Type: UTF8, Slot: 2},        // 8 count
+		{Type: UTF8, Slot: 3},        // 9 I
+	}
+	CP.MethodRefs = []MethodRefEntry{{ClassIndex: 3, NameAndType: 4}}
+	CP.FieldRefs = []FieldRefEntry{{ClassIndex: 3, NameAndType: 5}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&className)}
+	CP.NameAndTypes = []NameAndTypeEntry{{NameIndex: 6, DescIndex: 7}, {NameIndex: 8, DescIndex: 9}}
+	CP.Utf8Refs = []string{"inc", "()V", "count", "I"}
+	InitResolvedRefs(&CP)
+	return &CP
+}
+
+// a MethodRef is resolved on its first use and later uses get the cached entry
+func TestResolveMethodRefIsCached(t *testing.T) {
+	CP := makeRefsCP()
+
+	ref, ok := ResolveMethodRef(CP, 1)
+	if !ok || ref.ClassName != "Counter" || ref.MethodName != "inc" || ref.MethodType != "()V" {
+		t.Fatalf("ResolveMethodRef: expected Counter.inc()V, got %s.%s%s (%v)",
+			ref.ClassName, ref.MethodName, ref.MethodType, ok)
+	}
+	if CP.resolvedMethods[1].Load() == nil {
+		t.Error("ResolveMethodRef: expected the resolved entry to be cached")
+	}
+
+	// the cached entry, rather than the CP, is used from now on
+	CP.Utf8Refs[0] = "dec"
+	if ref, _ = ResolveMethodRef(CP, 1); ref.MethodName != "inc" {
+		t.Errorf("ResolveMethodRef: expected the cached method name inc, got %s", ref.MethodName)
+	}
+
+	if ref.Method.Meth != nil {
+		t.Error("ResolveMethodRef: expected no method before one is cached")
+	}
+	CacheResolvedMethod(CP, 1, MTentry{MType: 'J', Meth: JmEntry{MaxStack: 3}})
+	if ref, _ = ResolveMethodRef(CP, 1); ref.Method.MType != 'J' || ref.MethodName != "inc" {
+		t.Errorf("CacheResolvedMethod: expected the cached method, got: %v", ref)
+	}
+
+	if _, ok = ResolveMethodRef(CP, 2); ok {
+		t.Error("ResolveMethodRef: expected a FieldRef not to resolve as a MethodRef")
+	}
+}
+
+// a FieldRef is resolved on its first use and later uses get the cached entry
+func TestResolveFieldRefIsCached(t *testing.T) {
+	CP := makeRefsCP()
+
+	ref, ok := ResolveFieldRef(CP, 2)
+	if !ok || ref.ClassName != "Counter" || ref.FieldName != "count" || ref.FieldType != "I" {
+		t.Fatalf("ResolveFieldRef: expected Counter.count:I, got %s.%s:%s (%v)",
+			ref.ClassName, ref.FieldName, ref.FieldType, ok)
+	}
+
+	CP.Utf8Refs[2] = "total"
+	if ref, _ = ResolveFieldRef(CP, 2); ref.FieldName != "count" {
+		t.Errorf("ResolveFieldRef: expected the cached field name count, got %s", ref.FieldName)
+	}
+
+	if _, ok = ResolveFieldRef(CP, 1); ok {
+		t.Error("ResolveFieldRef: expected a MethodRef not to resolve as a FieldRef")
+	}
+	if _, ok = ResolveFieldRef(CP, 42); ok {
+		t.Error("ResolveFieldRef: expected an out-of-range entry not to resolve")
+	}
+}
+
+// the entries of a CP without cache slots are resolved from the CP every time
+func TestResolveWithoutCache(t *testing.T) {
+	CP := makeRefsCP()
+	CP.resolvedMethods, CP.resolvedFields = nil, nil
+
+	if ref, ok := ResolveMethodRef(CP, 1); !ok || ref.MethodName != "inc" {
+		t.Fatalf("ResolveMethodRef: expected Counter.inc()V, got %s.%s%s (%v)",
+			ref.ClassName, ref.MethodName, ref.MethodType, ok)
+	}
+	CacheResolvedMethod(CP, 1, MTentry{MType: 'J', Meth: JmEntry{MaxStack: 3}})
+
+	CP.Utf8Refs[0] = "dec"
+	ref, _ := ResolveMethodRef(CP, 1)
+	if ref.MethodName != "dec" || ref.Method.Meth != nil {
+		t.Errorf("ResolveMethodRef: expected dec()V, resolved anew, got %s (%v)", ref.MethodName, ref.Method)
+	}
+}
@@ -84,7 +84,11 @@ func parseConstantPool(rawBytes []byte, klass *ParsedClass) (int, error) {
 			if length == 0 {
 				content = ""
 			} else {
-				content = string(rawBytes[pos+1 : pos+length+1])
+				var err error
+				content, err = decodeModifiedUTF8(rawBytes[pos+1 : pos+length+1])
+				if err != nil {
+					return pos, cfe("invalid UTF8 string in CP entry #" + strconv.Itoa(i) + ": " + err.Error())
+				}
 			}
 			pos += length
 			utfe := utf8Entry{content}
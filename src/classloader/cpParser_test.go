@@ -21,6 +21,7 @@ import (
 // to the CP entry number for that record:
 // 0 - Dummy entry					TestDummyEntry
 // 1 - UTF							TestCPvalidUTF8Ref
+//   modified UTF-8					TestCPvalidModifiedUTF8Ref, TestCPinvalidModifiedUTF8Ref
 // 3 - IntConst						TestCPvalidIntConst
 // 4 - FloatConst					TestCPvalidFloatConst
 // 5 - LongConst 		 			TestCPvalidLongConst
@@ -884,3 +885,63 @@ func TestPrintOfCPpart2(t *testing.T) {
 	os.Stdout = normalStdout
 	os.Stderr = normalStderr
 }
+
+// UTF8 entries are in modified UTF-8, so an embedded NUL (0xC0 0x80) and a supplementary
+// character (a surrogate pair, here for U+1F600) must be decoded to standard UTF-8
+func TestCPvalidModifiedUTF8Ref(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.WARNING)
+
+	bytesToTest := []byte{
+		0xCA, 0xFE, 0xBA, 0xBE, 0x00,
+		0x00, 0xFF, 0xF0, 0x00, 0x00,
+		0x01, 0x00, 0x0A, 'A', 0xC0,
+		0x80, 'B', 0xED, 0xA0, 0xBD,
+		0xED, 0xB8, 0x80,
+	}
+
+	pc := ParsedClass{}
+	pc.cpCount = 2
+	loc, err := parseConstantPool(bytesToTest, &pc)
+	if err != nil {
+		t.Fatalf("Parsing valid modified UTF-8 CP entry generated an unexpected error: %v", err)
+	}
+
+	if loc != 22 {
+		t.Error("Was expecting a new position of 22, but got: " + strconv.Itoa(loc))
+	}
+
+	if pc.utf8Refs[0].content != "A\x00B\U0001F600" {
+		t.Errorf("Was expecting a UTF-8 string of %q, but got: %q", "A\x00B\U0001F600", pc.utf8Refs[0].content)
+	}
+}
+
+// a raw NUL byte is not allowed in a UTF8 entry: NUL must be encoded as 0xC0 0x80
+func TestCPinvalidModifiedUTF8Ref(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.WARNING)
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+
+	bytesToTest := []byte{
+		0xCA, 0xFE, 0xBA, 0xBE, 0x00,
+		0x00, 0xFF, 0xF0, 0x00, 0x00,
+		0x01, 0x00, 0x03, 'A', 0x00,
+		'B',
+	}
+
+	pc := ParsedClass{}
+	pc.cpCount = 2
+	_, err := parseConstantPool(bytesToTest, &pc)
+
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil {
+		t.Error("Expected an error for a UTF8 entry with a raw NUL byte, but got none")
+	}
+}
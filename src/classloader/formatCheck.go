@@ -64,21 +64,19 @@ func formatCheckConstantPool(klass *ParsedClass) error {
 		entry := klass.cpIndex[j]
 		switch entry.entryType {
 		case UTF8:
-			// points to an entry in utf8Refs, which holds a string. Check for:
-			// * No byte may have the value (byte)0.
-			// * No byte may lie in the range (byte)0xf0 to (byte)0xff
+			// points to an entry in utf8Refs, which holds a string. The string was
+			// decoded from modified UTF-8 when the CP was parsed, which rejected the
+			// bytes that may not appear in a UTF8 entry ((byte)0 and (byte)0xf0 to
+			// (byte)0xff). So here, we check only that the string is validly decoded.
 			whichUtf8 := entry.slot
 			if whichUtf8 < 0 || whichUtf8 >= len(klass.utf8Refs) {
 				return cfe("CP entry #" + strconv.Itoa(j) + "points to invalid UTF8 entry: " +
 					strconv.Itoa(whichUtf8))
 			}
 			utf8string := klass.utf8Refs[whichUtf8].content
-			utf8bytes := []byte(utf8string)
-			for _, char := range utf8bytes {
-				if char == 0x00 || (char >= 0xf0 && char <= 0xff) {
-					return cfe("UTF8 string for CP entry #" + strconv.Itoa(j) +
-						" contains an invalid character")
-				}
+			if !isValidDecodedUTF8(utf8string) {
+				return cfe("UTF8 string for CP entry #" + strconv.Itoa(j) +
+					" contains an invalid character")
 			}
 		case IntConst:
 			// there are no specific format checks for integers, so we only check
@@ -9,12 +9,21 @@ package classloader
 import (
 	"archive/zip"
 	"bytes"
+	"fmt"
 	"io"
 	"jacobin/globals"
 	"jacobin/log"
 	"strings"
 )
 
+// The base classes without which the JVM cannot run. If any of these cannot be loaded,
+// WalkBaseJmod() returns an error. Other base classes are optional: if one cannot be
+// loaded, a warning is logged and it is loaded later, if at all, when it is referenced.
+var requiredBaseClasses = []string{
+	"java/lang/Object.class",
+	"java/lang/String.class",
+}
+
 // Walk the Base Jmod file and invoke ParseAndPostClass for each class found in the classlist
 // Only called in one place: LoadBaseClasses.
 func WalkBaseJmod() error {
@@ -31,6 +40,7 @@ func WalkBaseJmod() error {
 	// Get the lib/classlist (bootstrap set of classes) if it exists
 	bootstrapSet := getClasslist(*zipReader)
 	useBootstrapSet := len(bootstrapSet) > 0
+	loaded := make(map[string]bool) // the classes that have been loaded, by file name
 
 	// For each class file in the base jmod,
 	// if it is in the classlist
@@ -49,32 +59,69 @@ func WalkBaseJmod() error {
 
 		// Is there a bootstrap list?
 		if useBootstrapSet {
-			// Yes, make sure that this class is on the list
+			// Yes, make sure that this class is on the list or is required
 			_, onList := bootstrapSet[strapFileName]
-			if !onList {
+			if !onList && !isRequiredBaseClass(strapFileName) {
 				continue
 			}
 		}
 
-		// Open the class file
-		rc, err := classFile.Open()
+		// Open, read, parse, and post the class into the MethArea
+		err = loadBaseClass(classFile)
 		if err != nil {
-			return err
+			if isRequiredBaseClass(strapFileName) {
+				return fmt.Errorf("required base class %s could not be loaded: %w",
+					strings.TrimSuffix(strapFileName, ".class"), err)
+			}
+			_ = log.Log(fmt.Sprintf("WalkBaseJmod: optional base class %s could not be loaded: %s",
+				strings.TrimSuffix(strapFileName, ".class"), err.Error()), log.WARNING)
+			continue
 		}
+		loaded[strapFileName] = true
+	}
 
-		// Read all of the bytes
-		classBytes, err := io.ReadAll(rc)
-		if err != nil {
-			return err
+	// Make sure that the required classes were found in the jmod file.
+	// Optional classes on the classlist that were not found are simply noted.
+	for _, className := range requiredBaseClasses {
+		if !loaded[className] {
+			return fmt.Errorf("required base class %s was not found in the jmod file",
+				strings.TrimSuffix(className, ".class"))
 		}
-		_ = rc.Close()
+	}
+	for className := range bootstrapSet {
+		if !loaded[className] && className != ".class" {
+			_ = log.Log("WalkBaseJmod: base class "+strings.TrimSuffix(className, ".class")+
+				" is on the classlist but was not loaded", log.CLASS)
+		}
+	}
+
+	return nil
+}
 
-		// Parse and post class into MethArea
-		ParseAndPostClass(&BootstrapCL, classFile.Name, classBytes)
+// loadBaseClass reads a class file from the base jmod file and posts it to the MethArea
+func loadBaseClass(classFile *zip.File) error {
+	rc, err := classFile.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
 
+	classBytes, err := io.ReadAll(rc)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	_, err = ParseAndPostClass(&BootstrapCL, classFile.Name, classBytes)
+	return err
+}
+
+func isRequiredBaseClass(strapFileName string) bool {
+	for _, className := range requiredBaseClasses {
+		if className == strapFileName {
+			return true
+		}
+	}
+	return false
 }
 
 // getClasslist returns the bootstrap lib/classlist as a Go-language map from the Java installation.
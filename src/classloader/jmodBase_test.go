@@ -0,0 +1,102 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)
+ */
+
+package classloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"jacobin/globals"
+	"jacobin/log"
+	"strings"
+	"testing"
+)
+
+// makeBaseJmod sets JmodBaseBytes to a jmod file holding the files, which are named as in java.base.jmod
+func makeBaseJmod(t *testing.T, files map[string][]byte) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, contents := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal("Unable to add file to jmod", err)
+		}
+		_, _ = w.Write(contents)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal("Unable to write jmod", err)
+	}
+	globals.GetGlobalRef().JmodBaseBytes = append([]byte{'J', 'M', 0x01, 0x00}, buf.Bytes()...)
+}
+
+func TestWalkBaseJmodSkipsOptionalClass(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	InitMethodArea()
+
+	// java/util/Optional is on the classlist, but its class file is corrupt,
+	// and java/util/Missing is on the classlist, but not in the jmod file
+	makeBaseJmod(t, map[string][]byte{
+		"lib/classlist":                    []byte("java/lang/Object\njava/lang/String\njava/util/Optional\njava/util/Missing\n"),
+		"classes/java/lang/Object.class":   makeClassBytes("java/lang/Object", "java/lang/Object", nil, false),
+		"classes/java/lang/String.class":   makeClassBytes("java/lang/String", "java/lang/Object", nil, false),
+		"classes/java/util/Optional.class": {0xCA, 0xFE, 0xBA, 0xBE},
+	})
+
+	if err := WalkBaseJmod(); err != nil {
+		t.Fatalf("WalkBaseJmod(): expected a missing optional class to be skipped, got: %s", err.Error())
+	}
+	for _, className := range []string{"java/lang/Object", "java/lang/String"} {
+		if MethAreaFetch(className) == nil {
+			t.Errorf("WalkBaseJmod(): expected %s to have been loaded", className)
+		}
+	}
+	if MethAreaFetch("java/util/Optional") != nil {
+		t.Errorf("WalkBaseJmod(): expected java/util/Optional not to have been loaded")
+	}
+}
+
+func TestWalkBaseJmodRequiredClass(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	InitMethodArea()
+
+	// java/lang/String is required, even though the classlist omits it
+	makeBaseJmod(t, map[string][]byte{
+		"lib/classlist":                  []byte("java/lang/Object\n"),
+		"classes/java/lang/Object.class": makeClassBytes("java/lang/Object", "java/lang/Object", nil, false),
+		"classes/java/lang/String.class": makeClassBytes("java/lang/String", "java/lang/Object", nil, false),
+	})
+	if err := WalkBaseJmod(); err != nil {
+		t.Fatalf("WalkBaseJmod(): unexpected error: %s", err.Error())
+	}
+	if MethAreaFetch("java/lang/String") == nil {
+		t.Errorf("WalkBaseJmod(): expected java/lang/String to have been loaded")
+	}
+
+	// a required class that cannot be loaded is reported by name
+	InitMethodArea()
+	makeBaseJmod(t, map[string][]byte{
+		"classes/java/lang/Object.class": makeClassBytes("java/lang/Object", "java/lang/Object", nil, false),
+		"classes/java/lang/String.class": {0xCA, 0xFE},
+	})
+	err := WalkBaseJmod()
+	if err == nil || !strings.Contains(err.Error(), "java/lang/String") {
+		t.Errorf("WalkBaseJmod(): expected an error naming java/lang/String, got: %v", err)
+	}
+
+	// as is a required class that is missing
+	InitMethodArea()
+	makeBaseJmod(t, map[string][]byte{
+		"classes/java/lang/String.class": makeClassBytes("java/lang/String", "java/lang/Object", nil, false),
+	})
+	err = WalkBaseJmod()
+	if err == nil || !strings.Contains(err.Error(), "java/lang/Object was not found") {
+		t.Errorf("WalkBaseJmod(): expected an error naming java/lang/Object, got: %v", err)
+	}
+}
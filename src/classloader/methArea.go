@@ -83,8 +83,9 @@ func MethAreaFetch(key string) *Klass {
 func MethAreaInsert(name string, klass *Klass) {
 	_ = log.Log("MethAreaInsert: key("+name+")", log.CLASS)
 	MethAreaMutex.Lock()
-	MethArea.Store(name, klass)
-	methAreaSize++
+	if _, replaced := MethArea.Swap(name, klass); !replaced { // a class loaded twice is counted once
+		methAreaSize++
+	}
 	MethAreaMutex.Unlock()
 
 	if klass.Status == 'F' || klass.Status == 'V' || klass.Status == 'L' {
@@ -105,12 +106,11 @@ func MethAreaSize() int {
 // MethAreaDelete deletes an entry in the method area
 // **at present, it is used only in testing **
 func MethAreaDelete(key string) {
-	if MethAreaFetch(key) != nil {
-		MethAreaMutex.Lock()
-		MethArea.Delete(key)
+	MethAreaMutex.Lock()
+	if _, deleted := MethArea.LoadAndDelete(key); deleted {
 		methAreaSize--
-		MethAreaMutex.Unlock()
 	}
+	MethAreaMutex.Unlock()
 }
 
 // Wait for klass.Status to no longer be 'I' (I = initializing)
@@ -143,10 +143,12 @@ func MethAreaDump() {
 	var entries []string
 	_ = log.Log("MethAreaDump: ", log.CLASS)
 
+	MethAreaMutex.RLock()
 	MethArea.Range(func(key, value interface{}) bool {
 		entries = append(entries, key.(string))
 		return true
 	})
+	MethAreaMutex.RUnlock()
 	sort.Strings(entries)
 	fmt.Println("---- start of method area dump ----")
 	for _, str := range entries {
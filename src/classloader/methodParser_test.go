@@ -95,6 +95,62 @@ func TestValidCodeMethodAttribute(t *testing.T) {
 		t.Error("Expected 0 attributes of Code attribute. Got: " + strconv.Itoa(len(meth.codeAttr.attributes)))
 	}
 }
+
+// max_stack and max_locals are u2 values, so both bytes of each must be used
+func TestCodeAttributeMaxStackAndMaxLocals(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+
+	klass := ParsedClass{}
+	klass.cpIndex = append(klass.cpIndex, cpEntry{})
+	klass.cpIndex = append(klass.cpIndex, cpEntry{UTF8, 0})
+	klass.utf8Refs = append(klass.utf8Refs, utf8Entry{"Code"})
+	klass.utf8Refs = append(klass.utf8Refs, utf8Entry{"testMethod"})
+	klass.cpCount = 2
+
+	meth := method{}
+	meth.name = 1
+
+	attrib := attr{}
+	attrib.attrName = 1
+	attrib.attrContent = []byte{
+		0x01, 0x02, // maxstack = 258
+		0x02, 0x03, // maxlocals = 515
+		0, 0, 0, 1, // code length = 1
+		0xB1, // return
+		0, 0, // number of exceptions = 0
+		0, 0, // attribute count of Code attribute = 0
+	}
+
+	err := parseCodeAttribute(attrib, &meth, &klass)
+	if err != nil {
+		t.Errorf("Unexpected error parsing valid Code attribute: %v", err)
+	}
+	if meth.codeAttr.maxStack != 258 {
+		t.Errorf("Expected maxStack of 258. Got: %d", meth.codeAttr.maxStack)
+	}
+	if meth.codeAttr.maxLocals != 515 {
+		t.Errorf("Expected maxLocals of 515. Got: %d", meth.codeAttr.maxLocals)
+	}
+
+	// a Code attribute that ends after max_stack is missing max_locals
+	meth = method{}
+	meth.name = 1
+	attrib.attrContent = []byte{0x01, 0x02, 0x02}
+	err = parseCodeAttribute(attrib, &meth, &klass)
+
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil {
+		t.Error("Expected an error parsing a Code attribute without max_locals, got none")
+	}
+}
+
 func Test1ValidMethodExceptionsAttribute(t *testing.T) {
 	globals.InitGlobals("test")
 	log.Init()
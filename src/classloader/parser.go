@@ -662,6 +662,44 @@ func parseClassAttributes(bytes []byte, loc int, klass *ParsedClass) (int, error
 		case "Deprecated":
 			klass.deprecated = true
 
+		case "InnerClasses":
+			// see: https://docs.oracle.com/javase/specs/jvms/se17/html/jvms-4.html#jvms-4.7.6
+			innerClassCount, err1 := intFrom2Bytes(attrib.attrContent, 0)
+			if err1 != nil || len(attrib.attrContent) < 2+(innerClassCount*8) {
+				return pos, cfe("Invalid InnerClasses attribute in class: " + klass.className)
+			}
+			loc = 2
+			for m := 0; m < innerClassCount; m++ {
+				innerIndex, _ := intFrom2Bytes(attrib.attrContent, loc)
+				outerIndex, _ := intFrom2Bytes(attrib.attrContent, loc+2)
+				simpleNameIndex, _ := intFrom2Bytes(attrib.attrContent, loc+4)
+				accessFlags, _ := intFrom2Bytes(attrib.attrContent, loc+6)
+				loc += 8
+
+				inner := innerClass{accessFlags: accessFlags}
+				inner.name, err = classNameFromCPEntry(klass, innerIndex)
+				if err != nil || inner.name == "" {
+					return pos, cfe("Invalid inner class #" + strconv.Itoa(m) +
+						" in InnerClasses attribute of class: " + klass.className)
+				}
+				if outerIndex != 0 { // 0 for local and anonymous classes
+					inner.outerClass, err = classNameFromCPEntry(klass, outerIndex)
+					if err != nil {
+						return pos, cfe("Invalid outer class of " + inner.name +
+							" in InnerClasses attribute of class: " + klass.className)
+					}
+				}
+				if simpleNameIndex != 0 { // 0 for anonymous classes
+					if simpleNameIndex >= len(klass.cpIndex) || klass.cpIndex[simpleNameIndex].entryType != UTF8 {
+						return pos, cfe("Invalid simple name of " + inner.name +
+							" in InnerClasses attribute of class: " + klass.className)
+					}
+					inner.simpleName = klass.utf8Refs[klass.cpIndex[simpleNameIndex].slot].content
+				}
+				klass.innerClasses = append(klass.innerClasses, inner)
+			}
+			_ = log.Log("    "+strconv.Itoa(innerClassCount)+" inner class(es)", log.FINEST)
+
 		case "SourceFile":
 			sourceNameIndex, _ := intFrom2Bytes(attrib.attrContent, 0)
 			utf8slot := klass.cpIndex[sourceNameIndex].slot
@@ -672,3 +710,16 @@ func parseClassAttributes(bytes []byte, loc int, klass *ParsedClass) (int, error
 	}
 	return pos, nil
 }
+
+// classNameFromCPEntry returns the name of the class referred to by the ClassRef at the
+// given index in the CP, or an error if the index does not point to a ClassRef
+func classNameFromCPEntry(klass *ParsedClass, index int) (string, error) {
+	if index < 1 || index >= len(klass.cpIndex) || klass.cpIndex[index].entryType != ClassRef {
+		return "", errors.New("CP entry " + strconv.Itoa(index) + " is not a class reference")
+	}
+	namePtr := stringPool.GetStringPointer(klass.classRefs[klass.cpIndex[index].slot])
+	if namePtr == nil {
+		return "", errors.New("CP entry " + strconv.Itoa(index) + " has no class name")
+	}
+	return *namePtr, nil
+}
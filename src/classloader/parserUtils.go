@@ -10,6 +10,8 @@ import (
 	"errors"
 	"jacobin/stringPool"
 	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // various utilities frequently used in parsing classfiles
@@ -46,6 +48,94 @@ func intFrom4Bytes(bytes []byte, pos int) (int, error) {
 	return retVal, nil
 }
 
+// decodeModifiedUTF8 converts the bytes of a CP UTF8 entry, which are in the JVM's
+// modified UTF-8, to a Go string in standard UTF-8. Modified UTF-8 differs in two ways:
+// NUL is encoded in two bytes (0xC0 0x80), and supplementary characters are encoded as
+// a surrogate pair (as in CESU-8), with each surrogate in three bytes. A lone surrogate,
+// which Java strings allow, is kept in its three-byte encoding. Consult:
+// https://docs.oracle.com/javase/specs/jvms/se17/html/jvms-4.html#jvms-4.4.7
+func decodeModifiedUTF8(bytes []byte) (string, error) {
+	plainASCII := true
+	for _, b := range bytes {
+		if b == 0x00 || b >= 0x80 {
+			plainASCII = false
+			break
+		}
+	}
+	if plainASCII { // the usual case, so skip the decoding
+		return string(bytes), nil
+	}
+
+	isContinuation := func(pos int) bool {
+		return pos < len(bytes) && bytes[pos]&0xC0 == 0x80
+	}
+
+	decoded := make([]byte, 0, len(bytes))
+	for i := 0; i < len(bytes); {
+		b := bytes[i]
+		switch {
+		case b == 0x00 || b >= 0xF0:
+			return "", errors.New("invalid byte 0x" + strconv.FormatInt(int64(b), 16) +
+				" at position " + strconv.Itoa(i))
+		case b < 0x80:
+			decoded = append(decoded, b)
+			i += 1
+		case b < 0xC0:
+			return "", errors.New("continuation byte without a leading byte at position " +
+				strconv.Itoa(i))
+		case b < 0xE0: // two bytes, which includes NUL as 0xC0 0x80
+			if !isContinuation(i + 1) {
+				return "", errors.New("truncated character at position " + strconv.Itoa(i))
+			}
+			r := rune(b&0x1F)<<6 | rune(bytes[i+1]&0x3F)
+			decoded = utf8.AppendRune(decoded, r)
+			i += 2
+		default: // three bytes
+			if !isContinuation(i+1) || !isContinuation(i+2) {
+				return "", errors.New("truncated character at position " + strconv.Itoa(i))
+			}
+			r := rune(b&0x0F)<<12 | rune(bytes[i+1]&0x3F)<<6 | rune(bytes[i+2]&0x3F)
+			if !utf16.IsSurrogate(r) {
+				decoded = utf8.AppendRune(decoded, r)
+				i += 3
+				continue
+			}
+
+			// a high surrogate followed by a low surrogate is a supplementary character
+			if r < 0xDC00 && i+3 < len(bytes) && bytes[i+3]&0xF0 == 0xE0 &&
+				isContinuation(i+4) && isContinuation(i+5) {
+				low := rune(bytes[i+3]&0x0F)<<12 | rune(bytes[i+4]&0x3F)<<6 | rune(bytes[i+5]&0x3F)
+				if low >= 0xDC00 && low <= 0xDFFF {
+					decoded = utf8.AppendRune(decoded, utf16.DecodeRune(r, low))
+					i += 6
+					continue
+				}
+			}
+			decoded = append(decoded, bytes[i:i+3]...) // a lone surrogate
+			i += 3
+		}
+	}
+	return string(decoded), nil
+}
+
+// isValidDecodedUTF8 checks a string decoded by decodeModifiedUTF8(): it must be valid
+// UTF-8, except for lone surrogates, which are kept in their three-byte encoding
+func isValidDecodedUTF8(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			if i+2 < len(s) && s[i] == 0xED && s[i+1] >= 0xA0 && s[i+1] <= 0xBF &&
+				s[i+2]&0xC0 == 0x80 {
+				i += 3
+				continue
+			}
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
 // finds and returns a UTF8 string when handed an index into the CP that points
 // to a UTF8 entry. Does extensive checking of values.
 func FetchUTF8string(klass *ParsedClass, index int) (string, error) {
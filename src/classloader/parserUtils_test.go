@@ -609,3 +609,30 @@ func TestResolveCPnameAndType(t *testing.T) {
 		t.Error("Expected different error msg on failed resolution of CPnameAndType. Got: " + msg)
 	}
 }
+
+// decoding of modified UTF-8: a lone surrogate is kept in its three-byte encoding,
+// which isValidDecodedUTF8() accepts, while truncated characters are errors
+func TestDecodeModifiedUTF8(t *testing.T) {
+	loneSurrogate := []byte{'a', 0xED, 0xA0, 0xBD, 'b'}
+	s, err := decodeModifiedUTF8(loneSurrogate)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding a lone surrogate: %v", err)
+	}
+	if s != string(loneSurrogate) {
+		t.Errorf("Expected the lone surrogate to be kept as is, got: %q", s)
+	}
+	if !isValidDecodedUTF8(s) {
+		t.Error("Expected a decoded lone surrogate to be valid")
+	}
+
+	if s, _ = decodeModifiedUTF8([]byte{0xC3, 0xA9, 0xE2, 0x82, 0xAC}); s != "é€" {
+		t.Errorf("Expected the two- and three-byte characters é€, got: %q", s)
+	}
+
+	if _, err = decodeModifiedUTF8([]byte{'a', 0xE2, 0x82}); err == nil {
+		t.Error("Expected an error decoding a truncated character, but got none")
+	}
+	if isValidDecodedUTF8("Bad\xFA") {
+		t.Error("Expected a string with the byte 0xFA to be invalid")
+	}
+}
@@ -1353,3 +1353,77 @@ func TestDeprecatedClassAttribute(t *testing.T) {
 	_ = wout.Close()
 	os.Stdout = normalStdout
 }
+
+// The SourceFile attribute names the class's source file, which is carried over into
+// the class data posted to the method area and shown in stack traces
+func TestSourceFileClassAttribute(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	klass := ParsedClass{}
+	klass.cpIndex = append(klass.cpIndex, cpEntry{})
+	klass.cpIndex = append(klass.cpIndex, cpEntry{UTF8, 0}) // UTF-8 rec w/ attribute name
+	klass.cpIndex = append(klass.cpIndex, cpEntry{UTF8, 1}) // UTF-8 rec w/ source file name
+	klass.utf8Refs = append(klass.utf8Refs, utf8Entry{"SourceFile"})
+	klass.utf8Refs = append(klass.utf8Refs, utf8Entry{"Hello.java"})
+	klass.cpCount = 3
+	klass.attribCount = 1
+
+	bytes := []byte{00, // dummy byte
+		00, 01, // CP[1] -> UTF8[0] -> "SourceFile"
+		00, 00, 00, 02, // length of attribute (always 2 for 'SourceFile')
+		00, 02} // CP[2] -> UTF8[1] -> "Hello.java"
+
+	_, err := parseClassAttributes(bytes, 0, &klass)
+	if err != nil {
+		t.Errorf("Unexpected error in test of parseClassAttributes(): %s", err.Error())
+	}
+
+	if klass.sourceFile != "Hello.java" {
+		t.Errorf("Expected source file Hello.java, got: %s", klass.sourceFile)
+	}
+
+	clData := convertToPostableClass(&klass)
+	if clData.SourceFile != "Hello.java" {
+		t.Errorf("Expected posted class's source file to be Hello.java, got: %s", clData.SourceFile)
+	}
+}
+
+// an InnerClasses entry must name its inner class with a class reference
+func TestInnerClassesAttributeWithInvalidInnerClass(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+
+	klass := ParsedClass{}
+	klass.cpIndex = append(klass.cpIndex, cpEntry{})
+	klass.cpIndex = append(klass.cpIndex, cpEntry{UTF8, 0}) // UTF-8 rec w/ attribute name
+	klass.utf8Refs = append(klass.utf8Refs, utf8Entry{"InnerClasses"})
+	klass.cpCount = 2
+	klass.attribCount = 1
+
+	bytes := []byte{00, // dummy byte
+		00, 01, // CP[1] -> UTF8[0] -> "InnerClasses"
+		00, 00, 00, 10, // length of attribute
+		00, 01, // number of inner classes
+		00, 01, // inner class: CP[1], which is not a class reference
+		00, 00, // outer class: none
+		00, 00, // simple name: none
+		00, 00} // access flags
+
+	_, err := parseClassAttributes(bytes, 0, &klass)
+
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil {
+		t.Error("Expected an error for an InnerClasses entry that is not a class reference, but got none")
+	}
+	if len(klass.innerClasses) != 0 {
+		t.Errorf("Expected no inner classes to be recorded, got %d", len(klass.innerClasses))
+	}
+}
@@ -76,6 +76,7 @@ const (
 	MalformedParameterizedTypeException
 	MalformedParametersException // for HotSpot reflection: param count wrong, CP index invalid, illegal flag combo
 	MirroredTypesException
+	MissingFormatArgumentException
 	MissingResourceException
 	NativeMethodException
 	NegativeArraySizeException
@@ -98,8 +99,10 @@ const (
 	UncheckedIOException
 	UndeclaredThrowableException
 	UnknownEntityException
+	UnknownFormatConversionException
 	UnmodifiableModuleException
 	UnmodifiableSetException
+	UnsupportedCharsetException
 	UnsupportedOperationException
 	UserPrincipalNotFoundException
 	VMDisconnectedException
@@ -195,6 +198,7 @@ const (
 	XMLStreamException
 
 	// Java errors
+	AbstractMethodError // for calls of methods that have no implementation
 	AnnotationFormatError
 	AssertionError
 	AWTError
@@ -297,6 +301,7 @@ var JVMexceptionNames = []string{
 	"java.lang.reflect.MalformedParameterizedTypeException",  // VERIFIED
 	"java.lang.reflect.MalformedParametersException",         // VERIFIED
 	"javax.lang.model.type.MirroredTypesException",           // VERIFIED
+	"java.util.MissingFormatArgumentException",               // VERIFIED
 	"java.util.MissingResourceException",                     // VERIFIED
 	"com.sun.jdi.NativeMethodException",                      // VERIFIED
 	"java.lang.NegativeArraySizeException",                   // VERIFIED
@@ -319,8 +324,10 @@ var JVMexceptionNames = []string{
 	"java.io.UncheckedIOException",                           // VERIFIED
 	"java.lang.reflect.UndeclaredThrowableException",         // VERIFIED
 	"javax.lang.model.UnknownEntityException",                // VERIFIED
+	"java.util.UnknownFormatConversionException",             // VERIFIED
 	"java.lang.instrument.UnmodifiableModuleException",       // VERIFIED
 	"javax.print.attribute.UnmodifiableSetException",         // VERIFIED
+	"java.nio.charset.UnsupportedCharsetException",           // VERIFIED
 	"java.lang.UnsupportedOperationException",                // VERIFIED
 	"java.nio.file.attribute.UserPrincipalNotFoundException", // VERIFIED
 	"com.sun.jdi.VMDisconnectedException",                    // VERIFIED
@@ -416,6 +423,7 @@ var JVMexceptionNames = []string{
 	"javax.xml.stream.XMLStreamException",                       // VERIFIED
 
 	// Java errors
+	"java.lang.AbstractMethodError",                            // VERIFIED
 	"java.lang.annotation.AnnotationFormatError",               // VERIFIED
 	"java.lang.AssertionError",                                 // VERIFIED
 	"java.awt.AWTError",                                        // VERIFIED
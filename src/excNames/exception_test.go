@@ -18,6 +18,7 @@ func details(t *testing.T, index int, expected string) {
 func TestExceptionTableAlignment(t *testing.T) {
 	details(t, IllegalArgumentException, "java.lang.IllegalArgumentException")
 	details(t, NoSuchDynamicMethodException, "jdk.dynalink.NoSuchDynamicMethodException")
+	details(t, UnsupportedCharsetException, "java.nio.charset.UnsupportedCharsetException")
 	details(t, WrongMethodTypeException, "java.lang.invoke.WrongMethodTypeException")
 	details(t, ClassNotLoadedException, "com.sun.jdi.ClassNotLoadedException")
 	details(t, InvalidTypeException, "com.sun.jdi.InvalidTypeException")
@@ -26,4 +27,6 @@ func TestExceptionTableAlignment(t *testing.T) {
 	details(t, XMLParseException, "javax.management.modelmbean.XMLParseException")
 	details(t, VirtualMachineError, "java.lang.VirtualMachineError")
 	details(t, UTFDataFormatException, "java.io.UTFDataFormatException")
+	details(t, MissingFormatArgumentException, "java.util.MissingFormatArgumentException")
+	details(t, UnknownFormatConversionException, "java.util.UnknownFormatConversionException")
 }
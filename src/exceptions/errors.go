@@ -12,6 +12,7 @@ import (
 	"jacobin/frames"
 	"jacobin/globals"
 	"jacobin/log"
+	"jacobin/object"
 	"jacobin/thread"
 	"runtime/debug"
 	"strings"
@@ -66,9 +67,24 @@ func GrabFrameStack(fs *list.List) *[]string {
 		entry := fmt.Sprintf("Method: %-40s PC: %03d", methName, val.PC)
 		stackListing = append(stackListing, entry)
 	}
+	stackListing = TruncateStackTrace(stackListing)
 	return &stackListing
 }
 
+// TruncateStackTrace limits a stack trace to the number of lines set by
+// -XX:MaxJavaStackTraceDepth (0 means no limit). If lines are left out, a final
+// line, indented like the others, states how many frames were omitted.
+func TruncateStackTrace(lines []string) []string {
+	maxDepth := globals.GetGlobalRef().MaxJavaStackTraceDepth
+	if maxDepth <= 0 || len(lines) <= maxDepth {
+		return lines
+	}
+	last := lines[maxDepth-1]
+	indent := last[:len(last)-len(strings.TrimLeft(last, " \t"))]
+	return append(lines[:maxDepth:maxDepth],
+		fmt.Sprintf("%s... %d frames omitted", indent, len(lines)-maxDepth))
+}
+
 // takes the panic cause (as returned by the golang runtime) and prints the
 // cause as determined by the runtime. Not sure it could ever be nil, but
 // covering our bases nonetheless.
@@ -150,3 +166,24 @@ func GetExceptionNameFromClassName(className string) string {
 
 	return excName
 }
+
+// SourceLocation returns the location shown in parentheses in a stack trace for the
+// given StackTraceElement, following the JDK's StackTraceElement.toString(): the source
+// file and line, just the source file if the line is not known, "Unknown Source" if the
+// class has no SourceFile attribute, and "Native Method" for native methods.
+func SourceLocation(ste *object.Object) string {
+	if lineNumber, ok := ste.FieldTable["lineNumber"].Fvalue.(int64); ok && lineNumber == -2 {
+		return "Native Method"
+	}
+
+	fileName, _ := ste.FieldTable["fileName"].Fvalue.(string)
+	if fileName == "" {
+		return "Unknown Source"
+	}
+
+	sourceLine, _ := ste.FieldTable["sourceLine"].Fvalue.(string)
+	if sourceLine == "" {
+		return fileName
+	}
+	return fileName + ":" + sourceLine
+}
@@ -13,6 +13,7 @@ import (
 	"jacobin/frames"
 	"jacobin/globals"
 	"jacobin/log"
+	"jacobin/object"
 	"jacobin/thread"
 	"os"
 	"runtime/debug"
@@ -370,3 +371,32 @@ func TestShowPanicCauseNil(t *testing.T) {
 		t.Errorf("Got unexpected message for nil panic cause: %s", errMsg)
 	}
 }
+
+// the location in parentheses in a stack trace line reports the source file and line
+// as available, and "Unknown Source" for classes compiled without a SourceFile attribute
+func TestSourceLocation(t *testing.T) {
+	tests := []struct {
+		fileName   string
+		sourceLine string
+		lineNumber int64
+		expected   string
+	}{
+		{"Hello.java", "12", 12, "Hello.java:12"},
+		{"Hello.java", "", -1, "Hello.java"},
+		{"", "12", 12, "Unknown Source"},
+		{"", "", -1, "Unknown Source"},
+		{"Hello.java", "", -2, "Native Method"},
+	}
+
+	for _, test := range tests {
+		ste := object.MakeEmptyObject()
+		ste.FieldTable["fileName"] = object.Field{Fvalue: test.fileName}
+		ste.FieldTable["sourceLine"] = object.Field{Fvalue: test.sourceLine}
+		ste.FieldTable["lineNumber"] = object.Field{Fvalue: test.lineNumber}
+
+		if location := SourceLocation(ste); location != test.expected {
+			t.Errorf("SourceLocation(%q, %q, %d): expected %q, got %q",
+				test.fileName, test.sourceLine, test.lineNumber, test.expected, location)
+		}
+	}
+}
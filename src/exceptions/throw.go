@@ -139,7 +139,8 @@ func ThrowEx(which int, msg string, f *frames.Frame) bool {
 	stackTrace := throwObj.FieldTable["stackTrace"].Fvalue.(*object.Object)
 	traceEntries := stackTrace.FieldTable["value"].Fvalue.([]*object.Object)
 
-	// now print out the JVM stack
+	// now print out the JVM stack, up to -XX:MaxJavaStackTraceDepth lines
+	var traceLines []string
 	for _, traceEntry := range traceEntries {
 		// HotSpot uses a slightly different format for method names:
 		// package.class.method, we prefer package/class.method, so we format
@@ -152,11 +153,13 @@ func ThrowEx(which int, msg string, f *frames.Frame) bool {
 			declaringClass = traceEntry.FieldTable["declaringClass"].Fvalue.(string)
 		}
 
-		traceInfo := fmt.Sprintf("  at %s.%s(%s:%s)",
+		traceInfo := fmt.Sprintf("  at %s.%s(%s)",
 			declaringClass,
 			traceEntry.FieldTable["methodName"].Fvalue.(string),
-			traceEntry.FieldTable["fileName"].Fvalue.(string),
-			traceEntry.FieldTable["sourceLine"].Fvalue.(string))
+			SourceLocation(traceEntry))
+		traceLines = append(traceLines, traceInfo)
+	}
+	for _, traceInfo := range TruncateStackTrace(traceLines) {
 		fmt.Fprintln(os.Stderr, traceInfo)
 	}
 
@@ -10,6 +10,7 @@ import (
 	"container/list"
 	"fmt"
 	"jacobin/log"
+	"sync"
 	"unsafe"
 )
 
@@ -57,18 +58,27 @@ func CreateFrameStack() *list.List {
 	return l
 }
 
+// framePool holds the frames released by ReleaseFrame() for reuse by CreateFrame().
+// Every method call needs a frame, so reusing frames, along with their op stacks and
+// local variables, spares Go's garbage collector in call-heavy programs.
+var framePool = sync.Pool{New: func() any { return new(Frame) }}
+
 // CreateFrame creates a raw frame and allocates an opStack of the passed-in size.
+// The frame is taken from the pool of released frames, if there's one.
 func CreateFrame(opStackSize int) *Frame {
-	fram := Frame{}
-	// fram.OpStack = *new(opStack)
+	fram := framePool.Get().(*Frame)
 
 	if opStackSize < 0 { // TODO: Check if this is possible. If so, decide what to do. Class is clearly malformed.
 		opStackSize = 0
 	}
 
-	// allocate the operand stack
-	for j := 0; j < opStackSize; j++ {
-		fram.OpStack = append(fram.OpStack, 0)
+	// allocate the operand stack, reusing a released frame's if it's large enough
+	if cap(fram.OpStack) < opStackSize {
+		fram.OpStack = make([]interface{}, opStackSize)
+	}
+	fram.OpStack = fram.OpStack[:opStackSize]
+	for j := range fram.OpStack {
+		fram.OpStack[j] = 0
 	}
 
 	// set top of stack to an empty stack
@@ -76,7 +86,21 @@ func CreateFrame(opStackSize int) *Frame {
 
 	fram.PC = 0
 	fram.ExceptionPC = -1
-	return &fram
+	return fram
+}
+
+// ReleaseFrame puts a frame that has been popped off its frame stack, and to which
+// nothing refers any longer, into the pool of frames that CreateFrame() reuses. The
+// frame is cleared, so that it holds no references to objects or to the method it ran.
+// Its op stack and local variables keep their capacity for reuse.
+func ReleaseFrame(f *Frame) {
+	clear(f.OpStack)
+	clear(f.Locals)
+	*f = Frame{
+		OpStack: f.OpStack[:0],
+		Locals:  f.Locals[:0],
+	}
+	framePool.Put(f)
 }
 
 // PushFrame pushes a frame. This simply adds a frame to the head of the list.
@@ -52,3 +52,46 @@ func TestFramePeek(t *testing.T) {
 		t.Errorf("Peeked at prior frame. Expected size of opstack to be 1, got: %d", len(peek.OpStack))
 	}
 }
+
+// a released frame is reused by CreateFrame() without any of the state it had
+func TestReleasedFrameIsCleared(t *testing.T) {
+	obj := &struct{ name string }{"held"}
+	f := CreateFrame(4)
+	f.Thread = 3
+	f.ClName = "Counter"
+	f.MethName = "inc"
+	f.MethType = "()V"
+	f.Meth = []byte{0x00, 0xB1}
+	f.CP = obj
+	f.Locals = append(f.Locals, obj, int64(7))
+	f.OpStack[0] = obj
+	f.TOS = 0
+	f.PC = 1
+	f.ExceptionPC = 1
+	f.Ftype = 'J'
+	ReleaseFrame(f)
+
+	for i := 0; i < 4; i++ { // the pool may not hand back the released frame at once
+		g := CreateFrame(2)
+		if g.Thread != 0 || g.ClName != "" || g.MethName != "" || g.MethType != "" ||
+			g.Meth != nil || g.CP != nil || g.Ftype != 0 {
+			t.Errorf("CreateFrame: expected a cleared frame, got: %+v", g)
+		}
+		if len(g.Locals) != 0 || len(g.OpStack) != 2 || g.OpStack[0] != 0 || g.OpStack[1] != 0 {
+			t.Errorf("CreateFrame: expected no locals and an empty op stack of 2, got: %v, %v",
+				g.Locals, g.OpStack)
+		}
+		if g.TOS != -1 || g.PC != 0 || g.ExceptionPC != -1 {
+			t.Errorf("CreateFrame: expected TOS -1, PC 0, and ExceptionPC -1, got: %d, %d, %d",
+				g.TOS, g.PC, g.ExceptionPC)
+		}
+		if g == f && cap(g.Locals) < 2 {
+			t.Error("CreateFrame: expected the released frame to keep the capacity of its locals")
+		}
+	}
+
+	// the released frame's slots no longer refer to what they held
+	if f.Locals[:2][0] == obj {
+		t.Error("ReleaseFrame: expected the frame's locals to be cleared")
+	}
+}
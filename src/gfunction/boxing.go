@@ -0,0 +1,56 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/object"
+	"jacobin/stringPool"
+	"jacobin/types"
+)
+
+// Boxing and unboxing of primitive values, that is, converting between a primitive and an
+// object of its wrapper class (Integer for int, and so on), as Integer.valueOf() and
+// Integer.intValue() do. The wrapper objects are built the same way as those returned by
+// the valueOf() gfunctions: the primitive is held in the "value" field.
+
+// the wrapper classes of the primitive types
+var boxClassNames = map[string]string{
+	types.Bool:   "java/lang/Boolean",
+	types.Byte:   "java/lang/Byte",
+	types.Char:   "java/lang/Character",
+	types.Double: "java/lang/Double",
+	types.Float:  "java/lang/Float",
+	types.Int:    "java/lang/Integer",
+	types.Long:   "java/lang/Long",
+	types.Short:  "java/lang/Short",
+}
+
+// Box returns an object of the wrapper class of the given primitive type (e.g., types.Int)
+// holding value, which is an int64 or a float64 as on the operand stack. Returns nil if
+// fieldType is not a primitive type.
+func Box(value any, fieldType string) *object.Object {
+	className, ok := boxClassNames[fieldType]
+	if !ok {
+		return nil
+	}
+	return populator(className, fieldType, value).(*object.Object)
+}
+
+// Unbox returns the primitive value held in an object of one of the wrapper classes,
+// and true. If obj is not a wrapper object, it returns nil and false.
+func Unbox(obj *object.Object) (any, bool) {
+	if object.IsNull(obj) {
+		return nil, false
+	}
+
+	className := *(stringPool.GetStringPointer(obj.KlassName))
+	fld, ok := obj.FieldTable["value"]
+	if !ok || boxClassNames[fld.Ftype] != className {
+		return nil, false
+	}
+	return fld.Fvalue, true
+}
@@ -0,0 +1,66 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"testing"
+)
+
+func TestBoxAndUnbox(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		value     any
+		fieldType string
+		className string
+	}{
+		{int64(42), types.Int, "java/lang/Integer"},
+		{int64(1 << 40), types.Long, "java/lang/Long"},
+		{2.5, types.Double, "java/lang/Double"},
+		{2.5, types.Float, "java/lang/Float"},
+		{types.JavaBoolTrue, types.Bool, "java/lang/Boolean"},
+		{int64('x'), types.Char, "java/lang/Character"},
+		{int64(-3), types.Byte, "java/lang/Byte"},
+		{int64(300), types.Short, "java/lang/Short"},
+	}
+
+	for _, test := range tests {
+		boxed := Box(test.value, test.fieldType)
+		if boxed == nil {
+			t.Errorf("Box(%v, %s): got nil", test.value, test.fieldType)
+			continue
+		}
+		if className := *(stringPool.GetStringPointer(boxed.KlassName)); className != test.className {
+			t.Errorf("Box(%v, %s): expected class %s, got %s", test.value, test.fieldType, test.className, className)
+		}
+		value, ok := Unbox(boxed)
+		if !ok || value != test.value {
+			t.Errorf("Unbox of %s: expected %v, got %v (%v)", test.className, test.value, value, ok)
+		}
+	}
+}
+
+func TestBoxOfReferenceType(t *testing.T) {
+	globals.InitGlobals("test")
+	if boxed := Box(int64(1), types.Ref); boxed != nil {
+		t.Errorf("Box of a reference type: expected nil, got %v", boxed)
+	}
+}
+
+func TestUnboxOfNonWrapper(t *testing.T) {
+	globals.InitGlobals("test")
+	if _, ok := Unbox(object.StringObjectFromGoString("42")); ok {
+		t.Errorf("Unbox of a String: expected it not to be unboxed")
+	}
+	if _, ok := Unbox(object.Null); ok {
+		t.Errorf("Unbox of null: expected it not to be unboxed")
+	}
+}
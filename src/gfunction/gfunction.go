@@ -14,6 +14,7 @@ import (
 	"jacobin/log"
 	"jacobin/object"
 	"jacobin/types"
+	"jacobin/util"
 	"os"
 	"strings"
 )
@@ -22,11 +23,13 @@ import (
 var MethodSignatures = make(map[string]GMeth)
 
 // File I/O and stream Field keys:
-var FileStatus string = "status"     // using this value in case some member function is looking at it
-var FilePath string = "FilePath"     // full absolute path of a file aka canonical path
-var FileHandle string = "FileHandle" // *os.File
-var FileMark string = "FileMark"     // file position relative to beginning (0)
-var FileAtEOF string = "FileAtEOF"   // file at EOF
+var FileStatus string = "status"               // using this value in case some member function is looking at it
+var FilePath string = "FilePath"               // full absolute path of a file aka canonical path
+var FileHandle string = "FileHandle"           // *os.File; in a PrintStream, any io.Writer
+var FileMark string = "FileMark"               // file position relative to beginning (0)
+var FileAtEOF string = "FileAtEOF"             // file at EOF
+var FileCharset string = "FileCharset"         // canonical name of the charset a writer encodes chars with
+var FilePendingChar string = "FilePendingChar" // a high surrogate a writer holds until the char after it is written
 
 // File I/O constants:
 var CreateFilePermissions os.FileMode = 0664 // When creating, read and write for user and group, others read-only
@@ -36,6 +39,10 @@ var MinRadix int64 = 2
 var MaxRadix int64 = 36
 var MaxIntValue int64 = 2147483647
 var MinIntValue int64 = -2147483648
+var MaxByteValue int64 = 127
+var MinByteValue int64 = -128
+var MaxShortValue int64 = 32767
+var MinShortValue int64 = -32768
 
 // GMeth is the entry in the MTable for Go functions. See MTable comments for details.
 //   - ParamSlots - the number of user parameters in a G function. E.g. For atan2, this would be 2.
@@ -46,12 +53,15 @@ type GMeth struct {
 	ParamSlots   int
 	GFunction    func([]interface{}) interface{}
 	NeedsContext bool
+	ParamTypes   []string // the parameter types in the key's descriptor, set by loadlib()
 }
 
-// G function error block.
+// G function error block. If Exception is set, it's an exception object that Java code
+// called by the G function threw, which is thrown as is, rather than as a new ExceptionType.
 type GErrBlk struct {
 	ExceptionType int
 	ErrMsg        string
+	Exception     *object.Object
 }
 
 // Construct a G function error block. Return a ptr to it.
@@ -62,73 +72,107 @@ func getGErrBlk(exceptionType int, errMsg string) *GErrBlk {
 	return &gErrBlk
 }
 
+// JavaException is the error returned by globals.FuncInvokeMethod when the Java method it
+// runs throws an exception that the method doesn't catch. Exception is the thrown object.
+type JavaException struct {
+	Exception *object.Object
+}
+
+func (e *JavaException) Error() string {
+	return "uncaught exception: " + object.GoStringFromStringPoolIndex(e.Exception.KlassName)
+}
+
+// Construct a G function error block for an exception thrown by Java code that the
+// G function called. Return a ptr to it.
+func getGErrBlkForException(javaExc *JavaException) *GErrBlk {
+	return &GErrBlk{ErrMsg: javaExc.Error(), Exception: javaExc.Exception}
+}
+
 // MTableLoadGFunctions loads the Go methods from files that contain them. It does this
 // by calling the Load_* function in each of those files to load whatever Go functions
 // they make available.
 func MTableLoadGFunctions(MTable *classloader.MT) {
 
-	// java/awt/*
-	Load_Awt_Graphics_Environment()
-
-	// java/io/*
-	Load_Io_BufferedReader()
-	Load_Io_Console()
-	Load_Io_File()
-	Load_Io_FileInputStream()
-	Load_Io_FileOutputStream()
-	Load_Io_FileReader()
-	Load_Io_FileWriter()
-	Load_Io_InputStreamReader()
-	Load_Io_OutputStreamWriter()
-	Load_Io_PrintStream()
-	Load_Io_RandomAccessFile()
-
-	// java/lang/*
-	Load_Lang_Boolean()
-	Load_Lang_Byte()
-	Load_Lang_Character()
-	Load_Lang_Class()
-	Load_Lang_Double()
-	Load_Lang_Float()
-	Load_Lang_Integer()
-	Load_Lang_Long()
-	Load_Lang_Math()
-	Load_Lang_Object()
-	Load_Lang_Short()
-	Load_Lang_String()
-	Load_Lang_StringBuilder()
-	Load_Lang_System()
-	Load_Lang_StackTraceELement()
-	Load_Lang_Thread()
-	Load_Lang_Throwable()
-	Load_Lang_UTF16()
-
-	// java/math/*
-	Load_Math_Big_Integer()
-
-	// java/nio/*
-	Load_Nio_Charset_Charset()
-
-	// java/security/*
-	Load_Security_SecureRandom()
-
-	// java/util/*
-	Load_Util_Concurrent_Atomic_AtomicInteger()
-	Load_Util_Concurrent_Atomic_Atomic_Long()
-	Load_Util_HashMap()
-	Load_Util_HexFormat()
-	Load_Util_Locale()
-	Load_Util_Random()
-
-	// jdk/internal/misc/*
-	Load_Jdk_Internal_Misc_Unsafe()
-	Load_Jdk_Internal_Misc_ScopedMemoryAccess()
-
-	// Load functions that invoke justReturn() and do nothing else.
-	Load_Just_Return()
-
-	// Load traps that lead to unconditional error returns.
-	Load_Traps()
+	ok := loadGFunctions(
+		// java/awt/*
+		Load_Awt_Graphics_Environment,
+
+		// java/io/*
+		Load_Io_BufferedReader,
+		Load_Io_Console,
+		Load_Io_File,
+		Load_Io_FileInputStream,
+		Load_Io_FileOutputStream,
+		Load_Io_FileReader,
+		Load_Io_FileWriter,
+		Load_Io_InputStream,
+		Load_Io_InputStreamReader,
+		Load_Io_OutputStreamWriter,
+		Load_Io_PrintStream,
+		Load_Io_RandomAccessFile,
+
+		// java/lang/*
+		Load_Lang_Boolean,
+		Load_Lang_Byte,
+		Load_Lang_Character,
+		Load_Lang_Class,
+		Load_Lang_Double,
+		Load_Lang_Enum,
+		Load_Lang_Float,
+		Load_Lang_Integer,
+		Load_Lang_Long,
+		Load_Lang_Math,
+		Load_Lang_Object,
+		Load_Lang_Runtime,
+		Load_Lang_Short,
+		Load_Lang_String,
+		Load_Lang_StringBuffer,
+		Load_Lang_StringBuilder,
+		Load_Lang_System,
+		Load_Lang_StackTraceELement,
+		Load_Lang_Thread,
+		Load_Lang_Throwable,
+		Load_Lang_UTF16,
+
+		// java/math/*
+		Load_Math_Big_Decimal,
+		Load_Math_Big_Integer,
+
+		// java/nio/*
+		Load_Nio_Charset_Charset,
+		Load_Nio_File_Files,
+		Load_Nio_File_Path,
+		Load_Nio_File_Paths,
+
+		// java/security/*
+		Load_Security_SecureRandom,
+
+		// java/util/*
+		Load_Util_Collections,
+		Load_Util_Concurrent_Atomic_AtomicInteger,
+		Load_Util_Concurrent_Atomic_Atomic_Long,
+		Load_Util_HashMap,
+		Load_Util_HexFormat,
+		Load_Util_Locale,
+		Load_Util_Optional,
+		Load_Util_Random,
+		Load_Util_StringTokenizer,
+
+		// jdk/internal/misc/*
+		Load_Jdk_Internal_Misc_Unsafe,
+		Load_Jdk_Internal_Misc_ScopedMemoryAccess,
+
+		// Load functions that invoke justReturn() and do nothing else.
+		Load_Just_Return,
+	)
+	if !ok {
+		exceptions.ThrowExNil(excNames.InternalException,
+			"MTableLoadGFunctions: at least one key was registered by more than one loader")
+	}
+
+	// Load traps that lead to unconditional error returns. These are loaded last, so that
+	// a class or function that has been implemented is never shadowed by its trap.
+	loadTraps(Load_Traps)
 
 	/*
 		With the accumulated MethodSignatures maps, load MTable.
@@ -137,13 +181,78 @@ func MTableLoadGFunctions(MTable *classloader.MT) {
 
 }
 
+// loadGFunctions runs the loaders in order and merges what they register into MethodSignatures.
+// Each loader writes into a fresh map, so that a key registered by two loaders is reported
+// instead of one silently overwriting the other. Returns false if there was any such collision.
+func loadGFunctions(loaders ...func()) bool {
+	ok := true
+	merged := make(map[string]GMeth)
+	for _, loader := range loaders {
+		MethodSignatures = make(map[string]GMeth)
+		loader()
+		for key, val := range MethodSignatures {
+			if _, duplicate := merged[key]; duplicate {
+				errMsg := fmt.Sprintf("loadGFunctions: duplicate key=%s", key)
+				log.Log(errMsg, log.SEVERE)
+				ok = false
+			}
+			merged[key] = val
+		}
+	}
+	MethodSignatures = merged
+	return ok
+}
+
+// loadTraps adds the traps registered by the loader to MethodSignatures. A trap is only a
+// placeholder for a class or function that is not yet supported, so a trap whose key is
+// already registered by a real implementation is dropped rather than replacing it.
+func loadTraps(loader func()) {
+	implemented := MethodSignatures
+	MethodSignatures = make(map[string]GMeth)
+	loader()
+	for key, val := range MethodSignatures {
+		if _, ok := implemented[key]; ok {
+			errMsg := fmt.Sprintf("loadTraps: %s is implemented, so its trap is ignored", key)
+			log.Log(errMsg, log.FINE)
+			continue
+		}
+		implemented[key] = val
+	}
+	MethodSignatures = implemented
+}
+
+// checkKey verifies that a MethodSignatures key has the shape class.name(params)return,
+// e.g., java/lang/String.indexOf(Ljava/lang/String;I)I, and that the descriptor is valid.
 func checkKey(key string) bool {
-	if strings.Index(key, ".") == -1 || strings.Index(key, "(") == -1 || strings.Index(key, ")") == -1 {
+	paren := strings.Index(key, "(")
+	if paren < 0 {
+		return false
+	}
+	dot := strings.LastIndex(key[:paren], ".")
+	if dot <= 0 || dot == paren-1 { // both a class name and a method name are required
+		return false
+	}
+
+	methName := key[dot+1 : paren]
+	if strings.ContainsAny(methName, "/;[") {
 		return false
 	}
-	if strings.HasSuffix(key, ")") {
+	if strings.ContainsAny(methName, "<>") && methName != "<init>" && methName != "<clinit>" {
+		return false
+	}
+
+	params, retType, err := util.ParseMethodDescriptor(key[paren:])
+	if err != nil {
 		return false
 	}
+
+	// constructors and static initializers return void; static initializers take no arguments
+	switch methName {
+	case "<init>":
+		return retType == "V"
+	case "<clinit>":
+		return retType == "V" && len(params) == 0
+	}
 	return true
 }
 
@@ -159,6 +268,10 @@ func loadlib(tbl *classloader.MT, libMeths map[string]GMeth) {
 		gme.ParamSlots = val.ParamSlots
 		gme.GFunction = val.GFunction
 		gme.NeedsContext = val.NeedsContext
+		// parse the descriptor here, once, so that calls to the gfunction don't have to
+		if paren := strings.Index(key, "("); paren >= 0 {
+			gme.ParamTypes, _, _ = util.ParseMethodDescriptor(key[paren:])
+		}
 
 		tableEntry := classloader.MTentry{
 			MType: 'G',
@@ -184,6 +297,61 @@ func populator(classname string, fldtype string, fldvalue interface{}) interface
 	return objPtr
 }
 
+// invokeCompareTo compares two objects by calling the compareTo() G function of the first
+// object's class, as Comparable-based code (sorting, min/max) does in the JDK. The typed
+// overload (e.g., Integer.compareTo(Integer)) is preferred over compareTo(Object). If the
+// class has no compareTo() or the objects are of different classes, they are not mutually
+// comparable and a ClassCastException results.
+func invokeCompareTo(obj1, obj2 *object.Object) (int64, *GErrBlk) {
+	if object.IsNull(obj1) || object.IsNull(obj2) {
+		return 0, getGErrBlk(excNames.NullPointerException, "invokeCompareTo: null operand")
+	}
+
+	className1 := object.GoStringFromStringPoolIndex(obj1.KlassName)
+	className2 := object.GoStringFromStringPoolIndex(obj2.KlassName)
+	if className1 != className2 {
+		errMsg := fmt.Sprintf("class %s cannot be cast to class %s", className2, className1)
+		return 0, getGErrBlk(excNames.ClassCastException, errMsg)
+	}
+
+	gmeth, ok := MethodSignatures[className1+".compareTo(L"+className1+";)I"]
+	if !ok {
+		gmeth, ok = MethodSignatures[className1+".compareTo(Ljava/lang/Object;)I"]
+	}
+	if !ok {
+		errMsg := fmt.Sprintf("class %s cannot be cast to class java/lang/Comparable", className1)
+		return 0, getGErrBlk(excNames.ClassCastException, errMsg)
+	}
+
+	ret := gmeth.GFunction([]interface{}{obj1, obj2})
+	switch ret.(type) {
+	case int64:
+		return ret.(int64), nil
+	case *GErrBlk:
+		return 0, ret.(*GErrBlk)
+	default:
+		errMsg := fmt.Sprintf("invokeCompareTo: unexpected compareTo() result type: %T", ret)
+		return 0, getGErrBlk(excNames.VirtualMachineError, errMsg)
+	}
+}
+
+// comparableCompareTo implements the compareTo(Ljava/lang/Object;)I bridge method of the
+// Comparable classes. It checks the argument's class and then dispatches to the class's
+// typed compareTo(), so it must only be registered for classes that have one.
+func comparableCompareTo(params []interface{}) interface{} {
+	obj1, ok1 := params[0].(*object.Object)
+	obj2, ok2 := params[1].(*object.Object)
+	if !ok1 || !ok2 {
+		return getGErrBlk(excNames.NullPointerException, "compareTo: null operand")
+	}
+
+	result, gerr := invokeCompareTo(obj1, obj2)
+	if gerr != nil {
+		return gerr
+	}
+	return result
+}
+
 // File set EOF condition.
 func eofSet(obj *object.Object, value bool) {
 	obj.FieldTable[FileAtEOF] = object.Field{Ftype: types.Bool, Fvalue: value}
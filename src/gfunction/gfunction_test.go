@@ -7,9 +7,16 @@
 package gfunction
 
 import (
+	"io"
 	"jacobin/classloader"
+	"jacobin/excNames"
 	"jacobin/globals"
 	"jacobin/log"
+	"jacobin/object"
+	"jacobin/types"
+	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -48,6 +55,55 @@ func TestMTableLoadLib(t *testing.T) {
 	if mte.NeedsContext {
 		t.Errorf("ERROR, Expecting MTable entry's NeedContext to be false\n")
 	}
+
+	// the descriptor is parsed once, here, rather than on every call
+	paramTypes := mtbl["test.f3(Ljava/lang/String;JZ)D"].Meth.(GMeth).ParamTypes
+	if !slices.Equal(paramTypes, []string{"Ljava/lang/String;", "J", "Z"}) {
+		t.Errorf("ERROR, Expecting f3 MTable entry's ParamTypes to be [Ljava/lang/String; J Z], got: %v\n",
+			paramTypes)
+	}
+}
+
+func TestCheckKeyValid(t *testing.T) {
+	validKeys := []string{
+		"java/lang/Object.<init>()V",
+		"java/lang/Math.<clinit>()V",
+		"java/lang/String.length()I",
+		"java/lang/String.indexOf(Ljava/lang/String;I)I",
+		"java/lang/System.arraycopy(Ljava/lang/Object;ILjava/lang/Object;II)V",
+		"java/lang/String.split(Ljava/lang/String;)[Ljava/lang/String;",
+		"java/util/Arrays.fill([[DD)V",
+		"java/util/Map$Entry.getKey()Ljava/lang/Object;",
+	}
+	for _, key := range validKeys {
+		if !checkKey(key) {
+			t.Errorf("Expecting key %s to be valid, but it was rejected", key)
+		}
+	}
+}
+
+func TestCheckKeyMalformed(t *testing.T) {
+	malformedKeys := []string{
+		"java/lang/String.length",                                        // no descriptor
+		"java/lang/Stringlength()I",                                      // no method name
+		".length()I",                                                     // no class name
+		"java/lang/String.()I",                                           // empty method name
+		"java/lang/String.length()",                                      // no return type
+		"java/lang/String.length(I",                                      // no closing parenthesis
+		"java/lang/String.length()II",                                    // two return types
+		"java/lang/String.length(Q)I",                                    // illegal type character
+		"java/lang/String.valueOf([)Ljava/lang/String;",                  // array without element type
+		"java/lang/String.valueOf(Ljava/lang/Object)V",                   // no terminating ';'
+		"java/io/FileWriter.<init>(Ljava/lang.String;)V",                 // dotted class name
+		"java/io/Reader.<init>(Ljava/io/InputStream;)Ljava/lang/String;", // constructor must return void
+		"java/lang/Math.<clinit>(I)V",                                    // static initializer takes no arguments
+		"java/lang/Math.<max>(II)I",                                      // only initializers have < or > in their names
+	}
+	for _, key := range malformedKeys {
+		if checkKey(key) {
+			t.Errorf("Expecting key %s to be rejected, but it was accepted", key)
+		}
+	}
 }
 
 // test loading of native functions
@@ -66,6 +122,82 @@ func TestMTableLoadGFunctions(t *testing.T) {
 	}
 }
 
+// a key registered by two loaders must be reported, not silently overwritten
+func TestLoadGFunctionsDuplicateKey(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	loader1 := func() {
+		MethodSignatures["test.dup()V"] = GMeth{ParamSlots: 0, GFunction: f1}
+		MethodSignatures["test.f2(I)V"] = GMeth{ParamSlots: 1, GFunction: f2}
+	}
+	loader2 := func() {
+		MethodSignatures["test.dup()V"] = GMeth{ParamSlots: 0, GFunction: f3}
+	}
+
+	if loadGFunctions(loader1, loader2) {
+		t.Errorf("Expecting loadGFunctions to report the duplicate key, but it did not")
+	}
+	if len(MethodSignatures) != 2 {
+		t.Errorf("Expecting 2 merged signatures, got: %d", len(MethodSignatures))
+	}
+
+	if !loadGFunctions(loader1) {
+		t.Errorf("Expecting no duplicate keys from a single loader, but one was reported")
+	}
+}
+
+// the loaders in MTableLoadGFunctions must not register the same key twice
+func TestMTableLoadGFunctionsNoDuplicates(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	stderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	MTableLoadGFunctions(&classloader.MTable)
+	_ = w.Close()
+	os.Stderr = stderr
+	out, _ := io.ReadAll(r)
+
+	if strings.Contains(string(out), "duplicate key") {
+		t.Errorf("Expecting no duplicate keys, got: %s", string(out))
+	}
+}
+
+// a trap must not shadow the real implementation of a class, whatever the load order
+func TestTrapDoesNotShadowImplementation(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	trapLoader := func() {
+		MethodSignatures["java/io/BufferedReader.<clinit>()V"] = GMeth{ParamSlots: 0, GFunction: trapClass}
+		MethodSignatures["test/Unimplemented.<clinit>()V"] = GMeth{ParamSlots: 0, GFunction: trapClass}
+	}
+	loadGFunctions(Load_Io_BufferedReader)
+	loadTraps(trapLoader)
+
+	gmeth, ok := MethodSignatures["java/io/BufferedReader.<clinit>()V"]
+	if !ok {
+		t.Fatalf("Expecting BufferedReader.<clinit>()V to be registered, but it was not")
+	}
+	if gerr, isErr := gmeth.GFunction(nil).(*GErrBlk); isErr &&
+		gerr.ExceptionType == excNames.UnsupportedOperationException {
+		t.Errorf("Expecting BufferedReader.<clinit>()V to be the implementation, but it is the trap")
+	}
+
+	// a trap for a class that has no implementation is still registered
+	gmeth, ok = MethodSignatures["test/Unimplemented.<clinit>()V"]
+	if !ok {
+		t.Fatalf("Expecting the trap for test/Unimplemented.<clinit>()V to be registered, but it was not")
+	}
+	if gerr, isErr := gmeth.GFunction(nil).(*GErrBlk); !isErr ||
+		gerr.ExceptionType != excNames.UnsupportedOperationException {
+		t.Errorf("Expecting test/Unimplemented.<clinit>()V to throw UnsupportedOperationException")
+	}
+}
+
 // make sure that JustReturn in fact does nothing
 func TestJustReturn(t *testing.T) {
 	retVal := justReturn(nil)
@@ -73,3 +205,63 @@ func TestJustReturn(t *testing.T) {
 		t.Errorf("Expecting nil return value, got: %v", retVal)
 	}
 }
+
+// make sure invokeCompareTo() dispatches to the right compareTo() G function
+func TestInvokeCompareToIntegers(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	int1 := populator("java/lang/Integer", types.Int, int64(-5)).(*object.Object)
+	int2 := populator("java/lang/Integer", types.Int, int64(12)).(*object.Object)
+
+	result, gerr := invokeCompareTo(int1, int2)
+	if gerr != nil || result >= 0 {
+		t.Errorf("Expecting -5 compareTo 12 to be negative, got %d (error: %v)", result, gerr)
+	}
+
+	result, gerr = invokeCompareTo(int2, int1)
+	if gerr != nil || result <= 0 {
+		t.Errorf("Expecting 12 compareTo -5 to be positive, got %d (error: %v)", result, gerr)
+	}
+
+	// the Comparable bridge method must give the same answer
+	ret := comparableCompareTo([]interface{}{int1, int1})
+	if ret != int64(0) {
+		t.Errorf("Expecting Integer.compareTo(Object) of equal values to be 0, got %v", ret)
+	}
+}
+
+func TestInvokeCompareToStrings(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_String()
+	str1 := object.StringObjectFromGoString("apple")
+	str2 := object.StringObjectFromGoString("banana")
+
+	result, gerr := invokeCompareTo(str1, str2)
+	if gerr != nil || result >= 0 {
+		t.Errorf("Expecting apple compareTo banana to be negative, got %d (error: %v)", result, gerr)
+	}
+
+	result, gerr = invokeCompareTo(str2, object.StringObjectFromGoString("banana"))
+	if gerr != nil || result != 0 {
+		t.Errorf("Expecting banana compareTo banana to be 0, got %d (error: %v)", result, gerr)
+	}
+}
+
+func TestInvokeCompareToMixedTypes(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	Load_Lang_Long()
+	intObj := populator("java/lang/Integer", types.Int, int64(1)).(*object.Object)
+	longObj := populator("java/lang/Long", types.Long, int64(1)).(*object.Object)
+
+	_, gerr := invokeCompareTo(intObj, longObj)
+	if gerr == nil || gerr.ExceptionType != excNames.ClassCastException {
+		t.Errorf("Expecting ClassCastException comparing Integer to Long, got %v", gerr)
+	}
+
+	ret := comparableCompareTo([]interface{}{longObj, object.StringObjectFromGoString("1")})
+	gerrBlk, ok := ret.(*GErrBlk)
+	if !ok || gerrBlk.ExceptionType != excNames.ClassCastException {
+		t.Errorf("Expecting ClassCastException comparing Long to String, got %v", ret)
+	}
+}
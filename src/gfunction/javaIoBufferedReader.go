@@ -164,7 +164,7 @@ func bufferedReaderReadLine(params []interface{}) interface{} {
 		buffer = append(buffer, byteBuf[0])
 	}
 
-	// Return the string.
-	return object.StringObjectFromByteArray(buffer)
+	// Return the string, decoded from the default charset.
+	return object.StringObjectFromGoString(decodeBytes(buffer, defaultCharsetName()))
 
 }
@@ -15,6 +15,8 @@ import (
 	"jacobin/statics"
 	"jacobin/types"
 	"os"
+	"strings"
+	"sync"
 	"syscall"
 )
 
@@ -101,6 +103,25 @@ func Load_Io_Console() {
 
 }
 
+var classNameConsole = "java/io/Console"
+
+// the one Console object, which System.console() returns if there is a console
+var consoleObject *object.Object
+var consoleOnce sync.Once
+
+func theConsole() *object.Object {
+	consoleOnce.Do(func() {
+		consoleObject = object.MakeEmptyObjectWithClassName(&classNameConsole)
+	})
+	return consoleObject
+}
+
+// isTerminal reports whether a stream is attached to a terminal
+func isTerminal(stream interface{}) bool {
+	file, ok := stream.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
 // "java/io/Console.<clinit>()V" - Initialise class Console.
 func consoleClinit([]interface{}) interface{} {
 	klass := classloader.MethAreaFetch("java/io/Console")
@@ -115,10 +136,12 @@ func consoleClinit([]interface{}) interface{} {
 // Flush java/lang/System.in/out/err.
 // "java/io/Console.flush()V"
 func consoleFlush([]interface{}) interface{} {
-	stdinout := statics.GetStaticValue("java/lang/System", "in").(*os.File)
-	_ = stdinout.Sync()
-	stdinout = statics.GetStaticValue("java/lang/System", "out").(*os.File)
-	_ = stdinout.Sync()
+	stdin := inputStreamFile(statics.GetStaticValue("java/lang/System", "in"))
+	_ = stdin.Sync()
+	// System.out might have been redirected (by System.setOut()) to something other than a file
+	if stdout, ok := printStreamWriter(statics.GetStaticValue("java/lang/System", "out")).(*os.File); ok {
+		_ = stdout.Sync()
+	}
 	// Note: java/lang/System.err is not associated with the system console.
 	return nil
 }
@@ -137,9 +160,9 @@ func consolePrintf(params []interface{}) interface{} {
 	}
 	objPtr := retval.(*object.Object)
 	str := object.GoStringFromStringObject(objPtr)
-	stdout := statics.GetStaticValue("java/lang/System", "out").(*os.File)
+	stdout := printStreamWriter(statics.GetStaticValue("java/lang/System", "out"))
 	_, _ = fmt.Fprint(stdout, str)
-	return stdout // Return the *os.File
+	return params[0] // Return the console, so that calls can be chained
 
 }
 
@@ -150,7 +173,8 @@ func consoleReadLine([]interface{}) interface{} {
 	var bb = []byte{0x00}
 	var nbytes int
 	var err error
-	stdin := statics.GetStaticValue("java/lang/System", "in").(*os.File)
+	var sawNewline bool
+	stdin := inputStreamFile(statics.GetStaticValue("java/lang/System", "in"))
 	for {
 		nbytes, err = stdin.Read(bb)
 		if nbytes == 0 {
@@ -161,11 +185,15 @@ func consoleReadLine([]interface{}) interface{} {
 			return getGErrBlk(excNames.IOException, errMsg)
 		}
 		if bb[0] == '\n' {
+			sawNewline = true
 			break
 		}
 		bytes = append(bytes, bb[0])
 	}
-	str := string(bytes)
+	if len(bytes) == 0 && !sawNewline {
+		return object.Null // the end of the input has been reached
+	}
+	str := strings.TrimSuffix(string(bytes), "\r")
 	return object.StringObjectFromGoString(str)
 }
 
@@ -185,7 +213,7 @@ func consoleReadPassword([]interface{}) interface{} {
 		errMsg := fmt.Sprintf("stdin.ReadPassword failed, reason: %s", err.Error())
 		return getGErrBlk(excNames.IOException, errMsg)
 	}
-	stdout := statics.GetStaticValue("java/lang/System", "out").(*os.File)
+	stdout := printStreamWriter(statics.GetStaticValue("java/lang/System", "out"))
 	_, _ = fmt.Fprint(stdout, "\n")
 
 	// Convert password to int64 array, insert into an object, and return to caller
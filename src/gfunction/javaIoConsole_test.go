@@ -0,0 +1,89 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"bytes"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/statics"
+	"jacobin/types"
+	"os"
+	"testing"
+)
+
+// redirectSystemStreams makes System.in read what is written to the returned pipe and
+// System.out write to the returned buffer, until the returned function restores them
+func redirectSystemStreams(t *testing.T) (*os.File, *bytes.Buffer, func()) {
+	savedIn, hadIn := statics.Statics["java/lang/System.in"]
+	savedOut, hadOut := statics.Statics["java/lang/System.out"]
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	_ = statics.AddStatic("java/lang/System.in", statics.Static{Type: "Ljava/io/InputStream;",
+		Value: newStdinInputStream(r)})
+	_ = setOut([]interface{}{NewPrintStream(&buf)})
+
+	return w, &buf, func() {
+		_ = r.Close()
+		if hadIn {
+			statics.Statics["java/lang/System.in"] = savedIn
+		} else {
+			delete(statics.Statics, "java/lang/System.in")
+		}
+		if hadOut {
+			statics.Statics["java/lang/System.out"] = savedOut
+		} else {
+			delete(statics.Statics, "java/lang/System.out")
+		}
+	}
+}
+
+// when System.in is not a terminal, as here, where it is a pipe, there is no console
+func TestConsoleIsNullWithoutTerminal(t *testing.T) {
+	globals.InitGlobals("test")
+	w, _, restore := redirectSystemStreams(t)
+	defer restore()
+	defer w.Close()
+
+	if ret := getConsole(nil); ret != object.Null {
+		t.Errorf("System.console(): expected null when System.in is a pipe, got %v", ret)
+	}
+}
+
+func TestConsoleReadLineAndPrintf(t *testing.T) {
+	globals.InitGlobals("test")
+	w, buf, restore := redirectSystemStreams(t)
+	defer restore()
+	_, _ = w.Write([]byte("first\r\nsecond\n\nlast"))
+	_ = w.Close()
+
+	console := theConsole()
+	for _, expected := range []string{"first", "second", "", "last"} {
+		ret, ok := consoleReadLine([]interface{}{console}).(*object.Object)
+		if !ok || object.IsNull(ret) {
+			t.Fatalf("readLine(): expected %q, got %v", expected, ret)
+		}
+		if got := object.GoStringFromStringObject(ret); got != expected {
+			t.Errorf("readLine(): expected %q, got %q", expected, got)
+		}
+	}
+	if ret := consoleReadLine([]interface{}{console}); ret != object.Null {
+		t.Errorf("readLine() at the end of the input: expected null, got %v", ret)
+	}
+
+	seven := makeBoxedForTest("java/lang/Integer", types.Int, int64(7))
+	ret := consolePrintf([]interface{}{console, object.StringObjectFromGoString("n=%d"), makePrintfArgs(seven)})
+	if ret != console {
+		t.Errorf("printf(): expected the console to be returned, got %v", ret)
+	}
+	if buf.String() != "n=7" {
+		t.Errorf("printf(): expected n=7 on System.out, got %q", buf.String())
+	}
+}
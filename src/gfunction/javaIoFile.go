@@ -9,10 +9,12 @@ package gfunction
 import (
 	"fmt"
 	"jacobin/excNames"
+	"jacobin/globals"
 	"jacobin/object"
 	"jacobin/types"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func Load_Io_File() {
@@ -70,6 +72,13 @@ func fileInit(params []interface{}) interface{} {
 		return getGErrBlk(excNames.NullPointerException, errMsg)
 	}
 
+	// A path built with a file.separator other than the platform's is converted to the
+	// platform's, so that the file system can find the file.
+	glob := globals.GetGlobalRef()
+	if glob.FileSeparator != string(os.PathSeparator) {
+		argPathStr = strings.ReplaceAll(argPathStr, glob.FileSeparator, string(os.PathSeparator))
+	}
+
 	// Create an absolute path string.
 	absPathStr, err := filepath.Abs(argPathStr)
 	if err != nil {
@@ -82,16 +91,16 @@ func fileInit(params []interface{}) interface{} {
 	fld = object.Field{Ftype: types.ByteArray, Fvalue: []byte(absPathStr)}
 	params[0].(*object.Object).FieldTable[FilePath] = fld
 
-	fld = object.Field{Ftype: types.Int, Fvalue: os.PathSeparator}
+	fld = object.Field{Ftype: types.Int, Fvalue: int64(glob.FileSeparator[0])}
 	params[0].(*object.Object).FieldTable["separatorChar"] = fld
 
-	fld = object.Field{Ftype: types.ByteArray, Fvalue: []byte{os.PathSeparator}}
+	fld = object.Field{Ftype: types.ByteArray, Fvalue: []byte(glob.FileSeparator)}
 	params[0].(*object.Object).FieldTable["separator"] = fld
 
-	fld = object.Field{Ftype: types.Int, Fvalue: os.PathListSeparator}
+	fld = object.Field{Ftype: types.Int, Fvalue: int64(glob.PathSeparator[0])}
 	params[0].(*object.Object).FieldTable["pathSeparatorChar"] = fld
 
-	fld = object.Field{Ftype: types.ByteArray, Fvalue: []byte{os.PathListSeparator}}
+	fld = object.Field{Ftype: types.ByteArray, Fvalue: []byte(glob.PathSeparator)}
 	params[0].(*object.Object).FieldTable["pathSeparator"] = fld
 
 	// Set status to "checked" (=1).
@@ -6,6 +6,11 @@
 
 package gfunction
 
+import (
+	"jacobin/object"
+	"jacobin/types"
+)
+
 func Load_Io_FileWriter() {
 
 	MethodSignatures["java/io/FileWriter.<clinit>()V"] =
@@ -38,6 +43,30 @@ func Load_Io_FileWriter() {
 			GFunction:  initFileOutputStreamStringBoolean,
 		}
 
+	MethodSignatures["java/io/FileWriter.<init>(Ljava/io/File;Ljava/nio/charset/Charset;)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  initFileWriterFileCharset,
+		}
+
+	MethodSignatures["java/io/FileWriter.<init>(Ljava/io/File;Ljava/nio/charset/Charset;Z)V"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  initFileWriterFileCharsetBoolean,
+		}
+
+	MethodSignatures["java/io/FileWriter.<init>(Ljava/lang/String;Ljava/nio/charset/Charset;)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  initFileWriterStringCharset,
+		}
+
+	MethodSignatures["java/io/FileWriter.<init>(Ljava/lang/String;Ljava/nio/charset/Charset;Z)V"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  initFileWriterStringCharsetBoolean,
+		}
+
 	MethodSignatures["java/io/FileWriter.close()V"] =
 		GMeth{
 			ParamSlots: 0,
@@ -72,7 +101,7 @@ func Load_Io_FileWriter() {
 	// Traps that do nothing but return an error
 	// -----------------------------------------
 
-	MethodSignatures["java/io/FileWriter.<init>(Ljava/io/File;Ljava/lang.String;)V"] =
+	MethodSignatures["java/io/FileWriter.<init>(Ljava/io/File;Ljava/lang/String;)V"] =
 		GMeth{
 			ParamSlots: 2,
 			GFunction:  trapFunction,
@@ -84,28 +113,61 @@ func Load_Io_FileWriter() {
 			GFunction:  trapFunction,
 		}
 
-	MethodSignatures["java/io/FileWriter.<init>(Ljava/io/File;Ljava/nio/charset/Charset;)V"] =
-		GMeth{
-			ParamSlots: 2,
-			GFunction:  trapFunction,
-		}
+}
 
-	MethodSignatures["java/io/FileWriter.<init>(Ljava/io/File;Ljava/nio/charset/Charset;Z)V"] =
-		GMeth{
-			ParamSlots: 3,
-			GFunction:  trapFunction,
-		}
+// "java/io/FileWriter.<init>(Ljava/io/File;Ljava/nio/charset/Charset;)V"
+func initFileWriterFileCharset(params []interface{}) interface{} {
+	charsetName, gerr := getCharsetName(params[2], "initFileWriterFileCharset")
+	if gerr != nil {
+		return gerr
+	}
+	if ret := initFileOutputStreamFile(params[:2]); ret != nil {
+		return ret
+	}
+	setWriterCharset(params[0].(*object.Object), charsetName)
+	return nil
+}
 
-	MethodSignatures["java/io/FileWriter.<init>(Ljava/lang/String;Ljava/nio/charset/Charset;)V"] =
-		GMeth{
-			ParamSlots: 2,
-			GFunction:  trapFunction,
-		}
+// "java/io/FileWriter.<init>(Ljava/io/File;Ljava/nio/charset/Charset;Z)V"
+func initFileWriterFileCharsetBoolean(params []interface{}) interface{} {
+	charsetName, gerr := getCharsetName(params[2], "initFileWriterFileCharsetBoolean")
+	if gerr != nil {
+		return gerr
+	}
+	if ret := initFileOutputStreamFileBoolean([]interface{}{params[0], params[1], params[3]}); ret != nil {
+		return ret
+	}
+	setWriterCharset(params[0].(*object.Object), charsetName)
+	return nil
+}
 
-	MethodSignatures["java/io/FileWriter.<init>(Ljava/lang/String;Ljava/nio/charset/Charset;Z)V"] =
-		GMeth{
-			ParamSlots: 3,
-			GFunction:  trapFunction,
-		}
+// "java/io/FileWriter.<init>(Ljava/lang/String;Ljava/nio/charset/Charset;)V"
+func initFileWriterStringCharset(params []interface{}) interface{} {
+	charsetName, gerr := getCharsetName(params[2], "initFileWriterStringCharset")
+	if gerr != nil {
+		return gerr
+	}
+	if ret := initFileOutputStreamString(params[:2]); ret != nil {
+		return ret
+	}
+	setWriterCharset(params[0].(*object.Object), charsetName)
+	return nil
+}
+
+// "java/io/FileWriter.<init>(Ljava/lang/String;Ljava/nio/charset/Charset;Z)V"
+func initFileWriterStringCharsetBoolean(params []interface{}) interface{} {
+	charsetName, gerr := getCharsetName(params[2], "initFileWriterStringCharsetBoolean")
+	if gerr != nil {
+		return gerr
+	}
+	if ret := initFileOutputStreamStringBoolean([]interface{}{params[0], params[1], params[3]}); ret != nil {
+		return ret
+	}
+	setWriterCharset(params[0].(*object.Object), charsetName)
+	return nil
+}
 
+// setWriterCharset records the charset that the writer's write functions encode chars with.
+func setWriterCharset(writer *object.Object, charsetName string) {
+	writer.FieldTable[FileCharset] = object.Field{Ftype: types.GolangString, Fvalue: charsetName}
 }
@@ -0,0 +1,208 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"bytes"
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeCharset returns a Charset object with the given name, laid out as in the JDK
+func makeCharset(name string) *object.Object {
+	className := "java/nio/charset/Charset"
+	cs := object.MakeEmptyObjectWithClassName(&className)
+	cs.FieldTable["name"] = object.Field{Ftype: types.Ref, Fvalue: object.StringObjectFromGoString(name)}
+	return cs
+}
+
+// writeWithFileWriter creates a FileWriter on pathStr using the named charset, writes str,
+// closes the writer, and returns the raw bytes of the file
+func writeWithFileWriter(t *testing.T, pathStr, charsetName, str string) []byte {
+	className := "java/io/FileWriter"
+	writer := object.MakeEmptyObjectWithClassName(&className)
+	ret := initFileWriterStringCharset([]interface{}{
+		writer, object.StringObjectFromGoString(pathStr), makeCharset(charsetName)})
+	if ret != nil {
+		t.Fatalf("initFileWriterStringCharset(%s): unexpected error: %v", charsetName, ret)
+	}
+
+	strObj := object.StringObjectFromGoString(str)
+	length := int64(len(object.JavaCharArrayFromGoString(str)))
+	if ret = oswWriteStringBuffer([]interface{}{writer, strObj, int64(0), length}); ret != nil {
+		t.Fatalf("oswWriteStringBuffer: unexpected error: %v", ret)
+	}
+	if ret = oswClose([]interface{}{writer}); ret != nil {
+		t.Fatalf("oswClose: unexpected error: %v", ret)
+	}
+
+	raw, err := os.ReadFile(pathStr)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%s) failed: %s", pathStr, err.Error())
+	}
+	return raw
+}
+
+func TestFileWriterUTF8Multibyte(t *testing.T) {
+	globals.InitGlobals("test")
+
+	pathStr := filepath.Join(t.TempDir(), "utf8.txt")
+	raw := writeWithFileWriter(t, pathStr, "UTF-8", "h€\U0001F600!")
+	expected := []byte{'h', 0xE2, 0x82, 0xAC, 0xF0, 0x9F, 0x98, 0x80, '!'}
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("UTF-8 FileWriter: expected bytes % X, got % X", expected, raw)
+	}
+}
+
+func TestFileWriterISO8859_1Accented(t *testing.T) {
+	globals.InitGlobals("test")
+
+	pathStr := filepath.Join(t.TempDir(), "latin1.txt")
+	raw := writeWithFileWriter(t, pathStr, "ISO-8859-1", "café€")
+	expected := []byte{'c', 'a', 'f', 0xE9, '?'} // the euro sign is not in ISO-8859-1
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("ISO-8859-1 FileWriter: expected bytes % X, got % X", expected, raw)
+	}
+}
+
+func TestFileWriterUTF8CharArrayAndSingleChar(t *testing.T) {
+	globals.InitGlobals("test")
+
+	pathStr := filepath.Join(t.TempDir(), "chars.txt")
+	className := "java/io/FileWriter"
+	writer := object.MakeEmptyObjectWithClassName(&className)
+	initFileWriterStringCharset([]interface{}{
+		writer, object.StringObjectFromGoString(pathStr), makeCharset("utf8")})
+
+	chars := object.Make1DimArray(object.INT, 3)
+	chars.FieldTable["value"] = object.Field{Ftype: types.CharArray, Fvalue: []int64{'x', 0xD83D, 0xDE00}}
+	oswWriteCharBuffer([]interface{}{writer, chars, int64(1), int64(2)})
+	oswWriteOneChar([]interface{}{writer, int64(0xE9)})
+	oswClose([]interface{}{writer})
+
+	raw, _ := os.ReadFile(pathStr)
+	expected := []byte{0xF0, 0x9F, 0x98, 0x80, 0xC3, 0xA9}
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("UTF-8 FileWriter: expected bytes % X, got % X", expected, raw)
+	}
+}
+
+// A surrogate pair written with write(int), one char at a time, is encoded as the character
+// it makes up. A high surrogate that isn't followed by a low one is encoded as unpaired,
+// when the next char is written or, if there is none, when the writer is flushed or closed.
+func TestFileWriterUTF8SurrogatePairCharByChar(t *testing.T) {
+	globals.InitGlobals("test")
+
+	pathStr := filepath.Join(t.TempDir(), "pair.txt")
+	className := "java/io/FileWriter"
+	writer := object.MakeEmptyObjectWithClassName(&className)
+	initFileWriterStringCharset([]interface{}{
+		writer, object.StringObjectFromGoString(pathStr), makeCharset("UTF-8")})
+
+	for _, ch := range []int64{0xD83D, 0xDE00, 0xD83D, 'a', 0xD83D} {
+		if ret := oswWriteOneChar([]interface{}{writer, ch}); ret != nil {
+			t.Fatalf("oswWriteOneChar(0x%X): unexpected error: %v", ch, ret)
+		}
+	}
+	if ret := oswFlush([]interface{}{writer}); ret != nil {
+		t.Fatalf("oswFlush: unexpected error: %v", ret)
+	}
+	oswWriteOneChar([]interface{}{writer, int64(0xD83D)})
+	if ret := oswClose([]interface{}{writer}); ret != nil {
+		t.Fatalf("oswClose: unexpected error: %v", ret)
+	}
+
+	raw, _ := os.ReadFile(pathStr)
+	expected := []byte{0xF0, 0x9F, 0x98, 0x80, '?', 'a', '?', '?'}
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("UTF-8 FileWriter: expected bytes % X, got % X", expected, raw)
+	}
+}
+
+func TestFileWriterUnsupportedCharset(t *testing.T) {
+	globals.InitGlobals("test")
+
+	pathStr := filepath.Join(t.TempDir(), "utf16.txt")
+	className := "java/io/FileWriter"
+	writer := object.MakeEmptyObjectWithClassName(&className)
+	ret := initFileWriterStringCharset([]interface{}{
+		writer, object.StringObjectFromGoString(pathStr), makeCharset("UTF-16")})
+
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.UnsupportedOperationException {
+		t.Errorf("expected UnsupportedOperationException for UTF-16, got %v", ret)
+	}
+	if _, err := os.Stat(pathStr); err == nil {
+		t.Errorf("the file should not be created when the charset is unsupported")
+	}
+}
+
+func TestOutputStreamWriterCharsetName(t *testing.T) {
+	globals.InitGlobals("test")
+
+	pathStr := filepath.Join(t.TempDir(), "osw.txt")
+	fosClassName := "java/io/FileOutputStream"
+	fos := object.MakeEmptyObjectWithClassName(&fosClassName)
+	if ret := initFileOutputStreamString([]interface{}{fos, object.StringObjectFromGoString(pathStr)}); ret != nil {
+		t.Fatalf("initFileOutputStreamString: unexpected error: %v", ret)
+	}
+
+	oswClassName := "java/io/OutputStreamWriter"
+	writer := object.MakeEmptyObjectWithClassName(&oswClassName)
+	ret := initOutputStreamWriterCharset([]interface{}{writer, fos, object.StringObjectFromGoString("ISO-8859-1")})
+	if ret != nil {
+		t.Fatalf("initOutputStreamWriterCharset: unexpected error: %v", ret)
+	}
+
+	oswWriteStringBuffer([]interface{}{writer, object.StringObjectFromGoString("naïve"), int64(1), int64(3)})
+	oswClose([]interface{}{writer})
+
+	raw, _ := os.ReadFile(pathStr)
+	expected := []byte{'a', 0xEF, 'v'}
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("ISO-8859-1 OutputStreamWriter: expected bytes % X, got % X", expected, raw)
+	}
+}
+
+// writers created without a charset, and BufferedReader.readLine(), use the default charset
+func TestWriterAndReaderUseDefaultCharset(t *testing.T) {
+	globals.InitGlobals("test")
+	globals.GetGlobalRef().FileEncoding = "ISO-8859-1"
+
+	pathStr := filepath.Join(t.TempDir(), "default.txt")
+	className := "java/io/FileWriter"
+	writer := object.MakeEmptyObjectWithClassName(&className)
+	if ret := initFileOutputStreamString([]interface{}{writer, object.StringObjectFromGoString(pathStr)}); ret != nil {
+		t.Fatalf("initFileOutputStreamString: unexpected error: %v", ret)
+	}
+	oswWriteStringBuffer([]interface{}{writer, object.StringObjectFromGoString("olé\n"), int64(0), int64(4)})
+	oswClose([]interface{}{writer})
+
+	raw, _ := os.ReadFile(pathStr)
+	if !bytes.Equal(raw, []byte{'o', 'l', 0xE9, '\n'}) {
+		t.Errorf("default-charset FileWriter: expected bytes 6F 6C E9 0A, got % X", raw)
+	}
+
+	osFile, err := os.Open(pathStr)
+	if err != nil {
+		t.Fatalf("os.Open(%s) failed: %s", pathStr, err.Error())
+	}
+	defer osFile.Close()
+	readerClassName := "java/io/BufferedReader"
+	reader := object.MakeEmptyObjectWithClassName(&readerClassName)
+	reader.FieldTable[FileHandle] = object.Field{Ftype: types.FileHandle, Fvalue: osFile}
+
+	line := bufferedReaderReadLine([]interface{}{reader})
+	if got := object.GoStringFromStringObject(line.(*object.Object)); got != "olé" {
+		t.Errorf("default-charset readLine: expected \"olé\", got %q", got)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/globals"
+	"jacobin/object"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// a path built with a file.separator other than the platform's still names the right file,
+// and the File's separator fields hold the file.separator and path.separator
+func TestFileWithSimulatedSeparators(t *testing.T) {
+	globals.InitGlobals("test")
+	glob := globals.GetGlobalRef()
+	glob.FileSeparator = "#"
+	glob.PathSeparator = ";"
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	className := "java/io/File"
+	file := object.MakeEmptyObjectWithClassName(&className)
+	if ret := fileInit([]interface{}{file, object.StringObjectFromGoString(dir + "#sub#new.txt")}); ret != nil {
+		t.Fatalf("fileInit: unexpected error: %v", ret)
+	}
+
+	expected := filepath.Join(dir, "sub", "new.txt")
+	if got := string(file.FieldTable[FilePath].Fvalue.([]byte)); got != expected {
+		t.Errorf("Expected the file's path to be %s, got %s", expected, got)
+	}
+	if got := string(file.FieldTable["separator"].Fvalue.([]byte)); got != "#" {
+		t.Errorf("Expected separator to be #, got %s", got)
+	}
+	if got := file.FieldTable["separatorChar"].Fvalue.(int64); got != '#' {
+		t.Errorf("Expected separatorChar to be '#', got %c", rune(got))
+	}
+	if got := string(file.FieldTable["pathSeparator"].Fvalue.([]byte)); got != ";" {
+		t.Errorf("Expected pathSeparator to be ;, got %s", got)
+	}
+
+	if ret := fileCreate([]interface{}{file}); ret != int64(1) {
+		t.Fatalf("createNewFile(): expected true, got %v", ret)
+	}
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("Expected %s to have been created, but got: %s", expected, err.Error())
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by  the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/object"
+	"jacobin/types"
+	"os"
+)
+
+// java.io.InputStream is abstract. Its methods are called on System.in, which is a
+// FileInputStream that reads from stdin, and on the FileInputStreams that programs
+// refer to as InputStreams. So here, they are implemented by the FileInputStream methods.
+
+var classNameFileInputStream = "java/io/FileInputStream"
+
+func Load_Io_InputStream() {
+
+	MethodSignatures["java/io/InputStream.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/io/InputStream.close()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  fisClose,
+		}
+
+	MethodSignatures["java/io/InputStream.read()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  fisReadOne,
+		}
+
+	MethodSignatures["java/io/InputStream.read([B)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  fisReadByteArray,
+		}
+
+	MethodSignatures["java/io/InputStream.read([BII)I"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  fisReadByteArrayOffset,
+		}
+
+}
+
+// newStdinInputStream creates the InputStream for System.in, which reads from the given file
+func newStdinInputStream(stdin *os.File) *object.Object {
+	is := object.MakeEmptyObjectWithClassName(&classNameFileInputStream)
+	is.FieldTable[FilePath] = object.Field{Ftype: types.ByteArray, Fvalue: []byte(stdin.Name())}
+	is.FieldTable[FileHandle] = object.Field{Ftype: types.FileHandle, Fvalue: stdin}
+	return is
+}
+
+// inputStreamFile returns the file that an InputStream reads from. For compatibility with
+// code that predates InputStream objects, the stream can also be a bare *os.File.
+func inputStreamFile(stream interface{}) *os.File {
+	if is, ok := stream.(*object.Object); ok {
+		return is.FieldTable[FileHandle].Fvalue.(*os.File)
+	}
+	return stream.(*os.File)
+}
@@ -57,7 +57,7 @@ func Load_Io_InputStreamReader() {
 	// Traps that do nothing but return an error
 	// -----------------------------------------
 
-	MethodSignatures["java/io/InputStreamReader.<init>(Ljava/io/InputStream;Ljava/lang.String;)V"] =
+	MethodSignatures["java/io/InputStreamReader.<init>(Ljava/io/InputStream;Ljava/lang/String;)V"] =
 		GMeth{
 			ParamSlots: 2,
 			GFunction:  trapFunction,
@@ -69,7 +69,7 @@ func Load_Io_InputStreamReader() {
 			GFunction:  trapFunction,
 		}
 
-	MethodSignatures["java/io/InputStreamReader.<init>(Ljava/io/InputStream;Ljava/nio/charset/CharsetDecoder;)Ljava/lang.String;"] =
+	MethodSignatures["java/io/InputStreamReader.<init>(Ljava/io/InputStream;Ljava/nio/charset/CharsetDecoder;)V"] =
 		GMeth{
 			ParamSlots: 2,
 			GFunction:  trapFunction,
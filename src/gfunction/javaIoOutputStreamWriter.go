@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"jacobin/excNames"
 	"jacobin/object"
+	"jacobin/types"
 	"os"
 )
 
@@ -27,6 +28,18 @@ func Load_Io_OutputStreamWriter() {
 			GFunction:  initOutputStreamWriter,
 		}
 
+	MethodSignatures["java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/lang/String;)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  initOutputStreamWriterCharset,
+		}
+
+	MethodSignatures["java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/nio/charset/Charset;)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  initOutputStreamWriterCharset,
+		}
+
 	MethodSignatures["java/io/OutputStreamWriter.close()V"] =
 		GMeth{
 			ParamSlots: 0,
@@ -61,19 +74,7 @@ func Load_Io_OutputStreamWriter() {
 	// Traps that do nothing but return an error
 	// -----------------------------------------
 
-	MethodSignatures["java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/lang.String;)V"] =
-		GMeth{
-			ParamSlots: 2,
-			GFunction:  trapFunction,
-		}
-
-	MethodSignatures["java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/nio/charset/Charset;)V"] =
-		GMeth{
-			ParamSlots: 2,
-			GFunction:  trapFunction,
-		}
-
-	MethodSignatures["java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/nio/charset/CharsetDecoder;)Ljava/lang.String;"] =
+	MethodSignatures["java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/nio/charset/CharsetEncoder;)V"] =
 		GMeth{
 			ParamSlots: 2,
 			GFunction:  trapFunction,
@@ -122,6 +123,58 @@ func initOutputStreamWriter(params []interface{}) interface{} {
 	return nil
 }
 
+// "java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/lang/String;)V"
+// "java/io/OutputStreamWriter.<init>(Ljava/io/OutputStream;Ljava/nio/charset/Charset;)V"
+func initOutputStreamWriterCharset(params []interface{}) interface{} {
+	charsetName, gerr := getCharsetName(params[2], "initOutputStreamWriterCharset")
+	if gerr != nil {
+		return gerr
+	}
+	if ret := initOutputStreamWriter(params[:2]); ret != nil {
+		return ret
+	}
+	setWriterCharset(params[0].(*object.Object), charsetName)
+	return nil
+}
+
+// oswGetCharset returns the charset the writer encodes chars with: the one it was
+// created with, if any, otherwise the default charset.
+func oswGetCharset(writer *object.Object) string {
+	if charsetName, ok := writer.FieldTable[FileCharset].Fvalue.(string); ok {
+		return charsetName
+	}
+	return defaultCharsetName()
+}
+
+// oswEncode encodes chars in the writer's charset. As the JDK's encoder does, a high surrogate
+// at the end of the chars is held on the writer, rather than encoded as unpaired, so that
+// a surrogate pair written in two writes, as by write(int), is encoded as the character it
+// makes up. If the next char written isn't a low surrogate, the held char is encoded as an
+// unpaired surrogate then.
+func oswEncode(writer *object.Object, chars []int64) []byte {
+	if pending, ok := writer.FieldTable[FilePendingChar].Fvalue.(int64); ok {
+		chars = append([]int64{pending}, chars...)
+		delete(writer.FieldTable, FilePendingChar)
+	}
+	if last := len(chars) - 1; last >= 0 && chars[last] >= 0xD800 && chars[last] < 0xDC00 {
+		writer.FieldTable[FilePendingChar] = object.Field{Ftype: types.Char, Fvalue: chars[last]}
+		chars = chars[:last]
+	}
+	return encodeChars(chars, oswGetCharset(writer))
+}
+
+// oswWritePendingChar writes a high surrogate the writer holds, which no low surrogate
+// followed, as the charset's replacement for an unpaired surrogate
+func oswWritePendingChar(writer *object.Object, osFile *os.File) error {
+	pending, ok := writer.FieldTable[FilePendingChar].Fvalue.(int64)
+	if !ok {
+		return nil
+	}
+	delete(writer.FieldTable, FilePendingChar)
+	_, err := osFile.Write(encodeChars([]int64{pending}, oswGetCharset(writer)))
+	return err
+}
+
 func oswClose(params []interface{}) interface{} {
 
 	// Get file handle.
@@ -131,7 +184,11 @@ func oswClose(params []interface{}) interface{} {
 		return getGErrBlk(excNames.IOException, errMsg)
 	}
 
-	// Close the file.
+	// Write any high surrogate still waiting for its low surrogate, then close the file.
+	if err := oswWritePendingChar(params[0].(*object.Object), osFile); err != nil {
+		errMsg := fmt.Sprintf("osFile.Write failed, reason: %s", err.Error())
+		return getGErrBlk(excNames.IOException, errMsg)
+	}
 	err := osFile.Close()
 	if err != nil {
 		errMsg := fmt.Sprintf("osFile.Close() failed, reason: %s", err.Error())
@@ -149,7 +206,11 @@ func oswFlush(params []interface{}) interface{} {
 		return getGErrBlk(excNames.IOException, errMsg)
 	}
 
-	// Flush the file's buffers.
+	// Write any high surrogate still waiting for its low surrogate, then flush the file's buffers.
+	if err := oswWritePendingChar(params[0].(*object.Object), osFile); err != nil {
+		errMsg := fmt.Sprintf("osFile.Write failed, reason: %s", err.Error())
+		return getGErrBlk(excNames.IOException, errMsg)
+	}
 	err := osFile.Sync()
 	if err != nil {
 		errMsg := fmt.Sprintf("osFile.Sync() failed, reason: %s", err.Error())
@@ -178,11 +239,10 @@ func oswWriteOneChar(params []interface{}) interface{} {
 		return getGErrBlk(excNames.IOException, errMsg)
 	}
 
-	// Create a one-byte buffer.
-	buffer := make([]byte, 1)
-	buffer[0] = byte(wint % 256)
+	// Encode the char. A high surrogate is held until the next char is written.
+	buffer := oswEncode(obj, []int64{wint & 0xFFFF})
 
-	// Write one byte.
+	// Write the char's bytes.
 	_, err := osFile.Write(buffer)
 	if err != nil {
 		errMsg := fmt.Sprintf("osFile.Write failed, reason: %s", err.Error())
@@ -221,11 +281,8 @@ func oswWriteCharBuffer(params []interface{}) interface{} {
 		return getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
 	}
 
-	// Create and fill a byte buffer.
-	outBytes := make([]byte, length)
-	for ii := int64(0); ii < length; ii++ {
-		outBytes[ii] = byte(intArray[offset+ii])
-	}
+	// Encode the chars into a byte buffer.
+	outBytes := oswEncode(params[0].(*object.Object), intArray[offset:offset+length])
 
 	// Write the byte buffer.
 	_, err := osFile.Write(outBytes)
@@ -256,21 +313,21 @@ func oswWriteStringBuffer(params []interface{}) interface{} {
 	offset := params[2].(int64)
 	length := params[3].(int64)
 
+	// The offset and length count chars, not bytes.
+	chars := object.JavaCharArrayFromGoString(string(paramBytes))
+
 	// Check parameters.
 	if length == 0 {
 		return int64(0)
 	}
-	if length < 0 || offset < 0 || length > (int64(len(paramBytes))-offset) {
-		errMsg := fmt.Sprintf("Error in parameters: offset=%d, length=%d, char.array.length=%d",
-			offset, length, len(paramBytes))
+	if length < 0 || offset < 0 || length > (int64(len(chars))-offset) {
+		errMsg := fmt.Sprintf("Error in parameters: offset=%d, length=%d, string.length=%d",
+			offset, length, len(chars))
 		return getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
 	}
 
-	// Create and fill a byte buffer.
-	outBytes := make([]byte, length)
-	for ii := int64(0); ii < length; ii++ {
-		outBytes[ii] = paramBytes[offset+ii]
-	}
+	// Encode the chars into a byte buffer.
+	outBytes := oswEncode(params[0].(*object.Object), chars[offset:offset+length])
 
 	// Write the byte buffer.
 	_, err := osFile.Write(outBytes)
@@ -8,11 +8,12 @@ package gfunction
 
 import (
 	"fmt"
+	"io"
 	"jacobin/excNames"
 	"jacobin/object"
 	"jacobin/types"
 	"math"
-	"os"
+	"strings"
 )
 
 /*
@@ -32,6 +33,16 @@ import (
  return value on the operand stack of the calling function.
 */
 
+// A PrintStream is an object whose FileHandle field holds the io.Writer that its output
+// goes to. This is an *os.File for System.out and System.err, but it can be any writer,
+// so that a host or test can capture what a program prints (see NewPrintStream() and
+// System.setOut()). Its autoFlush field holds a Java boolean that says whether the
+// writer is flushed whenever a line is completed, so that output that a buffered writer
+// holds back does not appear out of order with respect to other streams. Its charset
+// field holds the Charset in which the output is encoded.
+
+var classNamePrintStream = "java/io/PrintStream"
+
 func Load_Io_PrintStream() {
 	MethodSignatures["java/io/PrintStream.println()V"] = // println void
 		GMeth{
@@ -86,6 +97,12 @@ func Load_Io_PrintStream() {
 			GFunction:  PrintlnDoubleFloat,
 		}
 
+	MethodSignatures["java/io/PrintStream.println([C)V"] = // println char array
+		GMeth{
+			ParamSlots: 1, // 1 slot for the char array
+			GFunction:  PrintlnCharArray,
+		}
+
 	MethodSignatures["java/io/PrintStream.println(Ljava/lang/Object;)V"] = // println object
 		GMeth{
 			ParamSlots: 1, // 1 slot for the Object
@@ -140,6 +157,12 @@ func Load_Io_PrintStream() {
 			GFunction:  PrintFloat,
 		}
 
+	MethodSignatures["java/io/PrintStream.print([C)V"] = // print char array
+		GMeth{
+			ParamSlots: 1, // 1 slot for the char array
+			GFunction:  PrintCharArray,
+		}
+
 	MethodSignatures["java/io/PrintStream.print(Ljava/lang/Object;)V"] = // print object
 		GMeth{
 			ParamSlots: 1, // 1 slot for the Object
@@ -152,6 +175,30 @@ func Load_Io_PrintStream() {
 			GFunction:  Printf,
 		}
 
+	MethodSignatures["java/io/PrintStream.format(Ljava/lang/String;[Ljava/lang/Object;)Ljava/io/PrintStream;"] =
+		GMeth{
+			ParamSlots: 2, // the format string, the parameters (if any)
+			GFunction:  Printf,
+		}
+
+	MethodSignatures["java/io/PrintStream.append(Ljava/lang/CharSequence;)Ljava/io/PrintStream;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  PrintStreamAppend,
+		}
+
+	MethodSignatures["java/io/PrintStream.append(Ljava/lang/CharSequence;)Ljava/lang/Appendable;"] = // via Appendable
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  PrintStreamAppend,
+		}
+
+	MethodSignatures["java/io/PrintStream.flush()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  PrintStreamFlush,
+		}
+
 }
 
 // "java/io/PrintStream.println(Ljava/lang/String;)V"
@@ -165,10 +212,12 @@ func PrintlnString(params []interface{}) interface{} {
 	// Handle null strings as well as []byte.
 	fld := param1.FieldTable["value"]
 	if fld.Fvalue == nil {
-		fmt.Fprintln(params[0].(*os.File), "")
+		fmt.Fprintln(printStreamWriter(params[0]), "")
+		printStreamAutoFlush(params[0], true)
 	} else {
 		str := string(fld.Fvalue.([]byte))
-		fmt.Fprintln(params[0].(*os.File), str)
+		fmt.Fprintln(printStreamWriter(params[0]), str)
+		printStreamAutoFlush(params[0], true)
 	}
 
 	return nil
@@ -177,14 +226,16 @@ func PrintlnString(params []interface{}) interface{} {
 // PrintlnV = java/io/Prinstream.println() -- println() prints a newline (V = void)
 // "java/io/PrintStream.println()V"
 func PrintlnV(params []interface{}) interface{} {
-	fmt.Fprintln(params[0].(*os.File), "")
+	fmt.Fprintln(printStreamWriter(params[0]), "")
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
 // "java/io/PrintStream.println(C)V"
 func PrintlnChar(params []interface{}) interface{} {
 	cc := fmt.Sprint(params[1].(int64))
-	fmt.Fprintln(params[0].(*os.File), cc)
+	fmt.Fprintln(printStreamWriter(params[0]), cc)
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
@@ -193,7 +244,8 @@ func PrintlnChar(params []interface{}) interface{} {
 // "java/io/PrintStream.println(S)V"
 func PrintlnBIS(params []interface{}) interface{} {
 	intToPrint := params[1].(int64) // contains an int
-	fmt.Fprintln(params[0].(*os.File), intToPrint)
+	fmt.Fprintln(printStreamWriter(params[0]), intToPrint)
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
@@ -206,14 +258,16 @@ func PrintlnBoolean(params []interface{}) interface{} {
 	} else {
 		boolToPrint = false
 	}
-	fmt.Fprintln(params[0].(*os.File), boolToPrint)
+	fmt.Fprintln(printStreamWriter(params[0]), boolToPrint)
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
 // "java/io/PrintStream.println(J)V"
 func PrintlnLong(params []interface{}) interface{} {
 	longToPrint := params[1].(int64) // contains to an int64--the equivalent of a Java long
-	fmt.Fprintln(params[0].(*os.File), longToPrint)
+	fmt.Fprintln(printStreamWriter(params[0]), longToPrint)
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
@@ -222,7 +276,8 @@ func PrintlnLong(params []interface{}) interface{} {
 // "java/io/PrintStream.println(F)V"
 func PrintlnDoubleFloat(params []interface{}) interface{} {
 	doubleToPrint := params[1].(float64) // contains to a float64--the equivalent of a Java double
-	fmt.Fprintf(params[0].(*os.File), getDoubleFormat(doubleToPrint)+"\n", doubleToPrint)
+	fmt.Fprintf(printStreamWriter(params[0]), getDoubleFormat(doubleToPrint)+"\n", doubleToPrint)
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
@@ -233,20 +288,32 @@ func PrintlnObject(params []interface{}) interface{} {
 		errMsg := fmt.Sprintf("Expected params[1] of type *object.Object but observed type %T\n", params[1])
 		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
 	}
-	objPtr := params[1].(*object.Object)
-	fld := objPtr.FieldTable["value"]
-	if fld.Ftype == types.ByteArray {
-		fmt.Fprintln(params[0].(*os.File), string(fld.Fvalue.([]byte)))
-		return nil
+	str, gerr := printableObject(params[1].(*object.Object))
+	if gerr != nil {
+		return gerr
 	}
-	fmt.Fprintln(params[0].(*os.File), fld.Fvalue)
+	fmt.Fprintln(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], true)
+	return nil
+}
+
+// Println a char array. The chars are UTF-16 code units, so surrogate pairs are
+// combined into a single code point before printing.
+// "java/io/PrintStream.println([C)V"
+func PrintlnCharArray(params []interface{}) interface{} {
+	str, gerr := goStringFromCharArrayParam(params[1])
+	if gerr != nil {
+		return gerr
+	}
+	fmt.Fprintln(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], true)
 	return nil
 }
 
 // "java/io/PrintStream.print(C)V"
 func PrintChar(params []interface{}) interface{} {
 	cc := fmt.Sprint(params[1].(int64))
-	fmt.Fprint(params[0].(*os.File), cc)
+	fmt.Fprint(printStreamWriter(params[0]), cc)
 	return nil
 }
 
@@ -255,7 +322,7 @@ func PrintChar(params []interface{}) interface{} {
 // "java/io/PrintStream.print(S)V"
 func PrintBIS(params []interface{}) interface{} {
 	intToPrint := params[1].(int64) // contains an int
-	fmt.Fprint(params[0].(*os.File), intToPrint)
+	fmt.Fprint(printStreamWriter(params[0]), intToPrint)
 	return nil
 }
 
@@ -269,7 +336,7 @@ func PrintBoolean(params []interface{}) interface{} {
 	} else {
 		boolToPrint = false
 	}
-	fmt.Fprint(params[0].(*os.File), boolToPrint)
+	fmt.Fprint(printStreamWriter(params[0]), boolToPrint)
 	return nil
 }
 
@@ -278,7 +345,7 @@ func PrintBoolean(params []interface{}) interface{} {
 // "java/io/PrintStream.print(J)V"
 func PrintLong(params []interface{}) interface{} {
 	longToPrint := params[1].(int64) // contains to an int64--the equivalent of a Java long
-	fmt.Fprint(params[0].(*os.File), longToPrint)
+	fmt.Fprint(printStreamWriter(params[0]), longToPrint)
 	return nil
 }
 
@@ -287,7 +354,7 @@ func PrintLong(params []interface{}) interface{} {
 // "java/io/PrintStream.print(F)V"
 func PrintFloat(params []interface{}) interface{} {
 	floatToPrint := params[1].(float64) // contains to a float64--the equivalent of a Java double
-	fmt.Fprintf(params[0].(*os.File), getDoubleFormat(floatToPrint), floatToPrint)
+	fmt.Fprintf(printStreamWriter(params[0]), getDoubleFormat(floatToPrint), floatToPrint)
 	return nil
 }
 
@@ -296,7 +363,7 @@ func PrintFloat(params []interface{}) interface{} {
 // "java/io/PrintStream.print(D)V"
 func PrintDouble(params []interface{}) interface{} {
 	doubleToPrint := params[1].(float64) // contains to a float64--the equivalent of a Java double
-	fmt.Fprintf(params[0].(*os.File), getDoubleFormat(doubleToPrint), doubleToPrint)
+	fmt.Fprintf(printStreamWriter(params[0]), getDoubleFormat(doubleToPrint), doubleToPrint)
 	return nil
 }
 
@@ -312,7 +379,21 @@ func PrintString(params []interface{}) interface{} {
 		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
 	}
 
-	fmt.Fprint(params[0].(*os.File), str)
+	fmt.Fprint(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], strings.Contains(str, "\n"))
+	return nil
+}
+
+// Print a char array. The chars are UTF-16 code units, so surrogate pairs are
+// combined into a single code point before printing.
+// "java/io/PrintStream.print([C)V"
+func PrintCharArray(params []interface{}) interface{} {
+	str, gerr := goStringFromCharArrayParam(params[1])
+	if gerr != nil {
+		return gerr
+	}
+	fmt.Fprint(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], strings.Contains(str, "\n"))
 	return nil
 }
 
@@ -323,33 +404,84 @@ func PrintObject(params []interface{}) interface{} {
 		errMsg := fmt.Sprintf("Expected params[1] of type *object.Object but observed type %T\n", params[1])
 		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
 	}
-	objPtr := params[1].(*object.Object)
-	fld := objPtr.FieldTable["value"]
-	if fld.Ftype == types.ByteArray {
-		fmt.Fprint(params[0].(*os.File), string(fld.Fvalue.([]byte)))
-		return nil
+	str, gerr := printableObject(params[1].(*object.Object))
+	if gerr != nil {
+		return gerr
 	}
-	fmt.Fprint(params[0].(*os.File), fld.Fvalue)
+	fmt.Fprint(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], strings.Contains(str, "\n"))
 	return nil
 }
 
+// printableObject returns what print(Object) and println(Object) show for obj, which is
+// what String.valueOf(Object) returns: "null" for a null object, and otherwise the object's
+// toString(), dispatched as in Java, so that an override in the object's class is used
+func printableObject(obj *object.Object) (string, *GErrBlk) {
+	switch result := valueOfObject([]interface{}{obj}).(type) {
+	case *object.Object:
+		return object.GoStringFromStringObject(result), nil
+	case *GErrBlk:
+		return "", result
+	}
+	return "null", nil
+}
+
 // Printf -- handle the variable args and then call golang's own printf function
+// "java/io/PrintStream.format(Ljava/lang/String;[Ljava/lang/Object;)Ljava/io/PrintStream;"
 // "java/io/PrintStream.printf(Ljava/lang/String;[Ljava/lang/Object;)Ljava/io/PrintStream;"
 func Printf(params []interface{}) interface{} {
 	var intfSprintf = new([]interface{})
 	*intfSprintf = append(*intfSprintf, params[1])
 	*intfSprintf = append(*intfSprintf, params[2])
 	retval := StringFormatter(*intfSprintf)
-	switch retval.(type) {
+	var objPtr *object.Object
+	switch r := retval.(type) {
 	case *object.Object:
+		objPtr = r
+	case *GErrBlk: // formatting failed: throw the exception rather than return it as the result
+		return r
 	default:
-		return retval
+		errMsg := fmt.Sprintf("Printf: unexpected result from the formatter: %T", retval)
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
 	}
-	objPtr := retval.(*object.Object)
 	str := object.GoStringFromStringObject(objPtr)
-	fmt.Fprint(params[0].(*os.File), str)
-	return params[0] // Return the PrintStream object
+	fmt.Fprint(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], strings.Contains(str, "\n"))
+	return params[0] // Return the PrintStream object, so that calls can be chained
+}
+
+// Append a CharSequence (e.g., a String or a StringBuilder), which is printed as its
+// toString() would show it. As in the JDK, a null CharSequence is printed as "null".
+// "java/io/PrintStream.append(Ljava/lang/CharSequence;)Ljava/io/PrintStream;"
+// "java/io/PrintStream.append(Ljava/lang/CharSequence;)Ljava/lang/Appendable;"
+func PrintStreamAppend(params []interface{}) interface{} {
+	str := "null"
+	if csq, ok := params[1].(*object.Object); ok && !object.IsNull(csq) {
+		switch result := valueOfObject([]interface{}{csq}).(type) {
+		case *object.Object:
+			str = object.GoStringFromStringObject(result)
+		case *GErrBlk:
+			return result
+		}
+	}
+	fmt.Fprint(printStreamWriter(params[0]), str)
+	printStreamAutoFlush(params[0], strings.Contains(str, "\n"))
+	return params[0] // Return the PrintStream object, so that calls can be chained
+}
 
+// Extract the Go string from a char array parameter. As in the JDK, a null array
+// results in a NullPointerException.
+func goStringFromCharArrayParam(param interface{}) (string, *GErrBlk) {
+	arrObj, ok := param.(*object.Object)
+	if !ok || object.IsNull(arrObj) {
+		return "", getGErrBlk(excNames.NullPointerException, "char array is null")
+	}
+	chars, ok := arrObj.FieldTable["value"].Fvalue.([]int64)
+	if !ok {
+		errMsg := fmt.Sprintf("Expected a char array but observed type %T\n", arrObj.FieldTable["value"].Fvalue)
+		return "", getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+	return object.GoStringFromJavaCharArray(chars), nil
 }
 
 // Trying to approximate the exact formatting used in HotSpot JVM
@@ -365,3 +497,114 @@ func getDoubleFormat(d float64) string {
 		}
 	}
 }
+
+// NewPrintStream creates a PrintStream object whose output goes to the given writer.
+// Like System.out and System.err, it flushes its output whenever a line is completed.
+func NewPrintStream(w io.Writer) *object.Object {
+	return NewPrintStreamAutoFlush(w, true)
+}
+
+// NewPrintStreamAutoFlush creates a PrintStream object whose output goes to the given
+// writer and which flushes that output when a line is completed only if autoFlush is
+// set, as with the JDK's PrintStream(OutputStream, boolean) constructor.
+func NewPrintStreamAutoFlush(w io.Writer, autoFlush bool) *object.Object {
+	ps := object.MakeEmptyObjectWithClassName(&classNamePrintStream)
+	ps.FieldTable[FileHandle] = object.Field{Ftype: types.FileHandle, Fvalue: w}
+	ps.FieldTable["autoFlush"] = object.Field{Ftype: types.Bool, Fvalue: types.ConvertGoBoolToJavaBool(autoFlush)}
+	setPrintStreamCharset(ps, defaultCharsetName())
+	return ps
+}
+
+// newConsolePrintStream creates the PrintStream for System.out or System.err, whose
+// output is encoded in the console encoding set by -Dstdout.encoding or -Dstderr.encoding
+// rather than in the default charset. If that encoding is one Jacobin cannot encode,
+// UTF-8 is used.
+func newConsolePrintStream(w io.Writer, encoding string) *object.Object {
+	ps := NewPrintStream(w)
+	charsetName := canonicalCharsetName(encoding)
+	if charsetName == "" {
+		charsetName = CharsetUTF8
+	}
+	setPrintStreamCharset(ps, charsetName)
+	return ps
+}
+
+// setPrintStreamCharset sets the supported charset in which a PrintStream encodes its output
+func setPrintStreamCharset(ps *object.Object, canonicalName string) {
+	ps.FieldTable["charset"] = object.Field{Ftype: "Ljava/nio/charset/Charset;", Fvalue: newCharset(canonicalName)}
+}
+
+// printStreamWriter returns the writer that a PrintStream's output goes to. Go strings
+// are UTF-8, so if the stream's charset is another one, the writer encodes what is
+// written to it in that charset. For compatibility with code that predates PrintStream
+// objects, the stream can also be a bare io.Writer, such as an *os.File.
+func printStreamWriter(stream interface{}) io.Writer {
+	w := printStreamTarget(stream)
+	if ps, ok := stream.(*object.Object); ok {
+		if cs, ok := ps.FieldTable["charset"].Fvalue.(*object.Object); ok {
+			nameObj := cs.FieldTable["name"].Fvalue.(*object.Object)
+			if charsetName := object.GoStringFromStringObject(nameObj); charsetName != CharsetUTF8 {
+				return charsetWriter{w: w, charsetName: charsetName}
+			}
+		}
+	}
+	return w
+}
+
+// printStreamTarget returns the writer that a PrintStream's output goes to, as is
+func printStreamTarget(stream interface{}) io.Writer {
+	if ps, ok := stream.(*object.Object); ok {
+		return ps.FieldTable[FileHandle].Fvalue.(io.Writer)
+	}
+	return stream.(io.Writer)
+}
+
+// A charsetWriter encodes the UTF-8 text written to it in a supported charset, replacing
+// characters that the charset lacks with '?', and writes the result to w
+type charsetWriter struct {
+	w           io.Writer
+	charsetName string
+}
+
+func (cw charsetWriter) Write(p []byte) (int, error) {
+	encoded := encodeChars(object.JavaCharArrayFromGoString(string(p)), cw.charsetName)
+	if _, err := cw.w.Write(encoded); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// A writer that buffers its output, such as a *bufio.Writer, has a Flush() method. An
+// *os.File does not buffer what is written to it, so it needs no flushing.
+type printStreamFlusher interface {
+	Flush() error
+}
+
+// printStreamAutoFlush flushes a PrintStream's output if a line was completed and the
+// stream's autoflush flag is set. As in the JDK, println() always completes a line, while
+// print() and printf() do so only if they write a newline. A bare io.Writer is treated
+// as a stream with autoflush set.
+func printStreamAutoFlush(stream interface{}, lineCompleted bool) {
+	if !lineCompleted {
+		return
+	}
+	if ps, ok := stream.(*object.Object); ok {
+		if ps.FieldTable["autoFlush"].Fvalue != types.JavaBoolTrue {
+			return
+		}
+	}
+	if fl, ok := printStreamTarget(stream).(printStreamFlusher); ok {
+		_ = fl.Flush()
+	}
+}
+
+// PrintStreamFlush flushes any output that the PrintStream's writer has buffered
+// "java/io/PrintStream.flush()V"
+func PrintStreamFlush(params []interface{}) interface{} {
+	if fl, ok := printStreamTarget(params[0]).(printStreamFlusher); ok {
+		if err := fl.Flush(); err != nil {
+			return getGErrBlk(excNames.IOException, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/statics"
+	"jacobin/types"
+	"os"
+	"testing"
+)
+
+// runs a PrintStream gfunction against a pipe and returns what it wrote
+func capturePrintStream(gfunc func([]interface{}) interface{}, arg interface{}) (string, interface{}) {
+	r, w, _ := os.Pipe()
+	ret := gfunc([]interface{}{w, arg})
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out), ret
+}
+
+func TestPrintCharArrayRoundTrip(t *testing.T) {
+	globals.InitGlobals("test")
+
+	// mixes 1-, 2-, 3-, and 4-byte UTF-8 characters; the last two need surrogate pairs in UTF-16
+	original := "Añ€ 𝄞 ok 😀"
+	strObj := object.StringObjectFromGoString(original)
+	charArray := toCharArray([]interface{}{strObj}).(*object.Object)
+
+	chars := charArray.FieldTable["value"].Fvalue.([]int64)
+	if len(chars) != 12 {
+		t.Errorf("TestPrintCharArrayRoundTrip: expected 12 UTF-16 chars, got %d", len(chars))
+	}
+
+	out, ret := capturePrintStream(PrintCharArray, charArray)
+	if ret != nil {
+		t.Fatalf("TestPrintCharArrayRoundTrip: unexpected error: %v", ret)
+	}
+	if out != original {
+		t.Errorf("TestPrintCharArrayRoundTrip: expected '%s', got '%s'", original, out)
+	}
+}
+
+func TestPrintlnCharArrayRoundTrip(t *testing.T) {
+	globals.InitGlobals("test")
+
+	original := "Grüße, 世界 🌍"
+	strObj := object.StringObjectFromGoString(original)
+	charArray := toCharArray([]interface{}{strObj}).(*object.Object)
+
+	out, ret := capturePrintStream(PrintlnCharArray, charArray)
+	if ret != nil {
+		t.Fatalf("TestPrintlnCharArrayRoundTrip: unexpected error: %v", ret)
+	}
+	if out != original+"\n" {
+		t.Errorf("TestPrintlnCharArrayRoundTrip: expected '%s\\n', got '%s'", original, out)
+	}
+}
+
+func TestPrintCharArrayNull(t *testing.T) {
+	globals.InitGlobals("test")
+
+	_, ret := capturePrintStream(PrintCharArray, object.Null)
+	if _, ok := ret.(*GErrBlk); !ok {
+		t.Errorf("TestPrintCharArrayNull: expected a GErrBlk for a null array, got %v", ret)
+	}
+}
+
+// makes the Object[] of arguments passed to printf
+func makePrintfArgs(args ...*object.Object) *object.Object {
+	classStr := "[Ljava/lang/Object;"
+	argsObj := object.MakeEmptyObjectWithClassName(&classStr)
+	argsObj.FieldTable["value"] = object.Field{Ftype: classStr, Fvalue: args}
+	return argsObj
+}
+
+func TestPrintfChaining(t *testing.T) {
+	globals.InitGlobals("test")
+
+	var buf bytes.Buffer
+	ps := NewPrintStream(&buf)
+
+	intClass := "java/lang/Integer"
+	count := object.MakeEmptyObjectWithClassName(&intClass)
+	count.FieldTable["value"] = object.Field{Ftype: types.Int, Fvalue: int64(3)}
+
+	// the equivalent of System.out.printf("%d lambs", 3).printf(" and %s", "a ewe")
+	ret := Printf([]interface{}{ps, object.StringObjectFromGoString("%d lambs"), makePrintfArgs(count)})
+	if ret != ps {
+		t.Fatalf("TestPrintfChaining: expected the PrintStream to be returned, got %v", ret)
+	}
+	ret = Printf([]interface{}{ret, object.StringObjectFromGoString(" and %s"),
+		makePrintfArgs(object.StringObjectFromGoString("a ewe"))})
+	if ret != ps {
+		t.Fatalf("TestPrintfChaining: expected the PrintStream from the chained call, got %v", ret)
+	}
+
+	if buf.String() != "3 lambs and a ewe" {
+		t.Errorf("TestPrintfChaining: expected '3 lambs and a ewe', got '%s'", buf.String())
+	}
+}
+
+func TestPrintfMalformedFormat(t *testing.T) {
+	globals.InitGlobals("test")
+
+	var buf bytes.Buffer
+	ps := NewPrintStream(&buf)
+
+	// %d applied to a string
+	ret := Printf([]interface{}{ps, object.StringObjectFromGoString("count: %d"),
+		makePrintfArgs(object.StringObjectFromGoString("three"))})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok {
+		t.Fatalf("TestPrintfMalformedFormat: expected a GErrBlk, got %T", ret)
+	}
+	if gerr.ExceptionType != excNames.IllegalFormatConversionException {
+		t.Errorf("TestPrintfMalformedFormat: expected IllegalFormatConversionException, got %d",
+			gerr.ExceptionType)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("TestPrintfMalformedFormat: expected no output, got '%s'", buf.String())
+	}
+}
+
+func TestPrintfLiteralPercentBang(t *testing.T) {
+	globals.InitGlobals("test")
+
+	var buf bytes.Buffer
+	ps := NewPrintStream(&buf)
+
+	ret := Printf([]interface{}{ps, object.StringObjectFromGoString("100%%! %s"),
+		makePrintfArgs(object.StringObjectFromGoString("done"))})
+	if ret != ps {
+		t.Fatalf("TestPrintfLiteralPercentBang: expected the PrintStream to be returned, got %v", ret)
+	}
+	if buf.String() != "100%! done" {
+		t.Errorf("TestPrintfLiteralPercentBang: expected '100%%! done', got '%s'", buf.String())
+	}
+}
+
+func TestPrintCharArrayToPrintStream(t *testing.T) {
+	globals.InitGlobals("test")
+
+	var buf bytes.Buffer
+	ps := NewPrintStream(&buf)
+	charArray := toCharArray([]interface{}{object.StringObjectFromGoString("chars")}).(*object.Object)
+
+	if ret := PrintCharArray([]interface{}{ps, charArray}); ret != nil {
+		t.Fatalf("TestPrintCharArrayToPrintStream: unexpected error: %v", ret)
+	}
+	if ret := PrintlnCharArray([]interface{}{ps, charArray}); ret != nil {
+		t.Fatalf("TestPrintCharArrayToPrintStream: unexpected error: %v", ret)
+	}
+	if buf.String() != "charschars\n" {
+		t.Errorf("TestPrintCharArrayToPrintStream: expected 'charschars\\n', got '%s'", buf.String())
+	}
+}
+
+func TestPrintStreamAppend(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_StringBuilder() // so that a StringBuilder's toString() can be found
+
+	var buf bytes.Buffer
+	ps := NewPrintStream(&buf)
+
+	// the equivalent of ps.append("abc").append(new StringBuilder("def")).append(null)
+	ret := PrintStreamAppend([]interface{}{ps, object.StringObjectFromGoString("abc")})
+	if ret != ps {
+		t.Fatalf("TestPrintStreamAppend: expected the PrintStream to be returned, got %v", ret)
+	}
+	ret = PrintStreamAppend([]interface{}{ret, makeStringBuilder("def")})
+	if ret != ps {
+		t.Fatalf("TestPrintStreamAppend: expected the PrintStream from the chained call, got %v", ret)
+	}
+	PrintStreamAppend([]interface{}{ps, object.Null})
+
+	if buf.String() != "abcdefnull" {
+		t.Errorf("TestPrintStreamAppend: expected 'abcdefnull', got '%s'", buf.String())
+	}
+}
+
+// With autoflush, lines written to System.out and System.err appear in the order in which they
+// were written, even though each stream's writer buffers its output
+func TestPrintStreamAutoFlushPreservesOrder(t *testing.T) {
+	globals.InitGlobals("test")
+	originalErr, hadErr := statics.Statics["java/lang/System.err"]
+	originalOut, hadOut := statics.Statics["java/lang/System.out"]
+	defer func() {
+		if hadErr {
+			statics.Statics["java/lang/System.err"] = originalErr
+		} else {
+			delete(statics.Statics, "java/lang/System.err")
+		}
+		if hadOut {
+			statics.Statics["java/lang/System.out"] = originalOut
+		} else {
+			delete(statics.Statics, "java/lang/System.out")
+		}
+	}()
+
+	// the console: both streams end up in the same place, each through its own buffer
+	var console bytes.Buffer
+	_ = setOut([]interface{}{NewPrintStream(bufio.NewWriter(&console))})
+	_ = setErr([]interface{}{NewPrintStream(bufio.NewWriter(&console))})
+	out := statics.GetStaticValue("java/lang/System", "out")
+	errStream := statics.GetStaticValue("java/lang/System", "err")
+
+	_ = PrintlnString([]interface{}{out, object.StringObjectFromGoString("out 1")})
+	_ = PrintlnString([]interface{}{errStream, object.StringObjectFromGoString("err 1")})
+	_ = PrintBIS([]interface{}{out, int64(2)}) // no newline, so this stays in the buffer...
+	_ = PrintString([]interface{}{errStream, object.StringObjectFromGoString("err 2\n")})
+	_ = PrintlnV([]interface{}{out}) // ...until the line is completed
+	three := makeBoxedForTest("java/lang/Integer", types.Int, int64(3))
+	_ = Printf([]interface{}{out, object.StringObjectFromGoString("out %d%n"), makePrintfArgs(three)})
+
+	expected := "out 1\nerr 1\nerr 2\n2\nout 3" + lineSeparator()
+	if console.String() != expected {
+		t.Errorf("TestPrintStreamAutoFlushPreservesOrder: expected %q, got %q", expected, console.String())
+	}
+}
+
+// Without autoflush, output stays in the writer's buffer until flush() is called
+func TestPrintStreamWithoutAutoFlush(t *testing.T) {
+	globals.InitGlobals("test")
+
+	var buf bytes.Buffer
+	ps := NewPrintStreamAutoFlush(bufio.NewWriter(&buf), false)
+	_ = PrintlnString([]interface{}{ps, object.StringObjectFromGoString("held")})
+	if buf.Len() != 0 {
+		t.Errorf("TestPrintStreamWithoutAutoFlush: expected no output before flush(), got %q", buf.String())
+	}
+
+	if ret := PrintStreamFlush([]interface{}{ps}); ret != nil {
+		t.Fatalf("TestPrintStreamWithoutAutoFlush: flush() returned an unexpected value: %v", ret)
+	}
+	if buf.String() != "held\n" {
+		t.Errorf("TestPrintStreamWithoutAutoFlush: expected %q after flush(), got %q", "held\n", buf.String())
+	}
+}
+
+// System.out and System.err encode their output in the console encoding, which can differ
+// from file.encoding
+func TestPrintStreamConsoleEncoding(t *testing.T) {
+	globals.InitGlobals("test")
+	str := object.StringObjectFromGoString("café ñ")
+
+	tests := []struct {
+		encoding string
+		expected []byte
+	}{
+		{"ISO-8859-1", []byte{'c', 'a', 'f', 0xE9, ' ', 0xF1, '\n'}},
+		{"US-ASCII", []byte("caf? ?\n")},
+		{"UTF-8", []byte("café ñ\n")},
+		{"x-unsupported", []byte("café ñ\n")}, // an encoding Jacobin lacks falls back to UTF-8
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		ps := newConsolePrintStream(&buf, tt.encoding)
+		_ = PrintlnString([]interface{}{ps, str})
+		if !bytes.Equal(buf.Bytes(), tt.expected) {
+			t.Errorf("TestPrintStreamConsoleEncoding: with %s, expected bytes % X, got % X",
+				tt.encoding, tt.expected, buf.Bytes())
+		}
+	}
+}
+
+// The console encoding is independent of file.encoding and is reported by stdout.encoding
+func TestStdoutEncodingProperty(t *testing.T) {
+	globals.InitGlobals("test")
+	g := globals.GetGlobalRef()
+	g.FileEncoding = "UTF-8"
+	g.StdoutEncoding = "ISO-8859-1"
+	defer func() { g.StdoutEncoding = "UTF-8" }()
+
+	for _, name := range []string{"stdout.encoding", "sun.stdout.encoding"} {
+		prop := getProperty([]interface{}{object.StringObjectFromGoString(name)}).(*object.Object)
+		if object.GoStringFromStringObject(prop) != "ISO-8859-1" {
+			t.Errorf("TestStdoutEncodingProperty: expected %s to be ISO-8859-1, got %s",
+				name, object.GoStringFromStringObject(prop))
+		}
+	}
+
+	var buf bytes.Buffer
+	ps := newConsolePrintStream(&buf, g.StdoutEncoding)
+	_ = PrintString([]interface{}{ps, object.StringObjectFromGoString("é")})
+	if !bytes.Equal(buf.Bytes(), []byte{0xE9}) {
+		t.Errorf("TestStdoutEncodingProperty: expected byte E9, got % X", buf.Bytes())
+	}
+}
+
+// println(Object) and print(Object) of a StringBuilder print its contents, via its toString()
+func TestPrintObjectStringBuilder(t *testing.T) {
+	globals.InitGlobals("test")
+
+	className := "java/lang/StringBuilder"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	stringBuilderInitString([]interface{}{sb, object.StringObjectFromGoString("built up")})
+
+	if out, ret := capturePrintStream(PrintlnObject, sb); ret != nil || out != "built up\n" {
+		t.Errorf("println(Object) of a StringBuilder: expected %q, got %q (%v)", "built up\n", out, ret)
+	}
+	if out, ret := capturePrintStream(PrintObject, sb); ret != nil || out != "built up" {
+		t.Errorf("print(Object) of a StringBuilder: expected %q, got %q (%v)", "built up", out, ret)
+	}
+}
+
+// print(Object) of a boxed Integer prints its value, and of a String, the string itself
+func TestPrintObjectBoxedAndString(t *testing.T) {
+	globals.InitGlobals("test")
+
+	boxed := object.MakePrimitiveObject("java/lang/Integer", types.Int, int64(42))
+	if out, _ := capturePrintStream(PrintObject, boxed); out != "42" {
+		t.Errorf("print(Object) of an Integer: expected %q, got %q", "42", out)
+	}
+	str := object.StringObjectFromGoString("plain text")
+	if out, _ := capturePrintStream(PrintlnObject, str); out != "plain text\n" {
+		t.Errorf("println(Object) of a String: expected %q, got %q", "plain text\n", out)
+	}
+}
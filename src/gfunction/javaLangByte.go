@@ -35,18 +35,42 @@ func Load_Lang_Byte() {
 			GFunction:  byteDoubleValue,
 		}
 
+	MethodSignatures["java/lang/Byte.parseByte(Ljava/lang/String;)B"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  byteParseByte,
+		}
+
+	MethodSignatures["java/lang/Byte.parseByte(Ljava/lang/String;I)B"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  byteParseByteRadix,
+		}
+
 	MethodSignatures["java/lang/Byte.toString()Ljava/lang/String;"] =
 		GMeth{
 			ParamSlots: 0,
 			GFunction:  byteToString,
 		}
 
+	MethodSignatures["java/lang/Byte.toString(B)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  integerToStringI,
+		}
+
 	MethodSignatures["java/lang/Byte.valueOf(B)Ljava/lang/Byte;"] =
 		GMeth{
 			ParamSlots: 1,
 			GFunction:  byteValueOf,
 		}
 
+	MethodSignatures["java/lang/Byte.valueOf(Ljava/lang/String;)Ljava/lang/Byte;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  byteValueOfString,
+		}
+
 }
 
 // "java/lang/Byte.decode(Ljava/lang/String;)Ljava/lang/Byte;"
@@ -89,6 +113,20 @@ func byteDoubleValue(params []interface{}) interface{} {
 	return float64(bb)
 }
 
+// "java/lang/Byte.parseByte(Ljava/lang/String;)B"
+func byteParseByte(params []interface{}) interface{} {
+	return parseIntegralInRange(params[0], 10, MinByteValue, MaxByteValue)
+}
+
+// "java/lang/Byte.parseByte(Ljava/lang/String;I)B"
+func byteParseByteRadix(params []interface{}) interface{} {
+	rdx, ok := params[1].(int64)
+	if !ok {
+		return getGErrBlk(excNames.NumberFormatException, "Radix is not an integer")
+	}
+	return parseIntegralInRange(params[0], rdx, MinByteValue, MaxByteValue)
+}
+
 // "java/lang/Byte.toString()Ljava/lang/String;"
 func byteToString(params []interface{}) interface{} {
 	var ii int64
@@ -104,3 +142,12 @@ func byteValueOf(params []interface{}) interface{} {
 	int64Value := params[0].(int64)
 	return populator("java/lang/Byte", types.Byte, int64Value)
 }
+
+// "java/lang/Byte.valueOf(Ljava/lang/String;)Ljava/lang/Byte;"
+func byteValueOfString(params []interface{}) interface{} {
+	result := byteParseByte(params)
+	if int64Value, ok := result.(int64); ok {
+		return populator("java/lang/Byte", types.Byte, int64Value)
+	}
+	return result // an error block
+}
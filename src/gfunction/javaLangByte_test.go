@@ -0,0 +1,66 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+func TestByteParseBoundaries(t *testing.T) {
+	globals.InitGlobals("test")
+
+	for _, str := range []string{"-128", "127", "0", "+127"} {
+		result := byteParseByte([]interface{}{object.StringObjectFromGoString(str)})
+		if _, ok := result.(int64); !ok {
+			t.Errorf("TestByteParseBoundaries: expected %s to parse, got %v", str, result)
+		}
+	}
+
+	for _, str := range []string{"-129", "128", "", "12x"} {
+		result := byteParseByte([]interface{}{object.StringObjectFromGoString(str)})
+		gerr, ok := result.(*GErrBlk)
+		if !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("TestByteParseBoundaries: expected NumberFormatException for %q, got %v", str, result)
+		}
+	}
+}
+
+func TestByteParseRadix(t *testing.T) {
+	globals.InitGlobals("test")
+
+	result := byteParseByteRadix([]interface{}{object.StringObjectFromGoString("-80"), int64(16)})
+	if result != int64(-128) {
+		t.Errorf("TestByteParseRadix: expected -128, got %v", result)
+	}
+}
+
+func TestByteValueOfStringAndToString(t *testing.T) {
+	globals.InitGlobals("test")
+
+	result := byteValueOfString([]interface{}{object.StringObjectFromGoString("-128")})
+	obj, ok := result.(*object.Object)
+	if !ok {
+		t.Fatalf("TestByteValueOfStringAndToString: expected an object, got %v", result)
+	}
+	if obj.FieldTable["value"].Ftype != types.Byte || obj.FieldTable["value"].Fvalue != int64(-128) {
+		t.Errorf("TestByteValueOfStringAndToString: unexpected value field: %v", obj.FieldTable["value"])
+	}
+
+	str := object.GoStringFromStringObject(byteToString([]interface{}{obj}).(*object.Object))
+	if str != "-128" {
+		t.Errorf("TestByteValueOfStringAndToString: expected \"-128\", got %q", str)
+	}
+
+	result = byteValueOfString([]interface{}{object.StringObjectFromGoString("128")})
+	if _, ok := result.(*GErrBlk); !ok {
+		t.Errorf("TestByteValueOfStringAndToString: expected an error for 128, got %v", result)
+	}
+}
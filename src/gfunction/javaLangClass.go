@@ -127,7 +127,8 @@ func getAssertionsEnabledStatus([]interface{}) interface{} {
 	// note that statics have been preloaded before this function
 	// can be called, and CLI processing has also occurred. So, we
 	// know we have the latest assertion-enabled status.
-	x := statics.Statics["main.$assertionsDisabled"].Value.(int64)
+	assertionsDisabled, _ := statics.QueryStatic("main.$assertionsDisabled")
+	x := assertionsDisabled.Value.(int64)
 	return 1 - x // return the 0 if disabled, 1 if not.
 }
 
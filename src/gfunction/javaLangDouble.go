@@ -14,7 +14,6 @@ import (
 	"jacobin/types"
 	"math"
 	"strconv"
-	"unsafe"
 )
 
 func Load_Lang_Double() {
@@ -43,6 +42,12 @@ func Load_Lang_Double() {
 			GFunction:  doubleCompareTo,
 		}
 
+	MethodSignatures["java/lang/Double.compareTo(Ljava/lang/Object;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  comparableCompareTo,
+		}
+
 	MethodSignatures["java/lang/Double.doubleValue()D"] =
 		GMeth{
 			ParamSlots: 0,
@@ -188,26 +193,26 @@ func doubleToString(params []interface{}) interface{} {
 	var dd float64
 	parmObj := params[0].(*object.Object)
 	dd = parmObj.FieldTable["value"].Fvalue.(float64)
-	str := fmt.Sprintf("%f", dd)
+	str := javaFloatingPointToString(dd, 64)
 	objPtr := object.StringObjectFromGoString(str)
 	return objPtr
 }
 
-// Simulating doubleToRawLongBits in Go
+// Simulating doubleToRawLongBits in Go. Unlike doubleToLongBits, the bit pattern of a NaN is preserved.
 // "java/lang/Double.doubleToRawLongBits(D)J"
 func doubleToRawLongBits(params []interface{}) interface{} {
 	value := params[0].(float64)
-	return *(*int64)(unsafe.Pointer(&value))
+	return int64(math.Float64bits(value))
 }
 
-// Simulating doubleToLongBits in Go
+// Simulating doubleToLongBits in Go. As in Java, all NaNs map to the canonical NaN.
 // "java/lang/Double.doubleToLongBits(D)J"
 func doubleToLongBits(params []interface{}) interface{} {
 	value := params[0].(float64)
 	if !math.IsNaN(value) {
-		return *(*int64)(unsafe.Pointer(&value))
+		return int64(math.Float64bits(value))
 	}
-	return 0x7ff8000000000000 // equivalent to Java's 0x7ff8000000000000L
+	return int64(0x7ff8000000000000) // equivalent to Java's 0x7ff8000000000000L
 }
 
 // Simulating longBitsToDouble in Go
@@ -0,0 +1,48 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDoubleToLongBitsNormalValue(t *testing.T) {
+	bits := doubleToLongBits([]interface{}{1.5})
+	if bits != int64(0x3ff8000000000000) {
+		t.Errorf("TestDoubleToLongBitsNormalValue: expected 0x3ff8000000000000, got %x", bits)
+	}
+	value := longBitsToDouble([]interface{}{bits})
+	if value != 1.5 {
+		t.Errorf("TestDoubleToLongBitsNormalValue: expected the round trip to give 1.5, got %v", value)
+	}
+}
+
+func TestDoubleToLongBitsNegativeZero(t *testing.T) {
+	bits := doubleToLongBits([]interface{}{math.Copysign(0, -1)})
+	if bits != int64(math.MinInt64) { // 0x8000000000000000
+		t.Errorf("TestDoubleToLongBitsNegativeZero: expected 0x8000000000000000, got %x", bits)
+	}
+	value := longBitsToDouble([]interface{}{bits}).(float64)
+	if value != 0 || !math.Signbit(value) {
+		t.Errorf("TestDoubleToLongBitsNegativeZero: expected -0.0 from the round trip, got %v", value)
+	}
+}
+
+func TestDoubleToLongBitsNaN(t *testing.T) {
+	// a NaN with a non-canonical payload
+	nan := math.Float64frombits(0x7ff0000000000001)
+
+	bits := doubleToLongBits([]interface{}{nan})
+	if bits != int64(0x7ff8000000000000) {
+		t.Errorf("TestDoubleToLongBitsNaN: expected the canonical NaN 0x7ff8000000000000, got %x", bits)
+	}
+	rawBits := doubleToRawLongBits([]interface{}{nan})
+	if rawBits != int64(0x7ff0000000000001) {
+		t.Errorf("TestDoubleToLongBitsNaN: expected the raw bits 0x7ff0000000000001, got %x", rawBits)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"fmt"
+	"jacobin/classloader"
+	"jacobin/excNames"
+	"jacobin/object"
+	"jacobin/statics"
+	"jacobin/types"
+	"strings"
+)
+
+// Implementation of some of the functions in java/lang/Enum.
+// Strategy: javac compiles an enum to a class that extends java/lang/Enum. The class's
+// <clinit>() creates each enum constant by calling the enum's constructor, which passes
+// the constant's name and ordinal to Enum.<init>(), and stores it in a static field of
+// the same name; it then stores an array of all the constants in a synthetic static field,
+// which the synthetic values() method clones. Enum.<init>() here records the name and
+// ordinal in the "name" and "ordinal" fields of the enum constant, and Enum.valueOf()
+// finds the constants through the static fields that the class file marks as enum constants.
+
+// the access flag that marks a field as holding an enum constant (JVM spec, table 4.5-A)
+const accEnum = 0x4000
+
+func Load_Lang_Enum() {
+
+	MethodSignatures["java/lang/Enum.<init>(Ljava/lang/String;I)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  enumInit,
+		}
+
+	MethodSignatures["java/lang/Enum.compareTo(Ljava/lang/Enum;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  enumCompareTo,
+		}
+
+	MethodSignatures["java/lang/Enum.name()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  enumName,
+		}
+
+	MethodSignatures["java/lang/Enum.ordinal()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  enumOrdinal,
+		}
+
+	MethodSignatures["java/lang/Enum.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  enumName,
+		}
+
+	MethodSignatures["java/lang/Enum.valueOf(Ljava/lang/Class;Ljava/lang/String;)Ljava/lang/Enum;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  enumValueOf,
+		}
+
+}
+
+// "java/lang/Enum.<init>(Ljava/lang/String;I)V"
+func enumInit(params []interface{}) interface{} {
+	enum := params[0].(*object.Object)
+	enum.FieldTable["name"] = object.Field{Ftype: types.StringClassRef, Fvalue: params[1]}
+	enum.FieldTable["ordinal"] = object.Field{Ftype: types.Int, Fvalue: params[2].(int64)}
+	return nil
+}
+
+// "java/lang/Enum.name()Ljava/lang/String;"
+// "java/lang/Enum.toString()Ljava/lang/String;"
+func enumName(params []interface{}) interface{} {
+	enum := params[0].(*object.Object)
+	return enum.FieldTable["name"].Fvalue
+}
+
+// "java/lang/Enum.ordinal()I"
+func enumOrdinal(params []interface{}) interface{} {
+	enum := params[0].(*object.Object)
+	return enum.FieldTable["ordinal"].Fvalue.(int64)
+}
+
+// "java/lang/Enum.compareTo(Ljava/lang/Enum;)I"
+// Enum constants are ordered by their ordinals.
+func enumCompareTo(params []interface{}) interface{} {
+	this := params[0].(*object.Object)
+	other, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(other) {
+		return getGErrBlk(excNames.NullPointerException, "Enum.compareTo(): argument is null")
+	}
+	return this.FieldTable["ordinal"].Fvalue.(int64) - other.FieldTable["ordinal"].Fvalue.(int64)
+}
+
+// "java/lang/Enum.valueOf(Ljava/lang/Class;Ljava/lang/String;)Ljava/lang/Enum;"
+// Returns the enum constant of the given enum class that has the given name. The class
+// arrives as a string holding the class name, which is how Jacobin represents a class
+// loaded by LDC and returned by getClass().
+func enumValueOf(params []interface{}) interface{} {
+	class, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(class) {
+		return getGErrBlk(excNames.NullPointerException, "Enum.valueOf(): class is null")
+	}
+	name, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(name) {
+		return getGErrBlk(excNames.NullPointerException, "Enum.valueOf(): name is null")
+	}
+
+	className := strings.TrimPrefix(object.GoStringFromStringObject(class), "class ")
+	className = strings.ReplaceAll(className, ".", "/")
+	constName := object.GoStringFromStringObject(name)
+
+	k := classloader.MethAreaFetch(className)
+	if k == nil || k.Data == nil {
+		errMsg := fmt.Sprintf("Enum.valueOf(): %s is not an enum class", strings.ReplaceAll(className, "/", "."))
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	for _, f := range k.Data.Fields {
+		if !f.IsStatic || f.AccessFlags&accEnum == 0 || k.Data.CP.Utf8Refs[f.Name] != constName {
+			continue
+		}
+		if s, found := statics.QueryStatic(className + "." + constName); found {
+			return s.Value
+		}
+	}
+
+	errMsg := fmt.Sprintf("No enum constant %s.%s", strings.ReplaceAll(className, "/", "."), constName)
+	return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+}
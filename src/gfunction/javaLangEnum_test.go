@@ -0,0 +1,107 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/classloader"
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/statics"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"testing"
+)
+
+// makeTestEnum sets up the enum class className with a constant for each of names, as its
+// <clinit>() would, and returns the constants in the order of their ordinals
+func makeTestEnum(className string, names ...string) []*object.Object {
+	classloader.InitMethodArea()
+	cp := classloader.CPool{Utf8Refs: append([]string{}, names...)}
+	var fields []classloader.Field
+	var constants []*object.Object
+	for i, name := range names {
+		fields = append(fields, classloader.Field{AccessFlags: 0x4019, Name: uint16(i), IsStatic: true})
+		constant := object.MakeEmptyObjectWithClassName(&className)
+		enumInit([]interface{}{constant, object.StringObjectFromGoString(name), int64(i)})
+		_ = statics.AddStatic(className+"."+name, statics.Static{Type: "L" + className + ";", Value: constant})
+		constants = append(constants, constant)
+	}
+	classloader.MethAreaInsert(className, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:      className,
+			NameIndex: stringPool.GetStringIndex(&className),
+			Fields:    fields,
+			CP:        cp,
+			ClInit:    types.ClInitRun,
+		},
+	})
+	return constants
+}
+
+func TestEnumNameOrdinalToString(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Enum()
+	colors := makeTestEnum("com/example/Color", "RED", "GREEN", "BLUE")
+
+	for i, expected := range []string{"RED", "GREEN", "BLUE"} {
+		name := object.GoStringFromStringObject(enumName([]interface{}{colors[i]}).(*object.Object))
+		if name != expected {
+			t.Errorf("name(): expected %s, got %s", expected, name)
+		}
+		if ordinal := enumOrdinal([]interface{}{colors[i]}).(int64); ordinal != int64(i) {
+			t.Errorf("ordinal() of %s: expected %d, got %d", expected, i, ordinal)
+		}
+	}
+
+	toString := MethodSignatures["java/lang/Enum.toString()Ljava/lang/String;"].GFunction
+	if str := toString([]interface{}{colors[2]}).(*object.Object); object.GoStringFromStringObject(str) != "BLUE" {
+		t.Errorf("toString(): expected BLUE, got %s", object.GoStringFromStringObject(str))
+	}
+}
+
+func TestEnumCompareTo(t *testing.T) {
+	globals.InitGlobals("test")
+	colors := makeTestEnum("com/example/Color", "RED", "GREEN", "BLUE")
+
+	if cmp := enumCompareTo([]interface{}{colors[0], colors[2]}).(int64); cmp >= 0 {
+		t.Errorf("RED.compareTo(BLUE): expected a negative result, got %d", cmp)
+	}
+	if cmp := enumCompareTo([]interface{}{colors[1], colors[1]}).(int64); cmp != 0 {
+		t.Errorf("GREEN.compareTo(GREEN): expected 0, got %d", cmp)
+	}
+	ret := enumCompareTo([]interface{}{colors[0], object.Null})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("compareTo(null): expected a NullPointerException, got %v", ret)
+	}
+}
+
+func TestEnumValueOf(t *testing.T) {
+	globals.InitGlobals("test")
+	colors := makeTestEnum("com/example/Color", "RED", "GREEN", "BLUE")
+
+	// a class loaded by LDC is its internal name; one from getClass() is "class " + its name
+	for _, class := range []string{"com/example/Color", "class com.example.Color"} {
+		ret := enumValueOf([]interface{}{object.StringObjectFromGoString(class), object.StringObjectFromGoString("GREEN")})
+		if ret != colors[1] {
+			t.Errorf("valueOf(%s, GREEN): expected the GREEN constant, got %v", class, ret)
+		}
+	}
+
+	class := object.StringObjectFromGoString("com/example/Color")
+	ret := enumValueOf([]interface{}{class, object.StringObjectFromGoString("PURPLE")})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.IllegalArgumentException ||
+		gerr.ErrMsg != "No enum constant com.example.Color.PURPLE" {
+		t.Errorf("valueOf() of a missing constant: expected an IllegalArgumentException, got %v", ret)
+	}
+	ret = enumValueOf([]interface{}{class, object.Null})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("valueOf() of a null name: expected a NullPointerException, got %v", ret)
+	}
+}
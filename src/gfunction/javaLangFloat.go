@@ -8,7 +8,6 @@ package gfunction
 
 import (
 	"math"
-	"unsafe"
 )
 
 func Load_Lang_Float() {
@@ -41,26 +40,26 @@ func Load_Lang_Float() {
 
 }
 
-// Simulating intBitsToFloat in Go
+// Simulating intBitsToFloat in Go. Floats are held as float64s, so the 32-bit float is widened.
 // "java/lang/Float.intBitsToFloat(I)F"
 func intBitsToFloat(params []interface{}) interface{} {
 	bits := params[0].(int64)
-	return math.Float64frombits(uint64(bits))
+	return float64(math.Float32frombits(uint32(bits)))
 }
 
-// Simulating floatToRawIntBits in Go
+// Simulating floatToRawIntBits in Go. Unlike floatToIntBits, the bit pattern of a NaN is preserved.
 // "java/lang/Float.floatToRawIntBits(F)I"
 func floatToRawIntBits(params []interface{}) interface{} {
 	value := params[0].(float64)
-	return *(*int64)(unsafe.Pointer(&value))
+	return int64(int32(math.Float32bits(float32(value))))
 }
 
-// Simulating floatToIntBits in Go
+// Simulating floatToIntBits in Go. As in Java, all NaNs map to the canonical NaN.
 // "java/lang/Float.floatToIntBits(F)I"
 func floatToIntBits(params []interface{}) interface{} {
 	value := params[0].(float64)
-	if !math.IsNaN(float64(value)) {
-		return *(*int64)(unsafe.Pointer(&value))
+	if !math.IsNaN(value) {
+		return int64(int32(math.Float32bits(float32(value))))
 	}
-	return 0x7fc00000 // equivalent to Java's 0x7fc00000
+	return int64(0x7fc00000) // equivalent to Java's 0x7fc00000
 }
@@ -0,0 +1,56 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloatToIntBitsNormalValue(t *testing.T) {
+	bits := floatToIntBits([]interface{}{1.5})
+	if bits != int64(0x3fc00000) {
+		t.Errorf("TestFloatToIntBitsNormalValue: expected 0x3fc00000, got %x", bits)
+	}
+	value := intBitsToFloat([]interface{}{bits})
+	if value != 1.5 {
+		t.Errorf("TestFloatToIntBitsNormalValue: expected the round trip to give 1.5, got %v", value)
+	}
+
+	// a negative float's bits are a negative int
+	bits = floatToIntBits([]interface{}{-2.0})
+	if bits != int64(int32(-0x40000000)) { // 0xc0000000
+		t.Errorf("TestFloatToIntBitsNormalValue: expected 0xc0000000, got %x", bits)
+	}
+}
+
+func TestFloatToIntBitsNegativeZero(t *testing.T) {
+	bits := floatToIntBits([]interface{}{math.Copysign(0, -1)})
+	if bits != int64(math.MinInt32) { // 0x80000000
+		t.Errorf("TestFloatToIntBitsNegativeZero: expected 0x80000000, got %x", bits)
+	}
+	value := intBitsToFloat([]interface{}{bits}).(float64)
+	if value != 0 || !math.Signbit(value) {
+		t.Errorf("TestFloatToIntBitsNegativeZero: expected -0.0 from the round trip, got %v", value)
+	}
+}
+
+func TestFloatToIntBitsNaN(t *testing.T) {
+	nan := intBitsToFloat([]interface{}{int64(0x7fc00001)}).(float64)
+	if !math.IsNaN(nan) {
+		t.Fatalf("TestFloatToIntBitsNaN: expected intBitsToFloat(0x7fc00001) to be NaN, got %v", nan)
+	}
+
+	bits := floatToIntBits([]interface{}{nan})
+	if bits != int64(0x7fc00000) {
+		t.Errorf("TestFloatToIntBitsNaN: expected the canonical NaN 0x7fc00000, got %x", bits)
+	}
+	rawBits := floatToRawIntBits([]interface{}{nan})
+	if rawBits != int64(0x7fc00001) {
+		t.Errorf("TestFloatToIntBitsNaN: expected the raw bits 0x7fc00001, got %x", rawBits)
+	}
+}
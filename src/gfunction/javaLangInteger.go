@@ -11,6 +11,7 @@ import (
 	"jacobin/excNames"
 	"jacobin/object"
 	"jacobin/types"
+	"math/bits"
 	"strconv"
 	"strings"
 )
@@ -29,6 +30,18 @@ func Load_Lang_Integer() {
 			GFunction:  integerByteValue,
 		}
 
+	MethodSignatures["java/lang/Integer.compareTo(Ljava/lang/Integer;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  integerCompareTo,
+		}
+
+	MethodSignatures["java/lang/Integer.compareTo(Ljava/lang/Object;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  comparableCompareTo,
+		}
+
 	MethodSignatures["java/lang/Integer.decode(Ljava/lang/String;)Ljava/lang/Integer;"] =
 		GMeth{
 			ParamSlots: 1,
@@ -71,6 +84,30 @@ func Load_Lang_Integer() {
 			GFunction:  integerParseIntRadix,
 		}
 
+	MethodSignatures["java/lang/Integer.reverseBytes(I)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  integerReverseBytes,
+		}
+
+	MethodSignatures["java/lang/Integer.rotateLeft(II)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  integerRotateLeft,
+		}
+
+	MethodSignatures["java/lang/Integer.rotateRight(II)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  integerRotateRight,
+		}
+
+	MethodSignatures["java/lang/Integer.signum(I)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  integerSignum,
+		}
+
 	MethodSignatures["java/lang/Integer.valueOf(I)Ljava/lang/Integer;"] =
 		GMeth{
 			ParamSlots: 1,
@@ -107,6 +144,12 @@ func Load_Lang_Integer() {
 			GFunction:  integerToStringI,
 		}
 
+	MethodSignatures["java/lang/Integer.toString(II)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  integerToStringRadix,
+		}
+
 	MethodSignatures["java/lang/Integer.toUnsignedString(I)Ljava/lang/String;"] =
 		GMeth{
 			ParamSlots: 1,
@@ -129,29 +172,66 @@ func integerByteValue(params []interface{}) interface{} {
 	return ii
 }
 
+// "java/lang/Integer.compareTo(Ljava/lang/Integer;)I"
+func integerCompareTo(params []interface{}) interface{} {
+	ii1 := params[0].(*object.Object).FieldTable["value"].Fvalue.(int64)
+	ii2 := params[1].(*object.Object).FieldTable["value"].Fvalue.(int64)
+	if ii1 == ii2 {
+		return int64(0)
+	}
+	if ii1 < ii2 {
+		return int64(-1)
+	}
+	return int64(1)
+}
+
 // "java/lang/Integer.decode(Ljava/lang/String;)Ljava/lang/Integer;"
 func integerDecode(params []interface{}) interface{} {
-	// Extract and validate the string argument.
-	parmObj := params[0].(*object.Object)
-	strArg := object.GoStringFromStringObject(parmObj)
-	if len(strArg) < 1 {
-		return getGErrBlk(excNames.NumberFormatException, "Byte array length is zero")
+	strArg := object.GoStringFromStringObject(params[0].(*object.Object))
+	int64Value, gerr := decodeIntegral(strArg, MinIntValue, MaxIntValue)
+	if gerr != nil {
+		return gerr
 	}
 
-	// Replace a leading "#" with "0x" in strArg.
-	if strings.HasPrefix(strArg, "#") {
-		strArg = strings.Replace(strArg, "#", "0x", 1)
+	// Create Integer object.
+	return populator("java/lang/Integer", types.Int, int64Value)
+}
+
+// decodeIntegral parses a string with the rules of Integer.decode() and Long.decode(), and
+// checks that the result lies within [minValue, maxValue]. As in Java, an optional sign can
+// be followed by "0x", "0X", or "#" for a hexadecimal number, or by "0" for an octal one.
+// Otherwise, the number is decimal.
+func decodeIntegral(strArg string, minValue, maxValue int64) (int64, *GErrBlk) {
+	if len(strArg) < 1 {
+		return 0, getGErrBlk(excNames.NumberFormatException, "Zero length string")
 	}
 
-	// Parse the input integer.
-	int64Value, err := strconv.ParseInt(strArg, 10, 64)
-	if err != nil {
-		errMsg := fmt.Sprintf("strconv.ParseInt(%s,10,64) failed, failed, reason: %s", strArg, err.Error())
-		return getGErrBlk(excNames.NumberFormatException, errMsg)
+	// Separate the sign and the radix prefix, if any, from the digits.
+	sign := ""
+	digits := strArg
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	radix := 10
+	switch {
+	case strings.HasPrefix(digits, "0x"), strings.HasPrefix(digits, "0X"):
+		radix, digits = 16, digits[2:]
+	case strings.HasPrefix(digits, "#"):
+		radix, digits = 16, digits[1:]
+	case strings.HasPrefix(digits, "0") && len(digits) > 1:
+		radix, digits = 8, digits[1:]
+	}
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return 0, getGErrBlk(excNames.NumberFormatException, "Sign character in wrong position")
 	}
 
-	// Create Integer object.
-	return populator("java/lang/Integer", types.Int, int64Value)
+	// Parse the digits, which carry the sign so that the most negative value can be parsed.
+	value, err := strconv.ParseInt(sign+digits, radix, 64)
+	if err != nil || value > maxValue || value < minValue {
+		errMsg := fmt.Sprintf("For input string: \"%s\"", strArg)
+		return 0, getGErrBlk(excNames.NumberFormatException, errMsg)
+	}
+	return value, nil
 }
 
 // "java/lang/Integer.doubleValue()D"
@@ -174,30 +254,12 @@ func integerIntLongValue(params []interface{}) interface{} {
 // "java/lang/Integer.parseInt(Ljava/lang/String;)I"
 // Radix = 10
 func integerParseInt(params []interface{}) interface{} {
-	// Extract and validate the string argument.
-	parmObj := params[0].(*object.Object)
-	strArg := object.GoStringFromStringObject(parmObj)
-	if len(strArg) < 1 {
-		return getGErrBlk(excNames.NumberFormatException, "String length is zero")
-	}
-
-	// Replace a leading "#" with "0x" in strArg.
-	if strings.HasPrefix(strArg, "#") {
-		strArg = strings.Replace(strArg, "#", "0x", 1)
-	}
-
-	// Compute output.
-	output, err := strconv.ParseInt(strArg, 10, 64)
-	if err != nil {
-		errMsg := fmt.Sprintf("strconv.ParseInt(%s,10,64) failed, reason: %s", strArg, err.Error())
-		return getGErrBlk(excNames.NumberFormatException, errMsg)
-	}
-
-	// Return computed value.
-	return output
+	return integerParseIntRadix([]interface{}{params[0], int64(10)})
 }
 
 // "java/lang/Integer.parseInt(Ljava/lang/String;I)I"
+// As in Java, the digits can be preceded by a '+' or '-' sign, but not by a radix prefix
+// such as "0x" or "#": only decode() accepts those.
 func integerParseIntRadix(params []interface{}) interface{} {
 	// Extract and validate the string argument.
 	parmObj := params[0].(*object.Object)
@@ -206,11 +268,6 @@ func integerParseIntRadix(params []interface{}) interface{} {
 		return getGErrBlk(excNames.NumberFormatException, "String length is zero")
 	}
 
-	// Replace a leading "#" with "0x" in strArg.
-	if strings.HasPrefix(strArg, "#") {
-		strArg = strings.Replace(strArg, "#", "0x", 1)
-	}
-
 	// Extract and validate the radix.
 	switch params[1].(type) {
 	case int64:
@@ -242,6 +299,61 @@ func integerParseIntRadix(params []interface{}) interface{} {
 	return output
 }
 
+// parseIntegralInRange parses a string with Integer.parseInt()'s rules and then checks that the
+// result lies within [minValue, maxValue], as Byte.parseByte() and Short.parseShort() do.
+func parseIntegralInRange(strObj interface{}, radix int64, minValue, maxValue int64) interface{} {
+	result := integerParseIntRadix([]interface{}{strObj, radix})
+	value, ok := result.(int64)
+	if !ok { // an error block
+		return result
+	}
+	if value < minValue || value > maxValue {
+		strArg := object.GoStringFromStringObject(strObj.(*object.Object))
+		errMsg := fmt.Sprintf("Value out of range. Value:\"%s\" Radix:%d", strArg, radix)
+		return getGErrBlk(excNames.NumberFormatException, errMsg)
+	}
+	return value
+}
+
+// "java/lang/Integer.reverseBytes(I)I"
+func integerReverseBytes(params []interface{}) interface{} {
+	value := uint32(params[0].(int64))
+	return int64(int32(bits.ReverseBytes32(value)))
+}
+
+// "java/lang/Integer.rotateLeft(II)I"
+// As in Java, only the low 5 bits of the distance are used, so it is in the range 0..31.
+// A negative distance rotates right.
+func integerRotateLeft(params []interface{}) interface{} {
+	value := uint32(params[0].(int64))
+	distance := int(params[1].(int64) & 0x1f)
+	return int64(int32(bits.RotateLeft32(value, distance)))
+}
+
+// "java/lang/Integer.rotateRight(II)I"
+func integerRotateRight(params []interface{}) interface{} {
+	value := uint32(params[0].(int64))
+	distance := int(params[1].(int64) & 0x1f)
+	return int64(int32(bits.RotateLeft32(value, -distance)))
+}
+
+// "java/lang/Integer.signum(I)I"
+func integerSignum(params []interface{}) interface{} {
+	return signumInt64(int64(int32(params[0].(int64))))
+}
+
+// signumInt64 returns -1, 0, or 1 as the value is negative, zero, or positive
+func signumInt64(value int64) int64 {
+	switch {
+	case value < 0:
+		return -1
+	case value > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // "java/lang/Integer.valueOf(I)Ljava/lang/Integer;"
 func integerValueOf(params []interface{}) interface{} {
 	int64Value := params[0].(int64)
@@ -265,6 +377,19 @@ func integerToStringI(params []interface{}) interface{} {
 	return obj
 }
 
+// "java/lang/Integer.toString(II)Ljava/lang/String;"
+// As in Java, a radix outside of MinRadix..MaxRadix is replaced by 10, and a negative
+// value is shown as a '-' followed by the digits of its magnitude.
+func integerToStringRadix(params []interface{}) interface{} {
+	argInt64 := int64(int32(params[0].(int64)))
+	rdx := params[1].(int64)
+	if rdx < MinRadix || rdx > MaxRadix {
+		rdx = 10
+	}
+	str := strconv.FormatInt(argInt64, int(rdx))
+	return object.StringObjectFromGoString(str)
+}
+
 // "java/lang/Integer.toUnsignedString(I)Ljava/lang/String;"
 func integerToUnsignedString(params []interface{}) interface{} {
 	argInt64 := params[0].(int64)
@@ -0,0 +1,227 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"testing"
+)
+
+func TestIntegerRotateLeftWraps(t *testing.T) {
+	// the high bit wraps around to the low bit
+	result := integerRotateLeft([]interface{}{int64(-2147483647), int64(1)}) // 0x80000001
+	if result != int64(3) {
+		t.Errorf("TestIntegerRotateLeftWraps: expected 3, got %v", result)
+	}
+
+	// distances are masked to 0..31, so 33 is the same as 1, and -1 is the same as 31
+	result = integerRotateLeft([]interface{}{int64(-2147483647), int64(33)})
+	if result != int64(3) {
+		t.Errorf("TestIntegerRotateLeftWraps: expected 3 for a distance of 33, got %v", result)
+	}
+	result = integerRotateLeft([]interface{}{int64(1), int64(-1)})
+	if result != int64(-2147483648) {
+		t.Errorf("TestIntegerRotateLeftWraps: expected -2147483648 for a distance of -1, got %v", result)
+	}
+}
+
+func TestIntegerRotateRightWraps(t *testing.T) {
+	// the low bit wraps around to the high bit
+	result := integerRotateRight([]interface{}{int64(3), int64(1)})
+	if result != int64(-2147483647) {
+		t.Errorf("TestIntegerRotateRightWraps: expected -2147483647, got %v", result)
+	}
+}
+
+func TestIntegerRotateByZero(t *testing.T) {
+	for _, value := range []int64{0, 1, -1, 0x12345678, -2147483648} {
+		if result := integerRotateLeft([]interface{}{value, int64(0)}); result != value {
+			t.Errorf("TestIntegerRotateByZero: rotateLeft(%d, 0) returned %v", value, result)
+		}
+		if result := integerRotateRight([]interface{}{value, int64(32)}); result != value {
+			t.Errorf("TestIntegerRotateByZero: rotateRight(%d, 32) returned %v", value, result)
+		}
+	}
+}
+
+func TestIntegerReverseBytes(t *testing.T) {
+	result := integerReverseBytes([]interface{}{int64(0x12345678)})
+	if result != int64(0x78563412) {
+		t.Errorf("TestIntegerReverseBytes: expected 0x78563412, got %x", result)
+	}
+
+	// 0x000000FF becomes 0xFF000000, which is negative as an int
+	result = integerReverseBytes([]interface{}{int64(0xff)})
+	if result != int64(-16777216) {
+		t.Errorf("TestIntegerReverseBytes: expected -16777216, got %v", result)
+	}
+}
+
+func TestIntegerParseIntSignAndRadix(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		str      string
+		radix    int64
+		expected int64
+	}{
+		{"+42", 10, 42},
+		{"-42", 10, -42},
+		{"FF", 16, 255},
+		{"-ff", 16, -255},
+		{"+7fffffff", 16, 2147483647},
+		{"-80000000", 16, -2147483648},
+		{"zz", 36, 1295},
+	}
+	for _, tt := range tests {
+		result := integerParseIntRadix([]interface{}{object.StringObjectFromGoString(tt.str), tt.radix})
+		if result != tt.expected {
+			t.Errorf("TestIntegerParseIntSignAndRadix: parseInt(%q, %d): expected %d, got %v",
+				tt.str, tt.radix, tt.expected, result)
+		}
+	}
+
+	result := integerParseInt([]interface{}{object.StringObjectFromGoString("+42")})
+	if result != int64(42) {
+		t.Errorf("TestIntegerParseIntSignAndRadix: parseInt(\"+42\"): expected 42, got %v", result)
+	}
+}
+
+// Radix prefixes belong to decode(), so parseInt() rejects them, whatever the radix
+func TestIntegerParseIntRejectsRadixPrefixes(t *testing.T) {
+	globals.InitGlobals("test")
+
+	for _, str := range []string{"#10", "0x10", "+", "-", "++1", "2147483648", "-2147483649"} {
+		result := integerParseInt([]interface{}{object.StringObjectFromGoString(str)})
+		if gerr, ok := result.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("TestIntegerParseIntRejectsRadixPrefixes: parseInt(%q): expected a NumberFormatException, got %v",
+				str, result)
+		}
+	}
+
+	result := integerParseIntRadix([]interface{}{object.StringObjectFromGoString("0x10"), int64(16)})
+	if _, ok := result.(*GErrBlk); !ok {
+		t.Errorf("TestIntegerParseIntRejectsRadixPrefixes: parseInt(\"0x10\", 16): expected a NumberFormatException, got %v",
+			result)
+	}
+}
+
+func TestIntegerDecode(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		str      string
+		expected int64
+	}{
+		{"#10", 16},
+		{"0x10", 16},
+		{"-0X1f", -31},
+		{"010", 8},
+		{"+10", 10},
+		{"0", 0},
+		{"-2147483648", -2147483648},
+	}
+	for _, tt := range tests {
+		result := integerDecode([]interface{}{object.StringObjectFromGoString(tt.str)})
+		obj, ok := result.(*object.Object)
+		if !ok {
+			t.Errorf("TestIntegerDecode: decode(%q): expected an Integer, got %v", tt.str, result)
+			continue
+		}
+		if obj.FieldTable["value"].Fvalue != tt.expected {
+			t.Errorf("TestIntegerDecode: decode(%q): expected %d, got %v", tt.str, tt.expected, obj.FieldTable["value"].Fvalue)
+		}
+	}
+
+	for _, str := range []string{"0x-1", "#", "08", "2147483648"} {
+		result := integerDecode([]interface{}{object.StringObjectFromGoString(str)})
+		if gerr, ok := result.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("TestIntegerDecode: decode(%q): expected a NumberFormatException, got %v", str, result)
+		}
+	}
+}
+
+func TestIntegerDecodeRadixPrefixes(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		str      string
+		expected int64
+	}{
+		{"#FF", 255},
+		{"0x1A", 26},
+		{"010", 8},
+		{"-0x10", -16},
+		{"42", 42},
+	}
+	for _, tt := range tests {
+		result := integerDecode([]interface{}{object.StringObjectFromGoString(tt.str)})
+		obj, ok := result.(*object.Object)
+		if !ok {
+			t.Errorf("TestIntegerDecodeRadixPrefixes: decode(%q): expected an Integer, got %v", tt.str, result)
+			continue
+		}
+		if object.GoStringFromStringPoolIndex(obj.KlassName) != "java/lang/Integer" {
+			t.Errorf("TestIntegerDecodeRadixPrefixes: decode(%q): expected a java/lang/Integer, got %s",
+				tt.str, object.GoStringFromStringPoolIndex(obj.KlassName))
+		}
+		if obj.FieldTable["value"].Fvalue != tt.expected {
+			t.Errorf("TestIntegerDecodeRadixPrefixes: decode(%q): expected %d, got %v",
+				tt.str, tt.expected, obj.FieldTable["value"].Fvalue)
+		}
+	}
+}
+
+func TestIntegerDecodeMalformed(t *testing.T) {
+	globals.InitGlobals("test")
+
+	for _, str := range []string{"", "0x", "#-1", "0xG", "1 ", "--1"} {
+		result := integerDecode([]interface{}{object.StringObjectFromGoString(str)})
+		if gerr, ok := result.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("TestIntegerDecodeMalformed: decode(%q): expected a NumberFormatException, got %v", str, result)
+		}
+	}
+}
+
+func TestIntegerToStringRadix(t *testing.T) {
+	globals.InitGlobals("test")
+	tests := []struct {
+		value    int64
+		radix    int64
+		expected string
+	}{
+		{10, 2, "1010"},
+		{-10, 2, "-1010"},
+		{255, 16, "ff"},
+		{-255, 16, "-ff"},
+		{2147483647, 16, "7fffffff"},
+		{-2147483648, 16, "-80000000"},
+		{1295, 36, "zz"},
+		{-1295, 36, "-zz"},
+		{0, 36, "0"},
+		{-2147483648, 2, "-10000000000000000000000000000000"},
+		{123, 1, "123"}, // an invalid radix is replaced by 10
+		{-123, 37, "-123"},
+	}
+	for _, tt := range tests {
+		ret := integerToStringRadix([]interface{}{tt.value, tt.radix})
+		if got := object.GoStringFromStringObject(ret.(*object.Object)); got != tt.expected {
+			t.Errorf("Integer.toString(%d, %d): expected %s, got %s", tt.value, tt.radix, tt.expected, got)
+		}
+	}
+}
+
+func TestIntegerSignum(t *testing.T) {
+	for value, expected := range map[int64]int64{
+		-2147483648: -1, -5: -1, 0: 0, 7: 1, 2147483647: 1} {
+		if got := integerSignum([]interface{}{value}); got != expected {
+			t.Errorf("Integer.signum(%d): expected %d, got %v", value, expected, got)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"jacobin/excNames"
 	"jacobin/object"
 	"jacobin/types"
+	"math"
 	"math/bits"
 	"strconv"
 )
@@ -23,12 +24,48 @@ func Load_Lang_Long() {
 			GFunction:  justReturn,
 		}
 
+	MethodSignatures["java/lang/Long.compareTo(Ljava/lang/Long;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  longCompareTo,
+		}
+
+	MethodSignatures["java/lang/Long.compareTo(Ljava/lang/Object;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  comparableCompareTo,
+		}
+
+	MethodSignatures["java/lang/Long.compare(JJ)I"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  longCompare,
+		}
+
+	MethodSignatures["java/lang/Long.decode(Ljava/lang/String;)Ljava/lang/Long;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  longDecode,
+		}
+
 	MethodSignatures["java/lang/Long.doubleValue()D"] =
 		GMeth{
 			ParamSlots: 0,
 			GFunction:  longDoubleValue,
 		}
 
+	MethodSignatures["java/lang/Long.max(JJ)J"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  longMax,
+		}
+
+	MethodSignatures["java/lang/Long.min(JJ)J"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  longMin,
+		}
+
 	MethodSignatures["java/lang/Long.parseLong(Ljava/lang/String;)J"] =
 		GMeth{
 			ParamSlots: 1,
@@ -47,6 +84,18 @@ func Load_Lang_Long() {
 			GFunction:  longRotateRight,
 		}
 
+	MethodSignatures["java/lang/Long.signum(J)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  longSignum,
+		}
+
+	MethodSignatures["java/lang/Long.sum(JJ)J"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  longSum,
+		}
+
 	MethodSignatures["java/lang/Long.toHexString(J)Ljava/lang/String;"] =
 		GMeth{
 			ParamSlots: 2,
@@ -67,6 +116,45 @@ func Load_Lang_Long() {
 
 }
 
+// "java/lang/Long.compare(JJ)I"
+// The longs are compared rather than subtracted, so the result cannot overflow.
+// Each long takes two slots, so the second one is in params[2].
+func longCompare(params []interface{}) interface{} {
+	jj1 := params[0].(int64)
+	jj2 := params[2].(int64)
+	if jj1 == jj2 {
+		return int64(0)
+	}
+	if jj1 < jj2 {
+		return int64(-1)
+	}
+	return int64(1)
+}
+
+// "java/lang/Long.compareTo(Ljava/lang/Long;)I"
+func longCompareTo(params []interface{}) interface{} {
+	jj1 := params[0].(*object.Object).FieldTable["value"].Fvalue.(int64)
+	jj2 := params[1].(*object.Object).FieldTable["value"].Fvalue.(int64)
+	if jj1 == jj2 {
+		return int64(0)
+	}
+	if jj1 < jj2 {
+		return int64(-1)
+	}
+	return int64(1)
+}
+
+// "java/lang/Long.decode(Ljava/lang/String;)Ljava/lang/Long;"
+// The prefixes are the same as for Integer.decode().
+func longDecode(params []interface{}) interface{} {
+	str := object.GoStringFromStringObject(params[0].(*object.Object))
+	jj, gerr := decodeIntegral(str, math.MinInt64, math.MaxInt64)
+	if gerr != nil {
+		return gerr
+	}
+	return populator("java/lang/Long", types.Long, jj)
+}
+
 // "java/lang/Long.doubleValue()D"
 func longDoubleValue(params []interface{}) interface{} {
 	var jj int64
@@ -75,6 +163,16 @@ func longDoubleValue(params []interface{}) interface{} {
 	return float64(jj)
 }
 
+// "java/lang/Long.max(JJ)J"
+func longMax(params []interface{}) interface{} {
+	return max(params[0].(int64), params[2].(int64))
+}
+
+// "java/lang/Long.min(JJ)J"
+func longMin(params []interface{}) interface{} {
+	return min(params[0].(int64), params[2].(int64))
+}
+
 // "java/lang/Long.parseLong(Ljava/lang/String;)J"
 func longParseLong(params []interface{}) interface{} {
 	obj := params[1].(*object.Object)
@@ -103,6 +201,17 @@ func longRotateRight(params []interface{}) interface{} {
 	return int64(value)
 }
 
+// "java/lang/Long.signum(J)I"
+func longSignum(params []interface{}) interface{} {
+	return signumInt64(params[0].(int64))
+}
+
+// "java/lang/Long.sum(JJ)J"
+// As in Java, a sum that overflows wraps around.
+func longSum(params []interface{}) interface{} {
+	return params[0].(int64) + params[2].(int64)
+}
+
 // "java/lang/Long.valueOf(J)Ljava/lang/Long;"
 func longValueOf(params []interface{}) interface{} {
 	int64Value := params[0].(int64)
@@ -0,0 +1,107 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"math"
+	"testing"
+)
+
+// a long takes two slots, so each one is passed twice
+func longPair(jj1, jj2 int64) []interface{} {
+	return []interface{}{jj1, jj1, jj2, jj2}
+}
+
+func TestLongDecode(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		str      string
+		expected int64
+	}{
+		{"#FF", 255},
+		{"0x1A", 26},
+		{"-0X10", -16},
+		{"010", 8},
+		{"-017", -15},
+		{"42", 42},
+		{"0x7fffffffffffffff", math.MaxInt64},
+		{"-0x8000000000000000", math.MinInt64},
+		{"4294967296", 4294967296}, // beyond the range of an Integer
+	}
+	for _, tt := range tests {
+		result := longDecode([]interface{}{object.StringObjectFromGoString(tt.str)})
+		obj, ok := result.(*object.Object)
+		if !ok {
+			t.Errorf("TestLongDecode: decode(%q): expected a Long, got %v", tt.str, result)
+			continue
+		}
+		if object.GoStringFromStringPoolIndex(obj.KlassName) != "java/lang/Long" {
+			t.Errorf("TestLongDecode: decode(%q): expected a java/lang/Long, got %s",
+				tt.str, object.GoStringFromStringPoolIndex(obj.KlassName))
+		}
+		if obj.FieldTable["value"].Fvalue != tt.expected {
+			t.Errorf("TestLongDecode: decode(%q): expected %d, got %v", tt.str, tt.expected, obj.FieldTable["value"].Fvalue)
+		}
+	}
+
+	for _, str := range []string{"", "0x", "0x-1", "09", "0x8000000000000000"} {
+		result := longDecode([]interface{}{object.StringObjectFromGoString(str)})
+		if gerr, ok := result.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("TestLongDecode: decode(%q): expected a NumberFormatException, got %v", str, result)
+		}
+	}
+}
+
+// compare() must not subtract, which would overflow for these values
+func TestLongCompare(t *testing.T) {
+	tests := []struct {
+		jj1, jj2 int64
+		expected int64
+	}{
+		{1, 2, -1},
+		{2, 2, 0},
+		{2, 1, 1},
+		{math.MinInt64, 1, -1},
+		{math.MaxInt64, -1, 1},
+		{math.MinInt64, math.MaxInt64, -1},
+		{math.MaxInt64, math.MinInt64, 1},
+	}
+	for _, tt := range tests {
+		if result := longCompare(longPair(tt.jj1, tt.jj2)); result != tt.expected {
+			t.Errorf("TestLongCompare: compare(%d, %d): expected %d, got %v", tt.jj1, tt.jj2, tt.expected, result)
+		}
+	}
+}
+
+func TestLongMinMaxSum(t *testing.T) {
+	if result := longMin(longPair(math.MinInt64, 5)); result != int64(math.MinInt64) {
+		t.Errorf("TestLongMinMaxSum: expected min of %d, got %v", int64(math.MinInt64), result)
+	}
+	if result := longMax(longPair(-5, math.MaxInt64)); result != int64(math.MaxInt64) {
+		t.Errorf("TestLongMinMaxSum: expected max of %d, got %v", int64(math.MaxInt64), result)
+	}
+	if result := longSum(longPair(40, 2)); result != int64(42) {
+		t.Errorf("TestLongMinMaxSum: expected sum of 42, got %v", result)
+	}
+	if result := longSum(longPair(math.MaxInt64, 1)); result != int64(math.MinInt64) {
+		t.Errorf("TestLongMinMaxSum: expected an overflowing sum to wrap to %d, got %v", int64(math.MinInt64), result)
+	}
+}
+
+func TestLongSignum(t *testing.T) {
+	for value, expected := range map[int64]int64{
+		math.MinInt64: -1, -5: -1, 0: 0, 7: 1, 1 << 40: 1, math.MaxInt64: 1} {
+		// a long takes two slots, so it is in params[0] and params[1]
+		if got := longSignum([]interface{}{value, value}); got != expected {
+			t.Errorf("Long.signum(%d): expected %d, got %v", value, expected, got)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"math"
 	"math/big"
 	"math/rand"
+	"strings"
 )
 
 /*
@@ -101,9 +102,9 @@ func Load_Lang_Math() {
 	MethodSignatures["java/lang/Math.nextAfter(DD)D"] = GMeth{ParamSlots: 4, GFunction: nextAfterDD}
 	MethodSignatures["java/lang/Math.nextAfter(FD)F"] = GMeth{ParamSlots: 3, GFunction: nextAfterFD}
 	MethodSignatures["java/lang/Math.nextDown(D)D"] = GMeth{ParamSlots: 2, GFunction: nextDownFloat64}
-	MethodSignatures["java/lang/Math.nextDown(F)F"] = GMeth{ParamSlots: 1, GFunction: nextDownFloat64}
+	MethodSignatures["java/lang/Math.nextDown(F)F"] = GMeth{ParamSlots: 1, GFunction: nextDownFloat32}
 	MethodSignatures["java/lang/Math.nextUp(D)D"] = GMeth{ParamSlots: 2, GFunction: nextUpFloat64}
-	MethodSignatures["java/lang/Math.nextUp(F)F"] = GMeth{ParamSlots: 1, GFunction: nextUpFloat64}
+	MethodSignatures["java/lang/Math.nextUp(F)F"] = GMeth{ParamSlots: 1, GFunction: nextUpFloat32}
 	MethodSignatures["java/lang/Math.pow(DD)D"] = GMeth{ParamSlots: 4, GFunction: powFloat64}
 	MethodSignatures["java/lang/Math.random()D"] = GMeth{ParamSlots: 0, GFunction: randomFloat64}
 	MethodSignatures["java/lang/Math.rint(D)D"] = GMeth{ParamSlots: 2, GFunction: rintFloat64}
@@ -124,89 +125,7 @@ func Load_Lang_Math() {
 	MethodSignatures["java/lang/Math.toIntExact(J)I"] = GMeth{ParamSlots: 2, GFunction: toIntExactInt64}
 	MethodSignatures["java/lang/Math.toRadians(D)D"] = GMeth{ParamSlots: 2, GFunction: toRadiansFloat64}
 	MethodSignatures["java/lang/Math.ulp(D)D"] = GMeth{ParamSlots: 2, GFunction: ulpFloat64}
-	MethodSignatures["java/lang/Math.ulp(F)F"] = GMeth{ParamSlots: 1, GFunction: ulpFloat64}
-
-	MethodSignatures["java/lang/StrictMath.abs(D)D"] = GMeth{ParamSlots: 2, GFunction: absFloat64}
-	MethodSignatures["java/lang/StrictMath.abs(F)F"] = GMeth{ParamSlots: 1, GFunction: absFloat64}
-	MethodSignatures["java/lang/StrictMath.abs(I)I"] = GMeth{ParamSlots: 1, GFunction: absInt64}
-	MethodSignatures["java/lang/StrictMath.abs(J)J"] = GMeth{ParamSlots: 2, GFunction: absInt64}
-	MethodSignatures["java/lang/StrictMath.absExact(I)I"] = GMeth{ParamSlots: 1, GFunction: absInt64}
-	MethodSignatures["java/lang/StrictMath.absExact(J)J"] = GMeth{ParamSlots: 2, GFunction: absInt64}
-	MethodSignatures["java/lang/StrictMath.acos(D)D"] = GMeth{ParamSlots: 2, GFunction: acosFloat64}
-	MethodSignatures["java/lang/StrictMath.addExact(II)I"] = GMeth{ParamSlots: 2, GFunction: addExactII}
-	MethodSignatures["java/lang/StrictMath.addExact(JJ)J"] = GMeth{ParamSlots: 4, GFunction: addExactJJ}
-	MethodSignatures["java/lang/StrictMath.asin(D)D"] = GMeth{ParamSlots: 2, GFunction: asinFloat64}
-	MethodSignatures["java/lang/StrictMath.atan(D)D"] = GMeth{ParamSlots: 2, GFunction: atanFloat64}
-	MethodSignatures["java/lang/StrictMath.atan2(DD)D"] = GMeth{ParamSlots: 4, GFunction: atan2Float64}
-	MethodSignatures["java/lang/StrictMath.cbrt(D)D"] = GMeth{ParamSlots: 2, GFunction: cbrtFloat64}
-	MethodSignatures["java/lang/StrictMath.ceil(D)D"] = GMeth{ParamSlots: 2, GFunction: ceilFloat64}
-	MethodSignatures["java/lang/StrictMath.copySign(DD)D"] = GMeth{ParamSlots: 4, GFunction: copySignDD}
-	MethodSignatures["java/lang/StrictMath.copySign(FF)F"] = GMeth{ParamSlots: 2, GFunction: copySignFF}
-	MethodSignatures["java/lang/StrictMath.cos(D)D"] = GMeth{ParamSlots: 2, GFunction: cosFloat64}
-	MethodSignatures["java/lang/StrictMath.cosh(D)D"] = GMeth{ParamSlots: 2, GFunction: coshFloat64}
-	MethodSignatures["java/lang/StrictMath.decrementExact(I)I"] = GMeth{ParamSlots: 1, GFunction: decrementExactInt64}
-	MethodSignatures["java/lang/StrictMath.decrementExact(J)J"] = GMeth{ParamSlots: 2, GFunction: decrementExactInt64}
-	MethodSignatures["java/lang/StrictMath.exp(D)D"] = GMeth{ParamSlots: 2, GFunction: expFloat64}
-	MethodSignatures["java/lang/StrictMath.expm1(D)D"] = GMeth{ParamSlots: 2, GFunction: expm1Float64}
-	MethodSignatures["java/lang/StrictMath.floor(D)D"] = GMeth{ParamSlots: 2, GFunction: floorFloat64}
-	MethodSignatures["java/lang/StrictMath.floorDiv(II)I"] = GMeth{ParamSlots: 2, GFunction: floorDivII}
-	MethodSignatures["java/lang/StrictMath.floorDiv(JI)J"] = GMeth{ParamSlots: 3, GFunction: floorDivJx}
-	MethodSignatures["java/lang/StrictMath.floorDiv(JJ)J"] = GMeth{ParamSlots: 4, GFunction: floorDivJx}
-	MethodSignatures["java/lang/StrictMath.floorMod(II)I"] = GMeth{ParamSlots: 2, GFunction: floorModII}
-	MethodSignatures["java/lang/StrictMath.floorMod(JI)I"] = GMeth{ParamSlots: 3, GFunction: floorModJx}
-	MethodSignatures["java/lang/StrictMath.floorMod(JJ)J"] = GMeth{ParamSlots: 4, GFunction: floorModJx}
-	MethodSignatures["java/lang/StrictMath.fma(DDD)D"] = GMeth{ParamSlots: 6, GFunction: fmaDDD}
-	MethodSignatures["java/lang/StrictMath.fma(FFF)F"] = GMeth{ParamSlots: 3, GFunction: fmaFFF}
-	MethodSignatures["java/lang/StrictMath.getExponent(D)I"] = GMeth{ParamSlots: 2, GFunction: getExponentFloat64}
-	MethodSignatures["java/lang/StrictMath.getExponent(F)I"] = GMeth{ParamSlots: 1, GFunction: getExponentFloat64}
-	MethodSignatures["java/lang/StrictMath.hypot(DD)D"] = GMeth{ParamSlots: 4, GFunction: hypotFloat64}
-	MethodSignatures["java/lang/StrictMath.IEEEremainder(DD)D"] = GMeth{ParamSlots: 4, GFunction: IEEEremainderFloat64}
-	MethodSignatures["java/lang/StrictMath.incrementExact(I)I"] = GMeth{ParamSlots: 1, GFunction: incrementExactInt64}
-	MethodSignatures["java/lang/StrictMath.incrementExact(J)J"] = GMeth{ParamSlots: 2, GFunction: incrementExactInt64}
-	MethodSignatures["java/lang/StrictMath.log(D)D"] = GMeth{ParamSlots: 2, GFunction: logFloat64}
-	MethodSignatures["java/lang/StrictMath.log10(D)D"] = GMeth{ParamSlots: 2, GFunction: log10Float64}
-	MethodSignatures["java/lang/StrictMath.log1p(D)D"] = GMeth{ParamSlots: 2, GFunction: log1pFloat64}
-	MethodSignatures["java/lang/StrictMath.max(DD)D"] = GMeth{ParamSlots: 4, GFunction: maxDD}
-	MethodSignatures["java/lang/StrictMath.max(FF)F"] = GMeth{ParamSlots: 2, GFunction: maxFF}
-	MethodSignatures["java/lang/StrictMath.max(II)I"] = GMeth{ParamSlots: 2, GFunction: maxII}
-	MethodSignatures["java/lang/StrictMath.max(JJ)J"] = GMeth{ParamSlots: 4, GFunction: maxJJ}
-	MethodSignatures["java/lang/StrictMath.min(DD)D"] = GMeth{ParamSlots: 4, GFunction: minDD}
-	MethodSignatures["java/lang/StrictMath.min(FF)F"] = GMeth{ParamSlots: 2, GFunction: minFF}
-	MethodSignatures["java/lang/StrictMath.min(II)I"] = GMeth{ParamSlots: 2, GFunction: minII}
-	MethodSignatures["java/lang/StrictMath.min(JJ)J"] = GMeth{ParamSlots: 4, GFunction: minJJ}
-	MethodSignatures["java/lang/StrictMath.multiplyExact(II)I"] = GMeth{ParamSlots: 2, GFunction: multiplyExactII}
-	MethodSignatures["java/lang/StrictMath.multiplyExact(JI)I"] = GMeth{ParamSlots: 3, GFunction: multiplyExactJx}
-	MethodSignatures["java/lang/StrictMath.multiplyExact(JJ)J"] = GMeth{ParamSlots: 4, GFunction: multiplyExactJx}
-	MethodSignatures["java/lang/StrictMath.multiplyHigh(JJ)J"] = GMeth{ParamSlots: 4, GFunction: multiplyHighJJ}
-	MethodSignatures["java/lang/StrictMath.negateExact(I)I"] = GMeth{ParamSlots: 1, GFunction: negateExactInt64}
-	MethodSignatures["java/lang/StrictMath.negateExact(J)J"] = GMeth{ParamSlots: 2, GFunction: negateExactInt64}
-	MethodSignatures["java/lang/StrictMath.nextAfter(DD)D"] = GMeth{ParamSlots: 4, GFunction: nextAfterDD}
-	MethodSignatures["java/lang/StrictMath.nextAfter(FD)F"] = GMeth{ParamSlots: 3, GFunction: nextAfterFD}
-	MethodSignatures["java/lang/StrictMath.nextDown(D)D"] = GMeth{ParamSlots: 2, GFunction: nextDownFloat64}
-	MethodSignatures["java/lang/StrictMath.nextDown(F)F"] = GMeth{ParamSlots: 1, GFunction: nextDownFloat64}
-	MethodSignatures["java/lang/StrictMath.nextUp(D)D"] = GMeth{ParamSlots: 2, GFunction: nextUpFloat64}
-	MethodSignatures["java/lang/StrictMath.nextUp(F)F"] = GMeth{ParamSlots: 1, GFunction: nextUpFloat64}
-	MethodSignatures["java/lang/StrictMath.pow(DD)D"] = GMeth{ParamSlots: 4, GFunction: powFloat64}
-	MethodSignatures["java/lang/StrictMath.random()D"] = GMeth{ParamSlots: 0, GFunction: randomFloat64}
-	MethodSignatures["java/lang/StrictMath.rint(D)D"] = GMeth{ParamSlots: 2, GFunction: rintFloat64}
-	MethodSignatures["java/lang/StrictMath.round(D)J"] = GMeth{ParamSlots: 2, GFunction: roundInt64}
-	MethodSignatures["java/lang/StrictMath.round(F)I"] = GMeth{ParamSlots: 1, GFunction: roundInt64}
-	MethodSignatures["java/lang/StrictMath.scalb(DI)D"] = GMeth{ParamSlots: 3, GFunction: scalbDI}
-	MethodSignatures["java/lang/StrictMath.scalb(FI)F"] = GMeth{ParamSlots: 2, GFunction: scalbFI}
-	MethodSignatures["java/lang/StrictMath.signum(D)D"] = GMeth{ParamSlots: 2, GFunction: signumFloat64}
-	MethodSignatures["java/lang/StrictMath.signum(F)F"] = GMeth{ParamSlots: 1, GFunction: signumFloat64}
-	MethodSignatures["java/lang/StrictMath.sin(D)D"] = GMeth{ParamSlots: 2, GFunction: sinFloat64}
-	MethodSignatures["java/lang/StrictMath.sinh(D)D"] = GMeth{ParamSlots: 2, GFunction: sinhFloat64}
-	MethodSignatures["java/lang/StrictMath.sqrt(D)D"] = GMeth{ParamSlots: 2, GFunction: sqrtFloat64}
-	MethodSignatures["java/lang/StrictMath.subtractExact(II)I"] = GMeth{ParamSlots: 2, GFunction: subtractExactII}
-	MethodSignatures["java/lang/StrictMath.subtractExact(JJ)J"] = GMeth{ParamSlots: 4, GFunction: subtractExactJJ}
-	MethodSignatures["java/lang/StrictMath.tan(D)D"] = GMeth{ParamSlots: 2, GFunction: tanFloat64}
-	MethodSignatures["java/lang/StrictMath.tanh(D)D"] = GMeth{ParamSlots: 2, GFunction: tanhFloat64}
-	MethodSignatures["java/lang/StrictMath.toDegrees(D)D"] = GMeth{ParamSlots: 2, GFunction: toDegreesFloat64}
-	MethodSignatures["java/lang/StrictMath.toIntExact(J)I"] = GMeth{ParamSlots: 2, GFunction: toIntExactInt64}
-	MethodSignatures["java/lang/StrictMath.toRadians(D)D"] = GMeth{ParamSlots: 2, GFunction: toRadiansFloat64}
-	MethodSignatures["java/lang/StrictMath.ulp(D)D"] = GMeth{ParamSlots: 2, GFunction: ulpFloat64}
-	MethodSignatures["java/lang/StrictMath.ulp(F)F"] = GMeth{ParamSlots: 1, GFunction: ulpFloat64}
+	MethodSignatures["java/lang/Math.ulp(F)F"] = GMeth{ParamSlots: 1, GFunction: ulpFloat32}
 
 	MethodSignatures["java/lang/Math.<clinit>()V"] =
 		GMeth{
@@ -214,6 +133,25 @@ func Load_Lang_Math() {
 			GFunction:  mathClinit,
 		}
 
+	loadStrictMathAliases()
+
+}
+
+// StrictMath has the same methods as Math, and library code sometimes calls StrictMath
+// directly. So, every Math method is also registered under StrictMath, pointing to the
+// same G function. <clinit>() is excluded, as mathClinit() is specific to Math.
+func loadStrictMathAliases() {
+	var mathSignatures []string
+	for signature := range MethodSignatures {
+		if strings.HasPrefix(signature, "java/lang/Math.") && !strings.Contains(signature, "<clinit>") {
+			mathSignatures = append(mathSignatures, signature)
+		}
+	}
+
+	for _, signature := range mathSignatures {
+		strictSignature := "java/lang/StrictMath." + strings.TrimPrefix(signature, "java/lang/Math.")
+		MethodSignatures[strictSignature] = MethodSignatures[signature]
+	}
 }
 
 func mathClinit([]interface{}) interface{} {
@@ -518,11 +456,21 @@ func nextDownFloat64(params []interface{}) interface{} {
 	return math.Nextafter(params[0].(float64), math.Inf(-1))
 }
 
+// Next down float of float value, which is a bigger step than the next down double.
+func nextDownFloat32(params []interface{}) interface{} {
+	return float64(math.Nextafter32(float32(params[0].(float64)), float32(math.Inf(-1))))
+}
+
 // Next up double of float value.
 func nextUpFloat64(params []interface{}) interface{} {
 	return math.Nextafter(params[0].(float64), math.Inf(+1))
 }
 
+// Next up float of float value, which is a bigger step than the next up double.
+func nextUpFloat32(params []interface{}) interface{} {
+	return float64(math.Nextafter32(float32(params[0].(float64)), float32(math.Inf(+1))))
+}
+
 // Value of the first argument raised to the power of the second argument.
 func powFloat64(params []interface{}) interface{} {
 	return math.Pow(params[0].(float64), params[2].(float64))
@@ -568,11 +516,11 @@ func scalbFI(params []interface{}) interface{} {
 	return scalbFloat64I(xx, scaleFactor)
 }
 
-// Compute the signum value of an argument.
+// Compute the signum value of an argument. NaN, 0.0, and -0.0 are returned as is.
 func signumFloat64(params []interface{}) interface{} {
 	xx := params[0].(float64)
-	if math.IsNaN(xx) {
-		return math.NaN()
+	if math.IsNaN(xx) || xx == 0 {
+		return xx
 	}
 	if xx > 0 {
 		return 1.0
@@ -653,3 +601,18 @@ func ulpFloat64(params []interface{}) interface{} {
 	}
 	return next - xx
 }
+
+// ULP of a float, which is the distance to the next float rather than to the next double.
+func ulpFloat32(params []interface{}) interface{} {
+	xx := params[0].(float64)
+	if math.IsNaN(xx) || math.IsInf(xx, 0) {
+		return ulpFloat64(params)
+	}
+	ff := float32(math.Abs(xx))
+	next := math.Nextafter32(ff, float32(math.Inf(1)))
+	if math.IsInf(float64(next), 1) {
+		next = math.Nextafter32(ff, float32(math.Inf(-1)))
+		return float64(ff - next)
+	}
+	return float64(next - ff)
+}
@@ -0,0 +1,246 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// doubles occupy two slots, so one-arg functions receive (D, D) and two-arg functions (D, D, D, D)
+func mathParams1(d float64) []interface{} { return []interface{}{d, d} }
+
+func mathParams2(d1, d2 float64) []interface{} { return []interface{}{d1, d1, d2, d2} }
+
+func closeEnough(a, b float64) bool { return math.Abs(a-b) <= 1e-12*math.Max(1, math.Abs(b)) }
+
+func TestMathLog(t *testing.T) {
+	if ret := logFloat64(mathParams1(math.E)).(float64); !closeEnough(ret, 1.0) {
+		t.Errorf("log(e): expected 1.0, got %f", ret)
+	}
+	if ret := logFloat64(mathParams1(1.0)).(float64); ret != 0.0 {
+		t.Errorf("log(1): expected 0.0, got %f", ret)
+	}
+	if ret := logFloat64(mathParams1(-1.0)).(float64); !math.IsNaN(ret) {
+		t.Errorf("log(-1): expected NaN, got %f", ret)
+	}
+	if ret := logFloat64(mathParams1(0.0)).(float64); !math.IsInf(ret, -1) {
+		t.Errorf("log(0): expected -Infinity, got %f", ret)
+	}
+	if ret := logFloat64(mathParams1(math.Inf(1))).(float64); !math.IsInf(ret, 1) {
+		t.Errorf("log(Infinity): expected Infinity, got %f", ret)
+	}
+	if ret := logFloat64(mathParams1(math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("log(NaN): expected NaN, got %f", ret)
+	}
+}
+
+func TestMathLog10(t *testing.T) {
+	if ret := log10Float64(mathParams1(1000.0)).(float64); !closeEnough(ret, 3.0) {
+		t.Errorf("log10(1000): expected 3.0, got %f", ret)
+	}
+	if ret := log10Float64(mathParams1(0.01)).(float64); !closeEnough(ret, -2.0) {
+		t.Errorf("log10(0.01): expected -2.0, got %f", ret)
+	}
+	if ret := log10Float64(mathParams1(-10.0)).(float64); !math.IsNaN(ret) {
+		t.Errorf("log10(-10): expected NaN, got %f", ret)
+	}
+	if ret := log10Float64(mathParams1(0.0)).(float64); !math.IsInf(ret, -1) {
+		t.Errorf("log10(0): expected -Infinity, got %f", ret)
+	}
+}
+
+func TestMathExp(t *testing.T) {
+	if ret := expFloat64(mathParams1(0.0)).(float64); ret != 1.0 {
+		t.Errorf("exp(0): expected 1.0, got %f", ret)
+	}
+	if ret := expFloat64(mathParams1(1.0)).(float64); !closeEnough(ret, math.E) {
+		t.Errorf("exp(1): expected e, got %f", ret)
+	}
+	if ret := expFloat64(mathParams1(math.Inf(-1))).(float64); ret != 0.0 {
+		t.Errorf("exp(-Infinity): expected 0.0, got %f", ret)
+	}
+	if ret := expFloat64(mathParams1(math.Inf(1))).(float64); !math.IsInf(ret, 1) {
+		t.Errorf("exp(Infinity): expected Infinity, got %f", ret)
+	}
+	if ret := expFloat64(mathParams1(1000.0)).(float64); !math.IsInf(ret, 1) {
+		t.Errorf("exp(1000): expected overflow to Infinity, got %f", ret)
+	}
+	if ret := expFloat64(mathParams1(math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("exp(NaN): expected NaN, got %f", ret)
+	}
+}
+
+func TestMathCbrt(t *testing.T) {
+	if ret := cbrtFloat64(mathParams1(27.0)).(float64); ret != 3.0 {
+		t.Errorf("cbrt(27): expected 3.0, got %f", ret)
+	}
+	if ret := cbrtFloat64(mathParams1(-8.0)).(float64); ret != -2.0 { // unlike pow(), cbrt handles negatives
+		t.Errorf("cbrt(-8): expected -2.0, got %f", ret)
+	}
+	if ret := cbrtFloat64(mathParams1(math.Inf(-1))).(float64); !math.IsInf(ret, -1) {
+		t.Errorf("cbrt(-Infinity): expected -Infinity, got %f", ret)
+	}
+	if ret := cbrtFloat64(mathParams1(math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("cbrt(NaN): expected NaN, got %f", ret)
+	}
+}
+
+func TestMathHypot(t *testing.T) {
+	if ret := hypotFloat64(mathParams2(3.0, 4.0)).(float64); ret != 5.0 {
+		t.Errorf("hypot(3, 4): expected 5.0, got %f", ret)
+	}
+	if ret := hypotFloat64(mathParams2(-5.0, 12.0)).(float64); ret != 13.0 {
+		t.Errorf("hypot(-5, 12): expected 13.0, got %f", ret)
+	}
+	if ret := hypotFloat64(mathParams2(1e300, 1e300)).(float64); math.IsInf(ret, 0) {
+		t.Errorf("hypot(1e300, 1e300): expected no intermediate overflow, got %f", ret)
+	}
+	// per the Javadoc, an infinite argument yields Infinity even if the other is NaN
+	if ret := hypotFloat64(mathParams2(math.Inf(-1), math.NaN())).(float64); !math.IsInf(ret, 1) {
+		t.Errorf("hypot(-Infinity, NaN): expected Infinity, got %f", ret)
+	}
+	if ret := hypotFloat64(mathParams2(1.0, math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("hypot(1, NaN): expected NaN, got %f", ret)
+	}
+}
+
+func TestStrictMathAliasesMath(t *testing.T) {
+	Load_Lang_Math()
+
+	mathCount := 0
+	for signature, gmeth := range MethodSignatures {
+		if !strings.HasPrefix(signature, "java/lang/Math.") || strings.Contains(signature, "<clinit>") {
+			continue
+		}
+		mathCount++
+		strictSignature := strings.Replace(signature, "java/lang/Math.", "java/lang/StrictMath.", 1)
+		strictGmeth, ok := MethodSignatures[strictSignature]
+		if !ok {
+			t.Errorf("Missing StrictMath alias for %s", signature)
+			continue
+		}
+		if strictGmeth.ParamSlots != gmeth.ParamSlots {
+			t.Errorf("%s: expected %d param slots, got %d", strictSignature, gmeth.ParamSlots, strictGmeth.ParamSlots)
+		}
+	}
+	if mathCount == 0 {
+		t.Fatalf("Expected Load_Lang_Math() to load Math signatures, but none were found")
+	}
+
+	if _, ok := MethodSignatures["java/lang/StrictMath.<clinit>()V"]; ok {
+		t.Errorf("Math's <clinit> should not be aliased to StrictMath")
+	}
+
+	// the StrictMath entry must behave exactly like its Math counterpart
+	params := mathParams2(3.0, 4.0)
+	mathResult := MethodSignatures["java/lang/Math.hypot(DD)D"].GFunction(params).(float64)
+	strictResult := MethodSignatures["java/lang/StrictMath.hypot(DD)D"].GFunction(params).(float64)
+	if mathResult != strictResult || strictResult != 5.0 {
+		t.Errorf("Expected Math.hypot and StrictMath.hypot to both return 5.0, got %f and %f",
+			mathResult, strictResult)
+	}
+}
+
+func TestMathSignum(t *testing.T) {
+	if ret := signumFloat64(mathParams1(0.0)).(float64); ret != 0 {
+		t.Errorf("signum(0.0): expected 0.0, got %f", ret)
+	}
+	negZero := math.Copysign(0, -1)
+	if ret := signumFloat64(mathParams1(negZero)).(float64); ret != 0 || !math.Signbit(ret) {
+		t.Errorf("signum(-0.0): expected -0.0, got %f", ret)
+	}
+	if ret := signumFloat64(mathParams1(math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("signum(NaN): expected NaN, got %f", ret)
+	}
+	if ret := signumFloat64(mathParams1(math.Inf(-1))).(float64); ret != -1.0 {
+		t.Errorf("signum(-Infinity): expected -1.0, got %f", ret)
+	}
+	if ret := signumFloat64(mathParams1(2.5)).(float64); ret != 1.0 {
+		t.Errorf("signum(2.5): expected 1.0, got %f", ret)
+	}
+}
+
+func TestMathCopySign(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	if ret := copySignDD(mathParams2(3.0, negZero)).(float64); ret != -3.0 {
+		t.Errorf("copySign(3.0, -0.0): expected -3.0, got %f", ret)
+	}
+	if ret := copySignDD(mathParams2(negZero, 1.0)).(float64); ret != 0 || math.Signbit(ret) {
+		t.Errorf("copySign(-0.0, 1.0): expected 0.0, got %f", ret)
+	}
+	if ret := copySignDD(mathParams2(math.NaN(), -1.0)).(float64); !math.IsNaN(ret) {
+		t.Errorf("copySign(NaN, -1.0): expected NaN, got %f", ret)
+	}
+	if ret := copySignDD(mathParams2(math.Inf(1), -2.0)).(float64); !math.IsInf(ret, -1) {
+		t.Errorf("copySign(Infinity, -2.0): expected -Infinity, got %f", ret)
+	}
+}
+
+func TestMathNextUp(t *testing.T) {
+	minValue := math.SmallestNonzeroFloat64
+	for _, zero := range []float64{0.0, math.Copysign(0, -1)} {
+		if ret := nextUpFloat64(mathParams1(zero)).(float64); ret != minValue {
+			t.Errorf("nextUp(%f): expected Double.MIN_VALUE, got %g", zero, ret)
+		}
+	}
+	if ret := nextUpFloat64(mathParams1(1.0)).(float64); ret != 1.0+math.Pow(2, -52) {
+		t.Errorf("nextUp(1.0): expected 1.0 + 2^-52, got %g", ret)
+	}
+	if ret := nextUpFloat64(mathParams1(math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("nextUp(NaN): expected NaN, got %f", ret)
+	}
+	if ret := nextUpFloat64(mathParams1(math.Inf(1))).(float64); !math.IsInf(ret, 1) {
+		t.Errorf("nextUp(Infinity): expected Infinity, got %f", ret)
+	}
+	if ret := nextUpFloat64(mathParams1(math.Inf(-1))).(float64); ret != -math.MaxFloat64 {
+		t.Errorf("nextUp(-Infinity): expected -Double.MAX_VALUE, got %g", ret)
+	}
+	if ret := nextUpFloat32([]interface{}{1.0}).(float64); ret != 1.0+math.Pow(2, -23) {
+		t.Errorf("nextUp(1.0f): expected 1.0 + 2^-23, got %g", ret)
+	}
+}
+
+func TestMathNextDown(t *testing.T) {
+	if ret := nextDownFloat64(mathParams1(1.0)).(float64); ret != 1.0-math.Pow(2, -53) {
+		t.Errorf("nextDown(1.0): expected 1.0 - 2^-53, got %g", ret)
+	}
+	if ret := nextDownFloat64(mathParams1(0.0)).(float64); ret != -math.SmallestNonzeroFloat64 {
+		t.Errorf("nextDown(0.0): expected -Double.MIN_VALUE, got %g", ret)
+	}
+	if ret := nextDownFloat32([]interface{}{1.0}).(float64); ret != 1.0-math.Pow(2, -24) {
+		t.Errorf("nextDown(1.0f): expected 1.0 - 2^-24, got %g", ret)
+	}
+	if ret := nextDownFloat32([]interface{}{0.0}).(float64); ret != -float64(math.SmallestNonzeroFloat32) {
+		t.Errorf("nextDown(0.0f): expected -Float.MIN_VALUE, got %g", ret)
+	}
+	if ret := nextDownFloat32([]interface{}{math.Inf(-1)}).(float64); !math.IsInf(ret, -1) {
+		t.Errorf("nextDown(-Infinity): expected -Infinity, got %f", ret)
+	}
+}
+
+func TestMathUlp(t *testing.T) {
+	if ret := ulpFloat64(mathParams1(1.0)).(float64); ret != math.Pow(2, -52) {
+		t.Errorf("ulp(1.0): expected 2^-52, got %g", ret)
+	}
+	if ret := ulpFloat64(mathParams1(math.Copysign(0, -1))).(float64); ret != math.SmallestNonzeroFloat64 {
+		t.Errorf("ulp(-0.0): expected Double.MIN_VALUE, got %g", ret)
+	}
+	if ret := ulpFloat64(mathParams1(math.MaxFloat64)).(float64); ret != math.Pow(2, 971) {
+		t.Errorf("ulp(Double.MAX_VALUE): expected 2^971, got %g", ret)
+	}
+	if ret := ulpFloat64(mathParams1(math.NaN())).(float64); !math.IsNaN(ret) {
+		t.Errorf("ulp(NaN): expected NaN, got %f", ret)
+	}
+	if ret := ulpFloat64(mathParams1(math.Inf(-1))).(float64); !math.IsInf(ret, 1) {
+		t.Errorf("ulp(-Infinity): expected Infinity, got %f", ret)
+	}
+	if ret := ulpFloat32([]interface{}{1.0}).(float64); ret != math.Pow(2, -23) {
+		t.Errorf("ulp(1.0f): expected 2^-23, got %g", ret)
+	}
+}
@@ -7,7 +7,13 @@
 package gfunction
 
 import (
+	"fmt"
+	"jacobin/excNames"
 	"jacobin/object"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"slices"
+	"strings"
 )
 
 // Implementation of some of the functions in Java/lang/Class.
@@ -20,12 +26,63 @@ func Load_Lang_Object() {
 			GFunction:  justReturn,
 		}
 
+	MethodSignatures["java/lang/Object.clone()Ljava/lang/Object;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  objectClone,
+		}
+
 	MethodSignatures["java/lang/Object.getClass()Ljava/lang/Class;"] =
 		GMeth{
 			ParamSlots: 0,
 			GFunction:  objectGetClass,
 		}
 
+	MethodSignatures["java/lang/Object.hashCode()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  objectHashCode,
+		}
+
+	MethodSignatures["java/lang/Object.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  objectToString,
+		}
+
+}
+
+// "java/lang/Object.clone()Ljava/lang/Object;"
+// At present, only arrays can be cloned. As in the JDK, the clone of an array is a new
+// array of the same type holding the same elements; the elements themselves are not cloned.
+func objectClone(params []interface{}) interface{} {
+	obj := params[0].(*object.Object)
+	className := object.GoStringFromStringPoolIndex(obj.KlassName)
+	if !strings.HasPrefix(className, types.Array) {
+		errMsg := fmt.Sprintf("clone() is not supported for objects of class %s",
+			strings.ReplaceAll(className, "/", "."))
+		return getGErrBlk(excNames.CloneNotSupportedException, errMsg)
+	}
+
+	arr := obj.FieldTable["value"]
+	switch elements := arr.Fvalue.(type) {
+	case []byte:
+		arr.Fvalue = slices.Clone(elements)
+	case []int64:
+		arr.Fvalue = slices.Clone(elements)
+	case []float64:
+		arr.Fvalue = slices.Clone(elements)
+	case []*object.Object:
+		arr.Fvalue = slices.Clone(elements)
+	default:
+		errMsg := fmt.Sprintf("clone() is not supported for arrays of type %s", arr.Ftype)
+		return getGErrBlk(excNames.CloneNotSupportedException, errMsg)
+	}
+
+	clone := object.MakeEmptyObject()
+	clone.KlassName = stringPool.GetStringIndex(&className)
+	clone.FieldTable["value"] = arr
+	return clone
 }
 
 // "java/lang/Object.getClass()Ljava/lang/Class;"
@@ -35,3 +92,23 @@ func objectGetClass(params []interface{}) interface{} {
 	name := object.GoStringFromStringPoolIndex(wint)
 	return object.StringObjectFromGoString("class " + name)
 }
+
+// "java/lang/Object.hashCode()I"
+func objectHashCode(params []interface{}) interface{} {
+	obj := params[0].(*object.Object)
+	return object.IdentityHashCode(obj)
+}
+
+// "java/lang/Object.toString()Ljava/lang/String;"
+func objectToString(params []interface{}) interface{} {
+	obj := params[0].(*object.Object)
+	return object.StringObjectFromGoString(objectDefaultString(obj))
+}
+
+// objectDefaultString returns what Object.toString() returns for obj: the class name,
+// an @, and the identity hash code in hex, such as java.lang.Object@1b6d3586
+func objectDefaultString(obj *object.Object) string {
+	className := object.GoStringFromStringPoolIndex(obj.KlassName)
+	return fmt.Sprintf("%s@%x", strings.ReplaceAll(className, "/", "."),
+		uint32(object.IdentityHashCode(obj)))
+}
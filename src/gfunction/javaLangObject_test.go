@@ -0,0 +1,95 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"fmt"
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+func TestObjectHashCodeUsesIdentityHashCode(t *testing.T) {
+	className := "com/example/Widget"
+	widget := object.MakeEmptyObjectWithClassName(&className)
+	other := object.MakeEmptyObjectWithClassName(&className)
+
+	hash := objectHashCode([]interface{}{widget}).(int64)
+	if hash != object.IdentityHashCode(widget) {
+		t.Errorf("Object.hashCode(): expected %d, got %d", object.IdentityHashCode(widget), hash)
+	}
+	if again := objectHashCode([]interface{}{widget}).(int64); again != hash {
+		t.Errorf("Object.hashCode(): expected the same value on every call, got %d and %d", hash, again)
+	}
+	if objectHashCode([]interface{}{other}).(int64) == hash {
+		t.Errorf("Object.hashCode(): expected distinct objects to have distinct hash codes")
+	}
+}
+
+func TestObjectToStringDefaultFormat(t *testing.T) {
+	globals.InitGlobals("test")
+	className := "com/example/Widget"
+	widget := object.MakeEmptyObjectWithClassName(&className)
+
+	expected := fmt.Sprintf("com.example.Widget@%x", uint32(object.IdentityHashCode(widget)))
+	str := objectToString([]interface{}{widget}).(*object.Object)
+	if got := object.GoStringFromStringObject(str); got != expected {
+		t.Errorf("Object.toString(): expected %q, got %q", expected, got)
+	}
+}
+
+// println(Object) of a user object without a toString() prints what Object.toString() returns
+func TestPrintlnUserObjectUsesObjectToString(t *testing.T) {
+	globals.InitGlobals("test")
+	className := "com/example/Widget"
+	widget := object.MakeEmptyObjectWithClassName(&className)
+
+	expected := fmt.Sprintf("com.example.Widget@%x\n", uint32(object.IdentityHashCode(widget)))
+	out, ret := capturePrintStream(PrintlnObject, widget)
+	if ret != nil {
+		t.Fatalf("println(Object): unexpected error: %v", ret)
+	}
+	if out != expected {
+		t.Errorf("println(Object): expected %q, got %q", expected, out)
+	}
+	if out, _ = capturePrintStream(PrintlnObject, object.Null); out != "null\n" {
+		t.Errorf("println(Object) of null: expected %q, got %q", "null\n", out)
+	}
+}
+
+func TestObjectCloneArray(t *testing.T) {
+	globals.InitGlobals("test")
+	elements := []*object.Object{object.StringObjectFromGoString("a"), object.StringObjectFromGoString("b")}
+	arr := object.Make1DimRefArray(&types.StringClassName, 2)
+	copy(arr.FieldTable["value"].Fvalue.([]*object.Object), elements)
+
+	clone := objectClone([]interface{}{arr}).(*object.Object)
+	if clone == arr || clone.KlassName != arr.KlassName {
+		t.Fatalf("clone(): expected a new array of the same class")
+	}
+	cloned := clone.FieldTable["value"].Fvalue.([]*object.Object)
+	if len(cloned) != 2 || cloned[0] != elements[0] || cloned[1] != elements[1] {
+		t.Errorf("clone(): expected the same elements, got %v", cloned)
+	}
+	cloned[0] = elements[1] // changing the clone must not change the original
+	if arr.FieldTable["value"].Fvalue.([]*object.Object)[0] != elements[0] {
+		t.Errorf("clone(): the clone shares its elements with the original array")
+	}
+
+	ints := object.Make1DimArray(object.INT, 3)
+	ints.FieldTable["value"].Fvalue.([]int64)[1] = 7
+	if got := objectClone([]interface{}{ints}).(*object.Object).FieldTable["value"].Fvalue.([]int64); got[1] != 7 {
+		t.Errorf("clone() of an int array: expected [0 7 0], got %v", got)
+	}
+
+	ret := objectClone([]interface{}{elements[0]})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.CloneNotSupportedException {
+		t.Errorf("clone() of a non-array: expected a CloneNotSupportedException, got %v", ret)
+	}
+}
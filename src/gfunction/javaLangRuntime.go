@@ -0,0 +1,106 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/globals"
+	"jacobin/object"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Implementation of some of the functions in java/lang/Runtime. The memory figures
+// are taken from the Go runtime, which manages Jacobin's heap.
+
+func Load_Lang_Runtime() {
+
+	MethodSignatures["java/lang/Runtime.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/lang/Runtime.availableProcessors()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  runtimeAvailableProcessors,
+		}
+
+	MethodSignatures["java/lang/Runtime.freeMemory()J"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  runtimeFreeMemory,
+		}
+
+	MethodSignatures["java/lang/Runtime.gc()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  forceGC,
+		}
+
+	MethodSignatures["java/lang/Runtime.getRuntime()Ljava/lang/Runtime;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  runtimeGetRuntime,
+		}
+
+	MethodSignatures["java/lang/Runtime.maxMemory()J"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  runtimeMaxMemory,
+		}
+
+	MethodSignatures["java/lang/Runtime.totalMemory()J"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  runtimeTotalMemory,
+		}
+
+}
+
+// the Runtime object returned by every call to getRuntime()
+var runtimeSingleton *object.Object
+var runtimeOnce sync.Once
+
+// "java/lang/Runtime.getRuntime()Ljava/lang/Runtime;"
+func runtimeGetRuntime([]interface{}) interface{} {
+	runtimeOnce.Do(func() {
+		className := "java/lang/Runtime"
+		runtimeSingleton = object.MakeEmptyObjectWithClassName(&className)
+	})
+	return runtimeSingleton
+}
+
+// "java/lang/Runtime.availableProcessors()I"
+func runtimeAvailableProcessors([]interface{}) interface{} {
+	return int64(runtime.NumCPU())
+}
+
+// "java/lang/Runtime.totalMemory()J" returns the memory obtained from the OS by the Go runtime
+func runtimeTotalMemory([]interface{}) interface{} {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.Sys)
+}
+
+// "java/lang/Runtime.freeMemory()J" returns the part of the total memory not holding live objects
+func runtimeFreeMemory([]interface{}) interface{} {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.Sys - stats.HeapAlloc)
+}
+
+// "java/lang/Runtime.maxMemory()J" returns the maximum heap size set by -Xmx or, if there
+// is none, the Go runtime's memory limit (set by GOMEMLIMIT). As in the JDK, if there is no
+// limit, this is Long.MAX_VALUE.
+func runtimeMaxMemory([]interface{}) interface{} {
+	if maxHeap := globals.GetGlobalRef().MaxHeapSize; maxHeap > 0 {
+		return maxHeap
+	}
+	return debug.SetMemoryLimit(-1) // a negative value reads the limit without changing it
+}
@@ -0,0 +1,64 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/globals"
+	"jacobin/object"
+	"runtime"
+	"testing"
+)
+
+func TestRuntimeGetRuntimeIsSingleton(t *testing.T) {
+	globals.InitGlobals("test")
+	rt1 := runtimeGetRuntime(nil).(*object.Object)
+	rt2 := runtimeGetRuntime(nil).(*object.Object)
+	if rt1 != rt2 {
+		t.Errorf("Runtime.getRuntime(): expected the same object on every call")
+	}
+	if className := object.GoStringFromStringPoolIndex(rt1.KlassName); className != "java/lang/Runtime" {
+		t.Errorf("Runtime.getRuntime(): expected a java/lang/Runtime object, got: %s", className)
+	}
+}
+
+func TestRuntimeMemory(t *testing.T) {
+	globals.InitGlobals("test")
+	rt := runtimeGetRuntime(nil)
+
+	total := runtimeTotalMemory([]interface{}{rt}).(int64)
+	free := runtimeFreeMemory([]interface{}{rt}).(int64)
+	maxMem := runtimeMaxMemory([]interface{}{rt}).(int64)
+
+	if total <= 0 || free <= 0 || maxMem <= 0 {
+		t.Errorf("Runtime memory: expected positive values, got total=%d, free=%d, max=%d", total, free, maxMem)
+	}
+	if free > total {
+		t.Errorf("Runtime memory: free memory (%d) exceeds total memory (%d)", free, total)
+	}
+	if total > maxMem {
+		t.Errorf("Runtime memory: total memory (%d) exceeds max memory (%d)", total, maxMem)
+	}
+}
+
+// -Xmx sets the maximum memory that Runtime reports
+func TestRuntimeMaxMemoryFromXmx(t *testing.T) {
+	globals.InitGlobals("test")
+	globals.GetGlobalRef().MaxHeapSize = 512 << 20
+	rt := runtimeGetRuntime(nil)
+	if maxMem := runtimeMaxMemory([]interface{}{rt}).(int64); maxMem != 512<<20 {
+		t.Errorf("Runtime.maxMemory(): expected %d, got %d", 512<<20, maxMem)
+	}
+}
+
+func TestRuntimeAvailableProcessors(t *testing.T) {
+	globals.InitGlobals("test")
+	rt := runtimeGetRuntime(nil)
+	procs := runtimeAvailableProcessors([]interface{}{rt}).(int64)
+	if procs <= 0 || procs != int64(runtime.NumCPU()) {
+		t.Errorf("Runtime.availableProcessors(): expected %d, got %d", runtime.NumCPU(), procs)
+	}
+}
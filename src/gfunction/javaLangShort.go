@@ -7,6 +7,8 @@
 package gfunction
 
 import (
+	"fmt"
+	"jacobin/excNames"
 	"jacobin/object"
 	"jacobin/types"
 )
@@ -25,12 +27,42 @@ func Load_Lang_Short() {
 			GFunction:  shortDoubleValue,
 		}
 
+	MethodSignatures["java/lang/Short.parseShort(Ljava/lang/String;)S"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  shortParseShort,
+		}
+
+	MethodSignatures["java/lang/Short.parseShort(Ljava/lang/String;I)S"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  shortParseShortRadix,
+		}
+
+	MethodSignatures["java/lang/Short.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  shortToString,
+		}
+
+	MethodSignatures["java/lang/Short.toString(S)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  integerToStringI,
+		}
+
 	MethodSignatures["java/lang/Short.valueOf(S)Ljava/lang/Short;"] =
 		GMeth{
 			ParamSlots: 1,
 			GFunction:  shortValueOf,
 		}
 
+	MethodSignatures["java/lang/Short.valueOf(Ljava/lang/String;)Ljava/lang/Short;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  shortValueOfString,
+		}
+
 }
 
 // "java/lang/Short.doubleValue()D"
@@ -46,3 +78,33 @@ func shortValueOf(params []interface{}) interface{} {
 	int64Value := params[0].(int64)
 	return populator("java/lang/Short", types.Short, int64Value)
 }
+
+// "java/lang/Short.parseShort(Ljava/lang/String;)S"
+func shortParseShort(params []interface{}) interface{} {
+	return parseIntegralInRange(params[0], 10, MinShortValue, MaxShortValue)
+}
+
+// "java/lang/Short.parseShort(Ljava/lang/String;I)S"
+func shortParseShortRadix(params []interface{}) interface{} {
+	rdx, ok := params[1].(int64)
+	if !ok {
+		return getGErrBlk(excNames.NumberFormatException, "Radix is not an integer")
+	}
+	return parseIntegralInRange(params[0], rdx, MinShortValue, MaxShortValue)
+}
+
+// "java/lang/Short.toString()Ljava/lang/String;"
+func shortToString(params []interface{}) interface{} {
+	parmObj := params[0].(*object.Object)
+	ii := parmObj.FieldTable["value"].Fvalue.(int64)
+	return object.StringObjectFromGoString(fmt.Sprintf("%d", ii))
+}
+
+// "java/lang/Short.valueOf(Ljava/lang/String;)Ljava/lang/Short;"
+func shortValueOfString(params []interface{}) interface{} {
+	result := shortParseShort(params)
+	if int64Value, ok := result.(int64); ok {
+		return populator("java/lang/Short", types.Short, int64Value)
+	}
+	return result // an error block
+}
@@ -0,0 +1,66 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+func TestShortParseBoundaries(t *testing.T) {
+	globals.InitGlobals("test")
+
+	for _, str := range []string{"-32768", "32767", "0", "+32767"} {
+		result := shortParseShort([]interface{}{object.StringObjectFromGoString(str)})
+		if _, ok := result.(int64); !ok {
+			t.Errorf("TestShortParseBoundaries: expected %s to parse, got %v", str, result)
+		}
+	}
+
+	for _, str := range []string{"-32769", "32768", "", "12x"} {
+		result := shortParseShort([]interface{}{object.StringObjectFromGoString(str)})
+		gerr, ok := result.(*GErrBlk)
+		if !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("TestShortParseBoundaries: expected NumberFormatException for %q, got %v", str, result)
+		}
+	}
+}
+
+func TestShortParseRadix(t *testing.T) {
+	globals.InitGlobals("test")
+
+	result := shortParseShortRadix([]interface{}{object.StringObjectFromGoString("-80"), int64(16)})
+	if result != int64(-128) {
+		t.Errorf("TestShortParseRadix: expected -128, got %v", result)
+	}
+}
+
+func TestShortValueOfStringAndToString(t *testing.T) {
+	globals.InitGlobals("test")
+
+	result := shortValueOfString([]interface{}{object.StringObjectFromGoString("-32768")})
+	obj, ok := result.(*object.Object)
+	if !ok {
+		t.Fatalf("TestShortValueOfStringAndToString: expected an object, got %v", result)
+	}
+	if obj.FieldTable["value"].Ftype != types.Short || obj.FieldTable["value"].Fvalue != int64(-32768) {
+		t.Errorf("TestShortValueOfStringAndToString: unexpected value field: %v", obj.FieldTable["value"])
+	}
+
+	str := object.GoStringFromStringObject(shortToString([]interface{}{obj}).(*object.Object))
+	if str != "-32768" {
+		t.Errorf("TestShortValueOfStringAndToString: expected \"-32768\", got %q", str)
+	}
+
+	result = shortValueOfString([]interface{}{object.StringObjectFromGoString("32768")})
+	if _, ok := result.(*GErrBlk); !ok {
+		t.Errorf("TestShortValueOfStringAndToString: expected an error for 32768, got %v", result)
+	}
+}
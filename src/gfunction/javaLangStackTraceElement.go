@@ -15,6 +15,7 @@ import (
 	"jacobin/log"
 	"jacobin/object"
 	"jacobin/shutdown"
+	"jacobin/types"
 	"jacobin/util"
 	"sort"
 	"strings"
@@ -103,10 +104,8 @@ func of(params []interface{}) interface{} {
 // by repeated calls to initStackTraceElement() below.
 // Returns nothing.
 func initStackTraceElements(params []interface{}) interface{} {
-	arrayObjPtr := params[0].(*object.Object) // the array of stackTraceElements we'll fill in
-	arrayObj := *arrayObjPtr
+	arrayObj := params[0].(*object.Object) // the array of stackTraceElements we'll fill in
 	rawSteArray := arrayObj.FieldTable["value"].Fvalue.([]*object.Object)
-	// rawSteArray := *rawSteArrayPtr
 
 	throwable := params[1].(*object.Object) // pointer to the Throwable object
 	jvmStack := throwable.FieldTable["frameStackRef"].Fvalue.(*list.List)
@@ -116,7 +115,9 @@ func initStackTraceElements(params []interface{}) interface{} {
 		frame := e.Value.(*frames.Frame)
 		ste := rawSteArray[i]
 		i += 1
-		initStackTraceElement(ste, frame)
+		if ste != nil { // nil if the StackTraceElement could not be instantiated
+			initStackTraceElement(ste, frame)
+		}
 	}
 
 	return nil
@@ -128,15 +129,12 @@ func initStackTraceElements(params []interface{}) interface{} {
 // follow the HotSpot way of implementing it. Official definition:
 // initStackTraceElement(Ljava/lang/StackTraceElement;Ljava/lang/StackFrameInfo;)V
 func initStackTraceElement(ste *object.Object, frm *frames.Frame) {
-	frame := *frm
-	stackTrace := *ste
+	frame := *frm // a copy, so that setting its ExceptionPC below does not change the frame
 
-	// helper function to facilitate subsequent field updates
-	// (Thanks to JetBrains' AI Assistant for this suggestion)
+	// helper function to facilitate subsequent field updates. Fields are values, so each
+	// one is stored into the StackTraceElement's field table as a whole.
 	addField := func(name, value string) {
-		fld := object.Field{}
-		fld.Fvalue = value
-		stackTrace.FieldTable[name] = fld
+		ste.FieldTable[name] = object.Field{Fvalue: value}
 	}
 
 	addField("declaringClass", frame.ClName)
@@ -148,12 +146,16 @@ func initStackTraceElement(ste *object.Object, frm *frames.Frame) {
 	addField("fileName", methClass.Data.SourceFile)
 	addField("moduleName", methClass.Data.Module)
 
-	// now get the source line number for any non-JDK classes and non-constructors
+	// now get the source line number for any non-JDK classes and non-constructors. As in
+	// the JDK, lineNumber is -1 if the line is unknown and -2 if the method is native.
+	// sourceLine holds the same line as a string, which is how the exception messages use it.
 
 	addField("sourceLine", "") // the default if no source line data is available
+	ste.FieldTable["lineNumber"] = object.Field{Ftype: types.Int, Fvalue: int64(-1)}
 	if !util.IsFilePartOfJDK(&frame.MethName) && !strings.HasPrefix(frame.MethName, "<init>") {
 		rawMethod, _ := classloader.FetchMethodAndCP(frame.ClName, frame.MethName, frame.MethType)
 		if rawMethod.MType == 'G' { // nothing more to do if it's a native method
+			ste.FieldTable["lineNumber"] = object.Field{Ftype: types.Int, Fvalue: int64(-2)}
 			return
 		}
 		method := rawMethod.Meth.(classloader.JmEntry)
@@ -166,6 +168,7 @@ func initStackTraceElement(ste *object.Object, frm *frames.Frame) {
 				line := searchLineNumberTable(method.Attribs[i].AttrContent, frame.ExceptionPC)
 				if line != -1 { // -1 means not found
 					addField("sourceLine", fmt.Sprintf("%d", line))
+					ste.FieldTable["lineNumber"] = object.Field{Ftype: types.Int, Fvalue: int64(line)}
 				}
 			}
 		}
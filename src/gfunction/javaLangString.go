@@ -7,13 +7,18 @@
 package gfunction
 
 import (
+	"errors"
 	"fmt"
 	"jacobin/classloader"
 	"jacobin/excNames"
+	"jacobin/globals"
 	"jacobin/object"
 	"jacobin/types"
+	"math"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf16"
 )
 
 // We don't run String's static initializer block because the initialization
@@ -136,6 +141,13 @@ func Load_Lang_String() {
 			GFunction:  stringEquals,
 		}
 
+	// the hash code of the string, computed as the JDK does, so that switch on a string works
+	MethodSignatures["java/lang/String.hashCode()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringHashCode,
+		}
+
 	// get the bytes from a string
 	MethodSignatures["java/lang/String.getBytes()[B"] =
 		GMeth{
@@ -173,6 +185,13 @@ func Load_Lang_String() {
 			GFunction:  compareToCaseSensitive,
 		}
 
+	// The Comparable bridge method, which checks that the argument is a String.
+	MethodSignatures["java/lang/String.compareTo(Ljava/lang/Object;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  comparableCompareTo,
+		}
+
 	// Compare 2 strings lexicographically, ignoring case (upper/lower).
 	// The return value is a negative integer, zero, or a positive integer
 	// as the String argument is greater than, equal to, or less than this String,
@@ -212,6 +231,13 @@ func Load_Lang_String() {
 			GFunction:  trapFunction,
 		}
 
+	// Return true if the string is empty or contains only white space.
+	MethodSignatures["java/lang/String.isBlank()Z"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringIsBlank,
+		}
+
 	// Return the length of a String.
 	MethodSignatures["java/lang/String.isLatin1()Z"] =
 		GMeth{
@@ -233,6 +259,27 @@ func Load_Lang_String() {
 			GFunction:  stringRepeat,
 		}
 
+	// Return the string with all leading and trailing white space removed.
+	MethodSignatures["java/lang/String.strip()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stripString,
+		}
+
+	// Return the string with all leading white space removed.
+	MethodSignatures["java/lang/String.stripLeading()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stripLeading,
+		}
+
+	// Return the string with all trailing white space removed.
+	MethodSignatures["java/lang/String.stripTrailing()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stripTrailing,
+		}
+
 	// Return a string in all lower case, using the reference object string as input.
 	MethodSignatures["java/lang/String.substring(I)Ljava/lang/String;"] =
 		GMeth{
@@ -268,7 +315,7 @@ func Load_Lang_String() {
 			GFunction:  toUpperCase,
 		}
 
-	// Return a string in all lower case, using the reference object string as input.
+	// Return the string with all leading and trailing chars <= U+0020 removed.
 	MethodSignatures["java/lang/String.trim()Ljava/lang/String;"] =
 		GMeth{
 			ParamSlots: 0,
@@ -374,14 +421,32 @@ func stringEquals(params []interface{}) interface{} {
 	// params[1]: compare-to string Object
 	obj := params[0].(*object.Object)
 	str1 := object.GoStringFromStringObject(obj)
-	obj = params[1].(*object.Object)
+
+	// as in the JDK, a string is never equal to null or to an object that's not a string
+	obj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(obj) || obj.KlassName != types.StringPoolStringIndex {
+		return types.JavaBoolFalse
+	}
 	str2 := object.GoStringFromStringObject(obj)
 
 	// Are they equal in value?
 	if str1 == str2 {
-		return int64(1) // true
+		return types.JavaBoolTrue
 	}
-	return int64(0) // false
+	return types.JavaBoolFalse
+}
+
+// "java/lang/String.hashCode()I"
+// Computed as in the JDK: s[0]*31^(n-1) + s[1]*31^(n-2) + ... + s[n-1], where s[i] is the
+// i-th UTF-16 char of the string, using 32-bit int arithmetic. javac relies on this value,
+// as it compiles switch on a string to a LOOKUPSWITCH on the string's hash code.
+func stringHashCode(params []interface{}) interface{} {
+	str := object.GoStringFromStringObject(params[0].(*object.Object))
+	var hash int32
+	for _, char := range utf16.Encode([]rune(str)) {
+		hash = 31*hash + int32(char)
+	}
+	return int64(hash)
 }
 
 // Instantiate a new empty string - "java/lang/String.<init>()V"
@@ -436,19 +501,16 @@ func newStringFromChars(params []interface{}) interface{} {
 	// params[0] = reference string (to be updated with byte array)
 	// params[1] = byte array object
 	ints := params[1].(*object.Object).FieldTable["value"].Fvalue.([]int64)
-
-	var bytes []byte
-	for _, ii := range ints {
-		bytes = append(bytes, byte(ii&0xFF))
-	}
-	object.UpdateStringObjectFromBytes(params[0].(*object.Object), bytes)
+	str := object.GoStringFromJavaCharArray(ints)
+	object.UpdateStringObjectFromBytes(params[0].(*object.Object), []byte(str))
 	return nil
 }
 
 // "java/lang/String.getBytes()[B"
 func getBytesFromString(params []interface{}) interface{} {
-	// params[0] = reference string with byte array to be returned
-	bytes := object.ByteArrayFromStringObject(params[0].(*object.Object))
+	// params[0] = reference string whose chars are encoded in the default charset
+	str := object.GoStringFromStringObject(params[0].(*object.Object))
+	bytes := encodeChars(object.JavaCharArrayFromGoString(str), defaultCharsetName())
 	return populator("[B", types.ByteArray, bytes)
 }
 
@@ -496,7 +558,6 @@ func StringFormatter(params []interface{}) interface{} {
 	}
 
 	// Make sure that the argument slice is a reference array.
-	valuesOut := []any{}
 	fld := params[1].(*object.Object).FieldTable["value"]
 	if !strings.HasPrefix(fld.Ftype, types.RefArray) {
 		errMsg := fmt.Sprintf("StringFormatter: Expected Ftype=%s for params[1]: fld.Ftype=%s, fld.Fvalue=%v",
@@ -507,57 +568,251 @@ func StringFormatter(params []interface{}) interface{} {
 	// valuesIn = the reference array
 	valuesIn := fld.Fvalue.([]*object.Object)
 
-	// Main loop for reference array.
-	for ii := 0; ii < len(valuesIn); ii++ {
+	str, gerr := javaFormat(formatString, valuesIn)
+	if gerr != nil {
+		return gerr
+	}
 
-		// Get the current object's value field.
-		fld := valuesIn[ii].FieldTable["value"]
+	// Return a pointer to an object.Object that wraps the string byte array.
+	return object.StringObjectFromGoString(str)
+}
 
-		// If type is string object, process it.
-		if fld.Ftype == types.ByteArray {
-			str := string(fld.Fvalue.([]byte))
-			valuesOut = append(valuesOut, str)
-		} else {
-			// Not a string object.
-			switch fld.Ftype {
-			case types.ByteArray:
-				str := string(fld.Fvalue.([]byte))
-				valuesOut = append(valuesOut, str)
-			case types.Byte:
-				valuesOut = append(valuesOut, uint8(fld.Fvalue.(int64)))
-			case types.Bool:
-				var zz bool
-				if fld.Fvalue.(int64) == 0 {
-					zz = false
-				} else {
-					zz = true
-				}
-				valuesOut = append(valuesOut, zz)
-			case types.Char:
-				cc := fmt.Sprint(fld.Fvalue.(int64))
-				valuesOut = append(valuesOut, cc)
-			case types.Double:
-				valuesOut = append(valuesOut, fld.Fvalue.(float64))
-			case types.Float:
-				valuesOut = append(valuesOut, fld.Fvalue.(float64))
-			case types.Int:
-				valuesOut = append(valuesOut, fld.Fvalue.(int64))
-			case types.Long:
-				valuesOut = append(valuesOut, fld.Fvalue.(int64))
-			case types.Short:
-				valuesOut = append(valuesOut, fld.Fvalue.(int64))
-			default:
-				errMsg := fmt.Sprintf("StringFormatter: Invalid parameter %d is of type %s", ii+1, fld.Ftype)
-				return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+// javaFormat formats the arguments as directed by a Java format string, whose specifiers
+// have the syntax %[argument_index$][flags][width][.precision]conversion. Go's fmt does not
+// understand Java's conversions and does not fail on a mismatch, so each specifier is
+// validated against its argument here and then translated to the equivalent Go verb.
+func javaFormat(format string, args []*object.Object) (string, *GErrBlk) {
+	var sb strings.Builder
+	nextArg := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+		start := i
+		i++
+
+		// explicit argument index, e.g. %2$s
+		argIndex := -1
+		j := i
+		for j < len(format) && format[j] >= '0' && format[j] <= '9' {
+			j++
+		}
+		if j > i && j < len(format) && format[j] == '$' {
+			argIndex, _ = strconv.Atoi(format[i:j])
+			argIndex-- // Java's argument indexes start at 1
+			i = j + 1
+		}
+
+		flagsStart := i
+		for i < len(format) && strings.IndexByte("-#+ 0,", format[i]) >= 0 {
+			i++
+		}
+		flags := format[flagsStart:i]
+
+		widthStart := i
+		for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+			i++
+		}
+		width := format[widthStart:i]
+
+		precision := ""
+		if i < len(format) && format[i] == '.' {
+			precisionStart := i
+			i++
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
 			}
+			precision = format[precisionStart:i]
 		}
+
+		if i >= len(format) || precision == "." {
+			errMsg := fmt.Sprintf("Conversion = '%s'", format[start:])
+			return "", getGErrBlk(excNames.UnknownFormatConversionException, errMsg)
+		}
+		conv := format[i]
+		spec := format[start : i+1]
+
+		// the conversions that take no argument
+		switch conv {
+		case '%':
+			sb.WriteString(fmt.Sprintf("%"+strings.ReplaceAll(flags, "0", "")+width+"s", "%"))
+			continue
+		case 'n': // the platform's line separator, as in System.lineSeparator()
+			sb.WriteString(lineSeparator())
+			continue
+		}
+
+		if argIndex < 0 {
+			argIndex = nextArg
+			nextArg++
+		}
+		if argIndex >= len(args) {
+			errMsg := fmt.Sprintf("Format specifier '%s'", spec)
+			return "", getGErrBlk(excNames.MissingFormatArgumentException, errMsg)
+		}
+
+		text, gerr := formatJavaArg(conv, flags, width, precision, args[argIndex])
+		if gerr != nil {
+			return "", gerr
+		}
+		sb.WriteString(text)
 	}
+	return sb.String(), nil
+}
 
-	// Use golang fmt.Sprintf to do the heavy lifting.
-	str := fmt.Sprintf(formatString, valuesOut...)
+// formatJavaArg formats a single argument for the Java conversion conv. An argument whose
+// type does not suit the conversion results in an IllegalFormatConversionException.
+func formatJavaArg(conv byte, flags, width, precision string, arg *object.Object) (string, *GErrBlk) {
+	upper := conv >= 'A' && conv <= 'Z'
+	lowerConv := conv
+	if upper {
+		lowerConv = conv + ('a' - 'A')
+	}
 
-	// Return a pointer to an object.Object that wraps the string byte array.
-	return object.StringObjectFromGoString(str)
+	grouping := strings.Contains(flags, ",")
+	if grouping && lowerConv != 'd' && lowerConv != 'f' && lowerConv != 'g' {
+		errMsg := fmt.Sprintf("Conversion = %c, Flags = ,", conv)
+		return "", getGErrBlk(excNames.FormatFlagsConversionMismatchException, errMsg)
+	}
+	goSpec := "%" + strings.ReplaceAll(flags, ",", "") + width + precision
+
+	var text string
+	switch lowerConv {
+	case 'b':
+		value := !object.IsNull(arg)
+		if value && arg.FieldTable["value"].Ftype == types.Bool {
+			value = arg.FieldTable["value"].Fvalue.(int64) != 0
+		}
+		text = fmt.Sprintf(goSpec+"s", strconv.FormatBool(value))
+
+	case 's':
+		str := "null"
+		if !object.IsNull(arg) {
+			switch result := valueOfObject([]interface{}{arg}).(type) {
+			case *object.Object:
+				str = object.GoStringFromStringObject(result)
+			case *GErrBlk:
+				return "", result
+			}
+		}
+		text = fmt.Sprintf(goSpec+"s", str)
+
+	case 'c':
+		if object.IsNull(arg) {
+			text = fmt.Sprintf(goSpec+"s", "null")
+			break
+		}
+		switch arg.FieldTable["value"].Ftype {
+		case types.Char, types.Byte, types.Short, types.Int:
+			text = fmt.Sprintf(goSpec+"c", rune(arg.FieldTable["value"].Fvalue.(int64)))
+		default:
+			return "", formatConversionMismatch(conv, arg)
+		}
+
+	case 'd', 'o', 'x':
+		if object.IsNull(arg) {
+			text = fmt.Sprintf(goSpec+"s", "null")
+			break
+		}
+		fld := arg.FieldTable["value"]
+		var bits uint
+		switch fld.Ftype {
+		case types.Byte:
+			bits = 8
+		case types.Short:
+			bits = 16
+		case types.Int:
+			bits = 32
+		case types.Long:
+			bits = 64
+		default:
+			return "", formatConversionMismatch(conv, arg)
+		}
+		value := fld.Fvalue.(int64)
+		switch {
+		case lowerConv == 'd' && grouping:
+			text = fmt.Sprintf(goSpec+"s", groupDigits(strconv.FormatInt(value, 10)))
+		case lowerConv == 'd':
+			text = fmt.Sprintf(goSpec+"d", value)
+		default: // as in Java, negative values are shown in two's complement for the type's size
+			unsigned := uint64(value)
+			if bits < 64 {
+				unsigned &= (1 << bits) - 1
+			}
+			text = fmt.Sprintf(goSpec+string(lowerConv), unsigned)
+		}
+
+	case 'e', 'f', 'g':
+		if object.IsNull(arg) {
+			text = fmt.Sprintf(goSpec+"s", "null")
+			break
+		}
+		fld := arg.FieldTable["value"]
+		if fld.Ftype != types.Double && fld.Ftype != types.Float {
+			return "", formatConversionMismatch(conv, arg)
+		}
+		value := fld.Fvalue.(float64)
+		if grouping {
+			prec := 6
+			if precision != "" {
+				prec, _ = strconv.Atoi(precision[1:])
+			}
+			text = fmt.Sprintf("%"+strings.ReplaceAll(flags, ",", "")+width+"s",
+				groupDigits(strconv.FormatFloat(value, 'f', prec, 64)))
+		} else {
+			text = fmt.Sprintf(goSpec+string(lowerConv), value)
+		}
+
+	default:
+		errMsg := fmt.Sprintf("Conversion = '%c'", conv)
+		return "", getGErrBlk(excNames.UnknownFormatConversionException, errMsg)
+	}
+
+	if upper {
+		text = strings.ToUpper(text)
+	}
+	return text, nil
+}
+
+// formatConversionMismatch returns the exception for a conversion applied to an argument
+// of the wrong type. The message follows the JDK's, e.g. "d != java.lang.String".
+func formatConversionMismatch(conv byte, arg *object.Object) *GErrBlk {
+	className := object.GoStringFromStringPoolIndex(arg.KlassName)
+	errMsg := fmt.Sprintf("%c != %s", conv, strings.ReplaceAll(className, "/", "."))
+	return getGErrBlk(excNames.IllegalFormatConversionException, errMsg)
+}
+
+// groupDigits inserts a comma between each group of three digits in the integer part
+// of a number, as the ',' flag does.
+func groupDigits(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	fraction := ""
+	if dot := strings.IndexByte(number, '.'); dot >= 0 {
+		number, fraction = number[:dot], number[dot:]
+	}
+	var sb strings.Builder
+	for i, digit := range number {
+		if i > 0 && (len(number)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	return sign + sb.String() + fraction
+}
+
+// "java/lang/String.isBlank()Z"
+// White space is as defined by Character.isWhitespace(), as in String.strip().
+func stringIsBlank(params []interface{}) interface{} {
+	str := object.GoStringFromStringObject(params[0].(*object.Object))
+	if strings.TrimLeftFunc(str, isJavaWhitespace) == "" {
+		return types.JavaBoolTrue
+	}
+	return types.JavaBoolFalse
 }
 
 // "java/lang/String.isLatin1()Z"
@@ -578,17 +833,19 @@ func stringLength(params []interface{}) interface{} {
 func stringRepeat(params []interface{}) interface{} {
 	// params[0] = base string
 	// params[1] = int64 repetition factor
-	oldStr := object.GoStringFromStringObject(params[0].(*object.Object))
-	var newStr string
 	count := params[1].(int64)
-	for ii := int64(0); ii < count; ii++ {
-		newStr = newStr + oldStr
+	if count < 0 {
+		errMsg := fmt.Sprintf("stringRepeat: count is negative: %d", count)
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+	if count == 1 {
+		return params[0]
 	}
 
 	// Return new string in an object.
-	obj := object.StringObjectFromGoString(newStr)
+	oldStr := object.GoStringFromStringObject(params[0].(*object.Object))
+	obj := object.StringObjectFromGoString(strings.Repeat(oldStr, int(count)))
 	return obj
-
 }
 
 // "java/lang/String.substring(I)Ljava/lang/String;"
@@ -648,12 +905,9 @@ func substringStartEnd(params []interface{}) interface{} {
 // "java/lang/String.toCharArray()[C"
 func toCharArray(params []interface{}) interface{} {
 	// params[0]: input string
+	// Java chars are UTF-16 code units, so multi-byte characters are not split into bytes
 	obj := params[0].(*object.Object)
-	bytes := obj.FieldTable["value"].Fvalue.([]byte)
-	var iArray []int64
-	for _, bb := range bytes {
-		iArray = append(iArray, int64(bb))
-	}
+	iArray := object.JavaCharArrayFromGoString(object.GoStringFromStringObject(obj))
 	return populator("[C", types.IntArray, iArray)
 }
 
@@ -674,11 +928,54 @@ func toUpperCase(params []interface{}) interface{} {
 }
 
 // "java/lang/String.trim()Ljava/lang/String;"
+// As in Java, all chars up to and including the space (U+0020) count as white space.
 func trimString(params []interface{}) interface{} {
 	// params[0]: input string
-	str := strings.Trim(object.GoStringFromStringObject(params[0].(*object.Object)), " ")
-	obj := object.StringObjectFromGoString(str)
-	return obj
+	return trimStringFunc(params[0].(*object.Object), strings.TrimFunc, func(r rune) bool { return r <= ' ' })
+}
+
+// "java/lang/String.strip()Ljava/lang/String;"
+func stripString(params []interface{}) interface{} {
+	// params[0]: input string
+	return trimStringFunc(params[0].(*object.Object), strings.TrimFunc, isJavaWhitespace)
+}
+
+// "java/lang/String.stripLeading()Ljava/lang/String;"
+func stripLeading(params []interface{}) interface{} {
+	// params[0]: input string
+	return trimStringFunc(params[0].(*object.Object), strings.TrimLeftFunc, isJavaWhitespace)
+}
+
+// "java/lang/String.stripTrailing()Ljava/lang/String;"
+func stripTrailing(params []interface{}) interface{} {
+	// params[0]: input string
+	return trimStringFunc(params[0].(*object.Object), strings.TrimRightFunc, isJavaWhitespace)
+}
+
+// trimStringFunc applies trimmer to the string, removing the runes for which isSpace is true.
+// As in Java, if nothing is removed, the original string object is returned.
+func trimStringFunc(strObj *object.Object, trimmer func(string, func(rune) bool) string,
+	isSpace func(rune) bool) interface{} {
+	str := object.GoStringFromStringObject(strObj)
+	trimmed := trimmer(str, isSpace)
+	if len(trimmed) == len(str) {
+		return strObj
+	}
+	return object.StringObjectFromGoString(trimmed)
+}
+
+// isJavaWhitespace reports whether r is white space as defined by Java's Character.isWhitespace():
+// a Unicode space, line, or paragraph separator other than the non-breaking spaces U+00A0,
+// U+2007, and U+202F, or one of the controls \t, \n, \u000B, \f, \r, and \u001C through \u001F.
+func isJavaWhitespace(r rune) bool {
+	switch {
+	case r == '\u00A0', r == '\u2007', r == '\u202F':
+		return false
+	case r >= '\t' && r <= '\r', r >= '\u001C' && r <= '\u001F':
+		return true
+	default:
+		return unicode.In(r, unicode.Zs, unicode.Zl, unicode.Zp)
+	}
 }
 
 // "java/lang/String.valueOf(Z)Ljava/lang/String;"
@@ -699,7 +996,7 @@ func valueOfBoolean(params []interface{}) interface{} {
 func valueOfChar(params []interface{}) interface{} {
 	// params[0]: input char
 	value := params[0].(int64)
-	str := fmt.Sprintf("%c", value)
+	str := object.GoStringFromJavaCharArray([]int64{value})
 	obj := object.StringObjectFromGoString(str)
 	return obj
 }
@@ -709,10 +1006,7 @@ func valueOfCharArray(params []interface{}) interface{} {
 	// params[0]: input char array
 	propObj := params[0].(*object.Object)
 	intArray := propObj.FieldTable["value"].Fvalue.([]int64)
-	var str string
-	for _, ch := range intArray {
-		str += fmt.Sprintf("%c", ch)
-	}
+	str := object.GoStringFromJavaCharArray(intArray)
 	obj := object.StringObjectFromGoString(str)
 	return obj
 }
@@ -750,10 +1044,7 @@ func valueOfCharSubarray(params []interface{}) interface{} {
 func valueOfDouble(params []interface{}) interface{} {
 	// params[0]: input double
 	value := params[0].(float64)
-	str := strconv.FormatFloat(value, 'f', -1, 64)
-	if !strings.Contains(str, ".") {
-		str += ".0"
-	}
+	str := javaFloatingPointToString(value, 64)
 	obj := object.StringObjectFromGoString(str)
 	return obj
 }
@@ -762,14 +1053,48 @@ func valueOfDouble(params []interface{}) interface{} {
 func valueOfFloat(params []interface{}) interface{} {
 	// params[0]: input float
 	value := params[0].(float64)
-	str := strconv.FormatFloat(value, 'f', -1, 64)
-	if !strings.Contains(str, ".") {
-		str += ".0"
-	}
+	str := javaFloatingPointToString(value, 32)
 	obj := object.StringObjectFromGoString(str)
 	return obj
 }
 
+// javaFloatingPointToString formats a double (bitSize 64) or a float (bitSize 32) as Java's
+// Double.toString() and Float.toString() do: with the fewest digits that uniquely identify the
+// value, in plain decimal notation if 10^-3 <= |value| < 10^7, otherwise in scientific notation
+// (such as 1.0E-5 or 1.2345E20). Either way, there is always at least one digit after the point.
+func javaFloatingPointToString(value float64, bitSize int) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	case value == 0:
+		if math.Signbit(value) {
+			return "-0.0"
+		}
+		return "0.0"
+	}
+
+	if abs := math.Abs(value); abs >= 1e-3 && abs < 1e7 {
+		str := strconv.FormatFloat(value, 'f', -1, bitSize)
+		if !strings.Contains(str, ".") {
+			str += ".0"
+		}
+		return str
+	}
+
+	// Go formats as 1.2345e+20; Java as 1.2345E20
+	str := strconv.FormatFloat(value, 'e', -1, bitSize)
+	mantissa, exponent, _ := strings.Cut(str, "e")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	expValue, _ := strconv.Atoi(exponent)
+	return mantissa + "E" + strconv.Itoa(expValue)
+}
+
 // "java/lang/String.valueOf(I)Ljava/lang/String;"
 func valueOfInt(params []interface{}) interface{} {
 	// params[0]: input int
@@ -791,10 +1116,53 @@ func valueOfLong(params []interface{}) interface{} {
 // "java/lang/String.valueOf(Ljava/lang/Object;)Ljava/lang/String;"
 func valueOfObject(params []interface{}) interface{} {
 	// params[0]: input Object
-	ptrObj := params[0].(*object.Object)
-	str := ptrObj.FormatField("")
-	obj := object.StringObjectFromGoString(str)
-	return obj
+	ptrObj, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(ptrObj) {
+		return object.StringObjectFromGoString("null")
+	}
+
+	// As in Java, the result is the object's toString(), which for a String is the object itself.
+	className := object.GoStringFromStringPoolIndex(ptrObj.KlassName)
+	if className == types.StringClassName {
+		return ptrObj
+	}
+	if gmeth, ok := MethodSignatures[className+".toString()Ljava/lang/String;"]; ok {
+		return gmeth.GFunction([]interface{}{ptrObj})
+	}
+
+	// Boxed primitives whose class has no toString() G function are formatted from their value.
+	value := ptrObj.FieldTable["value"].Fvalue
+	switch className {
+	case "java/lang/Boolean":
+		return valueOfBoolean([]interface{}{value})
+	case "java/lang/Character":
+		return valueOfChar([]interface{}{value})
+	case "java/lang/Byte", "java/lang/Short", "java/lang/Integer", "java/lang/Long":
+		return valueOfLong([]interface{}{value})
+	case "java/lang/Float":
+		return valueOfFloat([]interface{}{value})
+	case "java/lang/Double":
+		return valueOfDouble([]interface{}{value})
+	}
+
+	// Otherwise, call the object's toString(), which finds any override in a user class. If
+	// that can't be done, use the format of Object.toString(), which is what it would return.
+	// An exception thrown by the toString() is passed on, to be thrown in the calling thread.
+	glob := globals.GetGlobalRef()
+	ret, err := glob.FuncInvokeMethod(ptrObj, "toString", "()Ljava/lang/String;")
+	var javaExc *JavaException
+	if errors.As(err, &javaExc) {
+		return getGErrBlkForException(javaExc)
+	}
+	if err == nil {
+		if str, ok := ret.(*object.Object); ok {
+			if object.IsNull(str) { // as in Java, a toString() that returns null shows as "null"
+				return object.StringObjectFromGoString("null")
+			}
+			return str
+		}
+	}
+	return object.StringObjectFromGoString(objectDefaultString(ptrObj))
 }
 
 // "java/lang/String.compareTo(Ljava/lang/String;)I"
@@ -0,0 +1,400 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by  the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/object"
+	"strings"
+	"sync"
+)
+
+// Implementation of the functions in Java/lang/StringBuffer.
+// StringBuffer has the same API as StringBuilder, differing only in that its methods are
+// synchronized. So the StringBuilder functions, which keep the contents in the "value"
+// field, serve for StringBuffer as well, each wrapped by synchronizedBuffer(), which holds
+// a lock on the buffer while the function runs. As with StringBuilder, chars() and
+// codePoints() are trapped (see Traps.go).
+
+func Load_Lang_StringBuffer() {
+
+	MethodSignatures["java/lang/StringBuffer.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/lang/StringBuffer.<init>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderInit,
+		}
+
+	MethodSignatures["java/lang/StringBuffer.<init>(I)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderInitCapacity,
+		}
+
+	MethodSignatures["java/lang/StringBuffer.<init>(Ljava/lang/CharSequence;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderInitCharSequence,
+		}
+
+	MethodSignatures["java/lang/StringBuffer.<init>(Ljava/lang/String;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderInitString,
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(C)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendChar),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(D)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderAppendDouble),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(F)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendFloat),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(I)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendInt),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(J)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderAppendLong),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/CharSequence;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendObject),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/CharSequence;II)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  synchronizedBuffer(stringBuilderAppendCharSequenceRange),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/Object;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendObject),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/String;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendString),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/StringBuffer;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendObject),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append(Z)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendBoolean),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append([C)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendCharArray),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.append([CII)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  synchronizedBuffer(stringBuilderAppendCharSubarray),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.appendCodePoint(I)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderAppendCodePoint),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.capacity()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  synchronizedBuffer(stringBuilderCapacity),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.charAt(I)C"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderCharAt),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.codePointAt(I)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderCodePointAt),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.codePointBefore(I)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderCodePointBefore),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.codePointCount(II)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderCodePointCount),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.compareTo(Ljava/lang/StringBuffer;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderCompareTo),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.delete(II)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderDelete),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.deleteCharAt(I)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderDeleteCharAt),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.ensureCapacity(I)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderEnsureCapacity),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.getChars(II[CI)V"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  synchronizedBuffer(stringBuilderGetChars),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.indexOf(Ljava/lang/String;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderIndexOf),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.indexOf(Ljava/lang/String;I)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderIndexOfFrom),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(IC)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertChar),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(ID)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  synchronizedBuffer(stringBuilderInsertDouble),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(IF)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertFloat),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(II)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertInt),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(IJ)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  synchronizedBuffer(stringBuilderInsertLong),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(ILjava/lang/CharSequence;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertObject),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(ILjava/lang/CharSequence;II)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  synchronizedBuffer(stringBuilderInsertCharSequenceRange),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(ILjava/lang/Object;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertObject),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(ILjava/lang/String;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertObject),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(IZ)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertBoolean),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(I[C)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderInsertCharArray),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.insert(I[CII)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  synchronizedBuffer(stringBuilderInsertCharSubarray),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.isLatin1()Z"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  synchronizedBuffer(isLatin1),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.lastIndexOf(Ljava/lang/String;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderLastIndexOf),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.lastIndexOf(Ljava/lang/String;I)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderLastIndexOfFrom),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.length()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  synchronizedBuffer(stringBuilderLength),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.offsetByCodePoints(II)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderOffsetByCodePoints),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.replace(IILjava/lang/String;)Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  synchronizedBuffer(stringBuilderReplace),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.reverse()Ljava/lang/StringBuffer;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  synchronizedBuffer(stringBuilderReverse),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.setCharAt(IC)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderSetCharAt),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.setLength(I)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderSetLength),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.subSequence(II)Ljava/lang/CharSequence;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderSubstringStartEnd),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.substring(I)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  synchronizedBuffer(stringBuilderSubstringToTheEnd),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.substring(II)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  synchronizedBuffer(stringBuilderSubstringStartEnd),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  synchronizedBuffer(stringBuilderToString),
+		}
+
+	MethodSignatures["java/lang/StringBuffer.trimToSize()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  synchronizedBuffer(stringBuilderTrimToSize),
+		}
+
+}
+
+// stringBufferLocks are the locks that synchronize StringBuffer's methods. A buffer is
+// locked by the lock its object id selects, so two buffers can share a lock, but no buffer
+// is ever locked by two different locks.
+var stringBufferLocks [64]sync.Mutex
+
+// synchronizedBuffer returns a G function that runs gfunction, a StringBuilder function,
+// on a StringBuffer, holding the buffer's lock while it does. Any object arguments other
+// than Strings and arrays, such as the CharSequence in append(CharSequence), are first
+// converted to Strings, calling their toString() if need be. This is done before locking,
+// as the toString() can use the same buffer, as in sb.append(sb), which Java allows.
+func synchronizedBuffer(gfunction func([]interface{}) interface{}) func([]interface{}) interface{} {
+	return func(params []interface{}) interface{} {
+		args := make([]interface{}, len(params))
+		copy(args, params)
+		for i := 1; i < len(args); i++ {
+			obj, ok := args[i].(*object.Object)
+			if !ok || object.IsNull(obj) || object.IsStringObject(obj) ||
+				strings.HasPrefix(object.GoStringFromStringPoolIndex(obj.KlassName), "[") {
+				continue
+			}
+			str := valueOfObject([]interface{}{obj})
+			if errBlk, isErr := str.(*GErrBlk); isErr {
+				return errBlk
+			}
+			args[i] = str
+		}
+
+		lock := &stringBufferLocks[object.ObjectId(params[0].(*object.Object))%uint64(len(stringBufferLocks))]
+		lock.Lock()
+		defer lock.Unlock()
+		return gfunction(args)
+	}
+}
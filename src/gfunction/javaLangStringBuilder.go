@@ -6,16 +6,920 @@
 
 package gfunction
 
-// Implementation of some of the functions in Java/lang/Class.
+import (
+	"fmt"
+	"jacobin/excNames"
+	"jacobin/object"
+	"jacobin/types"
+	"slices"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Implementation of the functions in Java/lang/StringBuilder.
+// Strategy: as in Java, the buffer is kept in the "value" field as an array of UTF-16
+// chars, the first "count" of which are the contents, so that indexes are char indexes and
+// any sequence of chars, such as a surrogate pair appended one char at a time, is kept as
+// is. The contents become a Go string only when a String is made from them, as by
+// toString() and substring(). As the buffer is not in the layout that the JDK's bytecode
+// for StringBuilder expects (a byte array and a coder), all of StringBuilder's public
+// methods, including those it inherits from AbstractStringBuilder, are implemented here.
+// The two that return streams, chars() and codePoints(), are trapped (see Traps.go).
 
 func Load_Lang_StringBuilder() {
 
+	MethodSignatures["java/lang/StringBuilder.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.<init>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderInit,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.<init>(I)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderInitCapacity,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.<init>(Ljava/lang/CharSequence;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderInitCharSequence,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.<init>(Ljava/lang/String;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderInitString,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(C)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendChar,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(D)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderAppendDouble,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(F)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendFloat,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(I)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendInt,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(J)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderAppendLong,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(Ljava/lang/CharSequence;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendObject,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(Ljava/lang/CharSequence;II)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  stringBuilderAppendCharSequenceRange,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(Ljava/lang/Object;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendObject,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(Ljava/lang/String;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendString,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(Ljava/lang/StringBuffer;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendObject,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append(Z)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendBoolean,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append([C)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendCharArray,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.append([CII)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  stringBuilderAppendCharSubarray,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.appendCodePoint(I)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderAppendCodePoint,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.capacity()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderCapacity,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.charAt(I)C"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderCharAt,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.codePointAt(I)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderCodePointAt,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.codePointBefore(I)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderCodePointBefore,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.codePointCount(II)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderCodePointCount,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.compareTo(Ljava/lang/StringBuilder;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderCompareTo,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.delete(II)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderDelete,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.deleteCharAt(I)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderDeleteCharAt,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.ensureCapacity(I)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderEnsureCapacity,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.getChars(II[CI)V"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  stringBuilderGetChars,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.indexOf(Ljava/lang/String;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderIndexOf,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.indexOf(Ljava/lang/String;I)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderIndexOfFrom,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(IC)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertChar,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(ID)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  stringBuilderInsertDouble,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(IF)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertFloat,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(II)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertInt,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(IJ)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  stringBuilderInsertLong,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(ILjava/lang/CharSequence;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertObject,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(ILjava/lang/CharSequence;II)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  stringBuilderInsertCharSequenceRange,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(ILjava/lang/Object;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertObject,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(ILjava/lang/String;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertObject,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(IZ)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertBoolean,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(I[C)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderInsertCharArray,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.insert(I[CII)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 4,
+			GFunction:  stringBuilderInsertCharSubarray,
+		}
+
 	MethodSignatures["java/lang/StringBuilder.isLatin1()Z"] =
 		GMeth{
 			ParamSlots: 0,
 			GFunction:  isLatin1,
 		}
 
+	MethodSignatures["java/lang/StringBuilder.lastIndexOf(Ljava/lang/String;)I"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderLastIndexOf,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.lastIndexOf(Ljava/lang/String;I)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderLastIndexOfFrom,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.length()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderLength,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.offsetByCodePoints(II)I"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderOffsetByCodePoints,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.replace(IILjava/lang/String;)Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  stringBuilderReplace,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.reverse()Ljava/lang/StringBuilder;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderReverse,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.setCharAt(IC)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderSetCharAt,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.setLength(I)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderSetLength,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.subSequence(II)Ljava/lang/CharSequence;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderSubstringStartEnd,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.substring(I)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringBuilderSubstringToTheEnd,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.substring(II)Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringBuilderSubstringStartEnd,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderToString,
+		}
+
+	MethodSignatures["java/lang/StringBuilder.trimToSize()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringBuilderTrimToSize,
+		}
+
+}
+
+// "java/lang/StringBuilder.<init>()V"
+// As in Java, the initial capacity is 16.
+func stringBuilderInit(params []interface{}) interface{} {
+	sbInitBuffer(params[0].(*object.Object), nil, 16)
+	return nil
+}
+
+// "java/lang/StringBuilder.<init>(Ljava/lang/CharSequence;)V"
+func stringBuilderInitCharSequence(params []interface{}) interface{} {
+	seqObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(seqObj) {
+		errMsg := "stringBuilderInitCharSequence: CharSequence argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	str, errBlk := sbStringOf(valueOfObject([]interface{}{seqObj}))
+	if errBlk != nil {
+		return errBlk
+	}
+	sbInitContents(params[0].(*object.Object), object.JavaCharArrayFromGoString(str))
+	return nil
+}
+
+// "java/lang/StringBuilder.<init>(I)V"
+// The int is the initial capacity, which is used to size the buffer. It can't be negative.
+func stringBuilderInitCapacity(params []interface{}) interface{} {
+	capacity := params[1].(int64)
+	if capacity < 0 {
+		errMsg := fmt.Sprintf("stringBuilderInitCapacity: capacity is negative: %d", capacity)
+		return getGErrBlk(excNames.NegativeArraySizeException, errMsg)
+	}
+	sbInitBuffer(params[0].(*object.Object), nil, capacity)
+	return nil
+}
+
+// "java/lang/StringBuilder.<init>(Ljava/lang/String;)V"
+func stringBuilderInitString(params []interface{}) interface{} {
+	strObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		errMsg := "stringBuilderInitString: String argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	sbInitContents(params[0].(*object.Object), object.JavaCharArrayFromGoString(object.GoStringFromStringObject(strObj)))
+	return nil
+}
+
+// "java/lang/StringBuilder.append(Ljava/lang/String;)Ljava/lang/StringBuilder;"
+func stringBuilderAppendString(params []interface{}) interface{} {
+	sb := params[0].(*object.Object)
+	str := "null" // as in Java, appending a null String appends "null"
+	if strObj, ok := params[1].(*object.Object); ok && !object.IsNull(strObj) {
+		str = object.GoStringFromStringObject(strObj)
+	}
+	sbAppendChars(sb, object.JavaCharArrayFromGoString(str))
+	return sb
+}
+
+// "java/lang/StringBuilder.append(Z)Ljava/lang/StringBuilder;"
+func stringBuilderAppendBoolean(params []interface{}) interface{} {
+	return sbAppend(params[0].(*object.Object), valueOfBoolean(params[1:]))
+}
+
+// "java/lang/StringBuilder.append(C)Ljava/lang/StringBuilder;"
+// The char is appended as is, so a surrogate pair appended one char at a time is kept.
+func stringBuilderAppendChar(params []interface{}) interface{} {
+	sb := params[0].(*object.Object)
+	sbAppendChars(sb, []int64{params[1].(int64)})
+	return sb
+}
+
+// "java/lang/StringBuilder.append(I)Ljava/lang/StringBuilder;"
+func stringBuilderAppendInt(params []interface{}) interface{} {
+	return sbAppend(params[0].(*object.Object), valueOfInt(params[1:]))
+}
+
+// "java/lang/StringBuilder.append(J)Ljava/lang/StringBuilder;"
+func stringBuilderAppendLong(params []interface{}) interface{} {
+	return sbAppend(params[0].(*object.Object), valueOfLong(params[1:]))
+}
+
+// "java/lang/StringBuilder.append(F)Ljava/lang/StringBuilder;"
+func stringBuilderAppendFloat(params []interface{}) interface{} {
+	return sbAppend(params[0].(*object.Object), valueOfFloat(params[1:]))
+}
+
+// "java/lang/StringBuilder.append(D)Ljava/lang/StringBuilder;"
+func stringBuilderAppendDouble(params []interface{}) interface{} {
+	return sbAppend(params[0].(*object.Object), valueOfDouble(params[1:]))
+}
+
+// "java/lang/StringBuilder.append(Ljava/lang/Object;)Ljava/lang/StringBuilder;"
+// Also used for append(CharSequence) and append(StringBuffer), which append the argument's
+// toString(). As in Java, appending null appends "null".
+func stringBuilderAppendObject(params []interface{}) interface{} {
+	return sbAppend(params[0].(*object.Object), valueOfObject(params[1:]))
+}
+
+// "java/lang/StringBuilder.append(Ljava/lang/CharSequence;II)Ljava/lang/StringBuilder;"
+// Appends the chars from start up to end of the CharSequence. A null CharSequence is
+// treated as "null".
+func stringBuilderAppendCharSequenceRange(params []interface{}) interface{} {
+	chars, errBlk := sbCharSequenceRange(params[1], params[2].(int64), params[3].(int64))
+	if errBlk != nil {
+		return errBlk
+	}
+	sb := params[0].(*object.Object)
+	sbAppendChars(sb, chars)
+	return sb
+}
+
+// "java/lang/StringBuilder.append([C)Ljava/lang/StringBuilder;"
+func stringBuilderAppendCharArray(params []interface{}) interface{} {
+	chars, errBlk := sbCharArray(params[1], 0, -1)
+	if errBlk != nil {
+		return errBlk
+	}
+	sb := params[0].(*object.Object)
+	sbAppendChars(sb, chars)
+	return sb
+}
+
+// "java/lang/StringBuilder.append([CII)Ljava/lang/StringBuilder;"
+// Appends len chars of the array, starting at offset.
+func stringBuilderAppendCharSubarray(params []interface{}) interface{} {
+	chars, errBlk := sbCharArray(params[1], params[2].(int64), params[3].(int64))
+	if errBlk != nil {
+		return errBlk
+	}
+	sb := params[0].(*object.Object)
+	sbAppendChars(sb, chars)
+	return sb
+}
+
+// "java/lang/StringBuilder.appendCodePoint(I)Ljava/lang/StringBuilder;"
+func stringBuilderAppendCodePoint(params []interface{}) interface{} {
+	codePoint := params[1].(int64)
+	if codePoint < 0 || codePoint > unicode.MaxRune {
+		errMsg := fmt.Sprintf("Not a valid Unicode code point: 0x%X", codePoint)
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+	sb := params[0].(*object.Object)
+	sbAppendChars(sb, sbCodePointChars(rune(codePoint)))
+	return sb
+}
+
+// "java/lang/StringBuilder.capacity()I"
+// The capacity is the number of chars the buffer can hold before it has to grow.
+func stringBuilderCapacity(params []interface{}) interface{} {
+	buffer, _ := params[0].(*object.Object).FieldTable["value"].Fvalue.([]int64)
+	return int64(len(buffer))
+}
+
+// "java/lang/StringBuilder.charAt(I)C"
+func stringBuilderCharAt(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	index := params[1].(int64)
+	if index < 0 || index >= int64(len(chars)) {
+		errMsg := fmt.Sprintf("index %d,length %d", index, len(chars))
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	return chars[index]
+}
+
+// "java/lang/StringBuilder.codePointAt(I)I"
+func stringBuilderCodePointAt(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	index := params[1].(int64)
+	if index < 0 || index >= int64(len(chars)) {
+		errMsg := fmt.Sprintf("index %d,length %d", index, len(chars))
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	if index+1 < int64(len(chars)) {
+		if r := utf16.DecodeRune(rune(chars[index]), rune(chars[index+1])); r != unicode.ReplacementChar {
+			return int64(r)
+		}
+	}
+	return chars[index]
+}
+
+// "java/lang/StringBuilder.codePointBefore(I)I"
+func stringBuilderCodePointBefore(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	index := params[1].(int64)
+	if index < 1 || index > int64(len(chars)) {
+		errMsg := fmt.Sprintf("index %d,length %d", index, len(chars))
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	if index >= 2 {
+		if r := utf16.DecodeRune(rune(chars[index-2]), rune(chars[index-1])); r != unicode.ReplacementChar {
+			return int64(r)
+		}
+	}
+	return chars[index-1]
+}
+
+// "java/lang/StringBuilder.codePointCount(II)I"
+// Counts the code points in the chars from beginIndex up to endIndex. A surrogate pair
+// counts as one code point and an unpaired surrogate as one.
+func stringBuilderCodePointCount(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	begin, end := params[1].(int64), params[2].(int64)
+	if begin < 0 || end > int64(len(chars)) || begin > end {
+		errMsg := fmt.Sprintf("begin %d, end %d, length %d", begin, end, len(chars))
+		return getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
+	}
+	count := int64(0)
+	for i := begin; i < end; i++ {
+		if i+1 < end && utf16.IsSurrogate(rune(chars[i])) &&
+			utf16.DecodeRune(rune(chars[i]), rune(chars[i+1])) != unicode.ReplacementChar {
+			i++
+		}
+		count++
+	}
+	return count
+}
+
+// "java/lang/StringBuilder.compareTo(Ljava/lang/StringBuilder;)I"
+// Compares the contents lexicographically, char by char, as Java does: the result is the
+// difference between the first chars that differ or, if there are none, between the lengths.
+func stringBuilderCompareTo(params []interface{}) interface{} {
+	other, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(other) {
+		errMsg := "stringBuilderCompareTo: argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	chars1 := sbChars(params[0].(*object.Object))
+	chars2 := sbChars(other)
+	for i := 0; i < len(chars1) && i < len(chars2); i++ {
+		if chars1[i] != chars2[i] {
+			return chars1[i] - chars2[i]
+		}
+	}
+	return int64(len(chars1) - len(chars2))
+}
+
+// "java/lang/StringBuilder.delete(II)Ljava/lang/StringBuilder;"
+// Deletes the chars from start up to end. As in Java, an end past the last char
+// deletes to the end of the contents.
+func stringBuilderDelete(params []interface{}) interface{} {
+	sb := params[0].(*object.Object)
+	start, end := params[1].(int64), params[2].(int64)
+	length := int64(len(sbChars(sb)))
+	if end > length {
+		end = length
+	}
+	if start < 0 || start > end {
+		errMsg := fmt.Sprintf("start %d, end %d, length %d", start, end, length)
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	sbSplice(sb, start, end, nil)
+	return sb
+}
+
+// "java/lang/StringBuilder.deleteCharAt(I)Ljava/lang/StringBuilder;"
+func stringBuilderDeleteCharAt(params []interface{}) interface{} {
+	sb := params[0].(*object.Object)
+	length := int64(len(sbChars(sb)))
+	index := params[1].(int64)
+	if index < 0 || index >= length {
+		errMsg := fmt.Sprintf("index %d,length %d", index, length)
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	sbSplice(sb, index, index+1, nil)
+	return sb
+}
+
+// "java/lang/StringBuilder.ensureCapacity(I)V"
+func stringBuilderEnsureCapacity(params []interface{}) interface{} {
+	sbEnsureCapacity(params[0].(*object.Object), params[1].(int64))
+	return nil
+}
+
+// "java/lang/StringBuilder.getChars(II[CI)V"
+// Copies the chars from srcBegin up to srcEnd into the char array, starting at dstBegin.
+func stringBuilderGetChars(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	srcBegin, srcEnd := params[1].(int64), params[2].(int64)
+	if srcBegin < 0 || srcEnd > int64(len(chars)) || srcBegin > srcEnd {
+		errMsg := fmt.Sprintf("begin %d, end %d, length %d", srcBegin, srcEnd, len(chars))
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+
+	dstObj, ok := params[3].(*object.Object)
+	if !ok || object.IsNull(dstObj) {
+		errMsg := "stringBuilderGetChars: destination array is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	dst, _ := dstObj.FieldTable["value"].Fvalue.([]int64)
+	dstBegin := params[4].(int64)
+	if dstBegin < 0 || dstBegin+srcEnd-srcBegin > int64(len(dst)) {
+		errMsg := fmt.Sprintf("begin %d, end %d, length %d", dstBegin, dstBegin+srcEnd-srcBegin, len(dst))
+		return getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
+	}
+	copy(dst[dstBegin:], chars[srcBegin:srcEnd])
+	return nil
+}
+
+// "java/lang/StringBuilder.indexOf(Ljava/lang/String;)I"
+func stringBuilderIndexOf(params []interface{}) interface{} {
+	return stringBuilderIndexOfFrom([]interface{}{params[0], params[1], int64(0)})
+}
+
+// "java/lang/StringBuilder.indexOf(Ljava/lang/String;I)I"
+// Searches from fromIndex on. A negative fromIndex searches from the start.
+func stringBuilderIndexOfFrom(params []interface{}) interface{} {
+	strObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		errMsg := "stringBuilderIndexOfFrom: String argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	chars := sbChars(params[0].(*object.Object))
+	target := object.JavaCharArrayFromGoString(object.GoStringFromStringObject(strObj))
+	from := max(params[2].(int64), 0)
+	for i := from; i+int64(len(target)) <= int64(len(chars)); i++ {
+		if slices.Equal(chars[i:i+int64(len(target))], target) {
+			return i
+		}
+	}
+	return int64(-1)
+}
+
+// "java/lang/StringBuilder.insert(IZ)Ljava/lang/StringBuilder;"
+func stringBuilderInsertBoolean(params []interface{}) interface{} {
+	return sbInsert(params[0].(*object.Object), params[1].(int64), valueOfBoolean(params[2:]))
+}
+
+// "java/lang/StringBuilder.insert(IC)Ljava/lang/StringBuilder;"
+func stringBuilderInsertChar(params []interface{}) interface{} {
+	return sbInsertChars(params[0].(*object.Object), params[1].(int64), []int64{params[2].(int64)})
+}
+
+// "java/lang/StringBuilder.insert(II)Ljava/lang/StringBuilder;"
+func stringBuilderInsertInt(params []interface{}) interface{} {
+	return sbInsert(params[0].(*object.Object), params[1].(int64), valueOfInt(params[2:]))
+}
+
+// "java/lang/StringBuilder.insert(IJ)Ljava/lang/StringBuilder;"
+func stringBuilderInsertLong(params []interface{}) interface{} {
+	return sbInsert(params[0].(*object.Object), params[1].(int64), valueOfLong(params[2:]))
+}
+
+// "java/lang/StringBuilder.insert(IF)Ljava/lang/StringBuilder;"
+func stringBuilderInsertFloat(params []interface{}) interface{} {
+	return sbInsert(params[0].(*object.Object), params[1].(int64), valueOfFloat(params[2:]))
+}
+
+// "java/lang/StringBuilder.insert(ID)Ljava/lang/StringBuilder;"
+func stringBuilderInsertDouble(params []interface{}) interface{} {
+	return sbInsert(params[0].(*object.Object), params[1].(int64), valueOfDouble(params[2:]))
+}
+
+// "java/lang/StringBuilder.insert(ILjava/lang/Object;)Ljava/lang/StringBuilder;"
+// Also used for insert(String) and insert(CharSequence). As in Java, inserting null
+// inserts "null".
+func stringBuilderInsertObject(params []interface{}) interface{} {
+	return sbInsert(params[0].(*object.Object), params[1].(int64), valueOfObject(params[2:]))
+}
+
+// "java/lang/StringBuilder.insert(ILjava/lang/CharSequence;II)Ljava/lang/StringBuilder;"
+// Inserts the chars from start up to end of the CharSequence at dstOffset.
+func stringBuilderInsertCharSequenceRange(params []interface{}) interface{} {
+	chars, errBlk := sbCharSequenceRange(params[2], params[3].(int64), params[4].(int64))
+	if errBlk != nil {
+		return errBlk
+	}
+	return sbInsertChars(params[0].(*object.Object), params[1].(int64), chars)
+}
+
+// "java/lang/StringBuilder.insert(I[C)Ljava/lang/StringBuilder;"
+func stringBuilderInsertCharArray(params []interface{}) interface{} {
+	chars, errBlk := sbCharArray(params[2], 0, -1)
+	if errBlk != nil {
+		return errBlk
+	}
+	return sbInsertChars(params[0].(*object.Object), params[1].(int64), chars)
+}
+
+// "java/lang/StringBuilder.insert(I[CII)Ljava/lang/StringBuilder;"
+// Inserts len chars of the array, starting at offset, at index.
+func stringBuilderInsertCharSubarray(params []interface{}) interface{} {
+	chars, errBlk := sbCharArray(params[2], params[3].(int64), params[4].(int64))
+	if errBlk != nil {
+		return errBlk
+	}
+	return sbInsertChars(params[0].(*object.Object), params[1].(int64), chars)
+}
+
+// "java/lang/StringBuilder.lastIndexOf(Ljava/lang/String;)I"
+func stringBuilderLastIndexOf(params []interface{}) interface{} {
+	length := int64(len(sbChars(params[0].(*object.Object))))
+	return stringBuilderLastIndexOfFrom([]interface{}{params[0], params[1], length})
+}
+
+// "java/lang/StringBuilder.lastIndexOf(Ljava/lang/String;I)I"
+// Searches backward from fromIndex. A fromIndex past the end searches from the end.
+func stringBuilderLastIndexOfFrom(params []interface{}) interface{} {
+	strObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		errMsg := "stringBuilderLastIndexOfFrom: String argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	chars := sbChars(params[0].(*object.Object))
+	target := object.JavaCharArrayFromGoString(object.GoStringFromStringObject(strObj))
+	from := min(params[2].(int64), int64(len(chars)-len(target)))
+	for i := from; i >= 0; i-- {
+		if slices.Equal(chars[i:i+int64(len(target))], target) {
+			return i
+		}
+	}
+	return int64(-1)
+}
+
+// "java/lang/StringBuilder.length()I"
+func stringBuilderLength(params []interface{}) interface{} {
+	return int64(len(sbChars(params[0].(*object.Object))))
+}
+
+// "java/lang/StringBuilder.offsetByCodePoints(II)I"
+// Returns the index that is codePointOffset code points from index, where a surrogate pair
+// counts as one code point. The offset can be negative.
+func stringBuilderOffsetByCodePoints(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	index, offset := params[1].(int64), params[2].(int64)
+	length := int64(len(chars))
+	if index < 0 || index > length {
+		errMsg := fmt.Sprintf("index %d,length %d", index, length)
+		return getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
+	}
+
+	isPair := func(i int64) bool { // is there a surrogate pair at chars[i:i+2]?
+		return i >= 0 && i+1 < length &&
+			utf16.DecodeRune(rune(chars[i]), rune(chars[i+1])) != unicode.ReplacementChar
+	}
+	for ; offset > 0; offset-- {
+		if index >= length {
+			return getGErrBlk(excNames.IndexOutOfBoundsException, "codePointOffset is past the end")
+		}
+		if isPair(index) {
+			index++
+		}
+		index++
+	}
+	for ; offset < 0; offset++ {
+		if index <= 0 {
+			return getGErrBlk(excNames.IndexOutOfBoundsException, "codePointOffset is before the start")
+		}
+		index--
+		if isPair(index - 1) {
+			index--
+		}
+	}
+	return index
+}
+
+// "java/lang/StringBuilder.replace(IILjava/lang/String;)Ljava/lang/StringBuilder;"
+// Replaces the chars from start up to end with the String. As in Java, an end past the
+// last char replaces up to the end of the contents.
+func stringBuilderReplace(params []interface{}) interface{} {
+	strObj, ok := params[3].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		errMsg := "stringBuilderReplace: String argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	sb := params[0].(*object.Object)
+	start, end := params[1].(int64), params[2].(int64)
+	length := int64(len(sbChars(sb)))
+	if start < 0 || start > length || start > end {
+		errMsg := fmt.Sprintf("start %d, end %d, length %d", start, end, length)
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	end = min(end, length)
+	replacement := object.JavaCharArrayFromGoString(object.GoStringFromStringObject(strObj))
+	sbSplice(sb, start, end, replacement)
+	return sb
+}
+
+// "java/lang/StringBuilder.reverse()Ljava/lang/StringBuilder;"
+// The chars are reversed in place. As in Java, a surrogate pair, which that leaves as a low
+// surrogate followed by a high one, is then put back in order, so supplementary characters survive.
+func stringBuilderReverse(params []interface{}) interface{} {
+	sb := params[0].(*object.Object)
+	chars := sbChars(sb)
+	slices.Reverse(chars)
+	for i := 0; i+1 < len(chars); i++ {
+		if utf16.DecodeRune(rune(chars[i+1]), rune(chars[i])) != unicode.ReplacementChar {
+			chars[i], chars[i+1] = chars[i+1], chars[i]
+			i++
+		}
+	}
+	return sb
+}
+
+// "java/lang/StringBuilder.setCharAt(IC)V"
+func stringBuilderSetCharAt(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	index := params[1].(int64)
+	if index < 0 || index >= int64(len(chars)) {
+		errMsg := fmt.Sprintf("index %d,length %d", index, len(chars))
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	chars[index] = params[2].(int64)
+	return nil
+}
+
+// "java/lang/StringBuilder.setLength(I)V"
+// Truncates the contents or, as in Java, pads them with '\u0000' chars to the new length.
+func stringBuilderSetLength(params []interface{}) interface{} {
+	newLength := params[1].(int64)
+	if newLength < 0 {
+		errMsg := fmt.Sprintf("stringBuilderSetLength: length is negative: %d", newLength)
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	sb := params[0].(*object.Object)
+	if length := int64(len(sbChars(sb))); newLength > length {
+		buffer := sbEnsureCapacity(sb, newLength)
+		clear(buffer[length:newLength]) // the buffer past the contents can hold deleted chars
+	}
+	sbSetCount(sb, newLength)
+	return nil
+}
+
+// "java/lang/StringBuilder.substring(I)Ljava/lang/String;"
+func stringBuilderSubstringToTheEnd(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	return sbSubstring(chars, params[1].(int64), int64(len(chars)))
+}
+
+// "java/lang/StringBuilder.substring(II)Ljava/lang/String;"
+// Also used for subSequence(II), as a String is a CharSequence.
+func stringBuilderSubstringStartEnd(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	return sbSubstring(chars, params[1].(int64), params[2].(int64))
+}
+
+// "java/lang/StringBuilder.toString()Ljava/lang/String;"
+func stringBuilderToString(params []interface{}) interface{} {
+	chars := sbChars(params[0].(*object.Object))
+	return object.StringObjectFromGoString(object.GoStringFromJavaCharArray(chars))
+}
+
+// "java/lang/StringBuilder.trimToSize()V"
+func stringBuilderTrimToSize(params []interface{}) interface{} {
+	sb := params[0].(*object.Object)
+	chars := sbChars(sb)
+	sb.FieldTable["value"] = object.Field{Ftype: types.IntArray, Fvalue: append(make([]int64, 0, len(chars)), chars...)}
+	return nil
 }
 
 // "java/lang/StringBuilder.isLatin1()Z"
@@ -23,3 +927,162 @@ func isLatin1([]interface{}) interface{} {
 	// TODO: Someday, jacobin will need to discern between StringLatin1 and StringUTF16.
 	return int64(1)
 }
+
+// sbSubstring returns a new String holding chars[start:end], after checking the bounds as Java does.
+func sbSubstring(chars []int64, start, end int64) interface{} {
+	length := int64(len(chars))
+	if start < 0 || end > length || start > end {
+		errMsg := fmt.Sprintf("start %d, end %d, length %d", start, end, length)
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	return object.StringObjectFromGoString(object.GoStringFromJavaCharArray(chars[start:end]))
+}
+
+// sbAppend appends a String, as returned by one of the String.valueOf() functions, to the
+// StringBuilder and returns the StringBuilder. If valueOf() returned an error, it's returned.
+func sbAppend(sb *object.Object, strObj interface{}) interface{} {
+	str, errBlk := sbStringOf(strObj)
+	if errBlk != nil {
+		return errBlk
+	}
+	sbAppendChars(sb, object.JavaCharArrayFromGoString(str))
+	return sb
+}
+
+// sbInsert inserts a String, as returned by one of the String.valueOf() functions, into the
+// StringBuilder at offset, which is a char index, and returns the StringBuilder. If valueOf()
+// returned an error, it's returned.
+func sbInsert(sb *object.Object, offset int64, strObj interface{}) interface{} {
+	str, errBlk := sbStringOf(strObj)
+	if errBlk != nil {
+		return errBlk
+	}
+	return sbInsertChars(sb, offset, object.JavaCharArrayFromGoString(str))
+}
+
+// sbInsertChars inserts the chars into the StringBuilder at offset, after checking it as
+// Java does, and returns the StringBuilder
+func sbInsertChars(sb *object.Object, offset int64, chars []int64) interface{} {
+	length := int64(len(sbChars(sb)))
+	if offset < 0 || offset > length {
+		errMsg := fmt.Sprintf("offset %d, length %d", offset, length)
+		return getGErrBlk(excNames.StringIndexOutOfBoundsException, errMsg)
+	}
+	sbSplice(sb, offset, offset, chars)
+	return sb
+}
+
+// sbStringOf returns the Go string held by a String object returned by a function such as
+// valueOfObject(), or the error block the function returned instead
+func sbStringOf(strObj interface{}) (string, interface{}) {
+	obj, ok := strObj.(*object.Object)
+	if !ok {
+		if errBlk, isErr := strObj.(*GErrBlk); isErr {
+			return "", errBlk
+		}
+		return "", getGErrBlk(excNames.IllegalArgumentException, "expected a String")
+	}
+	return object.GoStringFromStringObject(obj), nil
+}
+
+// sbCharSequenceRange returns the chars from start up to end of the CharSequence seq, after
+// checking the bounds as Java does. A null seq is treated as "null".
+func sbCharSequenceRange(seq interface{}, start, end int64) ([]int64, interface{}) {
+	str, errBlk := sbStringOf(valueOfObject([]interface{}{seq}))
+	if errBlk != nil {
+		return nil, errBlk
+	}
+	chars := object.JavaCharArrayFromGoString(str)
+	if start < 0 || start > end || end > int64(len(chars)) {
+		errMsg := fmt.Sprintf("start %d, end %d, length %d", start, end, len(chars))
+		return nil, getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
+	}
+	return chars[start:end], nil
+}
+
+// sbCharArray returns length chars of a char array object, starting at offset, after
+// checking the bounds as Java does. A length of -1 returns all the chars from offset on.
+func sbCharArray(arrayRef interface{}, offset, length int64) ([]int64, interface{}) {
+	arrayObj, ok := arrayRef.(*object.Object)
+	if !ok || object.IsNull(arrayObj) {
+		return nil, getGErrBlk(excNames.NullPointerException, "char array is null")
+	}
+	chars, _ := arrayObj.FieldTable["value"].Fvalue.([]int64)
+	if length == -1 {
+		length = int64(len(chars)) - offset
+	}
+	if offset < 0 || length < 0 || offset+length > int64(len(chars)) {
+		errMsg := fmt.Sprintf("offset %d, count %d, length %d", offset, length, len(chars))
+		return nil, getGErrBlk(excNames.IndexOutOfBoundsException, errMsg)
+	}
+	return chars[offset : offset+length], nil
+}
+
+// sbCodePointChars returns the UTF-16 chars of a code point: a surrogate pair for a
+// supplementary character, otherwise a single char
+func sbCodePointChars(codePoint rune) []int64 {
+	if r1, r2 := utf16.EncodeRune(codePoint); r1 != unicode.ReplacementChar {
+		return []int64{int64(r1), int64(r2)}
+	}
+	return []int64{int64(codePoint)}
+}
+
+// sbChars returns the contents of the StringBuilder, which are the first count chars of its
+// buffer. They're valid until the StringBuilder is next changed.
+func sbChars(sb *object.Object) []int64 {
+	buffer, _ := sb.FieldTable["value"].Fvalue.([]int64)
+	count, _ := sb.FieldTable["count"].Fvalue.(int64)
+	return buffer[:count]
+}
+
+// sbSetCount sets the number of chars of the StringBuilder's buffer that are its contents
+func sbSetCount(sb *object.Object, count int64) {
+	sb.FieldTable["count"] = object.Field{Ftype: types.Int, Fvalue: count}
+}
+
+// sbAppendChars appends the chars to the StringBuilder
+func sbAppendChars(sb *object.Object, chars []int64) {
+	length := int64(len(sbChars(sb)))
+	sbSplice(sb, length, length, chars)
+}
+
+// sbSplice replaces the chars of the StringBuilder from start up to end, which the caller
+// has checked, with insert. The chars after them are moved within the buffer, which grows
+// if it has to.
+func sbSplice(sb *object.Object, start, end int64, insert []int64) {
+	length := int64(len(sbChars(sb)))
+	newLength := length - (end - start) + int64(len(insert))
+	buffer := sbEnsureCapacity(sb, newLength)
+	copy(buffer[start+int64(len(insert)):], buffer[end:length])
+	copy(buffer[start:], insert)
+	sbSetCount(sb, newLength)
+}
+
+// sbInitBuffer sets up the buffer of a new StringBuilder, which holds capacity chars, with
+// the chars as its contents
+func sbInitBuffer(sb *object.Object, chars []int64, capacity int64) {
+	buffer := make([]int64, capacity)
+	copy(buffer, chars)
+	sb.FieldTable["value"] = object.Field{Ftype: types.IntArray, Fvalue: buffer}
+	sbSetCount(sb, int64(len(chars)))
+}
+
+// sbInitContents sets the initial contents of a new StringBuilder. As in Java, the initial
+// capacity leaves room for 16 more chars.
+func sbInitContents(sb *object.Object, chars []int64) {
+	sbInitBuffer(sb, chars, int64(len(chars))+16)
+}
+
+// sbEnsureCapacity makes the StringBuilder's buffer hold at least minimum chars and returns
+// the buffer. As in Java, a buffer that grows at least doubles (plus 2), so that appending
+// char by char doesn't reallocate the buffer each time.
+func sbEnsureCapacity(sb *object.Object, minimum int64) []int64 {
+	buffer, _ := sb.FieldTable["value"].Fvalue.([]int64)
+	if minimum <= int64(len(buffer)) {
+		return buffer
+	}
+	grown := make([]int64, max(2*int64(len(buffer))+2, minimum))
+	copy(grown, buffer)
+	sb.FieldTable["value"] = object.Field{Ftype: types.IntArray, Fvalue: grown}
+	return grown
+}
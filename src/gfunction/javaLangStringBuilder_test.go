@@ -0,0 +1,615 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"sync"
+	"testing"
+	"time"
+)
+
+// makeStringBuilder returns a StringBuilder holding str, built via <init>() and append()
+func makeStringBuilder(str string) *object.Object {
+	className := "java/lang/StringBuilder"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	stringBuilderInit([]interface{}{sb})
+	stringBuilderAppendString([]interface{}{sb, object.StringObjectFromGoString(str)})
+	return sb
+}
+
+func TestStringBuilderIndexOf(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("key=value;key2=value2")
+	tests := []struct {
+		target   string
+		expected int64
+	}{
+		{"key", 0},
+		{"=", 3},
+		{";", 9},
+		{"value2", 15},
+		{"", 0},
+		{"missing", -1},
+	}
+
+	for _, tt := range tests {
+		ret := stringBuilderIndexOf([]interface{}{sb, object.StringObjectFromGoString(tt.target)})
+		if ret != tt.expected {
+			t.Errorf("indexOf(%q): expected %d, got %v", tt.target, tt.expected, ret)
+		}
+	}
+}
+
+// indexes count Java chars, not the bytes of the UTF-8 encoding
+func TestStringBuilderIndexOfNonASCII(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("prix: 5€ | total")
+	ret := stringBuilderIndexOf([]interface{}{sb, object.StringObjectFromGoString("|")})
+	if ret != int64(9) {
+		t.Errorf("indexOf(\"|\"): expected 9, got %v", ret)
+	}
+}
+
+func TestStringBuilderIndexOfNull(t *testing.T) {
+	globals.InitGlobals("test")
+
+	ret := stringBuilderIndexOf([]interface{}{makeStringBuilder("abc"), object.Null})
+	gErr, ok := ret.(*GErrBlk)
+	if !ok || gErr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("indexOf(null): expected NullPointerException, got %v", ret)
+	}
+}
+
+func TestStringBuilderSubstring(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("hello, world")
+	tests := []struct {
+		params   []interface{}
+		fn       func([]interface{}) interface{}
+		expected string
+	}{
+		{[]interface{}{sb, int64(7)}, stringBuilderSubstringToTheEnd, "world"},
+		{[]interface{}{sb, int64(0)}, stringBuilderSubstringToTheEnd, "hello, world"},
+		{[]interface{}{sb, int64(12)}, stringBuilderSubstringToTheEnd, ""},
+		{[]interface{}{sb, int64(0), int64(5)}, stringBuilderSubstringStartEnd, "hello"},
+		{[]interface{}{sb, int64(5), int64(5)}, stringBuilderSubstringStartEnd, ""},
+		{[]interface{}{sb, int64(0), int64(12)}, stringBuilderSubstringStartEnd, "hello, world"},
+	}
+
+	for _, tt := range tests {
+		ret := tt.fn(tt.params)
+		strObj, ok := ret.(*object.Object)
+		if !ok {
+			t.Errorf("substring%v: expected a String, got %v", tt.params[1:], ret)
+			continue
+		}
+		if got := object.GoStringFromStringObject(strObj); got != tt.expected {
+			t.Errorf("substring%v: expected %q, got %q", tt.params[1:], tt.expected, got)
+		}
+	}
+}
+
+func TestStringBuilderSubstringBadBounds(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("hello")
+	tests := [][]interface{}{
+		{sb, int64(-1), int64(2)},
+		{sb, int64(0), int64(6)},
+		{sb, int64(3), int64(2)},
+		{sb, int64(6)},
+	}
+
+	for _, params := range tests {
+		var ret interface{}
+		if len(params) == 2 {
+			ret = stringBuilderSubstringToTheEnd(params)
+		} else {
+			ret = stringBuilderSubstringStartEnd(params)
+		}
+		gErr, ok := ret.(*GErrBlk)
+		if !ok || gErr.ExceptionType != excNames.StringIndexOutOfBoundsException {
+			t.Errorf("substring%v: expected StringIndexOutOfBoundsException, got %v", params[1:], ret)
+		}
+	}
+}
+
+func TestStringBuilderAppendAndToString(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("abc")
+	if ret := stringBuilderAppendString([]interface{}{sb, object.Null}); ret != sb {
+		t.Errorf("append: expected the StringBuilder itself to be returned")
+	}
+
+	str := object.GoStringFromStringObject(stringBuilderToString([]interface{}{sb}).(*object.Object))
+	if str != "abcnull" {
+		t.Errorf("toString: expected \"abcnull\", got %q", str)
+	}
+	if length := stringBuilderLength([]interface{}{sb}); length != int64(7) {
+		t.Errorf("length: expected 7, got %v", length)
+	}
+}
+
+func TestStringBuilderInitFromString(t *testing.T) {
+	globals.InitGlobals("test")
+
+	className := "java/lang/StringBuilder"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	if ret := stringBuilderInitString([]interface{}{sb, object.StringObjectFromGoString("abc")}); ret != nil {
+		t.Fatalf("<init>(\"abc\"): unexpected error: %v", ret)
+	}
+	stringBuilderAppendString([]interface{}{sb, object.StringObjectFromGoString("d")})
+
+	str := object.GoStringFromStringObject(stringBuilderToString([]interface{}{sb}).(*object.Object))
+	if str != "abcd" {
+		t.Errorf("expected \"abcd\", got %q", str)
+	}
+	if length := stringBuilderLength([]interface{}{sb}); length != int64(4) {
+		t.Errorf("length(): expected 4, got %v", length)
+	}
+}
+
+func TestStringBuilderInitFromNullString(t *testing.T) {
+	globals.InitGlobals("test")
+
+	className := "java/lang/StringBuilder"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	ret := stringBuilderInitString([]interface{}{sb, object.Null})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("<init>(null): expected NullPointerException, got %v", ret)
+	}
+}
+
+func TestStringBuilderInitWithCapacity(t *testing.T) {
+	globals.InitGlobals("test")
+
+	className := "java/lang/StringBuilder"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	if ret := stringBuilderInitCapacity([]interface{}{sb, int64(32)}); ret != nil {
+		t.Fatalf("<init>(32): unexpected error: %v", ret)
+	}
+	if length := stringBuilderLength([]interface{}{sb}); length != int64(0) {
+		t.Errorf("length(): expected an empty builder, got length %v", length)
+	}
+	stringBuilderAppendString([]interface{}{sb, object.StringObjectFromGoString("abc")})
+	str := object.GoStringFromStringObject(stringBuilderToString([]interface{}{sb}).(*object.Object))
+	if str != "abc" {
+		t.Errorf("expected \"abc\", got %q", str)
+	}
+
+	ret := stringBuilderInitCapacity([]interface{}{sb, int64(-1)})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NegativeArraySizeException {
+		t.Errorf("<init>(-1): expected NegativeArraySizeException, got %v", ret)
+	}
+}
+
+// sbString returns the contents of the StringBuilder, via its toString()
+func sbString(sb *object.Object) string {
+	return object.GoStringFromStringObject(stringBuilderToString([]interface{}{sb}).(*object.Object))
+}
+
+// The JDK's bytecode for StringBuilder can't run against a "value" field holding a Go byte array,
+// so every public method has to be registered, either as a G function or as a trap.
+func TestStringBuilderPublicMethodsAreRegistered(t *testing.T) {
+	globals.InitGlobals("test")
+	MethodSignatures = make(map[string]GMeth)
+	Load_Lang_StringBuilder()
+	Load_Traps()
+
+	methods := []string{
+		"<init>()V", "<init>(I)V", "<init>(Ljava/lang/String;)V", "<init>(Ljava/lang/CharSequence;)V",
+		"append(Z)Ljava/lang/StringBuilder;", "append(C)Ljava/lang/StringBuilder;",
+		"append(I)Ljava/lang/StringBuilder;", "append(J)Ljava/lang/StringBuilder;",
+		"append(F)Ljava/lang/StringBuilder;", "append(D)Ljava/lang/StringBuilder;",
+		"append([C)Ljava/lang/StringBuilder;", "append([CII)Ljava/lang/StringBuilder;",
+		"append(Ljava/lang/Object;)Ljava/lang/StringBuilder;", "append(Ljava/lang/String;)Ljava/lang/StringBuilder;",
+		"append(Ljava/lang/StringBuffer;)Ljava/lang/StringBuilder;",
+		"append(Ljava/lang/CharSequence;)Ljava/lang/StringBuilder;",
+		"append(Ljava/lang/CharSequence;II)Ljava/lang/StringBuilder;",
+		"appendCodePoint(I)Ljava/lang/StringBuilder;", "capacity()I", "charAt(I)C", "chars()Ljava/util/stream/IntStream;",
+		"codePointAt(I)I", "codePointBefore(I)I", "codePointCount(II)I", "codePoints()Ljava/util/stream/IntStream;",
+		"compareTo(Ljava/lang/StringBuilder;)I", "delete(II)Ljava/lang/StringBuilder;",
+		"deleteCharAt(I)Ljava/lang/StringBuilder;", "ensureCapacity(I)V", "getChars(II[CI)V",
+		"indexOf(Ljava/lang/String;)I", "indexOf(Ljava/lang/String;I)I",
+		"insert(IZ)Ljava/lang/StringBuilder;", "insert(IC)Ljava/lang/StringBuilder;",
+		"insert(II)Ljava/lang/StringBuilder;", "insert(IJ)Ljava/lang/StringBuilder;",
+		"insert(IF)Ljava/lang/StringBuilder;", "insert(ID)Ljava/lang/StringBuilder;",
+		"insert(I[C)Ljava/lang/StringBuilder;", "insert(I[CII)Ljava/lang/StringBuilder;",
+		"insert(ILjava/lang/Object;)Ljava/lang/StringBuilder;", "insert(ILjava/lang/String;)Ljava/lang/StringBuilder;",
+		"insert(ILjava/lang/CharSequence;)Ljava/lang/StringBuilder;",
+		"insert(ILjava/lang/CharSequence;II)Ljava/lang/StringBuilder;",
+		"lastIndexOf(Ljava/lang/String;)I", "lastIndexOf(Ljava/lang/String;I)I", "length()I",
+		"offsetByCodePoints(II)I", "replace(IILjava/lang/String;)Ljava/lang/StringBuilder;",
+		"reverse()Ljava/lang/StringBuilder;", "setCharAt(IC)V", "setLength(I)V",
+		"subSequence(II)Ljava/lang/CharSequence;", "substring(I)Ljava/lang/String;",
+		"substring(II)Ljava/lang/String;", "toString()Ljava/lang/String;", "trimToSize()V",
+	}
+	for _, method := range methods {
+		if _, ok := MethodSignatures["java/lang/StringBuilder."+method]; !ok {
+			t.Errorf("StringBuilder.%s is not registered", method)
+		}
+	}
+}
+
+func TestStringBuilderAppendPrimitives(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("")
+	stringBuilderAppendInt([]interface{}{sb, int64(-42)})
+	stringBuilderAppendChar([]interface{}{sb, int64(' ')})
+	stringBuilderAppendLong([]interface{}{sb, int64(1) << 40, int64(1) << 40})
+	stringBuilderAppendBoolean([]interface{}{sb, int64(1)})
+	stringBuilderAppendFloat([]interface{}{sb, 1.5})
+	stringBuilderAppendDouble([]interface{}{sb, 0.25, 0.25})
+	stringBuilderAppendObject([]interface{}{sb, object.Null})
+	stringBuilderAppendObject([]interface{}{sb, makeStringBuilder("!")})
+	stringBuilderAppendCodePoint([]interface{}{sb, int64(0x1F600)})
+
+	expected := "-42 1099511627776true1.50.25null!\U0001F600"
+	if got := sbString(sb); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if length := stringBuilderLength([]interface{}{sb}); length != int64(35) {
+		t.Errorf("length(): expected 35, got %v", length)
+	}
+}
+
+func TestStringBuilderAppendCharArrays(t *testing.T) {
+	globals.InitGlobals("test")
+
+	chars := object.Make1DimArray(object.INT, 5)
+	chars.FieldTable["value"] = object.Field{Ftype: chars.FieldTable["value"].Ftype,
+		Fvalue: object.JavaCharArrayFromGoString("hello")}
+
+	sb := makeStringBuilder("")
+	stringBuilderAppendCharArray([]interface{}{sb, chars})
+	stringBuilderAppendCharSubarray([]interface{}{sb, chars, int64(1), int64(3)})
+	stringBuilderAppendCharSequenceRange([]interface{}{sb, object.StringObjectFromGoString("world"), int64(0), int64(2)})
+	if got := sbString(sb); got != "helloellwo" {
+		t.Errorf("expected \"helloellwo\", got %q", got)
+	}
+
+	ret := stringBuilderAppendCharSubarray([]interface{}{sb, chars, int64(3), int64(3)})
+	if gErr, ok := ret.(*GErrBlk); !ok || gErr.ExceptionType != excNames.IndexOutOfBoundsException {
+		t.Errorf("append(chars, 3, 3): expected IndexOutOfBoundsException, got %v", ret)
+	}
+}
+
+func TestStringBuilderInsert(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("ac")
+	stringBuilderInsertChar([]interface{}{sb, int64(1), int64('b')})
+	stringBuilderInsertInt([]interface{}{sb, int64(0), int64(12)})
+	stringBuilderInsertObject([]interface{}{sb, int64(5), object.StringObjectFromGoString("-end")})
+	stringBuilderInsertDouble([]interface{}{sb, int64(2), 2.5, 2.5})
+	if got := sbString(sb); got != "122.5abc-end" {
+		t.Errorf("expected \"122.5abc-end\", got %q", got)
+	}
+
+	for _, offset := range []int64{-1, 13} {
+		ret := stringBuilderInsertChar([]interface{}{sb, offset, int64('x')})
+		if gErr, ok := ret.(*GErrBlk); !ok || gErr.ExceptionType != excNames.StringIndexOutOfBoundsException {
+			t.Errorf("insert(%d, 'x'): expected StringIndexOutOfBoundsException, got %v", offset, ret)
+		}
+	}
+}
+
+func TestStringBuilderEditing(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("hello, world")
+	stringBuilderSetCharAt([]interface{}{sb, int64(0), int64('H')})
+	stringBuilderDeleteCharAt([]interface{}{sb, int64(5)})
+	stringBuilderReplace([]interface{}{sb, int64(6), int64(100), object.StringObjectFromGoString("there")})
+	if got := sbString(sb); got != "Hello there" {
+		t.Errorf("expected \"Hello there\", got %q", got)
+	}
+
+	stringBuilderDelete([]interface{}{sb, int64(5), int64(100)})
+	stringBuilderReverse([]interface{}{sb})
+	if got := sbString(sb); got != "olleH" {
+		t.Errorf("expected \"olleH\", got %q", got)
+	}
+
+	stringBuilderSetLength([]interface{}{sb, int64(2)})
+	stringBuilderSetLength([]interface{}{sb, int64(4)})
+	if got := sbString(sb); got != "ol\x00\x00" {
+		t.Errorf("setLength(): expected \"ol\\x00\\x00\", got %q", got)
+	}
+
+	ret := stringBuilderDelete([]interface{}{sb, int64(3), int64(2)})
+	if gErr, ok := ret.(*GErrBlk); !ok || gErr.ExceptionType != excNames.StringIndexOutOfBoundsException {
+		t.Errorf("delete(3, 2): expected StringIndexOutOfBoundsException, got %v", ret)
+	}
+	ret = stringBuilderSetLength([]interface{}{sb, int64(-1)})
+	if gErr, ok := ret.(*GErrBlk); !ok || gErr.ExceptionType != excNames.StringIndexOutOfBoundsException {
+		t.Errorf("setLength(-1): expected StringIndexOutOfBoundsException, got %v", ret)
+	}
+}
+
+func TestStringBuilderCharsAndCodePoints(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("a\U0001F600b")
+	if ch := stringBuilderCharAt([]interface{}{sb, int64(3)}); ch != int64('b') {
+		t.Errorf("charAt(3): expected 'b', got %v", ch)
+	}
+	if cp := stringBuilderCodePointAt([]interface{}{sb, int64(1)}); cp != int64(0x1F600) {
+		t.Errorf("codePointAt(1): expected 0x1F600, got %v", cp)
+	}
+	if cp := stringBuilderCodePointBefore([]interface{}{sb, int64(3)}); cp != int64(0x1F600) {
+		t.Errorf("codePointBefore(3): expected 0x1F600, got %v", cp)
+	}
+	if count := stringBuilderCodePointCount([]interface{}{sb, int64(0), int64(4)}); count != int64(3) {
+		t.Errorf("codePointCount(0, 4): expected 3, got %v", count)
+	}
+	if index := stringBuilderOffsetByCodePoints([]interface{}{sb, int64(0), int64(2)}); index != int64(3) {
+		t.Errorf("offsetByCodePoints(0, 2): expected 3, got %v", index)
+	}
+	if index := stringBuilderOffsetByCodePoints([]interface{}{sb, int64(3), int64(-1)}); index != int64(1) {
+		t.Errorf("offsetByCodePoints(3, -1): expected 1, got %v", index)
+	}
+
+	stringBuilderReverse([]interface{}{sb})
+	if got := sbString(sb); got != "b\U0001F600a" {
+		t.Errorf("reverse(): expected the surrogate pair to be kept, got %q", got)
+	}
+
+	ret := stringBuilderCharAt([]interface{}{sb, int64(4)})
+	if gErr, ok := ret.(*GErrBlk); !ok || gErr.ExceptionType != excNames.StringIndexOutOfBoundsException {
+		t.Errorf("charAt(4): expected StringIndexOutOfBoundsException, got %v", ret)
+	}
+}
+
+// A surrogate pair appended, inserted or set one char at a time is kept in the buffer as
+// the two chars, which make up a single supplementary character in the String.
+func TestStringBuilderSurrogatePairCharByChar(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("")
+	stringBuilderAppendChar([]interface{}{sb, int64(0xD83D)})
+	if ch := stringBuilderCharAt([]interface{}{sb, int64(0)}); ch != int64(0xD83D) {
+		t.Errorf("charAt(0) of an unpaired high surrogate: expected 0xD83D, got %v", ch)
+	}
+	stringBuilderAppendChar([]interface{}{sb, int64(0xDE00)})
+	if length := stringBuilderLength([]interface{}{sb}); length != int64(2) {
+		t.Errorf("length(): expected 2, got %v", length)
+	}
+	if got := sbString(sb); got != "\U0001F600" {
+		t.Errorf("toString(): expected %q, got %q", "\U0001F600", got)
+	}
+
+	// copy "a\U0001F600b" char by char, back to front, into another builder
+	src := makeStringBuilder("a\U0001F600b")
+	dst := makeStringBuilder("")
+	for i := stringBuilderLength([]interface{}{src}).(int64) - 1; i >= 0; i-- {
+		ch := stringBuilderCharAt([]interface{}{src, i})
+		stringBuilderInsertChar([]interface{}{dst, int64(0), ch})
+	}
+	if got := sbString(dst); got != "a\U0001F600b" {
+		t.Errorf("insert(0, char) of each char: expected %q, got %q", "a\U0001F600b", got)
+	}
+
+	stringBuilderSetCharAt([]interface{}{dst, int64(0), int64(0xD83D)})
+	stringBuilderSetCharAt([]interface{}{dst, int64(1), int64(0xDE00)})
+	stringBuilderSetCharAt([]interface{}{dst, int64(2), int64('x')})
+	if got := sbString(dst); got != "\U0001F600xb" {
+		t.Errorf("setCharAt(): expected %q, got %q", "\U0001F600xb", got)
+	}
+}
+
+func TestStringBuilderSearchAndCompare(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("abcabc")
+	abc := object.StringObjectFromGoString("abc")
+	if index := stringBuilderIndexOfFrom([]interface{}{sb, abc, int64(1)}); index != int64(3) {
+		t.Errorf("indexOf(\"abc\", 1): expected 3, got %v", index)
+	}
+	if index := stringBuilderLastIndexOf([]interface{}{sb, abc}); index != int64(3) {
+		t.Errorf("lastIndexOf(\"abc\"): expected 3, got %v", index)
+	}
+	if index := stringBuilderLastIndexOfFrom([]interface{}{sb, abc, int64(2)}); index != int64(0) {
+		t.Errorf("lastIndexOf(\"abc\", 2): expected 0, got %v", index)
+	}
+
+	tests := []struct {
+		other    string
+		expected int64
+	}{
+		{"abcabc", 0},
+		{"abd", -1},
+		{"abc", 3},
+		{"abcabcd", -1},
+	}
+	for _, tt := range tests {
+		ret := stringBuilderCompareTo([]interface{}{sb, makeStringBuilder(tt.other)})
+		if ret != tt.expected {
+			t.Errorf("compareTo(%q): expected %d, got %v", tt.other, tt.expected, ret)
+		}
+	}
+}
+
+func TestStringBuilderCapacity(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sb := makeStringBuilder("")
+	if capacity := stringBuilderCapacity([]interface{}{sb}); capacity != int64(16) {
+		t.Errorf("capacity(): expected 16, got %v", capacity)
+	}
+	stringBuilderAppendString([]interface{}{sb, object.StringObjectFromGoString("0123456789abcdefg")})
+	if capacity := stringBuilderCapacity([]interface{}{sb}); capacity != int64(34) {
+		t.Errorf("capacity() after growing: expected 34, got %v", capacity)
+	}
+	stringBuilderEnsureCapacity([]interface{}{sb, int64(100)})
+	if capacity := stringBuilderCapacity([]interface{}{sb}); capacity != int64(100) {
+		t.Errorf("capacity() after ensureCapacity(100): expected 100, got %v", capacity)
+	}
+	stringBuilderTrimToSize([]interface{}{sb})
+	if capacity := stringBuilderCapacity([]interface{}{sb}); capacity != int64(17) {
+		t.Errorf("capacity() after trimToSize(): expected 17, got %v", capacity)
+	}
+	if got := sbString(sb); got != "0123456789abcdefg" {
+		t.Errorf("expected the contents to be kept, got %q", got)
+	}
+}
+
+func TestStringBuilderGetChars(t *testing.T) {
+	globals.InitGlobals("test")
+
+	dst := object.Make1DimArray(object.INT, 5)
+	sb := makeStringBuilder("hello")
+	if ret := stringBuilderGetChars([]interface{}{sb, int64(1), int64(4), dst, int64(2)}); ret != nil {
+		t.Fatalf("getChars(1, 4, dst, 2): unexpected error: %v", ret)
+	}
+	chars := dst.FieldTable["value"].Fvalue.([]int64)
+	if got := object.GoStringFromJavaCharArray(chars[2:]); got != "ell" {
+		t.Errorf("getChars(1, 4, dst, 2): expected \"ell\", got %q", got)
+	}
+
+	ret := stringBuilderGetChars([]interface{}{sb, int64(0), int64(4), dst, int64(2)})
+	if gErr, ok := ret.(*GErrBlk); !ok || gErr.ExceptionType != excNames.IndexOutOfBoundsException {
+		t.Errorf("getChars(0, 4, dst, 2): expected IndexOutOfBoundsException, got %v", ret)
+	}
+}
+
+// StringBuffer has the same API as StringBuilder and is implemented by the same functions
+func TestStringBufferAppendAndToString(t *testing.T) {
+	globals.InitGlobals("test")
+	MethodSignatures = make(map[string]GMeth)
+	Load_Lang_StringBuffer()
+	call := func(sig string, params ...interface{}) interface{} {
+		gm, ok := MethodSignatures["java/lang/StringBuffer."+sig]
+		if !ok {
+			t.Fatalf("StringBuffer.%s is not registered", sig)
+		}
+		if len(params)-1 != gm.ParamSlots {
+			t.Fatalf("StringBuffer.%s: expected %d param slots, got %d", sig, len(params)-1, gm.ParamSlots)
+		}
+		return gm.GFunction(params)
+	}
+
+	className := "java/lang/StringBuffer"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	call("<init>(Ljava/lang/String;)V", sb, object.StringObjectFromGoString("one"))
+	for _, str := range []string{", two", ", three"} {
+		ret := call("append(Ljava/lang/String;)Ljava/lang/StringBuffer;", sb, object.StringObjectFromGoString(str))
+		if ret != sb {
+			t.Errorf("append(%q): expected the StringBuffer to be returned, got %v", str, ret)
+		}
+	}
+	call("append(Ljava/lang/String;)Ljava/lang/StringBuffer;", sb, object.Null)
+
+	str := call("toString()Ljava/lang/String;", sb).(*object.Object)
+	if got := object.GoStringFromStringObject(str); got != "one, two, threenull" {
+		t.Errorf("toString(): expected \"one, two, threenull\", got %q", got)
+	}
+	if got := call("length()I", sb); got != int64(19) {
+		t.Errorf("length(): expected 19, got %v", got)
+	}
+
+	empty := object.MakeEmptyObjectWithClassName(&className)
+	call("<init>()V", empty)
+	if got := object.GoStringFromStringObject(call("toString()Ljava/lang/String;", empty).(*object.Object)); got != "" {
+		t.Errorf("new StringBuffer().toString(): expected an empty string, got %q", got)
+	}
+}
+
+// StringBuffer's methods are synchronized, so appends from several threads are all kept
+func TestStringBufferConcurrentAppends(t *testing.T) {
+	globals.InitGlobals("test")
+	MethodSignatures = make(map[string]GMeth)
+	Load_Lang_StringBuffer()
+	appendString := MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/String;)Ljava/lang/StringBuffer;"].GFunction
+	length := MethodSignatures["java/lang/StringBuffer.length()I"].GFunction
+
+	className := "java/lang/StringBuffer"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	stringBuilderInit([]interface{}{sb})
+	x := object.StringObjectFromGoString("x")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				appendString([]interface{}{sb, x})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := length([]interface{}{sb}); got != int64(1600) {
+		t.Errorf("length() after 1600 appends from 8 threads: expected 1600, got %v", got)
+	}
+}
+
+// As in Java, a StringBuffer can be appended to itself: its toString() isn't called under its lock
+func TestStringBufferAppendItself(t *testing.T) {
+	globals.InitGlobals("test")
+	MethodSignatures = make(map[string]GMeth)
+	Load_Lang_StringBuffer()
+
+	className := "java/lang/StringBuffer"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	stringBuilderInitString([]interface{}{sb, object.StringObjectFromGoString("ab")})
+
+	done := make(chan interface{})
+	go func() {
+		appendObject := MethodSignatures["java/lang/StringBuffer.append(Ljava/lang/Object;)Ljava/lang/StringBuffer;"].GFunction
+		done <- appendObject([]interface{}{sb, sb})
+	}()
+	select {
+	case ret := <-done:
+		if ret != sb {
+			t.Fatalf("append(itself): expected the StringBuffer to be returned, got %v", ret)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("append(itself): deadlocked")
+	}
+	if got := sbString(sb); got != "abab" {
+		t.Errorf("append(itself): expected \"abab\", got %q", got)
+	}
+}
+
+// StringBuffer keeps a surrogate pair appended one char at a time, as StringBuilder does
+func TestStringBufferSurrogatePairCharByChar(t *testing.T) {
+	globals.InitGlobals("test")
+	MethodSignatures = make(map[string]GMeth)
+	Load_Lang_StringBuffer()
+	appendChar := MethodSignatures["java/lang/StringBuffer.append(C)Ljava/lang/StringBuffer;"].GFunction
+	length := MethodSignatures["java/lang/StringBuffer.length()I"].GFunction
+	reverse := MethodSignatures["java/lang/StringBuffer.reverse()Ljava/lang/StringBuffer;"].GFunction
+	toString := MethodSignatures["java/lang/StringBuffer.toString()Ljava/lang/String;"].GFunction
+
+	className := "java/lang/StringBuffer"
+	sb := object.MakeEmptyObjectWithClassName(&className)
+	stringBuilderInit([]interface{}{sb})
+	for _, ch := range []int64{0xD83D, 0xDE00, 'a'} {
+		appendChar([]interface{}{sb, ch})
+	}
+	if got := length([]interface{}{sb}); got != int64(3) {
+		t.Errorf("length(): expected 3, got %v", got)
+	}
+	if got := object.GoStringFromStringObject(toString([]interface{}{sb}).(*object.Object)); got != "\U0001F600a" {
+		t.Errorf("toString(): expected %q, got %q", "\U0001F600a", got)
+	}
+
+	reverse([]interface{}{sb})
+	if got := object.GoStringFromStringObject(toString([]interface{}{sb}).(*object.Object)); got != "a\U0001F600" {
+		t.Errorf("reverse(): expected %q, got %q", "a\U0001F600", got)
+	}
+}
@@ -7,11 +7,14 @@
 package gfunction
 
 import (
+	"fmt"
 	"jacobin/classloader"
 	"jacobin/excNames"
 	"jacobin/globals"
 	"jacobin/object"
 	"jacobin/types"
+	"math"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -224,3 +227,414 @@ func TestSprintf_3(t *testing.T) {
 		t.Errorf("TestSprintf_2: result type %T makes no sense", result)
 	}
 }
+
+// trim() removes only chars <= U+0020, while strip() removes Unicode white space as
+// defined by Character.isWhitespace(), which excludes the non-breaking space U+00A0
+func TestTrimVersusStrip(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		input, trim, strip, stripLeading, stripTrailing string
+	}{
+		{" \tabc\n ", "abc", "abc", "abc\n ", " \tabc"},
+		{"\u00A0abc\u00A0", "\u00A0abc\u00A0", "\u00A0abc\u00A0", "\u00A0abc\u00A0", "\u00A0abc\u00A0"},
+		{" \u00A0abc\u00A0 ", "\u00A0abc\u00A0", "\u00A0abc\u00A0", "\u00A0abc\u00A0 ", " \u00A0abc\u00A0"},
+		{"\u2003abc\u3000", "\u2003abc\u3000", "abc", "abc\u3000", "\u2003abc"},
+		{"\u0000abc\u0001", "abc", "\u0000abc\u0001", "\u0000abc\u0001", "\u0000abc\u0001"},
+		{"    ", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		funcs := []struct {
+			name     string
+			fn       func([]interface{}) interface{}
+			expected string
+		}{
+			{"trim", trimString, tt.trim},
+			{"strip", stripString, tt.strip},
+			{"stripLeading", stripLeading, tt.stripLeading},
+			{"stripTrailing", stripTrailing, tt.stripTrailing},
+		}
+		for _, f := range funcs {
+			ret := f.fn([]interface{}{object.StringObjectFromGoString(tt.input)})
+			got := object.GoStringFromStringObject(ret.(*object.Object))
+			if got != f.expected {
+				t.Errorf("%s(%q): expected %q, got %q", f.name, tt.input, f.expected, got)
+			}
+		}
+	}
+}
+
+// as in Java, trim() and strip() return the same object when there is nothing to remove,
+// here because neither treats the non-breaking space as white space
+func TestTrimAndStripReturnSameObjectWhenUnchanged(t *testing.T) {
+	globals.InitGlobals("test")
+
+	strObj := object.StringObjectFromGoString("\u00A0no padding\u00A0")
+	for name, fn := range map[string]func([]interface{}) interface{}{
+		"trim": trimString, "strip": stripString, "stripLeading": stripLeading, "stripTrailing": stripTrailing,
+	} {
+		if ret := fn([]interface{}{strObj}); ret != strObj {
+			t.Errorf("%s: expected the same String object to be returned", name)
+		}
+	}
+
+	if ret := stripString([]interface{}{object.StringObjectFromGoString(" padded ")}); ret == strObj {
+		t.Errorf("strip: expected a new String object when white space is removed")
+	}
+}
+
+func TestStringRepeat(t *testing.T) {
+	globals.InitGlobals("test")
+
+	ab := object.StringObjectFromGoString("ab")
+
+	ret := stringRepeat([]interface{}{ab, int64(0)})
+	if got := object.GoStringFromStringObject(ret.(*object.Object)); got != "" {
+		t.Errorf("repeat(0): expected an empty string, got %q", got)
+	}
+
+	ret = stringRepeat([]interface{}{ab, int64(3)})
+	if got := object.GoStringFromStringObject(ret.(*object.Object)); got != "ababab" {
+		t.Errorf("repeat(3): expected \"ababab\", got %q", got)
+	}
+
+	if ret = stringRepeat([]interface{}{ab, int64(1)}); ret != ab {
+		t.Errorf("repeat(1): expected the same String object to be returned")
+	}
+}
+
+func TestStringRepeatNegativeCount(t *testing.T) {
+	globals.InitGlobals("test")
+
+	ret := stringRepeat([]interface{}{object.StringObjectFromGoString("ab"), int64(-1)})
+	gErr, ok := ret.(*GErrBlk)
+	if !ok || gErr.ExceptionType != excNames.IllegalArgumentException {
+		t.Errorf("repeat(-1): expected IllegalArgumentException, got %v", ret)
+	}
+}
+
+func TestStringIsBlank(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"", types.JavaBoolTrue},
+		{"  \t", types.JavaBoolTrue},
+		{"\n\u2028\r", types.JavaBoolTrue},
+		{" x ", types.JavaBoolFalse},
+		{"\u00A0", types.JavaBoolFalse}, // not white space to Character.isWhitespace()
+	}
+
+	for _, tt := range tests {
+		ret := stringIsBlank([]interface{}{object.StringObjectFromGoString(tt.input)})
+		if ret != tt.expected {
+			t.Errorf("isBlank(%q): expected %d, got %v", tt.input, tt.expected, ret)
+		}
+	}
+}
+
+// valueOfGoString calls a String.valueOf() G function and returns the result as a Go string
+func valueOfGoString(t *testing.T, fn func([]interface{}) interface{}, arg interface{}) string {
+	ret := fn([]interface{}{arg})
+	strObj, ok := ret.(*object.Object)
+	if !ok {
+		t.Fatalf("valueOf: expected a String object, got %v", ret)
+	}
+	return object.GoStringFromStringObject(strObj)
+}
+
+func TestStringValueOfIntegralTypes(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		name     string
+		fn       func([]interface{}) interface{}
+		arg      interface{}
+		expected string
+	}{
+		{"valueOf(I)", valueOfInt, int64(-2147483648), "-2147483648"},
+		{"valueOf(J)", valueOfLong, int64(9223372036854775807), "9223372036854775807"},
+		{"valueOf(C)", valueOfChar, int64('A'), "A"},
+		{"valueOf(C)", valueOfChar, int64(0x20AC), "€"},
+		{"valueOf(Z)", valueOfBoolean, types.JavaBoolTrue, "true"},
+		{"valueOf(Z)", valueOfBoolean, types.JavaBoolFalse, "false"},
+	}
+
+	for _, tt := range tests {
+		if got := valueOfGoString(t, tt.fn, tt.arg); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestStringValueOfDouble(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		arg      float64
+		expected string
+	}{
+		{1.0, "1.0"},
+		{-42.0, "-42.0"},
+		{3.14159, "3.14159"},
+		{0.1, "0.1"},
+		{0.001, "0.001"},
+		{0.0001, "1.0E-4"},
+		{1234567.0, "1234567.0"},
+		{1.0e7, "1.0E7"},
+		{1.2345e20, "1.2345E20"},
+		{math.Copysign(0, -1), "-0.0"},
+		{math.NaN(), "NaN"},
+		{math.Inf(1), "Infinity"},
+		{math.Inf(-1), "-Infinity"},
+	}
+
+	for _, tt := range tests {
+		if got := valueOfGoString(t, valueOfDouble, tt.arg); got != tt.expected {
+			t.Errorf("valueOf(D) of %v: expected %q, got %q", tt.arg, tt.expected, got)
+		}
+	}
+}
+
+// for whole numbers in its plain-decimal range, getDoubleFormat() (used by println)
+// yields exactly Java's output, so valueOf(double) must agree with it there
+func TestStringValueOfDoubleMatchesGetDoubleFormat(t *testing.T) {
+	globals.InitGlobals("test")
+
+	for _, d := range []float64{1, 2, 10, 255, 1000, 65536, 9999999} {
+		expected := fmt.Sprintf(getDoubleFormat(d), d)
+		if got := valueOfGoString(t, valueOfDouble, d); got != expected {
+			t.Errorf("valueOf(D) of %v: expected %q (as printed by println), got %q", d, expected, got)
+		}
+	}
+}
+
+func TestStringValueOfFloat(t *testing.T) {
+	globals.InitGlobals("test")
+
+	// the float is widened to a double, but formatted with the digits a float needs
+	if got := valueOfGoString(t, valueOfFloat, float64(float32(0.1))); got != "0.1" {
+		t.Errorf("valueOf(F) of 0.1f: expected \"0.1\", got %q", got)
+	}
+	if got := valueOfGoString(t, valueOfFloat, float64(float32(1.5e10))); got != "1.5E10" {
+		t.Errorf("valueOf(F) of 1.5e10f: expected \"1.5E10\", got %q", got)
+	}
+}
+
+func TestStringValueOfCharArray(t *testing.T) {
+	globals.InitGlobals("test")
+
+	chars := object.Make1DimArray(object.INT, 0)
+	chars.FieldTable["value"] = object.Field{Ftype: types.CharArray, Fvalue: object.JavaCharArrayFromGoString("héllo")}
+	if got := valueOfGoString(t, valueOfCharArray, chars); got != "héllo" {
+		t.Errorf("valueOf([C): expected \"héllo\", got %q", got)
+	}
+}
+
+func TestStringValueOfObject(t *testing.T) {
+	globals.InitGlobals("test")
+
+	if got := valueOfGoString(t, valueOfObject, object.Null); got != "null" {
+		t.Errorf("valueOf(Object) of null: expected \"null\", got %q", got)
+	}
+
+	str := object.StringObjectFromGoString("same")
+	if ret := valueOfObject([]interface{}{str}); ret != str {
+		t.Errorf("valueOf(Object) of a String: expected the same object to be returned")
+	}
+
+	boxed := []struct {
+		obj      *object.Object
+		expected string
+	}{
+		{populator("java/lang/Boolean", types.Bool, types.JavaBoolTrue).(*object.Object), "true"},
+		{populator("java/lang/Character", types.Char, int64('x')).(*object.Object), "x"},
+		{populator("java/lang/Long", types.Long, int64(-7)).(*object.Object), "-7"},
+		{populator("java/lang/Double", types.Double, 2.5).(*object.Object), "2.5"},
+	}
+	for _, tt := range boxed {
+		if got := valueOfGoString(t, valueOfObject, tt.obj); got != tt.expected {
+			t.Errorf("valueOf(Object) of %s: expected %q, got %q",
+				object.GoStringFromStringPoolIndex(tt.obj.KlassName), tt.expected, got)
+		}
+	}
+
+	className := "com/example/Widget"
+	widget := object.MakeEmptyObjectWithClassName(&className)
+	expected := fmt.Sprintf("com.example.Widget@%x", uint32(object.IdentityHashCode(widget)))
+	if got := valueOfGoString(t, valueOfObject, widget); got != expected {
+		t.Errorf("valueOf(Object) of a plain object: expected %q, got %q", expected, got)
+	}
+}
+
+func TestGetBytesUsesDefaultCharset(t *testing.T) {
+	globals.InitGlobals("test")
+	str := object.StringObjectFromGoString("café")
+
+	// the default charset is UTF-8
+	ret := getBytesFromString([]interface{}{str}).(*object.Object)
+	if got := ret.FieldTable["value"].Fvalue.([]byte); string(got) != "caf\xC3\xA9" {
+		t.Errorf("getBytes() in UTF-8: expected % X, got % X", []byte("caf\xC3\xA9"), got)
+	}
+
+	// as set by -Dfile.encoding=ISO-8859-1
+	globals.GetGlobalRef().FileEncoding = "ISO-8859-1"
+	ret = getBytesFromString([]interface{}{str}).(*object.Object)
+	if got := ret.FieldTable["value"].Fvalue.([]byte); string(got) != "caf\xE9" {
+		t.Errorf("getBytes() in ISO-8859-1: expected % X, got % X", []byte("caf\xE9"), got)
+	}
+
+	prop := getProperty([]interface{}{object.StringObjectFromGoString("file.encoding")})
+	if got := object.GoStringFromStringObject(prop.(*object.Object)); got != "ISO-8859-1" {
+		t.Errorf("file.encoding property: expected \"ISO-8859-1\", got %q", got)
+	}
+}
+
+// an encoding Jacobin cannot handle is reported as set, but UTF-8 is used
+func TestGetBytesUnsupportedDefaultCharset(t *testing.T) {
+	globals.InitGlobals("test")
+	globals.GetGlobalRef().FileEncoding = "EBCDIC-XYZ"
+
+	ret := getBytesFromString([]interface{}{object.StringObjectFromGoString("é")}).(*object.Object)
+	if got := ret.FieldTable["value"].Fvalue.([]byte); string(got) != "\xC3\xA9" {
+		t.Errorf("getBytes(): expected a fallback to UTF-8, got % X", got)
+	}
+}
+
+// formats with String.format() and returns the resulting string or the exception block
+func formatForTest(format string, args ...*object.Object) (string, *GErrBlk) {
+	result := sprintf([]interface{}{object.StringObjectFromGoString(format), makePrintfArgs(args...)})
+	if gerr, ok := result.(*GErrBlk); ok {
+		return "", gerr
+	}
+	return object.GoStringFromStringObject(result.(*object.Object)), nil
+}
+
+func makeBoxedForTest(className string, ftype string, value interface{}) *object.Object {
+	obj := object.MakeEmptyObjectWithClassName(&className)
+	obj.FieldTable["value"] = object.Field{Ftype: ftype, Fvalue: value}
+	return obj
+}
+
+func TestSprintfMissingArgument(t *testing.T) {
+	globals.InitGlobals("test")
+	_, gerr := formatForTest("%s and %s", object.StringObjectFromGoString("one"))
+	if gerr == nil {
+		t.Fatalf("TestSprintfMissingArgument: expected an exception, got none")
+	}
+	if gerr.ExceptionType != excNames.MissingFormatArgumentException {
+		t.Errorf("TestSprintfMissingArgument: expected MissingFormatArgumentException, got %d", gerr.ExceptionType)
+	}
+	if gerr.ErrMsg != "Format specifier '%s'" {
+		t.Errorf("TestSprintfMissingArgument: unexpected message: %s", gerr.ErrMsg)
+	}
+}
+
+func TestSprintfTypeMismatch(t *testing.T) {
+	globals.InitGlobals("test")
+	_, gerr := formatForTest("%d", object.StringObjectFromGoString("three"))
+	if gerr == nil {
+		t.Fatalf("TestSprintfTypeMismatch: expected an exception, got none")
+	}
+	if gerr.ExceptionType != excNames.IllegalFormatConversionException {
+		t.Errorf("TestSprintfTypeMismatch: expected IllegalFormatConversionException, got %d", gerr.ExceptionType)
+	}
+	if gerr.ErrMsg != "d != java.lang.String" {
+		t.Errorf("TestSprintfTypeMismatch: unexpected message: %s", gerr.ErrMsg)
+	}
+
+	_, gerr = formatForTest("%f", makeBoxedForTest("java/lang/Integer", types.Int, int64(3)))
+	if gerr == nil || gerr.ExceptionType != excNames.IllegalFormatConversionException {
+		t.Errorf("TestSprintfTypeMismatch: expected IllegalFormatConversionException for %%f of an Integer")
+	}
+}
+
+func TestSprintfUnknownConversion(t *testing.T) {
+	globals.InitGlobals("test")
+	_, gerr := formatForTest("%q", object.StringObjectFromGoString("x"))
+	if gerr == nil || gerr.ExceptionType != excNames.UnknownFormatConversionException {
+		t.Errorf("TestSprintfUnknownConversion: expected UnknownFormatConversionException, got %v", gerr)
+	}
+}
+
+func TestSprintfLineSeparator(t *testing.T) {
+	globals.InitGlobals("test")
+	str, gerr := formatForTest("a%nb%%")
+	if gerr != nil {
+		t.Fatalf("TestSprintfLineSeparator: unexpected exception: %s", gerr.ErrMsg)
+	}
+	expected := "a\nb%"
+	if runtime.GOOS == "windows" {
+		expected = "a\r\nb%"
+	}
+	if str != expected {
+		t.Errorf("TestSprintfLineSeparator: expected %q, got %q", expected, str)
+	}
+}
+
+func TestSprintfJavaConversions(t *testing.T) {
+	globals.InitGlobals("test")
+	str, gerr := formatForTest("%2$s %1$5d",
+		makeBoxedForTest("java/lang/Integer", types.Int, int64(42)),
+		object.StringObjectFromGoString("answer"))
+	if gerr != nil {
+		t.Fatalf("TestSprintfJavaConversions: unexpected exception: %s", gerr.ErrMsg)
+	}
+	if str != "answer    42" {
+		t.Errorf("TestSprintfJavaConversions: expected %q, got %q", "answer    42", str)
+	}
+
+	str, gerr = formatForTest("%-4b|%x|%X|%,d|%.2f|%S|%c",
+		makeBoxedForTest("java/lang/Boolean", types.Bool, types.JavaBoolTrue),
+		makeBoxedForTest("java/lang/Integer", types.Int, int64(-1)),
+		makeBoxedForTest("java/lang/Long", types.Long, int64(255)),
+		makeBoxedForTest("java/lang/Long", types.Long, int64(-1234567)),
+		makeBoxedForTest("java/lang/Double", types.Double, 3.14159),
+		object.Null,
+		makeBoxedForTest("java/lang/Character", types.Char, int64('J')))
+	if gerr != nil {
+		t.Fatalf("TestSprintfJavaConversions: unexpected exception: %s", gerr.ErrMsg)
+	}
+	expected := "true|ffffffff|FF|-1,234,567|3.14|NULL|J"
+	if str != expected {
+		t.Errorf("TestSprintfJavaConversions: expected %q, got %q", expected, str)
+	}
+}
+
+func TestStringHashCode(t *testing.T) {
+	globals.InitGlobals("test")
+	tests := []struct {
+		str      string
+		expected int64
+	}{
+		{"", 0},
+		{"a", 97},
+		{"hello", 99162322},
+		{"banana", -1396355227}, // the arithmetic wraps, as Java's 32-bit ints do
+		{"polygenelubricants", -2147483648},
+		{"Añ€😀", 77019199}, // computed over UTF-16 chars, so 😀 is two surrogates
+	}
+	for _, tt := range tests {
+		if got := stringHashCode([]interface{}{object.StringObjectFromGoString(tt.str)}); got != tt.expected {
+			t.Errorf("%q.hashCode(): expected %d, got %v", tt.str, tt.expected, got)
+		}
+	}
+}
+
+func TestStringEqualsNonStrings(t *testing.T) {
+	globals.InitGlobals("test")
+	empty := object.StringObjectFromGoString("")
+	if stringEquals([]interface{}{empty, object.StringObjectFromGoString("")}) != types.JavaBoolTrue {
+		t.Errorf("\"\".equals(\"\"): expected true")
+	}
+	if stringEquals([]interface{}{empty, object.Null}) != types.JavaBoolFalse {
+		t.Errorf("\"\".equals(null): expected false")
+	}
+	className := "com/example/Widget"
+	if stringEquals([]interface{}{empty, object.MakeEmptyObjectWithClassName(&className)}) != types.JavaBoolFalse {
+		t.Errorf("\"\".equals(a non-string): expected false")
+	}
+}
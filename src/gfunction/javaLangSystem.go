@@ -79,6 +79,12 @@ func Load_Lang_System() {
 			GFunction:  getProperty,
 		}
 
+	MethodSignatures["java/lang/System.lineSeparator()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  systemLineSeparator,
+		}
+
 	MethodSignatures["java/lang/System.registerNatives()V"] =
 		GMeth{
 			ParamSlots: 0,
@@ -91,6 +97,18 @@ func Load_Lang_System() {
 			GFunction:  getConsole,
 		}
 
+	MethodSignatures["java/lang/System.setErr(Ljava/io/PrintStream;)V"] = // redirect System.err
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  setErr,
+		}
+
+	MethodSignatures["java/lang/System.setOut(Ljava/io/PrintStream;)V"] = // redirect System.out
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  setOut,
+		}
+
 	MethodSignatures["java/lang/System.<clinit>()V"] =
 		GMeth{
 			ParamSlots: 0,
@@ -120,14 +138,35 @@ func clinit([]interface{}) interface{} {
 		return getGErrBlk(excNames.ClassNotLoadedException, errMsg)
 	}
 	if klass.Data.ClInit != types.ClInitRun {
-		_ = statics.AddStatic("java/lang/System.in", statics.Static{Type: "GS", Value: os.Stdin})
-		_ = statics.AddStatic("java/lang/System.err", statics.Static{Type: "GS", Value: os.Stderr})
-		_ = statics.AddStatic("java/lang/System.out", statics.Static{Type: "GS", Value: os.Stdout})
+		_ = statics.AddStatic("java/lang/System.in", statics.Static{Type: "Ljava/io/InputStream;",
+			Value: newStdinInputStream(os.Stdin)})
+		g := globals.GetGlobalRef()
+		_ = statics.AddStatic("java/lang/System.err", statics.Static{Type: "Ljava/io/PrintStream;",
+			Value: newConsolePrintStream(os.Stderr, g.StderrEncoding)})
+		_ = statics.AddStatic("java/lang/System.out", statics.Static{Type: "Ljava/io/PrintStream;",
+			Value: newConsolePrintStream(os.Stdout, g.StdoutEncoding)})
 		klass.Data.ClInit = types.ClInitRun
 	}
 	return nil
 }
 
+// setErr replaces System.err with the given PrintStream
+// "java/lang/System.setErr(Ljava/io/PrintStream;)V"
+func setErr(params []interface{}) interface{} {
+	_ = statics.AddStatic("java/lang/System.err",
+		statics.Static{Type: "Ljava/io/PrintStream;", Value: params[0]})
+	return nil
+}
+
+// setOut replaces System.out with the given PrintStream. Subsequent output to System.out
+// goes to the writer the PrintStream carries (see NewPrintStream()).
+// "java/lang/System.setOut(Ljava/io/PrintStream;)V"
+func setOut(params []interface{}) interface{} {
+	_ = statics.AddStatic("java/lang/System.out",
+		statics.Static{Type: "Ljava/io/PrintStream;", Value: params[0]})
+	return nil
+}
+
 // arrayCopy copies an array or subarray from one array to another, both of which must exist.
 // It is a complex native function in the JDK. Javadoc here:
 // docs.oracle.com/en/java/javase/17/docs/api/java.base/java/lang/System.html#arraycopy(java.lang.Object,int,java.lang.Object,int,int)
@@ -267,9 +306,15 @@ func arrayCopy(params []interface{}) interface{} {
 	return nil
 }
 
-// Return the system input console as a *os.File.
+// Return the Console, if there is one. As in the JDK, there is a console only if
+// both System.in and System.out are attached to a terminal; otherwise, this returns null.
+// "java/lang/System.console()Ljava/io/Console;"
 func getConsole([]interface{}) interface{} {
-	return statics.GetStaticValue("java/lang/System", "in")
+	if !isTerminal(inputStreamFile(statics.GetStaticValue("java/lang/System", "in"))) ||
+		!isTerminal(printStreamTarget(statics.GetStaticValue("java/lang/System", "out"))) {
+		return object.Null
+	}
+	return theConsole()
 }
 
 // Return time in milliseconds, measured since midnight of Jan 1, 1970
@@ -292,12 +337,31 @@ func exitI(params []interface{}) interface{} {
 	return 0 // this code is not executed as previous line ends Jacobin
 }
 
-// Force a garbage collection cycle.
+// Force a garbage collection cycle. Jacobin relies on Go's garbage collector, so this
+// is a hint to run it, which is ignored if -XX:+DisableExplicitGC was specified.
+// Also used for "java/lang/Runtime.gc()V"
 func forceGC([]interface{}) interface{} {
+	if globals.GetGlobalRef().DisableExplicitGC {
+		return nil
+	}
 	runtime.GC()
 	return nil
 }
 
+// "java/lang/System.lineSeparator()Ljava/lang/String;"
+// As in the JDK, this is the value of the line.separator property.
+func systemLineSeparator([]interface{}) interface{} {
+	return getProperty([]interface{}{object.StringObjectFromGoString("line.separator")})
+}
+
+// lineSeparator returns the platform's line separator: "\r\n" on Windows, "\n" elsewhere.
+func lineSeparator() string {
+	if runtime.GOOS == "windows" {
+		return "\r\n"
+	}
+	return "\n"
+}
+
 // Get a property
 func getProperty(params []interface{}) interface{} {
 	propObj := params[0].(*object.Object) // string
@@ -311,9 +375,12 @@ func getProperty(params []interface{}) interface{} {
 	case "file.encoding":
 		value = g.FileEncoding
 	case "file.separator":
-		value = string(os.PathSeparator)
+		value = g.FileSeparator
 	case "java.class.path":
-		value = "." // OpenJDK JVM default value
+		value = g.ClassPath
+		if value == "" {
+			value = "." // OpenJDK JVM default value
+		}
 	case "java.compiler": // the name of the JIT compiler (we don't have a JIT)
 		value = "no JIT"
 	case "java.home":
@@ -344,13 +411,13 @@ func getProperty(params []interface{}) interface{} {
 	case "java.vm.version":
 		value = strconv.Itoa(g.MaxJavaVersion)
 	case "line.separator":
-		if operSys == "windows" {
-			value = "\\r\\n"
-		} else {
-			value = "\\n"
-		}
+		value = lineSeparator()
 	case "native.encoding": // hard to find out what this is, so hard-coding to UTF8
 		value = "UTF8"
+	case "stderr.encoding", "sun.stderr.encoding":
+		value = g.StderrEncoding
+	case "stdout.encoding", "sun.stdout.encoding":
+		value = g.StdoutEncoding
 	case "os.arch":
 		value = runtime.GOARCH
 	case "os.name":
@@ -358,7 +425,7 @@ func getProperty(params []interface{}) interface{} {
 	case "os.version":
 		value = "not yet available"
 	case "path.separator":
-		value = string(os.PathSeparator)
+		value = g.PathSeparator
 	case "user.dir": // present working directory
 		value, _ = os.Getwd()
 	case "user.home":
@@ -7,9 +7,15 @@
 package gfunction
 
 import (
+	"bytes"
+	"jacobin/classloader"
 	"jacobin/globals"
 	"jacobin/object"
+	"jacobin/statics"
 	"jacobin/stringPool"
+	"jacobin/types"
+	"os"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -244,3 +250,207 @@ func TestArrayCopyInvalidLength(t *testing.T) {
 		t.Errorf("Expected error re invalid length, got %s", errMsg)
 	}
 }
+
+// System.setOut() redirects System.out to a PrintStream whose output goes to an in-memory buffer
+func TestSetOutRedirectsToBuffer(t *testing.T) {
+	globals.InitGlobals("test")
+	original, hadOriginal := statics.Statics["java/lang/System.out"]
+	defer func() {
+		if hadOriginal {
+			statics.Statics["java/lang/System.out"] = original
+		} else {
+			delete(statics.Statics, "java/lang/System.out")
+		}
+	}()
+
+	var buf bytes.Buffer
+	if ret := setOut([]interface{}{NewPrintStream(&buf)}); ret != nil {
+		t.Fatalf("System.setOut() returned an unexpected value: %v", ret)
+	}
+
+	// this is what GETSTATIC java/lang/System.out pushes as the object of a println()
+	out := statics.GetStaticValue("java/lang/System", "out")
+	_ = PrintlnString([]interface{}{out, object.StringObjectFromGoString("hello, buffer")})
+	_ = PrintBIS([]interface{}{out, int64(42)})
+	_ = PrintlnBoolean([]interface{}{out, int64(1)})
+
+	expected := "hello, buffer\n42true\n"
+	if buf.String() != expected {
+		t.Errorf("Expected System.out to capture %q, got %q", expected, buf.String())
+	}
+}
+
+// System.setErr() does the same for System.err, without disturbing System.out
+func TestSetErrRedirectsToBuffer(t *testing.T) {
+	globals.InitGlobals("test")
+	originalErr, hadErr := statics.Statics["java/lang/System.err"]
+	originalOut, hadOut := statics.Statics["java/lang/System.out"]
+	defer func() {
+		if hadErr {
+			statics.Statics["java/lang/System.err"] = originalErr
+		} else {
+			delete(statics.Statics, "java/lang/System.err")
+		}
+		if hadOut {
+			statics.Statics["java/lang/System.out"] = originalOut
+		} else {
+			delete(statics.Statics, "java/lang/System.out")
+		}
+	}()
+
+	var outBuf, errBuf bytes.Buffer
+	_ = setOut([]interface{}{NewPrintStream(&outBuf)})
+	_ = setErr([]interface{}{NewPrintStream(&errBuf)})
+
+	errStream := statics.GetStaticValue("java/lang/System", "err")
+	_ = PrintlnString([]interface{}{errStream, object.StringObjectFromGoString("oops")})
+
+	if errBuf.String() != "oops\n" {
+		t.Errorf("Expected System.err to capture %q, got %q", "oops\n", errBuf.String())
+	}
+	if outBuf.Len() != 0 {
+		t.Errorf("Expected nothing written to System.out, got %q", outBuf.String())
+	}
+}
+
+func TestLineSeparator(t *testing.T) {
+	globals.InitGlobals("test")
+
+	expected := "\n"
+	if runtime.GOOS == "windows" {
+		expected = "\r\n"
+	}
+
+	sep := systemLineSeparator(nil).(*object.Object)
+	if object.GoStringFromStringObject(sep) != expected {
+		t.Errorf("TestLineSeparator: expected %q, got %q", expected, object.GoStringFromStringObject(sep))
+	}
+
+	prop := getProperty([]interface{}{object.StringObjectFromGoString("line.separator")}).(*object.Object)
+	if object.GoStringFromStringObject(prop) != expected {
+		t.Errorf("TestLineSeparator: expected line.separator to be %q, got %q",
+			expected, object.GoStringFromStringObject(prop))
+	}
+}
+
+// file.separator, path.separator, and java.class.path report what was set on the command line
+func TestSeparatorAndClassPathProperties(t *testing.T) {
+	globals.InitGlobals("test")
+	glob := globals.GetGlobalRef()
+
+	prop := func(name string) string {
+		return object.GoStringFromStringObject(getProperty([]interface{}{object.StringObjectFromGoString(name)}).(*object.Object))
+	}
+	if got := prop("path.separator"); got != string(os.PathListSeparator) {
+		t.Errorf("Expected the default path.separator to be %q, got %q", string(os.PathListSeparator), got)
+	}
+	if got := prop("java.class.path"); got != "." {
+		t.Errorf("Expected the default java.class.path to be \".\", got %q", got)
+	}
+
+	glob.FileSeparator = "#"
+	glob.PathSeparator = ";"
+	glob.ClassPath = "classes;lib/app.jar"
+	for name, expected := range map[string]string{
+		"file.separator": "#", "path.separator": ";", "java.class.path": "classes;lib/app.jar"} {
+		if got := prop(name); got != expected {
+			t.Errorf("Expected %s to be %q, got %q", name, expected, got)
+		}
+	}
+}
+
+// System.gc() runs Go's garbage collector, unless -XX:+DisableExplicitGC was specified
+func TestSystemGC(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_System()
+
+	gmeth, ok := MethodSignatures["java/lang/System.gc()V"]
+	if !ok {
+		t.Fatalf("System.gc()V: method not found in MethodSignatures")
+	}
+	if gmeth.ParamSlots != 0 {
+		t.Errorf("System.gc()V: expected 0 param slots, got %d", gmeth.ParamSlots)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if ret := gmeth.GFunction(nil); ret != nil {
+		t.Errorf("System.gc()V: expected nil return, got %v", ret)
+	}
+	runtime.ReadMemStats(&after)
+	if after.NumGC <= before.NumGC {
+		t.Errorf("System.gc()V: expected a garbage collection to run")
+	}
+
+	globals.GetGlobalRef().DisableExplicitGC = true
+	runtime.ReadMemStats(&before)
+	if ret := gmeth.GFunction(nil); ret != nil {
+		t.Errorf("System.gc()V with explicit GC disabled: expected nil return, got %v", ret)
+	}
+	runtime.ReadMemStats(&after)
+	if after.NumGC != before.NumGC {
+		t.Errorf("System.gc()V: expected no garbage collection when explicit GC is disabled")
+	}
+}
+
+// System.<clinit>() makes System.in an InputStream reading from stdin, and System.out
+// and System.err PrintStreams, so that what GETSTATIC pushes for them can be used
+func TestSystemStreamsAfterClinit(t *testing.T) {
+	globals.InitGlobals("test")
+	classloader.InitMethodArea()
+	saved := make(map[string]statics.Static)
+	for _, name := range []string{"in", "out", "err"} {
+		if static, ok := statics.Statics["java/lang/System."+name]; ok {
+			saved[name] = static
+		}
+	}
+	originalStdin := os.Stdin
+	defer func() {
+		os.Stdin = originalStdin
+		for _, name := range []string{"in", "out", "err"} {
+			if static, ok := saved[name]; ok {
+				statics.Statics["java/lang/System."+name] = static
+			} else {
+				delete(statics.Statics, "java/lang/System."+name)
+			}
+		}
+	}()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	os.Stdin = r
+	_, _ = w.Write([]byte("Az"))
+	_ = w.Close()
+
+	systemName := "java/lang/System"
+	classloader.MethAreaInsert(systemName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data:   &classloader.ClData{Name: systemName, ClInit: types.ClInitNotRun},
+	})
+	if ret := clinit(nil); ret != nil {
+		t.Fatalf("System.<clinit>(): unexpected error: %v", ret)
+	}
+
+	in, ok := statics.GetStaticValue(systemName, "in").(*object.Object)
+	if !ok {
+		t.Fatalf("System.in: expected an InputStream object, got %T", statics.GetStaticValue(systemName, "in"))
+	}
+	Load_Io_InputStream()
+	read := MethodSignatures["java/io/InputStream.read()I"].GFunction
+	for _, expected := range []int64{'A', 'z', -1} {
+		if got := read([]interface{}{in}); got != expected {
+			t.Errorf("System.in.read(): expected %d, got %v", expected, got)
+		}
+	}
+
+	for _, name := range []string{"out", "err"} {
+		stream, ok := statics.GetStaticValue(systemName, name).(*object.Object)
+		if !ok || object.GoStringFromStringPoolIndex(stream.KlassName) != "java/io/PrintStream" {
+			t.Errorf("System.%s: expected a PrintStream object, got %v", name, statics.GetStaticValue(systemName, name))
+		}
+	}
+}
@@ -7,7 +7,13 @@
 package gfunction
 
 import (
+	"fmt"
 	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/types"
+	"sync"
 	"time"
 )
 
@@ -29,18 +35,249 @@ import (
 
 func Load_Lang_Thread() {
 
+	MethodSignatures["java/lang/Thread.<init>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  threadInit,
+		}
+
+	MethodSignatures["java/lang/Thread.<init>(Ljava/lang/Runnable;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  threadInitWithRunnable,
+		}
+
+	MethodSignatures["java/lang/Thread.isDaemon()Z"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  threadIsDaemon,
+		}
+
+	MethodSignatures["java/lang/Thread.join()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  threadJoin,
+		}
+
+	MethodSignatures["java/lang/Thread.join(J)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  threadJoin,
+		}
+
 	MethodSignatures["java/lang/Thread.registerNatives()V"] =
 		GMeth{
 			ParamSlots: 0,
 			GFunction:  justReturn,
 		}
 
+	MethodSignatures["java/lang/Thread.run()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  threadRun,
+		}
+
+	MethodSignatures["java/lang/Thread.setDaemon(Z)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  threadSetDaemon,
+		}
+
 	MethodSignatures["java/lang/Thread.sleep(J)V"] =
 		GMeth{
 			ParamSlots: 2,
 			GFunction:  threadSleep,
 		}
 
+	MethodSignatures["java/lang/Thread.start()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  threadStart,
+		}
+
+}
+
+// The Thread object holds the Runnable passed to the constructor in its "target" field.
+// When the thread is started, its "done" field is set to a channel that is closed when
+// the thread's run() method returns, which is what join() waits on. Its "daemon" field
+// is set by setDaemon().
+const (
+	threadTarget = "target"
+	threadDone   = "done"
+	threadDaemon = "daemon"
+)
+
+// guards the "done" and "daemon" fields of Thread objects, which start(), join(), and
+// setDaemon() can access concurrently
+var threadLock sync.Mutex
+
+// the started non-daemon threads that have not yet finished, which the JVM waits for
+// before it exits (see WaitForNonDaemonThreads())
+var nonDaemonThreads sync.WaitGroup
+
+// "java/lang/Thread.<init>()V"
+func threadInit(params []interface{}) interface{} {
+	return threadInitWithRunnable([]interface{}{params[0], object.Null})
+}
+
+// "java/lang/Thread.<init>(Ljava/lang/Runnable;)V"
+func threadInitWithRunnable(params []interface{}) interface{} {
+	th, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(th) {
+		errMsg := "threadInitWithRunnable: Thread object is invalid"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	runnable, ok := params[1].(*object.Object)
+	if !ok {
+		runnable = object.Null
+	}
+	th.FieldTable[threadTarget] = object.Field{Ftype: "Ljava/lang/Runnable;", Fvalue: runnable}
+	return nil
+}
+
+// "java/lang/Thread.start()V" runs the thread's run() on a goroutine backed by its own
+// execution thread. A Thread can be started only once. A subclass of Thread can override
+// run(), so for a subclass, the thread runs the Thread object's own run(). For a Thread
+// itself, whose run() just calls the Runnable passed to the constructor, the thread runs
+// the Runnable's run() directly.
+func threadStart(params []interface{}) interface{} {
+	th, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(th) {
+		errMsg := "threadStart: Thread object is invalid"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	threadLock.Lock()
+	defer threadLock.Unlock()
+	if _, started := th.FieldTable[threadDone]; started {
+		return getGErrBlk(excNames.IllegalThreadStateException, "Thread has already been started")
+	}
+	done := make(chan struct{})
+	th.FieldTable[threadDone] = object.Field{Ftype: types.Ref, Fvalue: done}
+
+	runnable := th
+	if object.GoStringFromStringPoolIndex(th.KlassName) == "java/lang/Thread" {
+		target, ok := th.FieldTable[threadTarget].Fvalue.(*object.Object)
+		if !ok || object.IsNull(target) { // with no Runnable, Thread.run() does nothing
+			close(done)
+			return nil
+		}
+		runnable = target
+	}
+
+	daemon, _ := th.FieldTable[threadDaemon].Fvalue.(int64)
+	if daemon == 0 {
+		nonDaemonThreads.Add(1)
+	}
+	runThread := globals.GetGlobalRef().FuncRunThread
+	go func() {
+		defer close(done)
+		if daemon == 0 {
+			defer nonDaemonThreads.Done()
+		}
+		if err := runThread(runnable); err != nil {
+			_ = log.Log(fmt.Sprintf("threadStart: %s", err.Error()), log.SEVERE)
+		}
+	}()
+	return nil
+}
+
+// "java/lang/Thread.run()V" runs the run() method of the Runnable passed to the
+// constructor, if any. Called when run() is called directly, rather than via start(),
+// and when a subclass of Thread that does not override run() is started.
+func threadRun(params []interface{}) interface{} {
+	th, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(th) {
+		errMsg := "threadRun: Thread object is invalid"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	target, ok := th.FieldTable[threadTarget].Fvalue.(*object.Object)
+	if !ok || object.IsNull(target) {
+		return nil
+	}
+	if err := globals.GetGlobalRef().FuncRunThread(target); err != nil {
+		return err
+	}
+	return nil
+}
+
+// "java/lang/Thread.setDaemon(Z)V" marks the thread as a daemon thread, which the JVM
+// does not wait for before it exits, or as a user thread. It can't be changed once the
+// thread has been started.
+func threadSetDaemon(params []interface{}) interface{} {
+	th, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(th) {
+		errMsg := "threadSetDaemon: Thread object is invalid"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	threadLock.Lock()
+	defer threadLock.Unlock()
+	if _, started := th.FieldTable[threadDone]; started {
+		return getGErrBlk(excNames.IllegalThreadStateException, "Thread has already been started")
+	}
+	th.FieldTable[threadDaemon] = object.Field{Ftype: types.Bool, Fvalue: params[1].(int64)}
+	return nil
+}
+
+// "java/lang/Thread.isDaemon()Z"
+func threadIsDaemon(params []interface{}) interface{} {
+	th, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(th) {
+		errMsg := "threadIsDaemon: Thread object is invalid"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	threadLock.Lock()
+	defer threadLock.Unlock()
+	daemon, _ := th.FieldTable[threadDaemon].Fvalue.(int64)
+	return daemon
+}
+
+// WaitForNonDaemonThreads waits until all the started threads that are not daemon
+// threads have finished. As in the JDK, the JVM calls it when main() has returned,
+// so that the program ends only when its last user thread does.
+func WaitForNonDaemonThreads() {
+	nonDaemonThreads.Wait()
+}
+
+// "java/lang/Thread.join()V" and "java/lang/Thread.join(J)V" wait for the thread to finish,
+// in the latter case for no more than the given number of milliseconds (0 means forever).
+// Joining a thread that was never started returns at once.
+func threadJoin(params []interface{}) interface{} {
+	th, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(th) {
+		errMsg := "threadJoin: Thread object is invalid"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	var millis int64
+	if len(params) > 1 {
+		millis, ok = params[1].(int64)
+		if !ok || millis < 0 {
+			errMsg := "threadJoin: timeout value is negative or invalid"
+			return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+		}
+	}
+
+	threadLock.Lock()
+	done, started := th.FieldTable[threadDone].Fvalue.(chan struct{})
+	threadLock.Unlock()
+	if !started {
+		return nil
+	}
+
+	if millis == 0 {
+		<-done
+		return nil
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Duration(millis) * time.Millisecond):
+	}
+	return nil
 }
 
 // "java/lang/Thread.sleep(J)V"
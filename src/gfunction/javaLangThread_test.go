@@ -0,0 +1,202 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"sync"
+	"testing"
+	"time"
+)
+
+var threadClassName = "java/lang/Thread"
+
+// makeThread returns a Thread object constructed with a Runnable of the given class
+func makeThread(t *testing.T, runnableClass string) *object.Object {
+	th := object.MakeEmptyObjectWithClassName(&threadClassName)
+	runnable := object.MakeEmptyObjectWithClassName(&runnableClass)
+	if ret := threadInitWithRunnable([]interface{}{th, runnable}); ret != nil {
+		t.Fatalf("Thread.<init>: unexpected error: %v", ret)
+	}
+	return th
+}
+func TestThreadStartAndJoin(t *testing.T) {
+	globals.InitGlobals("test")
+
+	// the runnables append to a synchronized collection
+	var lock sync.Mutex
+	var ran []string
+	globals.GetGlobalRef().FuncRunThread = func(runnable any) error {
+		time.Sleep(10 * time.Millisecond) // make sure join() has to wait
+		lock.Lock()
+		defer lock.Unlock()
+		ran = append(ran, object.GoStringFromStringPoolIndex(runnable.(*object.Object).KlassName))
+		return nil
+	}
+
+	threads := []*object.Object{makeThread(t, "Worker"), makeThread(t, "Worker"), makeThread(t, "Worker")}
+	for _, th := range threads {
+		if ret := threadStart([]interface{}{th}); ret != nil {
+			t.Fatalf("Thread.start: unexpected error: %v", ret)
+		}
+	}
+	for _, th := range threads {
+		if ret := threadJoin([]interface{}{th}); ret != nil {
+			t.Fatalf("Thread.join: unexpected error: %v", ret)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(ran) != len(threads) {
+		t.Fatalf("expected %d runnables to have run before join() returned, got %d", len(threads), len(ran))
+	}
+	for _, name := range ran {
+		if name != "Worker" {
+			t.Errorf("expected the Worker runnable to run, got %s", name)
+		}
+	}
+}
+
+func TestThreadStartTwice(t *testing.T) {
+	globals.InitGlobals("test")
+	globals.GetGlobalRef().FuncRunThread = func(runnable any) error { return nil }
+
+	th := makeThread(t, "Worker")
+	if ret := threadStart([]interface{}{th}); ret != nil {
+		t.Fatalf("Thread.start: unexpected error: %v", ret)
+	}
+	ret := threadStart([]interface{}{th})
+	errBlk, ok := ret.(*GErrBlk)
+	if !ok || errBlk.ExceptionType != excNames.IllegalThreadStateException {
+		t.Errorf("expected IllegalThreadStateException on second start(), got: %v", ret)
+	}
+	_ = threadJoin([]interface{}{th})
+}
+
+func TestThreadJoinWithTimeout(t *testing.T) {
+	globals.InitGlobals("test")
+
+	release := make(chan struct{})
+	globals.GetGlobalRef().FuncRunThread = func(runnable any) error {
+		<-release
+		return nil
+	}
+
+	// a thread that was never started is joined at once
+	th := makeThread(t, "Worker")
+	if ret := threadJoin([]interface{}{th, int64(0)}); ret != nil {
+		t.Fatalf("Thread.join(J) on an unstarted thread: unexpected error: %v", ret)
+	}
+
+	// join(J) returns after the timeout if the thread is still running
+	_ = threadStart([]interface{}{th})
+	start := time.Now()
+	if ret := threadJoin([]interface{}{th, int64(20)}); ret != nil {
+		t.Fatalf("Thread.join(J): unexpected error: %v", ret)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Thread.join(J): expected to wait at least 20ms, waited %v", elapsed)
+	}
+
+	ret := threadJoin([]interface{}{th, int64(-1)})
+	if errBlk, ok := ret.(*GErrBlk); !ok || errBlk.ExceptionType != excNames.IllegalArgumentException {
+		t.Errorf("Thread.join(J): expected IllegalArgumentException for a negative timeout, got: %v", ret)
+	}
+
+	close(release)
+	_ = threadJoin([]interface{}{th})
+}
+
+// a subclass of Thread can override run(), so starting it runs the Thread object itself,
+// whereas a Thread with no Runnable does nothing
+func TestThreadSubclassStartRunsThread(t *testing.T) {
+	globals.InitGlobals("test")
+
+	ran := make(chan string, 2)
+	globals.GetGlobalRef().FuncRunThread = func(runnable any) error {
+		ran <- object.GoStringFromStringPoolIndex(runnable.(*object.Object).KlassName)
+		return nil
+	}
+
+	subclassName := "Ticker"
+	sub := object.MakeEmptyObjectWithClassName(&subclassName)
+	plain := object.MakeEmptyObjectWithClassName(&threadClassName)
+	for _, th := range []*object.Object{sub, plain} {
+		if ret := threadInit([]interface{}{th}); ret != nil {
+			t.Fatalf("Thread.<init>: unexpected error: %v", ret)
+		}
+		if ret := threadStart([]interface{}{th}); ret != nil {
+			t.Fatalf("Thread.start: unexpected error: %v", ret)
+		}
+		_ = threadJoin([]interface{}{th})
+	}
+
+	close(ran)
+	var names []string
+	for name := range ran {
+		names = append(names, name)
+	}
+	if len(names) != 1 || names[0] != subclassName {
+		t.Errorf("expected only the Ticker thread to run, got %v", names)
+	}
+}
+
+// the JVM waits for the non-daemon threads to finish, but not for daemon threads
+func TestWaitForNonDaemonThreads(t *testing.T) {
+	globals.InitGlobals("test")
+
+	daemon := makeThread(t, "Worker")
+	user := makeThread(t, "Worker")
+
+	release := make(chan struct{})
+	releaseDaemon := make(chan struct{})
+	defer close(releaseDaemon)
+	globals.GetGlobalRef().FuncRunThread = func(runnable any) error {
+		if runnable == daemon.FieldTable[threadTarget].Fvalue {
+			<-releaseDaemon
+		} else {
+			<-release
+		}
+		return nil
+	}
+
+	if ret := threadSetDaemon([]interface{}{daemon, int64(1)}); ret != nil {
+		t.Fatalf("Thread.setDaemon: unexpected error: %v", ret)
+	}
+	if threadIsDaemon([]interface{}{daemon}) != int64(1) {
+		t.Error("Thread.isDaemon: expected the thread to be a daemon thread")
+	}
+	_ = threadStart([]interface{}{daemon})
+	ret := threadSetDaemon([]interface{}{daemon, int64(0)})
+	if errBlk, ok := ret.(*GErrBlk); !ok || errBlk.ExceptionType != excNames.IllegalThreadStateException {
+		t.Errorf("Thread.setDaemon: expected IllegalThreadStateException after start(), got: %v", ret)
+	}
+
+	_ = threadStart([]interface{}{user})
+
+	waited := make(chan struct{})
+	go func() {
+		WaitForNonDaemonThreads()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("WaitForNonDaemonThreads: expected to wait for the running user thread")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// the daemon thread is still running when the user thread is done
+	close(release)
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("WaitForNonDaemonThreads: expected to return once the user thread finished")
+	}
+}
@@ -190,3 +190,157 @@ func InstantiateFillIn(name string, _ *list.List) (any, error) {
 	o.KlassName = stringPool.GetStringIndex(&name)
 	return o, nil
 }
+
+// GetStackTraces returns one StackTraceElement per frame, most recent frame first, and
+// the elements carry the class and method names of their frames
+func TestGetStackTracesTwoFrames(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	globals.GetGlobalRef().FuncInstantiateClass = InstantiateFillIn
+
+	for _, name := range []string{"app/Main", "app/Worker"} {
+		clData := classloader.ClData{Name: name, SourceFile: name[4:] + ".java", CP: classloader.CPool{}}
+		classloader.MethAreaInsert(name, &classloader.Klass{Loader: "app", Data: &clData})
+	}
+	CP := classloader.CPool{}
+	classloader.MTable["app/Main.main([Ljava/lang/String;)V"] =
+		classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{Cp: &CP}}
+	classloader.MTable["app/Worker.run()V"] =
+		classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{Cp: &CP}}
+
+	caller := frames.CreateFrame(2)
+	caller.ClName = "app/Main"
+	caller.MethName = "main"
+	caller.MethType = "([Ljava/lang/String;)V"
+	callee := frames.CreateFrame(2)
+	callee.ClName = "app/Worker"
+	callee.MethName = "run"
+	callee.MethType = "()V"
+
+	jvmStack := frames.CreateFrameStack()
+	_ = frames.PushFrame(jvmStack, caller)
+	_ = frames.PushFrame(jvmStack, callee)
+
+	className := "java/lang/Throwable"
+	throwable := object.MakeEmptyObjectWithClassName(&className)
+	throwable.FieldTable["frameStackRef"] = object.Field{Fvalue: jvmStack}
+
+	stackTrace := GetStackTraces([]interface{}{throwable})
+	elements := stackTrace.FieldTable["value"].Fvalue.([]*object.Object)
+	if len(elements) != 2 {
+		t.Fatalf("TestGetStackTracesTwoFrames: expected 2 elements, got %d", len(elements))
+	}
+
+	expected := []struct{ declaringClass, methodName, fileName string }{
+		{"app/Worker", "run", "Worker.java"},
+		{"app/Main", "main", "Main.java"},
+	}
+	for i, exp := range expected {
+		ste := elements[i].FieldTable
+		if ste["declaringClass"].Fvalue != exp.declaringClass {
+			t.Errorf("TestGetStackTracesTwoFrames: element %d: expected declaringClass %s, got %v",
+				i, exp.declaringClass, ste["declaringClass"].Fvalue)
+		}
+		if ste["methodName"].Fvalue != exp.methodName {
+			t.Errorf("TestGetStackTracesTwoFrames: element %d: expected methodName %s, got %v",
+				i, exp.methodName, ste["methodName"].Fvalue)
+		}
+		if ste["fileName"].Fvalue != exp.fileName {
+			t.Errorf("TestGetStackTracesTwoFrames: element %d: expected fileName %s, got %v",
+				i, exp.fileName, ste["fileName"].Fvalue)
+		}
+	}
+}
+
+// initStackTraceElements fills in each element from its frame: the class, the method,
+// the source file, and the line number found in the method's LineNumberTable at the
+// frame's PC, or -1 if there is no table and -2 if the method is native
+func TestInitStackTraceElementsFillsFields(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.SEVERE)
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	globals.GetGlobalRef().FuncInstantiateClass = InstantiateFillIn
+
+	clData := classloader.ClData{Name: "app/Shop", SourceFile: "Shop.java", CP: classloader.CPool{}}
+	classloader.MethAreaInsert("app/Shop", &classloader.Klass{Loader: "app", Data: &clData})
+
+	// the line number table maps bytecodes 0-9 to line 10 and bytecodes 10 on to line 12
+	CP := classloader.CPool{Utf8Refs: []string{"Code", "LineNumberTable"}}
+	lineNumberTable := classloader.Attr{
+		AttrName:    1,
+		AttrSize:    10,
+		AttrContent: []byte{0, 2, 0, 0, 0, 10, 0, 10, 0, 12},
+	}
+	classloader.MTable["app/Shop.buy(I)V"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{Cp: &CP, Attribs: []classloader.Attr{lineNumberTable}}}
+	classloader.MTable["app/Shop.sell()V"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{Cp: &CP}}
+	classloader.MTable["app/Shop.count()I"] = classloader.MTentry{MType: 'G',
+		Meth: GMeth{}}
+
+	jvmStack := frames.CreateFrameStack()
+	for _, meth := range []struct {
+		name, methType string
+		pc             int
+	}{{"count", "()I", 0}, {"sell", "()V", 3}, {"buy", "(I)V", 12}} {
+		f := frames.CreateFrame(2)
+		f.ClName = "app/Shop"
+		f.MethName = meth.name
+		f.MethType = meth.methType
+		f.PC = meth.pc
+		f.ExceptionPC = -1
+		_ = frames.PushFrame(jvmStack, f)
+	}
+
+	className := "java/lang/Throwable"
+	throwable := object.MakeEmptyObjectWithClassName(&className)
+	throwable.FieldTable["frameStackRef"] = object.Field{Fvalue: jvmStack}
+
+	steClassName := "java/lang/StackTraceElement"
+	array := object.Make1DimRefArray(&steClassName, 3)
+	elements := array.FieldTable["value"].Fvalue.([]*object.Object)
+	for i := range elements {
+		ste, _ := InstantiateFillIn(steClassName, nil)
+		elements[i] = ste.(*object.Object)
+	}
+
+	initStackTraceElements([]interface{}{array, throwable})
+
+	expected := []struct {
+		methodName string
+		lineNumber int64
+		sourceLine string
+	}{
+		{"buy", 12, "12"},
+		{"sell", -1, ""},
+		{"count", -2, ""},
+	}
+	for i, exp := range expected {
+		ste := elements[i].FieldTable
+		if ste["declaringClass"].Fvalue != "app/Shop" {
+			t.Errorf("TestInitStackTraceElementsFillsFields: element %d: expected declaringClass app/Shop, got %v",
+				i, ste["declaringClass"].Fvalue)
+		}
+		if ste["methodName"].Fvalue != exp.methodName {
+			t.Errorf("TestInitStackTraceElementsFillsFields: element %d: expected methodName %s, got %v",
+				i, exp.methodName, ste["methodName"].Fvalue)
+		}
+		if ste["fileName"].Fvalue != "Shop.java" {
+			t.Errorf("TestInitStackTraceElementsFillsFields: element %d: expected fileName Shop.java, got %v",
+				i, ste["fileName"].Fvalue)
+		}
+		if ste["lineNumber"].Fvalue != exp.lineNumber {
+			t.Errorf("TestInitStackTraceElementsFillsFields: element %d: expected lineNumber %d, got %v",
+				i, exp.lineNumber, ste["lineNumber"].Fvalue)
+		}
+		if ste["sourceLine"].Fvalue != exp.sourceLine {
+			t.Errorf("TestInitStackTraceElementsFillsFields: element %d: expected sourceLine %q, got %v",
+				i, exp.sourceLine, ste["sourceLine"].Fvalue)
+		}
+	}
+}
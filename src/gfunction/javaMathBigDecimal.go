@@ -0,0 +1,360 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by  the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"errors"
+	"fmt"
+	"jacobin/classloader"
+	"jacobin/excNames"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/statics"
+	"jacobin/types"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/*
+The BigDecimal object is implemented as in the JDK: an unscaled value, which is a Golang
+*big.Int held in the "value" field, and a scale, held in the "scale" field. The number it
+represents is unscaled value * 10^-scale, so 0.1 is 1 with a scale of 1 and is exact.
+*/
+
+var bigDecimalClassName = "java/math/BigDecimal"
+
+// The rounding modes, which have the ordinals of java.math.RoundingMode and the values of
+// the BigDecimal.ROUND_* constants.
+const (
+	roundUp = iota
+	roundDown
+	roundCeiling
+	roundFloor
+	roundHalfUp
+	roundHalfDown
+	roundHalfEven
+	roundUnnecessary
+)
+
+func Load_Math_Big_Decimal() {
+
+	MethodSignatures["java/math/BigDecimal.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  bigDecimalClinit,
+		}
+
+	MethodSignatures["java/math/BigDecimal.<init>(Ljava/lang/String;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  bigDecimalInitString,
+		}
+
+	MethodSignatures["java/math/BigDecimal.add(Ljava/math/BigDecimal;)Ljava/math/BigDecimal;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  bigDecimalAdd,
+		}
+
+	MethodSignatures["java/math/BigDecimal.multiply(Ljava/math/BigDecimal;)Ljava/math/BigDecimal;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  bigDecimalMultiply,
+		}
+
+	MethodSignatures["java/math/BigDecimal.setScale(II)Ljava/math/BigDecimal;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  bigDecimalSetScale,
+		}
+
+	MethodSignatures["java/math/BigDecimal.subtract(Ljava/math/BigDecimal;)Ljava/math/BigDecimal;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  bigDecimalSubtract,
+		}
+
+	MethodSignatures["java/math/BigDecimal.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  bigDecimalToString,
+		}
+
+	MethodSignatures["java/math/BigDecimal.valueOf(D)Ljava/math/BigDecimal;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  bigDecimalValueOf,
+		}
+
+}
+
+// setBigDecimalFields sets the unscaled value and the scale of a BigDecimal object.
+func setBigDecimalFields(obj *object.Object, unscaled *big.Int, scale int64) {
+	obj.FieldTable["value"] = object.Field{Ftype: types.BigInteger, Fvalue: unscaled}
+	obj.FieldTable["scale"] = object.Field{Ftype: types.Int, Fvalue: scale}
+}
+
+// makeBigDecimal creates a BigDecimal object with the given unscaled value and scale.
+func makeBigDecimal(unscaled *big.Int, scale int64) *object.Object {
+	obj := object.MakeEmptyObjectWithClassName(&bigDecimalClassName)
+	setBigDecimalFields(obj, unscaled, scale)
+	return obj
+}
+
+// getBigDecimalFields returns the unscaled value and the scale of a BigDecimal object.
+func getBigDecimalFields(obj *object.Object) (*big.Int, int64) {
+	return obj.FieldTable["value"].Fvalue.(*big.Int), obj.FieldTable["scale"].Fvalue.(int64)
+}
+
+// addStaticBigDecimal: Form a BigDecimal object, with a scale of 0, based on the parameter value.
+func addStaticBigDecimal(argName string, argValue int64) {
+	name := fmt.Sprintf("%s.%s", bigDecimalClassName, argName)
+	obj := makeBigDecimal(big.NewInt(argValue), 0)
+	_ = statics.AddStatic(name, statics.Static{Type: "Ljava/math/BigDecimal;", Value: obj})
+}
+
+// parseBigDecimal parses a string in the format that new BigDecimal(String) accepts: an
+// optional sign, digits with an optional decimal point, and an optional exponent, such as
+// -12.50 or 1.5E+3. It returns the unscaled value and the scale.
+func parseBigDecimal(str string) (*big.Int, int64, error) {
+	significand, exponentStr, hasExponent := str, "", false
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		significand, exponentStr, hasExponent = str[:i], str[i+1:], true
+	}
+
+	sign := ""
+	if strings.HasPrefix(significand, "-") || strings.HasPrefix(significand, "+") {
+		sign, significand = significand[:1], significand[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(significand, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil, 0, errors.New("character array is missing digits or has a non-digit")
+	}
+
+	var exponent int64
+	if hasExponent {
+		var err error
+		exponent, err = strconv.ParseInt(exponentStr, 10, 32)
+		if err != nil {
+			return nil, 0, errors.New("exponent is not a valid int")
+		}
+	}
+
+	scale := int64(len(fracPart)) - exponent
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return nil, 0, errors.New("scale out of range")
+	}
+	unscaled, _ := new(big.Int).SetString(sign+digits, 10)
+	return unscaled, scale, nil
+}
+
+// alignBigDecimals returns the unscaled values of the two BigDecimals at the larger of
+// their scales, and that scale, so that they can be added or subtracted.
+func alignBigDecimals(xx, yy *object.Object) (*big.Int, *big.Int, int64) {
+	xxValue, xxScale := getBigDecimalFields(xx)
+	yyValue, yyScale := getBigDecimalFields(yy)
+	if xxScale < yyScale {
+		return rescale(xxValue, yyScale-xxScale), yyValue, yyScale
+	}
+	return xxValue, rescale(yyValue, xxScale-yyScale), xxScale
+}
+
+// rescale multiplies an unscaled value by 10^digits, so that it has digits more digits of scale.
+func rescale(unscaled *big.Int, digits int64) *big.Int {
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(digits), nil)
+	return new(big.Int).Mul(unscaled, factor)
+}
+
+// roundDivide divides an unscaled value by 10^digits, so that it has digits fewer digits of
+// scale, and rounds the quotient as the rounding mode says. It returns an error if the
+// rounding mode is invalid, or is UNNECESSARY when rounding is necessary.
+func roundDivide(unscaled *big.Int, digits int64, roundingMode int64) (*big.Int, error) {
+	if roundingMode < roundUp || roundingMode > roundUnnecessary {
+		return nil, errors.New("Invalid rounding mode")
+	}
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(digits), nil)
+	quotient, remainder := new(big.Int).QuoRem(unscaled, divisor, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient, nil
+	}
+
+	// compare the discarded fraction to one half, which is how 2 * |remainder| compares to the divisor
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	cmpHalf := half.Cmp(divisor)
+	sign := unscaled.Sign()
+
+	var awayFromZero bool
+	switch roundingMode {
+	case roundUp:
+		awayFromZero = true
+	case roundDown:
+		awayFromZero = false
+	case roundCeiling:
+		awayFromZero = sign > 0
+	case roundFloor:
+		awayFromZero = sign < 0
+	case roundHalfUp:
+		awayFromZero = cmpHalf >= 0
+	case roundHalfDown:
+		awayFromZero = cmpHalf > 0
+	case roundHalfEven:
+		awayFromZero = cmpHalf > 0 || (cmpHalf == 0 && quotient.Bit(0) == 1)
+	case roundUnnecessary:
+		return nil, errors.New("Rounding necessary")
+	}
+
+	if awayFromZero {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient, nil
+}
+
+// "java/math/BigDecimal.<clinit>()V"
+func bigDecimalClinit([]interface{}) interface{} {
+	klass := classloader.MethAreaFetch(bigDecimalClassName)
+	if klass == nil {
+		errMsg := fmt.Sprintf("BigDecimal<clinit>: Expected %s to be in the MethodArea, but it was not", bigDecimalClassName)
+		_ = log.Log(errMsg, log.SEVERE)
+		return getGErrBlk(excNames.ClassNotLoadedException, errMsg)
+	}
+	if klass.Data.ClInit != types.ClInitRun {
+		addStaticBigDecimal("ONE", int64(1))
+		addStaticBigDecimal("TEN", int64(10))
+		addStaticBigDecimal("ZERO", int64(0))
+		klass.Data.ClInit = types.ClInitRun
+	}
+	return nil
+}
+
+// "java/math/BigDecimal.<init>(Ljava/lang/String;)V"
+func bigDecimalInitString(params []interface{}) interface{} {
+	// params[0]: base object
+	// params[1]: String object
+	obj := params[0].(*object.Object)
+	strObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		return getGErrBlk(excNames.NullPointerException, "BigDecimal.<init>: string argument is null")
+	}
+	str := object.GoStringFromStringObject(strObj)
+
+	unscaled, scale, err := parseBigDecimal(str)
+	if err != nil {
+		errMsg := fmt.Sprintf("<init> string (%s) is not a valid decimal number: %s", str, err.Error())
+		return getGErrBlk(excNames.NumberFormatException, errMsg)
+	}
+
+	setBigDecimalFields(obj, unscaled, scale)
+	return nil
+}
+
+// "java/math/BigDecimal.add(Ljava/math/BigDecimal;)Ljava/math/BigDecimal;"
+// The scale of the sum is the larger of the two scales.
+func bigDecimalAdd(params []interface{}) interface{} {
+	xx, yy, scale := alignBigDecimals(params[0].(*object.Object), params[1].(*object.Object))
+	return makeBigDecimal(new(big.Int).Add(xx, yy), scale)
+}
+
+// "java/math/BigDecimal.multiply(Ljava/math/BigDecimal;)Ljava/math/BigDecimal;"
+// The scale of the product is the sum of the two scales.
+func bigDecimalMultiply(params []interface{}) interface{} {
+	xxValue, xxScale := getBigDecimalFields(params[0].(*object.Object))
+	yyValue, yyScale := getBigDecimalFields(params[1].(*object.Object))
+	return makeBigDecimal(new(big.Int).Mul(xxValue, yyValue), xxScale+yyScale)
+}
+
+// "java/math/BigDecimal.setScale(II)Ljava/math/BigDecimal;"
+// Returns a BigDecimal with the given scale, rounding the value with the given rounding
+// mode if digits have to be dropped.
+func bigDecimalSetScale(params []interface{}) interface{} {
+	// params[0]: base object
+	// params[1]: new scale
+	// params[2]: rounding mode
+	unscaled, scale := getBigDecimalFields(params[0].(*object.Object))
+	newScale := params[1].(int64)
+	roundingMode := params[2].(int64)
+
+	if newScale >= scale {
+		return makeBigDecimal(rescale(unscaled, newScale-scale), newScale)
+	}
+
+	rounded, err := roundDivide(unscaled, scale-newScale, roundingMode)
+	if err != nil {
+		if roundingMode == roundUnnecessary {
+			return getGErrBlk(excNames.ArithmeticException, err.Error())
+		}
+		return getGErrBlk(excNames.IllegalArgumentException, err.Error())
+	}
+	return makeBigDecimal(rounded, newScale)
+}
+
+// "java/math/BigDecimal.subtract(Ljava/math/BigDecimal;)Ljava/math/BigDecimal;"
+// The scale of the difference is the larger of the two scales.
+func bigDecimalSubtract(params []interface{}) interface{} {
+	xx, yy, scale := alignBigDecimals(params[0].(*object.Object), params[1].(*object.Object))
+	return makeBigDecimal(new(big.Int).Sub(xx, yy), scale)
+}
+
+// "java/math/BigDecimal.toString()Ljava/lang/String;"
+// As in the JDK, the number is in plain notation (such as 12.50) if its scale is not
+// negative and its adjusted exponent is at least -6; otherwise it's in scientific notation,
+// (such as 1.250E+3), where the adjusted exponent is the exponent of the first digit.
+func bigDecimalToString(params []interface{}) interface{} {
+	unscaled, scale := getBigDecimalFields(params[0].(*object.Object))
+
+	coeff := new(big.Int).Abs(unscaled).String()
+	sign := ""
+	if unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	adjusted := -scale + int64(len(coeff)-1)
+
+	var str string
+	switch {
+	case scale == 0:
+		str = coeff
+	case scale > 0 && adjusted >= -6:
+		if int64(len(coeff)) > scale {
+			point := int64(len(coeff)) - scale
+			str = coeff[:point] + "." + coeff[point:]
+		} else {
+			str = "0." + strings.Repeat("0", int(scale)-len(coeff)) + coeff
+		}
+	default:
+		str = coeff[:1]
+		if len(coeff) > 1 {
+			str += "." + coeff[1:]
+		}
+		str += "E"
+		if adjusted >= 0 {
+			str += "+"
+		}
+		str += strconv.FormatInt(adjusted, 10)
+	}
+
+	return object.StringObjectFromGoString(sign + str)
+}
+
+// "java/math/BigDecimal.valueOf(D)Ljava/math/BigDecimal;"
+// As in the JDK, the BigDecimal is the one for the string that Double.toString() returns,
+// so valueOf(0.1) is exactly 0.1, rather than the binary value nearest to 0.1.
+func bigDecimalValueOf(params []interface{}) interface{} {
+	// params[0]: double
+	dd := params[0].(float64)
+	if math.IsNaN(dd) || math.IsInf(dd, 0) {
+		return getGErrBlk(excNames.NumberFormatException, "Infinite or NaN")
+	}
+
+	unscaled, scale, err := parseBigDecimal(javaFloatingPointToString(dd, 64))
+	if err != nil {
+		return getGErrBlk(excNames.NumberFormatException, err.Error())
+	}
+	return makeBigDecimal(unscaled, scale)
+}
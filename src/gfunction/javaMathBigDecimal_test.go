@@ -0,0 +1,132 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"math"
+	"testing"
+)
+
+// newBigDecimal does what new BigDecimal(str) does
+func newBigDecimal(t *testing.T, str string) *object.Object {
+	obj := object.MakeEmptyObjectWithClassName(&bigDecimalClassName)
+	if ret := bigDecimalInitString([]interface{}{obj, object.StringObjectFromGoString(str)}); ret != nil {
+		t.Fatalf("new BigDecimal(%q): unexpected error: %v", str, ret)
+	}
+	return obj
+}
+
+func bigDecimalString(obj interface{}) string {
+	return object.GoStringFromStringObject(bigDecimalToString([]interface{}{obj}).(*object.Object))
+}
+
+func TestBigDecimalStringRoundTrip(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct{ in, out string }{
+		{"0", "0"},
+		{"12.50", "12.50"},
+		{"-0.001", "-0.001"},
+		{"+7", "7"},
+		{".5", "0.5"},
+		{"0.0000001", "1E-7"},
+		{"1E+3", "1E+3"},
+		{"1.250e3", "1250"},
+		{"-4.5E-2", "-0.045"},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+	}
+	for _, tt := range tests {
+		if got := bigDecimalString(newBigDecimal(t, tt.in)); got != tt.out {
+			t.Errorf("new BigDecimal(%q).toString(): expected %s, got %s", tt.in, tt.out, got)
+		}
+	}
+
+	for _, str := range []string{"", ".", "1.2.3", "12a", "1E", "1E+", "--1", " 1"} {
+		obj := object.MakeEmptyObjectWithClassName(&bigDecimalClassName)
+		ret := bigDecimalInitString([]interface{}{obj, object.StringObjectFromGoString(str)})
+		if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("new BigDecimal(%q): expected a NumberFormatException, got %v", str, ret)
+		}
+	}
+}
+
+// 0.1 + 0.2 is exactly 0.3 in decimal, unlike in binary floating point
+func TestBigDecimalExactArithmetic(t *testing.T) {
+	globals.InitGlobals("test")
+
+	sum := bigDecimalAdd([]interface{}{newBigDecimal(t, "0.1"), newBigDecimal(t, "0.2")})
+	if got := bigDecimalString(sum); got != "0.3" {
+		t.Errorf("0.1 + 0.2: expected 0.3, got %s", got)
+	}
+
+	tenth := bigDecimalValueOf([]interface{}{0.1, 0.1})
+	fifth := bigDecimalValueOf([]interface{}{0.2, 0.2})
+	if got := bigDecimalString(bigDecimalAdd([]interface{}{tenth, fifth})); got != "0.3" {
+		t.Errorf("valueOf(0.1) + valueOf(0.2): expected 0.3, got %s", got)
+	}
+	if got := bigDecimalString(bigDecimalValueOf([]interface{}{1e10, 1e10})); got != "1.0E+10" {
+		t.Errorf("valueOf(1e10): expected 1.0E+10, got %s", got)
+	}
+	ret := bigDecimalValueOf([]interface{}{math.Inf(1), math.Inf(1)})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+		t.Errorf("valueOf(Infinity): expected a NumberFormatException, got %v", ret)
+	}
+
+	// the scale of a sum or difference is the larger scale; of a product, the sum of the scales
+	if got := bigDecimalString(bigDecimalSubtract([]interface{}{newBigDecimal(t, "1"), newBigDecimal(t, "0.75")})); got != "0.25" {
+		t.Errorf("1 - 0.75: expected 0.25, got %s", got)
+	}
+	if got := bigDecimalString(bigDecimalAdd([]interface{}{newBigDecimal(t, "1.10"), newBigDecimal(t, "2.2")})); got != "3.30" {
+		t.Errorf("1.10 + 2.2: expected 3.30, got %s", got)
+	}
+	if got := bigDecimalString(bigDecimalMultiply([]interface{}{newBigDecimal(t, "1.5"), newBigDecimal(t, "-2.25")})); got != "-3.375" {
+		t.Errorf("1.5 * -2.25: expected -3.375, got %s", got)
+	}
+}
+
+func TestBigDecimalSetScale(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		in    string
+		scale int64
+		mode  int64
+		out   string
+	}{
+		{"1.5", 3, roundUnnecessary, "1.500"},
+		{"2.345", 2, roundHalfUp, "2.35"},
+		{"2.345", 2, roundHalfDown, "2.34"},
+		{"2.345", 2, roundHalfEven, "2.34"},
+		{"2.355", 2, roundHalfEven, "2.36"},
+		{"-2.345", 2, roundHalfUp, "-2.35"},
+		{"2.341", 2, roundUp, "2.35"},
+		{"-2.349", 2, roundDown, "-2.34"},
+		{"-2.341", 2, roundCeiling, "-2.34"},
+		{"-2.341", 2, roundFloor, "-2.35"},
+		{"2.341", 2, roundFloor, "2.34"},
+		{"1234.5", -2, roundHalfUp, "1.2E+3"},
+		{"2.300", 1, roundUnnecessary, "2.3"},
+	}
+	for _, tt := range tests {
+		ret := bigDecimalSetScale([]interface{}{newBigDecimal(t, tt.in), tt.scale, tt.mode})
+		if got := bigDecimalString(ret); got != tt.out {
+			t.Errorf("%s.setScale(%d, %d): expected %s, got %s", tt.in, tt.scale, tt.mode, tt.out, got)
+		}
+	}
+
+	ret := bigDecimalSetScale([]interface{}{newBigDecimal(t, "2.345"), int64(2), int64(roundUnnecessary)})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.ArithmeticException {
+		t.Errorf("2.345.setScale(2, UNNECESSARY): expected an ArithmeticException, got %v", ret)
+	}
+	ret = bigDecimalSetScale([]interface{}{newBigDecimal(t, "2.345"), int64(2), int64(8)})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.IllegalArgumentException {
+		t.Errorf("setScale() with an invalid rounding mode: expected an IllegalArgumentException, got %v", ret)
+	}
+}
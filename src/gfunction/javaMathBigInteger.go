@@ -483,6 +483,7 @@ func bigIntegerInitByteArray(params []interface{}) interface{} {
 	zz := BytesToBigInt(bytes)
 
 	// Update base object and return nil
+	fld.Ftype = types.BigInteger
 	fld.Fvalue = zz
 	obj.FieldTable["value"] = fld
 	return nil
@@ -502,6 +503,7 @@ func bigIntegerInitProbablyPrime(params []interface{}) interface{} {
 	zz, errMsg := getPrime(int(bitLength))
 	if zz != nil {
 		// Update base object and return nil
+		fld.Ftype = types.BigInteger
 		fld.Fvalue = zz
 		obj.FieldTable["value"] = fld
 		return nil
@@ -531,6 +533,7 @@ func bigIntegerInitRandom(params []interface{}) interface{} {
 	}
 
 	// Update base object and return nil
+	fldBase.Ftype = types.BigInteger
 	fldBase.Fvalue = zz
 	objBase.FieldTable["value"] = fldBase
 	return nil
@@ -542,15 +545,20 @@ func bigIntegerInitString(params []interface{}) interface{} {
 	// params[1]: String object
 	obj := params[0].(*object.Object)
 	fld := obj.FieldTable["value"]
-	str := object.GoStringFromStringObject(params[1].(*object.Object))
+	strObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		return getGErrBlk(excNames.NullPointerException, "BigInteger.<init>: string argument is null")
+	}
+	str := object.GoStringFromStringObject(strObj)
 	var zz = new(big.Int)
-	_, ok := zz.SetString(str, 10)
+	_, ok = zz.SetString(str, 10)
 	if !ok {
 		errMsg := fmt.Sprintf("<init> string (%s) not all numerics", str)
 		return getGErrBlk(excNames.NumberFormatException, errMsg)
 	}
 
 	// Update base object and return nil
+	fld.Ftype = types.BigInteger
 	fld.Fvalue = zz
 	obj.FieldTable["value"] = fld
 	return nil
@@ -563,16 +571,21 @@ func bigIntegerInitStringRadix(params []interface{}) interface{} {
 	// params[2]: radix int64
 	obj := params[0].(*object.Object)
 	fld := obj.FieldTable["value"]
-	str := object.GoStringFromStringObject(params[1].(*object.Object))
+	strObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(strObj) {
+		return getGErrBlk(excNames.NullPointerException, "BigInteger.<init>: string argument is null")
+	}
+	str := object.GoStringFromStringObject(strObj)
 	rdx := params[2].(int64)
 	var zz = new(big.Int)
-	_, ok := zz.SetString(str, int(rdx))
+	_, ok = zz.SetString(str, int(rdx))
 	if !ok {
 		errMsg := fmt.Sprintf("<init> string (%s) not all numerics or the radix (%d) is invalid", str, rdx)
 		return getGErrBlk(excNames.NumberFormatException, errMsg)
 	}
 
 	// Update base object and return nil
+	fld.Ftype = types.BigInteger
 	fld.Fvalue = zz
 	obj.FieldTable["value"] = fld
 	return nil
@@ -0,0 +1,93 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"math/big"
+	"testing"
+)
+
+// newBigInteger does what new BigInteger(str) does
+func newBigInteger(t *testing.T, str string) *object.Object {
+	obj := object.MakeEmptyObjectWithClassName(&bigIntegerClassName)
+	if ret := bigIntegerInitString([]interface{}{obj, object.StringObjectFromGoString(str)}); ret != nil {
+		t.Fatalf("new BigInteger(%q): unexpected error: %v", str, ret)
+	}
+	return obj
+}
+
+func bigIntegerString(obj interface{}) string {
+	return object.GoStringFromStringObject(bigIntegerToString([]interface{}{obj}).(*object.Object))
+}
+
+func TestBigIntegerStringRoundTrip(t *testing.T) {
+	globals.InitGlobals("test")
+
+	for _, str := range []string{"0", "-1", "123456789012345678901234567890", "-98765432109876543210"} {
+		obj := newBigInteger(t, str)
+		if obj.FieldTable["value"].Ftype != types.BigInteger {
+			t.Errorf("new BigInteger(%q): expected the value field to be a BigInteger, got %s",
+				str, obj.FieldTable["value"].Ftype)
+		}
+		if got := bigIntegerString(obj); got != str {
+			t.Errorf("new BigInteger(%q).toString(): got %s", str, got)
+		}
+	}
+
+	for _, str := range []string{"", "12a", "1.5", " 7", "--3"} {
+		obj := object.MakeEmptyObjectWithClassName(&bigIntegerClassName)
+		ret := bigIntegerInitString([]interface{}{obj, object.StringObjectFromGoString(str)})
+		if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NumberFormatException {
+			t.Errorf("new BigInteger(%q): expected a NumberFormatException, got %v", str, ret)
+		}
+	}
+
+	obj := object.MakeEmptyObjectWithClassName(&bigIntegerClassName)
+	ret := bigIntegerInitString([]interface{}{obj, object.Null})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("new BigInteger(null): expected a NullPointerException, got %v", ret)
+	}
+}
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	globals.InitGlobals("test")
+
+	// Long.MAX_VALUE + Long.MAX_VALUE overflows a long, but not a BigInteger
+	maxLong := bigIntegerValueOf([]interface{}{int64(9223372036854775807), int64(9223372036854775807)})
+	sum := bigIntegerAdd([]interface{}{maxLong, maxLong})
+	if got := bigIntegerString(sum); got != "18446744073709551614" {
+		t.Errorf("Long.MAX_VALUE + Long.MAX_VALUE: expected 18446744073709551614, got %s", got)
+	}
+
+	xx := newBigInteger(t, "100000000000000000000000000000")
+	yy := newBigInteger(t, "99999999999999999999999999999")
+	if got := bigIntegerString(bigIntegerAdd([]interface{}{xx, yy})); got != "199999999999999999999999999999" {
+		t.Errorf("add: got %s", got)
+	}
+	if got := bigIntegerString(bigIntegerSubtract([]interface{}{yy, xx})); got != "-1" {
+		t.Errorf("subtract: expected -1, got %s", got)
+	}
+	expected := new(big.Int).Mul(xx.FieldTable["value"].Fvalue.(*big.Int), yy.FieldTable["value"].Fvalue.(*big.Int))
+	if got := bigIntegerString(bigIntegerMultiply([]interface{}{xx, yy})); got != expected.String() {
+		t.Errorf("multiply: expected %s, got %s", expected.String(), got)
+	}
+
+	// unlike remainder(), mod() never returns a negative number
+	if got := bigIntegerString(bigIntegerMod([]interface{}{newBigInteger(t, "-7"), newBigInteger(t, "3")})); got != "2" {
+		t.Errorf("-7 mod 3: expected 2, got %s", got)
+	}
+	for _, modulus := range []string{"0", "-3"} {
+		ret := bigIntegerMod([]interface{}{xx, newBigInteger(t, modulus)})
+		if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.ArithmeticException {
+			t.Errorf("mod %s: expected an ArithmeticException, got %v", modulus, ret)
+		}
+	}
+}
@@ -6,22 +6,214 @@
 
 package gfunction
 
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
 // Implementation of some of the functions in Java/nio/charset/Charset.
 
+// Canonical names of the character sets that Jacobin can encode.
+const (
+	CharsetUTF8      = "UTF-8"
+	CharsetUSASCII   = "US-ASCII"
+	CharsetISO8859_1 = "ISO-8859-1"
+)
+
 func Load_Nio_Charset_Charset() {
 
-	// Class initialisation for Console.
 	MethodSignatures["java/nio/charset/Charset.<clinit>()V"] =
 		GMeth{
 			ParamSlots: 0,
-			GFunction:  trapFunction,
+			GFunction:  justReturn,
 		}
 
 	// Get the default character set.
 	MethodSignatures["java/nio/charset/Charset.defaultCharset()Ljava/nio/charset/Charset;"] =
 		GMeth{
 			ParamSlots: 0,
-			GFunction:  trapFunction,
+			GFunction:  charsetDefaultCharset,
+		}
+
+	MethodSignatures["java/nio/charset/Charset.forName(Ljava/lang/String;)Ljava/nio/charset/Charset;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  charsetForName,
+		}
+
+	MethodSignatures["java/nio/charset/Charset.name()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  charsetName,
+		}
+
+	MethodSignatures["java/nio/charset/Charset.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  charsetName,
+		}
+
+}
+
+// "java/nio/charset/Charset.defaultCharset()Ljava/nio/charset/Charset;"
+func charsetDefaultCharset([]interface{}) interface{} {
+	return newCharset(defaultCharsetName())
+}
+
+// "java/nio/charset/Charset.forName(Ljava/lang/String;)Ljava/nio/charset/Charset;"
+func charsetForName(params []interface{}) interface{} {
+	nameObj, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(nameObj) {
+		return getGErrBlk(excNames.IllegalArgumentException, "Null charset name")
+	}
+
+	name := object.GoStringFromStringObject(nameObj)
+	canonical := canonicalCharsetName(name)
+	if canonical == "" {
+		return getGErrBlk(excNames.UnsupportedCharsetException, name)
+	}
+	return newCharset(canonical)
+}
+
+// "java/nio/charset/Charset.name()Ljava/lang/String;"
+// "java/nio/charset/Charset.toString()Ljava/lang/String;"
+func charsetName(params []interface{}) interface{} {
+	nameObj, ok := params[0].(*object.Object).FieldTable["name"].Fvalue.(*object.Object)
+	if !ok {
+		errMsg := "charsetName: Charset object lacks a name field"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+	return nameObj
+}
+
+// newCharset creates a Charset object for a supported charset. As in the JDK, the charset's
+// canonical name is in the "name" field.
+func newCharset(canonicalName string) *object.Object {
+	className := "java/nio/charset/Charset"
+	obj := object.MakeEmptyObjectWithClassName(&className)
+	nameObj := object.StringObjectFromGoString(canonicalName)
+	obj.FieldTable["name"] = object.Field{Ftype: "Ljava/lang/String;", Fvalue: nameObj}
+	return obj
+}
+
+// canonicalCharsetName maps a character set name or one of its common aliases to the
+// canonical name of a character set Jacobin supports. It returns "" for any other name.
+func canonicalCharsetName(name string) string {
+	switch strings.ToUpper(name) {
+	case "UTF-8", "UTF8":
+		return CharsetUTF8
+	case "US-ASCII", "ASCII", "US_ASCII", "ISO646-US":
+		return CharsetUSASCII
+	case "ISO-8859-1", "ISO8859-1", "ISO8859_1", "ISO_8859_1", "LATIN1", "L1":
+		return CharsetISO8859_1
+	default:
+		return ""
+	}
+}
+
+// defaultCharsetName returns the canonical name of the default charset, which is set
+// by -Dfile.encoding. If that charset is one Jacobin cannot encode, UTF-8 is used.
+func defaultCharsetName() string {
+	charsetName := canonicalCharsetName(globals.GetGlobalRef().FileEncoding)
+	if charsetName == "" {
+		return CharsetUTF8
+	}
+	return charsetName
+}
+
+// getCharsetName returns the canonical name of the character set passed as a parameter,
+// either as a Charset object (whose name is in its "name" field, as in the JDK) or as a
+// String. Character sets that Jacobin cannot encode result in an UnsupportedOperationException.
+func getCharsetName(param interface{}, funcName string) (string, *GErrBlk) {
+	obj, ok := param.(*object.Object)
+	if !ok || object.IsNull(obj) {
+		errMsg := funcName + ": character set argument is null"
+		return "", getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	nameObj := obj
+	if fld, ok := obj.FieldTable["name"]; ok {
+		nameObj, ok = fld.Fvalue.(*object.Object)
+		if !ok || object.IsNull(nameObj) {
+			errMsg := funcName + ": Charset object lacks a name"
+			return "", getGErrBlk(excNames.IllegalArgumentException, errMsg)
+		}
+	}
+
+	name := object.GoStringFromStringObject(nameObj)
+	canonical := canonicalCharsetName(name)
+	if canonical == "" {
+		errMsg := funcName + ": character set not yet supported: " + name
+		return "", getGErrBlk(excNames.UnsupportedOperationException, errMsg)
+	}
+	return canonical, nil
+}
+
+// encodeChars encodes Java chars (UTF-16 code units) in the given supported character set.
+// As with the JDK's encoders, characters that cannot be encoded are replaced with '?'.
+func encodeChars(chars []int64, charsetName string) []byte {
+	switch charsetName {
+	case CharsetUSASCII, CharsetISO8859_1:
+		maxChar := int64(0xFF)
+		if charsetName == CharsetUSASCII {
+			maxChar = 0x7F
 		}
+		bytes := make([]byte, len(chars))
+		for i, ch := range chars {
+			if ch >= 0 && ch <= maxChar {
+				bytes[i] = byte(ch)
+			} else {
+				bytes[i] = '?'
+			}
+		}
+		return bytes
+	default: // UTF-8
+		var bytes []byte
+		for i := 0; i < len(chars); i++ {
+			r := rune(uint16(chars[i]))
+			if utf16.IsSurrogate(r) {
+				if i+1 < len(chars) {
+					pair := utf16.DecodeRune(r, rune(uint16(chars[i+1])))
+					if pair != unicode.ReplacementChar {
+						bytes = utf8.AppendRune(bytes, pair)
+						i++
+						continue
+					}
+				}
+				r = '?' // an unpaired surrogate
+			}
+			bytes = utf8.AppendRune(bytes, r)
+		}
+		return bytes
+	}
+}
 
+// decodeBytes decodes bytes in the given supported character set into a Go string.
+// Invalid UTF-8 sequences become U+FFFD, as with the JDK's decoders.
+func decodeBytes(bytes []byte, charsetName string) string {
+	switch charsetName {
+	case CharsetUSASCII:
+		runes := make([]rune, len(bytes))
+		for i, b := range bytes {
+			if b <= 0x7F {
+				runes[i] = rune(b)
+			} else {
+				runes[i] = unicode.ReplacementChar
+			}
+		}
+		return string(runes)
+	case CharsetISO8859_1:
+		runes := make([]rune, len(bytes))
+		for i, b := range bytes {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default: // UTF-8
+		return strings.ToValidUTF8(string(bytes), "\uFFFD")
+	}
 }
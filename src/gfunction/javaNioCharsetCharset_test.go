@@ -0,0 +1,94 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"bytes"
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"testing"
+)
+
+// charsetNameOf calls Charset.name() and returns the result as a Go string
+func charsetNameOf(t *testing.T, charset interface{}) string {
+	cs, ok := charset.(*object.Object)
+	if !ok {
+		t.Fatalf("expected a Charset object, got %v", charset)
+	}
+	return object.GoStringFromStringObject(charsetName([]interface{}{cs}).(*object.Object))
+}
+
+func TestCharsetForNameKnown(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		name, canonical string
+	}{
+		{"UTF-8", "UTF-8"},
+		{"utf8", "UTF-8"},
+		{"US-ASCII", "US-ASCII"},
+		{"ascii", "US-ASCII"},
+		{"ISO-8859-1", "ISO-8859-1"},
+		{"latin1", "ISO-8859-1"},
+	}
+
+	for _, tt := range tests {
+		cs := charsetForName([]interface{}{object.StringObjectFromGoString(tt.name)})
+		if got := charsetNameOf(t, cs); got != tt.canonical {
+			t.Errorf("forName(%q).name(): expected %q, got %q", tt.name, tt.canonical, got)
+		}
+	}
+}
+
+func TestCharsetForNameUnknown(t *testing.T) {
+	globals.InitGlobals("test")
+
+	ret := charsetForName([]interface{}{object.StringObjectFromGoString("X-NO-SUCH-CHARSET")})
+	gErr, ok := ret.(*GErrBlk)
+	if !ok || gErr.ExceptionType != excNames.UnsupportedCharsetException {
+		t.Errorf("forName of an unknown charset: expected UnsupportedCharsetException, got %v", ret)
+	}
+
+	ret = charsetForName([]interface{}{object.Null})
+	gErr, ok = ret.(*GErrBlk)
+	if !ok || gErr.ExceptionType != excNames.IllegalArgumentException {
+		t.Errorf("forName(null): expected IllegalArgumentException, got %v", ret)
+	}
+}
+
+func TestCharsetDefaultCharset(t *testing.T) {
+	globals.InitGlobals("test")
+
+	if got := charsetNameOf(t, charsetDefaultCharset(nil)); got != "UTF-8" {
+		t.Errorf("defaultCharset(): expected \"UTF-8\", got %q", got)
+	}
+
+	globals.GetGlobalRef().FileEncoding = "latin1"
+	if got := charsetNameOf(t, charsetDefaultCharset(nil)); got != "ISO-8859-1" {
+		t.Errorf("defaultCharset() with file.encoding=latin1: expected \"ISO-8859-1\", got %q", got)
+	}
+}
+
+// a Charset from forName() can be handed to the charset-aware writers
+func TestCharsetForNameUSASCIIEncoding(t *testing.T) {
+	globals.InitGlobals("test")
+
+	cs := charsetForName([]interface{}{object.StringObjectFromGoString("US-ASCII")})
+	name, gerr := getCharsetName(cs, "TestCharsetForNameUSASCIIEncoding")
+	if gerr != nil {
+		t.Fatalf("getCharsetName: unexpected error: %s", gerr.ErrMsg)
+	}
+
+	encoded := encodeChars(object.JavaCharArrayFromGoString("naïve"), name)
+	if !bytes.Equal(encoded, []byte("na?ve")) {
+		t.Errorf("US-ASCII encoding: expected \"na?ve\", got %q", encoded)
+	}
+	if decoded := decodeBytes([]byte("a\xE9b"), name); decoded != "a\uFFFDb" {
+		t.Errorf("US-ASCII decoding: expected \"a\\uFFFDb\", got %q", decoded)
+	}
+}
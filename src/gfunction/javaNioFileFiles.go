@@ -0,0 +1,101 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"fmt"
+	"jacobin/excNames"
+	"jacobin/object"
+	"os"
+)
+
+// Implementation of some of the static functions in java/nio/file/Files.
+
+func Load_Nio_File_Files() {
+
+	MethodSignatures["java/nio/file/Files.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/nio/file/Files.readAllBytes(Ljava/nio/file/Path;)[B"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  filesReadAllBytes,
+		}
+
+	MethodSignatures["java/nio/file/Files.write(Ljava/nio/file/Path;[B)Ljava/nio/file/Path;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  filesWrite,
+		}
+
+	// The form javac emits for Files.write(path, bytes): the OpenOption varargs array is empty.
+	MethodSignatures["java/nio/file/Files.write(Ljava/nio/file/Path;[B[Ljava/nio/file/OpenOption;)Ljava/nio/file/Path;"] =
+		GMeth{
+			ParamSlots: 3,
+			GFunction:  filesWriteWithOptions,
+		}
+
+}
+
+// "java/nio/file/Files.readAllBytes(Ljava/nio/file/Path;)[B"
+func filesReadAllBytes(params []interface{}) interface{} {
+	pathStr, gerr := pathGetString(params[0], "filesReadAllBytes")
+	if gerr != nil {
+		return gerr
+	}
+
+	bytes, err := os.ReadFile(pathStr)
+	if err != nil {
+		errMsg := fmt.Sprintf("os.ReadFile(%s) failed, reason: %s", pathStr, err.Error())
+		return getGErrBlk(excNames.IOException, errMsg)
+	}
+
+	return object.MakeArrayFromRawArray(bytes)
+}
+
+// "java/nio/file/Files.write(Ljava/nio/file/Path;[B)Ljava/nio/file/Path;"
+// As with the JDK's default options, the file is created if need be and otherwise truncated.
+func filesWrite(params []interface{}) interface{} {
+	pathStr, gerr := pathGetString(params[0], "filesWrite")
+	if gerr != nil {
+		return gerr
+	}
+
+	arrObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(arrObj) {
+		errMsg := "filesWrite: byte array argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	bytes, ok := arrObj.FieldTable["value"].Fvalue.([]byte)
+	if !ok {
+		errMsg := "filesWrite: byte array argument lacks a \"value\" field"
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	err := os.WriteFile(pathStr, bytes, CreateFilePermissions)
+	if err != nil {
+		errMsg := fmt.Sprintf("os.WriteFile(%s) failed, reason: %s", pathStr, err.Error())
+		return getGErrBlk(excNames.IOException, errMsg)
+	}
+
+	return params[0]
+}
+
+// "java/nio/file/Files.write(Ljava/nio/file/Path;[B[Ljava/nio/file/OpenOption;)Ljava/nio/file/Path;"
+// Only the default options are supported, so any explicit OpenOption is rejected.
+func filesWriteWithOptions(params []interface{}) interface{} {
+	if optsObj, ok := params[2].(*object.Object); ok && !object.IsNull(optsObj) {
+		if opts, ok := optsObj.FieldTable["value"].Fvalue.([]*object.Object); ok && len(opts) > 0 {
+			errMsg := "filesWriteWithOptions: OpenOption arguments are not yet supported"
+			return getGErrBlk(excNames.UnsupportedOperationException, errMsg)
+		}
+	}
+	return filesWrite(params[:2])
+}
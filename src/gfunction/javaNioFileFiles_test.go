@@ -0,0 +1,104 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"bytes"
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilesWriteThenReadAllBytes(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := newPath(filepath.Join(t.TempDir(), "roundtrip.bin"))
+	data := []byte{0x00, 'J', 'a', 'c', 'o', 'b', 'i', 'n', 0xFF, 0x80}
+
+	ret := filesWrite([]interface{}{path, object.MakeArrayFromRawArray(data)})
+	if ret != path {
+		t.Fatalf("filesWrite: expected the Path argument to be returned, got %v", ret)
+	}
+
+	ret = filesReadAllBytes([]interface{}{path})
+	arr, ok := ret.(*object.Object)
+	if !ok {
+		t.Fatalf("filesReadAllBytes: expected a byte array object, got %T", ret)
+	}
+	readBack := arr.FieldTable["value"].Fvalue.([]byte)
+	if !bytes.Equal(readBack, data) {
+		t.Errorf("filesReadAllBytes: expected %v, got %v", data, readBack)
+	}
+}
+
+func TestFilesWriteTruncatesExistingFile(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := newPath(filepath.Join(t.TempDir(), "truncate.txt"))
+	filesWrite([]interface{}{path, object.MakeArrayFromRawArray([]byte("a longer first version"))})
+	filesWrite([]interface{}{path, object.MakeArrayFromRawArray([]byte("short"))})
+
+	arr := filesReadAllBytes([]interface{}{path}).(*object.Object)
+	if string(arr.FieldTable["value"].Fvalue.([]byte)) != "short" {
+		t.Errorf("filesWrite: expected the file to be truncated, got %q",
+			string(arr.FieldTable["value"].Fvalue.([]byte)))
+	}
+}
+
+func TestFilesWriteWithEmptyOptions(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := newPath(filepath.Join(t.TempDir(), "options.txt"))
+	className := "java/nio/file/OpenOption"
+	opts := object.Make1DimRefArray(&className, 0)
+
+	ret := filesWriteWithOptions([]interface{}{path, object.MakeArrayFromRawArray([]byte("ok")), opts})
+	if ret != path {
+		t.Fatalf("filesWriteWithOptions: expected the Path argument to be returned, got %v", ret)
+	}
+
+	opts = object.Make1DimRefArray(&className, 1)
+	ret = filesWriteWithOptions([]interface{}{path, object.MakeArrayFromRawArray([]byte("ok")), opts})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.UnsupportedOperationException {
+		t.Errorf("filesWriteWithOptions: expected UnsupportedOperationException, got %v", ret)
+	}
+}
+
+func TestFilesReadAllBytesMissingFile(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := newPath(filepath.Join(t.TempDir(), "no-such-file"))
+	ret := filesReadAllBytes([]interface{}{path})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.IOException {
+		t.Errorf("filesReadAllBytes: expected IOException for a missing file, got %v", ret)
+	}
+}
+
+func TestFilesWriteToDirectoryFails(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := newPath(t.TempDir())
+	ret := filesWrite([]interface{}{path, object.MakeArrayFromRawArray([]byte("x"))})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.IOException {
+		t.Errorf("filesWrite: expected IOException when writing to a directory, got %v", ret)
+	}
+}
+
+func TestFilesReadAllBytesNullPath(t *testing.T) {
+	globals.InitGlobals("test")
+
+	ret := filesReadAllBytes([]interface{}{object.Null})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("filesReadAllBytes: expected NullPointerException for a null Path, got %v", ret)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/object"
+	"jacobin/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Implementation of a minimal java/nio/file/Path.
+// Strategy: a Path is an object whose FilePath field holds the path string. The string is
+// neither made absolute nor normalized: as in the JDK, "." and ".." segments are kept as
+// written. This is all that Files needs to locate a file.
+
+var classNamePath = "java/nio/file/Path"
+
+func Load_Nio_File_Path() {
+
+	MethodSignatures["java/nio/file/Path.resolve(Ljava/lang/String;)Ljava/nio/file/Path;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  pathResolve,
+		}
+
+	MethodSignatures["java/nio/file/Path.toString()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  pathToString,
+		}
+
+}
+
+// "java/nio/file/Path.resolve(Ljava/lang/String;)Ljava/nio/file/Path;"
+// As in the JDK, an absolute argument is returned as is and an empty one returns this path.
+// Otherwise, the argument is joined to this path.
+func pathResolve(params []interface{}) interface{} {
+	pathStr, gerr := pathGetString(params[0], "pathResolve")
+	if gerr != nil {
+		return gerr
+	}
+
+	otherObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(otherObj) {
+		errMsg := "pathResolve: String argument is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	otherStr := object.GoStringFromStringObject(otherObj)
+
+	switch {
+	case filepath.IsAbs(otherStr):
+		return newPath(pathJoin(otherStr))
+	case otherStr == "":
+		return params[0]
+	default:
+		return newPath(pathJoin(pathStr, otherStr))
+	}
+}
+
+// "java/nio/file/Path.toString()Ljava/lang/String;"
+func pathToString(params []interface{}) interface{} {
+	pathStr, gerr := pathGetString(params[0], "pathToString")
+	if gerr != nil {
+		return gerr
+	}
+	return object.StringObjectFromGoString(pathStr)
+}
+
+// newPath creates a Path object for the given path string.
+func newPath(pathStr string) *object.Object {
+	obj := object.MakeEmptyObjectWithClassName(&classNamePath)
+	obj.FieldTable[FilePath] = object.Field{Ftype: types.ByteArray, Fvalue: []byte(pathStr)}
+	return obj
+}
+
+// pathJoin joins path segments with the separator, as the JDK does. Unlike filepath.Join(),
+// it doesn't clean the path, which would drop "." segments and resolve ".." ones: it only
+// skips empty segments and removes redundant separators and any trailing one.
+func pathJoin(segments ...string) string {
+	var nonEmpty []string
+	for _, segment := range segments {
+		if segment != "" {
+			nonEmpty = append(nonEmpty, segment)
+		}
+	}
+	joined := strings.Join(nonEmpty, string(filepath.Separator))
+
+	path := make([]byte, 0, len(joined))
+	for i := 0; i < len(joined); i++ {
+		if os.IsPathSeparator(joined[i]) && len(path) > 0 && os.IsPathSeparator(path[len(path)-1]) {
+			continue
+		}
+		path = append(path, joined[i])
+	}
+	if len(path) > 1 && os.IsPathSeparator(path[len(path)-1]) { // a root keeps its separator
+		path = path[:len(path)-1]
+	}
+	return string(path)
+}
+
+// pathGetString returns the path string held in a Path object passed as a parameter.
+func pathGetString(param interface{}, funcName string) (string, *GErrBlk) {
+	pathObj, ok := param.(*object.Object)
+	if !ok || object.IsNull(pathObj) {
+		errMsg := funcName + ": Path argument is null"
+		return "", getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	pathBytes, ok := pathObj.FieldTable[FilePath].Fvalue.([]byte)
+	if !ok {
+		errMsg := funcName + ": Path object lacks a FilePath field"
+		return "", getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+	return string(pathBytes), nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/object"
+)
+
+// Implementation of java/nio/file/Paths, which creates the Path objects used by Files.
+
+func Load_Nio_File_Paths() {
+
+	MethodSignatures["java/nio/file/Paths.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/nio/file/Paths.get(Ljava/lang/String;[Ljava/lang/String;)Ljava/nio/file/Path;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  pathsGet,
+		}
+
+}
+
+// "java/nio/file/Paths.get(Ljava/lang/String;[Ljava/lang/String;)Ljava/nio/file/Path;"
+// The first segment and any further segments in the varargs array are joined into one path,
+// which is not normalized (see pathJoin()).
+func pathsGet(params []interface{}) interface{} {
+	firstObj, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(firstObj) {
+		errMsg := "pathsGet: first path segment is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	segments := []string{object.GoStringFromStringObject(firstObj)}
+
+	if moreObj, ok := params[1].(*object.Object); ok && !object.IsNull(moreObj) {
+		more, ok := moreObj.FieldTable["value"].Fvalue.([]*object.Object)
+		if !ok {
+			errMsg := "pathsGet: varargs argument is not a String array"
+			return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+		}
+		for _, segment := range more {
+			if object.IsNull(segment) {
+				errMsg := "pathsGet: path segment is null"
+				return getGErrBlk(excNames.NullPointerException, errMsg)
+			}
+			segments = append(segments, object.GoStringFromStringObject(segment))
+		}
+	}
+
+	return newPath(pathJoin(segments...))
+}
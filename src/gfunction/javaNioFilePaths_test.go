@@ -0,0 +1,142 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"path/filepath"
+	"testing"
+)
+
+// makeStringArray returns a Java String[] holding the given strings
+func makeStringArray(strs ...string) *object.Object {
+	className := "java/lang/String"
+	arr := object.Make1DimRefArray(&className, int64(len(strs)))
+	elements := arr.FieldTable["value"].Fvalue.([]*object.Object)
+	for i, str := range strs {
+		elements[i] = object.StringObjectFromGoString(str)
+	}
+	return arr
+}
+
+// pathAsGoString calls Path.toString() and returns the result as a Go string
+func pathAsGoString(t *testing.T, path interface{}) string {
+	ret := pathToString([]interface{}{path})
+	strObj, ok := ret.(*object.Object)
+	if !ok {
+		t.Fatalf("pathToString: expected a String object, got %v", ret)
+	}
+	return object.GoStringFromStringObject(strObj)
+}
+
+func TestPathsGetJoinsSegments(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := pathsGet([]interface{}{object.StringObjectFromGoString("usr"), makeStringArray("local", "bin")})
+	expected := filepath.Join("usr", "local", "bin")
+	if got := pathAsGoString(t, path); got != expected {
+		t.Errorf("pathsGet: expected %q, got %q", expected, got)
+	}
+}
+
+// As in the JDK, Paths.get() and resolve() keep "." and ".." segments; only normalize() would
+// remove them. Empty segments and redundant or trailing separators are dropped.
+func TestPathsGetKeepsDotSegments(t *testing.T) {
+	globals.InitGlobals("test")
+
+	tests := []struct {
+		first    string
+		more     []string
+		expected string
+	}{
+		{filepath.FromSlash("a/../b"), nil, filepath.FromSlash("a/../b")},
+		{filepath.FromSlash("./x"), nil, filepath.FromSlash("./x")},
+		{"a", []string{"..", "b"}, filepath.FromSlash("a/../b")},
+		{".", []string{"x", "."}, filepath.FromSlash("./x/.")},
+		{filepath.FromSlash("a//b/"), []string{"", "c"}, filepath.FromSlash("a/b/c")},
+	}
+	for _, tt := range tests {
+		path := pathsGet([]interface{}{object.StringObjectFromGoString(tt.first), makeStringArray(tt.more...)})
+		if got := pathAsGoString(t, path); got != tt.expected {
+			t.Errorf("pathsGet(%q, %q): expected %q, got %q", tt.first, tt.more, tt.expected, got)
+		}
+	}
+
+	parent := newPath(filepath.FromSlash("project/src"))
+	resolved := pathResolve([]interface{}{parent, object.StringObjectFromGoString(filepath.FromSlash("../lib/./x"))})
+	if got, expected := pathAsGoString(t, resolved), filepath.FromSlash("project/src/../lib/./x"); got != expected {
+		t.Errorf("pathResolve: expected %q, got %q", expected, got)
+	}
+}
+
+func TestPathsGetWithEmptyVarargs(t *testing.T) {
+	globals.InitGlobals("test")
+
+	path := pathsGet([]interface{}{object.StringObjectFromGoString("data.txt"), makeStringArray()})
+	if got := pathAsGoString(t, path); got != "data.txt" {
+		t.Errorf("pathsGet: expected %q, got %q", "data.txt", got)
+	}
+}
+
+func TestPathsGetNullSegment(t *testing.T) {
+	globals.InitGlobals("test")
+
+	more := makeStringArray("a", "b")
+	more.FieldTable["value"].Fvalue.([]*object.Object)[1] = object.Null
+	ret := pathsGet([]interface{}{object.StringObjectFromGoString("dir"), more})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("pathsGet: expected NullPointerException for a null segment, got %v", ret)
+	}
+}
+
+func TestPathResolveChild(t *testing.T) {
+	globals.InitGlobals("test")
+
+	parent := pathsGet([]interface{}{object.StringObjectFromGoString("project"), makeStringArray("src")})
+	child := pathResolve([]interface{}{parent, object.StringObjectFromGoString("main.go")})
+	expected := filepath.Join("project", "src", "main.go")
+	if got := pathAsGoString(t, child); got != expected {
+		t.Errorf("pathResolve: expected %q, got %q", expected, got)
+	}
+
+	// the original path is unchanged
+	if got := pathAsGoString(t, parent); got != filepath.Join("project", "src") {
+		t.Errorf("pathResolve: parent path was modified to %q", got)
+	}
+}
+
+func TestPathResolveAbsoluteAndEmpty(t *testing.T) {
+	globals.InitGlobals("test")
+
+	parent := newPath("project")
+	abs, _ := filepath.Abs("elsewhere")
+	resolved := pathResolve([]interface{}{parent, object.StringObjectFromGoString(abs)})
+	if got := pathAsGoString(t, resolved); got != abs {
+		t.Errorf("pathResolve: expected absolute argument %q to be returned, got %q", abs, got)
+	}
+
+	resolved = pathResolve([]interface{}{parent, object.StringObjectFromGoString("")})
+	if resolved != parent {
+		t.Errorf("pathResolve: expected an empty argument to return the same path")
+	}
+}
+
+func TestPathsGetFeedsFiles(t *testing.T) {
+	globals.InitGlobals("test")
+
+	dir := newPath(t.TempDir())
+	path := pathResolve([]interface{}{dir, object.StringObjectFromGoString("out.txt")})
+	filesWrite([]interface{}{path, object.MakeArrayFromRawArray([]byte("hello"))})
+
+	arr := filesReadAllBytes([]interface{}{path}).(*object.Object)
+	if string(arr.FieldTable["value"].Fvalue.([]byte)) != "hello" {
+		t.Errorf("expected to read back \"hello\", got %q", string(arr.FieldTable["value"].Fvalue.([]byte)))
+	}
+}
@@ -26,7 +26,7 @@ func Load_Security_SecureRandom() {
 			GFunction:  secureRandomInit,
 		}
 
-	MethodSignatures["java/security/SecureRandom.<init>(Ljava.security.SecureRandomSpi;Ljava.security.Provider;)V"] =
+	MethodSignatures["java/security/SecureRandom.<init>(Ljava/security/SecureRandomSpi;Ljava/security/Provider;)V"] =
 		GMeth{
 			ParamSlots: 2,
 			GFunction:  trapFunction,
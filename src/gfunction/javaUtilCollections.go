@@ -0,0 +1,236 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"fmt"
+	"jacobin/excNames"
+	"jacobin/object"
+	"jacobin/types"
+	"sort"
+	"sync"
+)
+
+// Implementation of some of the functions in java/util/Collections.
+// Strategy: the lists handled here are the array-backed ones (ArrayList, Vector), whose
+// elements live in the "elementData" field and whose element count is in the "size" field.
+// Elements are ordered by calling each element's compareTo() G function (see invokeCompareTo()).
+
+func Load_Util_Collections() {
+
+	MethodSignatures["java/util/Collections.max(Ljava/util/Collection;)Ljava/lang/Object;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  collectionsMax,
+		}
+
+	MethodSignatures["java/util/Collections.min(Ljava/util/Collection;)Ljava/lang/Object;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  collectionsMin,
+		}
+
+	MethodSignatures["java/util/Collections.reverse(Ljava/util/List;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  collectionsReverse,
+		}
+
+	MethodSignatures["java/util/Collections.shuffle(Ljava/util/List;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  collectionsShuffle,
+		}
+
+	MethodSignatures["java/util/Collections.shuffle(Ljava/util/List;Ljava/util/Random;)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  collectionsShuffleRandom,
+		}
+
+	MethodSignatures["java/util/Collections.sort(Ljava/util/List;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  collectionsSort,
+		}
+
+}
+
+// "java/util/Collections.sort(Ljava/util/List;)V"
+// Like the JDK's implementation, the sort is stable: equal elements keep their relative order.
+func collectionsSort(params []interface{}) interface{} {
+	elements, gerr := collectionsGetElements(params, "collectionsSort")
+	if gerr != nil {
+		return gerr
+	}
+
+	var cmpErr *GErrBlk
+	sort.SliceStable(elements, func(i, j int) bool {
+		if cmpErr != nil {
+			return false
+		}
+		result, gerr := invokeCompareTo(elements[i], elements[j])
+		if gerr != nil {
+			cmpErr = gerr
+			return false
+		}
+		return result < 0
+	})
+	if cmpErr != nil {
+		return cmpErr
+	}
+
+	collectionsBumpModCount(params[0].(*object.Object))
+	return nil
+}
+
+// "java/util/Collections.reverse(Ljava/util/List;)V"
+func collectionsReverse(params []interface{}) interface{} {
+	elements, gerr := collectionsGetElements(params, "collectionsReverse")
+	if gerr != nil {
+		return gerr
+	}
+
+	for i, j := 0, len(elements)-1; i < j; i, j = i+1, j-1 {
+		elements[i], elements[j] = elements[j], elements[i]
+	}
+	return nil
+}
+
+// the Random used by shuffle(List), which, as in the JDK, is created on first use
+// and shared by all calls
+var (
+	collectionsShuffleRnd     *object.Object
+	collectionsShuffleRndOnce sync.Once
+)
+
+// "java/util/Collections.shuffle(Ljava/util/List;)V"
+func collectionsShuffle(params []interface{}) interface{} {
+	collectionsShuffleRndOnce.Do(func() {
+		className := "java/util/Random"
+		collectionsShuffleRnd = object.MakeEmptyObjectWithClassName(&className)
+		randomInitVoid([]interface{}{collectionsShuffleRnd})
+	})
+	return collectionsShuffleRandom([]interface{}{params[0], collectionsShuffleRnd})
+}
+
+// "java/util/Collections.shuffle(Ljava/util/List;Ljava/util/Random;)V"
+// This is the JDK's Fisher-Yates shuffle: working back from the end of the list, each
+// element is swapped with one chosen by rnd.nextInt() from those not yet placed. So, for
+// a Random with a given seed, the result is the same as on the JDK.
+func collectionsShuffleRandom(params []interface{}) interface{} {
+	elements, gerr := collectionsGetElements(params, "collectionsShuffleRandom")
+	if gerr != nil {
+		return gerr
+	}
+
+	rndObj, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(rndObj) {
+		errMsg := "collectionsShuffleRandom: Random is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	if _, ok := rndObj.FieldTable["value"].Fvalue.(Random); !ok {
+		errMsg := fmt.Sprintf("collectionsShuffleRandom: unsupported Random type: %s",
+			object.GoStringFromStringPoolIndex(rndObj.KlassName))
+		return getGErrBlk(excNames.UnsupportedOperationException, errMsg)
+	}
+
+	withRandom(rndObj, func(r *Random) interface{} {
+		for i := len(elements); i > 1; i-- {
+			j := r.nextInt(int32(i))
+			elements[i-1], elements[j] = elements[j], elements[i-1]
+		}
+		return nil
+	})
+	return nil
+}
+
+// "java/util/Collections.max(Ljava/util/Collection;)Ljava/lang/Object;"
+func collectionsMax(params []interface{}) interface{} {
+	return collectionsExtreme(params, "collectionsMax", 1)
+}
+
+// "java/util/Collections.min(Ljava/util/Collection;)Ljava/lang/Object;"
+func collectionsMin(params []interface{}) interface{} {
+	return collectionsExtreme(params, "collectionsMin", -1)
+}
+
+// collectionsExtreme returns the largest (sign = 1) or smallest (sign = -1) element of
+// the collection. As in the JDK, the first of several equal extremes is the one returned.
+func collectionsExtreme(params []interface{}, funcName string, sign int64) interface{} {
+	elements, gerr := collectionsGetElements(params, funcName)
+	if gerr != nil {
+		return gerr
+	}
+	if len(elements) == 0 {
+		errMsg := fmt.Sprintf("%s: collection is empty", funcName)
+		return getGErrBlk(excNames.NoSuchElementException, errMsg)
+	}
+
+	candidate := elements[0]
+	for _, element := range elements[1:] {
+		result, gerr := invokeCompareTo(element, candidate)
+		if gerr != nil {
+			return gerr
+		}
+		if result*sign > 0 {
+			candidate = element
+		}
+	}
+	return candidate
+}
+
+// collectionsGetElements validates the list passed in params[0] and returns the slice of
+// its live elements. The slice shares its backing array with the list, so changes to the
+// order of the returned elements are changes to the list itself.
+func collectionsGetElements(params []interface{}, funcName string) ([]*object.Object, *GErrBlk) {
+	if len(params) < 1 {
+		errMsg := fmt.Sprintf("%s: missing collection argument", funcName)
+		return nil, getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	listObj, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(listObj) {
+		errMsg := fmt.Sprintf("%s: collection is null", funcName)
+		return nil, getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	dataFld, ok := listObj.FieldTable["elementData"]
+	if !ok {
+		errMsg := fmt.Sprintf("%s: unsupported collection type: %s",
+			funcName, object.GoStringFromStringPoolIndex(listObj.KlassName))
+		return nil, getGErrBlk(excNames.UnsupportedOperationException, errMsg)
+	}
+
+	dataObj, ok := dataFld.Fvalue.(*object.Object)
+	if !ok || object.IsNull(dataObj) {
+		return []*object.Object{}, nil // no backing array yet, so the list is empty
+	}
+	elements, ok := dataObj.FieldTable["value"].Fvalue.([]*object.Object)
+	if !ok {
+		errMsg := fmt.Sprintf("%s: collection's elementData is not a reference array", funcName)
+		return nil, getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	size := int64(len(elements))
+	if sizeFld, ok := listObj.FieldTable["size"]; ok {
+		if sz, ok := sizeFld.Fvalue.(int64); ok && sz >= 0 && sz <= size {
+			size = sz
+		}
+	}
+	return elements[:size], nil
+}
+
+// collectionsBumpModCount increments the list's modCount, as ArrayList.sort() does,
+// so that any iterator open on the list detects the structural change.
+func collectionsBumpModCount(listObj *object.Object) {
+	if fld, ok := listObj.FieldTable["modCount"]; ok {
+		if count, ok := fld.Fvalue.(int64); ok {
+			listObj.FieldTable["modCount"] = object.Field{Ftype: types.Int, Fvalue: count + 1}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+// builds an ArrayList object holding the given elements, with spare capacity
+// at the end of elementData as a real ArrayList would typically have.
+func makeArrayList(elements ...*object.Object) *object.Object {
+	className := "java/util/ArrayList"
+	list := object.MakeEmptyObjectWithClassName(&className)
+
+	objClassName := "java/lang/Object"
+	data := object.Make1DimRefArray(&objClassName, int64(len(elements)+4))
+	copy(data.FieldTable["value"].Fvalue.([]*object.Object), elements)
+
+	list.FieldTable["elementData"] = object.Field{Ftype: "[Ljava/lang/Object;", Fvalue: data}
+	list.FieldTable["size"] = object.Field{Ftype: types.Int, Fvalue: int64(len(elements))}
+	list.FieldTable["modCount"] = object.Field{Ftype: types.Int, Fvalue: int64(0)}
+	return list
+}
+
+func makeIntegerList(values ...int64) *object.Object {
+	var elements []*object.Object
+	for _, v := range values {
+		elements = append(elements, populator("java/lang/Integer", types.Int, v).(*object.Object))
+	}
+	return makeArrayList(elements...)
+}
+
+func makeStringList(values ...string) *object.Object {
+	var elements []*object.Object
+	for _, v := range values {
+		elements = append(elements, object.StringObjectFromGoString(v))
+	}
+	return makeArrayList(elements...)
+}
+
+func listElements(list *object.Object) []*object.Object {
+	data := list.FieldTable["elementData"].Fvalue.(*object.Object)
+	size := list.FieldTable["size"].Fvalue.(int64)
+	return data.FieldTable["value"].Fvalue.([]*object.Object)[:size]
+}
+
+func TestCollectionsSortIntegers(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	list := makeIntegerList(42, -7, 19, 0, 19, 3)
+
+	ret := collectionsSort([]interface{}{list})
+	if ret != nil {
+		t.Fatalf("TestCollectionsSortIntegers: unexpected error: %v", ret)
+	}
+
+	expected := []int64{-7, 0, 3, 19, 19, 42}
+	for i, elem := range listElements(list) {
+		value := elem.FieldTable["value"].Fvalue.(int64)
+		if value != expected[i] {
+			t.Errorf("TestCollectionsSortIntegers: element %d, expected %d, got %d", i, expected[i], value)
+		}
+	}
+
+	if list.FieldTable["modCount"].Fvalue.(int64) != 1 {
+		t.Errorf("TestCollectionsSortIntegers: expected modCount to be incremented")
+	}
+}
+
+func TestCollectionsSortStrings(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_String()
+	list := makeStringList("pear", "apple", "fig", "Banana", "cherry")
+
+	ret := collectionsSort([]interface{}{list})
+	if ret != nil {
+		t.Fatalf("TestCollectionsSortStrings: unexpected error: %v", ret)
+	}
+
+	expected := []string{"Banana", "apple", "cherry", "fig", "pear"}
+	for i, elem := range listElements(list) {
+		str := object.GoStringFromStringObject(elem)
+		if str != expected[i] {
+			t.Errorf("TestCollectionsSortStrings: element %d, expected %s, got %s", i, expected[i], str)
+		}
+	}
+}
+
+func TestCollectionsSortNotMutuallyComparable(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	Load_Lang_String()
+	integer := populator("java/lang/Integer", types.Int, int64(1)).(*object.Object)
+	list := makeArrayList(integer, object.StringObjectFromGoString("one"))
+
+	ret := collectionsSort([]interface{}{list})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok {
+		t.Fatalf("TestCollectionsSortNotMutuallyComparable: expected a GErrBlk, got %T", ret)
+	}
+	if gerr.ExceptionType != excNames.ClassCastException {
+		t.Errorf("TestCollectionsSortNotMutuallyComparable: expected ClassCastException, got %d",
+			gerr.ExceptionType)
+	}
+}
+
+func TestCollectionsReverse(t *testing.T) {
+	globals.InitGlobals("test")
+	list := makeStringList("a", "b", "c", "d")
+
+	ret := collectionsReverse([]interface{}{list})
+	if ret != nil {
+		t.Fatalf("TestCollectionsReverse: unexpected error: %v", ret)
+	}
+
+	expected := []string{"d", "c", "b", "a"}
+	for i, elem := range listElements(list) {
+		str := object.GoStringFromStringObject(elem)
+		if str != expected[i] {
+			t.Errorf("TestCollectionsReverse: element %d, expected %s, got %s", i, expected[i], str)
+		}
+	}
+}
+
+func TestCollectionsMaxMin(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	list := makeIntegerList(5, 17, -3, 8)
+
+	maxObj := collectionsMax([]interface{}{list}).(*object.Object)
+	if maxObj.FieldTable["value"].Fvalue.(int64) != 17 {
+		t.Errorf("TestCollectionsMaxMin: expected max of 17, got %d", maxObj.FieldTable["value"].Fvalue.(int64))
+	}
+
+	minObj := collectionsMin([]interface{}{list}).(*object.Object)
+	if minObj.FieldTable["value"].Fvalue.(int64) != -3 {
+		t.Errorf("TestCollectionsMaxMin: expected min of -3, got %d", minObj.FieldTable["value"].Fvalue.(int64))
+	}
+}
+
+func TestCollectionsMaxOfEmptyList(t *testing.T) {
+	globals.InitGlobals("test")
+	list := makeArrayList()
+
+	ret := collectionsMax([]interface{}{list})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.NoSuchElementException {
+		t.Errorf("TestCollectionsMaxOfEmptyList: expected NoSuchElementException, got %v", ret)
+	}
+}
+
+// a shuffle with new Random(42) must put the list in the same order as the JDK does
+func TestCollectionsShuffleWithSeededRandom(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	list := makeIntegerList(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+
+	ret := collectionsShuffleRandom([]interface{}{list, newSeededRandom(42)})
+	if ret != nil {
+		t.Fatalf("TestCollectionsShuffleWithSeededRandom: unexpected error: %v", ret)
+	}
+
+	expected := []int64{5, 7, 3, 2, 8, 10, 9, 6, 4, 1}
+	for i, elem := range listElements(list) {
+		value := elem.FieldTable["value"].Fvalue.(int64)
+		if value != expected[i] {
+			t.Errorf("TestCollectionsShuffleWithSeededRandom: element %d, expected %d, got %d", i, expected[i], value)
+		}
+	}
+}
+
+func TestCollectionsShuffleNullRandom(t *testing.T) {
+	globals.InitGlobals("test")
+	list := makeIntegerList(1, 2, 3)
+
+	ret := collectionsShuffleRandom([]interface{}{list, object.Null})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("TestCollectionsShuffleNullRandom: expected NullPointerException, got %v", ret)
+	}
+}
+
+// shuffle(List) uses its own Random, so the order can't be predicted, but the
+// elements must all still be there
+func TestCollectionsShuffleKeepsElements(t *testing.T) {
+	globals.InitGlobals("test")
+	Load_Lang_Integer()
+	list := makeIntegerList(1, 2, 3, 4, 5, 6)
+
+	if ret := collectionsShuffle([]interface{}{list}); ret != nil {
+		t.Fatalf("TestCollectionsShuffleKeepsElements: unexpected error: %v", ret)
+	}
+
+	var sum int64
+	for _, elem := range listElements(list) {
+		sum += elem.FieldTable["value"].Fvalue.(int64)
+	}
+	if sum != 21 || len(listElements(list)) != 6 {
+		t.Errorf("TestCollectionsShuffleKeepsElements: elements changed, got %v", listElements(list))
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"jacobin/excNames"
 	"jacobin/object"
 	"jacobin/types"
+	"math"
 )
 
 // Implementation of some of the functions in in Java/lang/Class.
@@ -25,6 +26,12 @@ func Load_Util_HashMap() {
 			GFunction:  hashMapHash,
 		}
 
+	MethodSignatures["java/util/HashMap.replace(Ljava/lang/Object;Ljava/lang/Object;)Ljava/lang/Object;"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  hashMapReplace,
+		}
+
 }
 
 // hashMapHash accepts a pointer to an object and returns
@@ -40,11 +47,11 @@ func hashMapHash(params []interface{}) interface{} {
 		case types.ByteArray:
 			bytes = obj.FieldTable["value"].Fvalue.([]byte)
 		case types.Bool, types.Byte, types.Char, types.Int, types.Long, types.Short:
-			bytes := make([]byte, 8)
+			bytes = make([]byte, 8)
 			binary.BigEndian.PutUint64(bytes, uint64(fld.Fvalue.(int64)))
 		case types.Double, types.Float:
-			bytes := make([]byte, 8)
-			binary.BigEndian.PutUint64(bytes, uint64(fld.Fvalue.(float64)))
+			bytes = make([]byte, 8)
+			binary.BigEndian.PutUint64(bytes, math.Float64bits(fld.Fvalue.(float64)))
 		default:
 			str := fmt.Sprintf("Unrecognized object field type: %T", fld.Ftype)
 			return getGErrBlk(excNames.VirtualMachineError, str)
@@ -59,3 +66,107 @@ func hashMapHash(params []interface{}) interface{} {
 	}
 	return hashValue
 }
+
+// java/util/HashMap.replace(Ljava/lang/Object;Ljava/lang/Object;)Ljava/lang/Object;
+// Replaces the value for the given key only if the key is already present in the map.
+// Returns the previous value, or null if the key was not found. The lookup walks the
+// same node table (HashMap.table of HashMap$Node) that the JDK's put() and get() build,
+// so the map stays consistent with the bytecode-executed methods.
+func hashMapReplace(params []interface{}) interface{} {
+	if len(params) != 3 {
+		errMsg := fmt.Sprintf("hashMapReplace: expected 3 parameters, got %d", len(params))
+		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
+	}
+
+	this, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(this) {
+		errMsg := "hashMapReplace: HashMap object is null"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+
+	key, _ := params[1].(*object.Object)
+	newValue := params[2]
+
+	node := hashMapFindNode(this, key)
+	if node == nil {
+		return object.Null // key not present, so nothing is replaced
+	}
+
+	oldValue := node.FieldTable["value"].Fvalue
+	node.FieldTable["value"] = object.Field{Ftype: node.FieldTable["value"].Ftype, Fvalue: newValue}
+	if oldValue == nil {
+		return object.Null
+	}
+	return oldValue
+}
+
+// hashMapFindNode locates the HashMap$Node holding the given key, or returns nil if
+// there is no such node. It mirrors HashMap.getNode(): hash the key, index into the
+// table, and walk the chain of nodes comparing hashes and then keys.
+func hashMapFindNode(hashMap *object.Object, key *object.Object) *object.Object {
+	tableFld, ok := hashMap.FieldTable["table"]
+	if !ok {
+		return nil
+	}
+	tableObj, ok := tableFld.Fvalue.(*object.Object)
+	if !ok || object.IsNull(tableObj) {
+		return nil // the table is allocated lazily on the first put()
+	}
+	table, ok := tableObj.FieldTable["value"].Fvalue.([]*object.Object)
+	if !ok || len(table) == 0 {
+		return nil
+	}
+
+	var hash int64 = 0 // per HashMap.hash(), a null key always hashes to 0
+	if !object.IsNull(key) {
+		h, ok := hashMapHash([]interface{}{key}).(int64)
+		if !ok {
+			return nil
+		}
+		hash = h
+	}
+
+	index := (int64(len(table)) - 1) & hash
+	for node := table[index]; !object.IsNull(node); {
+		nodeHash, _ := node.FieldTable["hash"].Fvalue.(int64)
+		nodeKey, _ := node.FieldTable["key"].Fvalue.(*object.Object)
+		if nodeHash == hash && hashMapKeysEqual(key, nodeKey) {
+			return node
+		}
+		next, ok := node.FieldTable["next"].Fvalue.(*object.Object)
+		if !ok {
+			break
+		}
+		node = next
+	}
+	return nil
+}
+
+// hashMapKeysEqual compares two keys the way HashMap does: by identity first and
+// then by value. Since equals() cannot be dispatched from here, value equality is
+// limited to objects of the same class whose "value" fields hold equal contents,
+// which covers strings and the boxed primitives.
+func hashMapKeysEqual(key1, key2 *object.Object) bool {
+	if key1 == key2 {
+		return true
+	}
+	if object.IsNull(key1) || object.IsNull(key2) || key1.KlassName != key2.KlassName {
+		return false
+	}
+
+	fld1, ok1 := key1.FieldTable["value"]
+	fld2, ok2 := key2.FieldTable["value"]
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	switch fld1.Fvalue.(type) {
+	case []byte:
+		bytes2, ok := fld2.Fvalue.([]byte)
+		return ok && string(fld1.Fvalue.([]byte)) == string(bytes2)
+	case int64, float64, bool, byte:
+		return fld1.Fvalue == fld2.Fvalue
+	default:
+		return false
+	}
+}
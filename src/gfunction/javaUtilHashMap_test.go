@@ -0,0 +1,155 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+// builds a HashMap object whose node table holds a single entry, laid out
+// the way the JDK's HashMap.putVal() would leave it.
+func makeHashMapWithOneEntry(key, value *object.Object) *object.Object {
+	className := "java/util/HashMap"
+	hashMap := object.MakeEmptyObjectWithClassName(&className)
+
+	nodeClassName := "java/util/HashMap$Node"
+	tableObj := object.Make1DimRefArray(&nodeClassName, 16)
+	table := tableObj.FieldTable["value"].Fvalue.([]*object.Object)
+
+	hash := hashMapHash([]interface{}{key}).(int64)
+	node := object.MakeEmptyObjectWithClassName(&nodeClassName)
+	node.FieldTable["hash"] = object.Field{Ftype: types.Int, Fvalue: hash}
+	node.FieldTable["key"] = object.Field{Ftype: "Ljava/lang/Object;", Fvalue: key}
+	node.FieldTable["value"] = object.Field{Ftype: "Ljava/lang/Object;", Fvalue: value}
+	node.FieldTable["next"] = object.Field{Ftype: "Ljava/util/HashMap$Node;", Fvalue: nil}
+	table[(int64(len(table))-1)&hash] = node
+
+	hashMap.FieldTable["table"] = object.Field{Ftype: "[Ljava/util/HashMap$Node;", Fvalue: tableObj}
+	return hashMap
+}
+
+func TestHashMapReplaceExistingKey(t *testing.T) {
+	globals.InitGlobals("test")
+	oldValue := object.StringObjectFromGoString("old")
+	hashMap := makeHashMapWithOneEntry(object.StringObjectFromGoString("alpha"), oldValue)
+
+	// use a distinct but equal key object, as a Java program typically would
+	lookupKey := object.StringObjectFromGoString("alpha")
+	newValue := object.StringObjectFromGoString("new")
+	ret := hashMapReplace([]interface{}{hashMap, lookupKey, newValue})
+
+	if ret != oldValue {
+		t.Errorf("TestHashMapReplaceExistingKey: expected old value to be returned, got %v", ret)
+	}
+
+	node := hashMapFindNode(hashMap, lookupKey)
+	if node == nil {
+		t.Fatalf("TestHashMapReplaceExistingKey: key is no longer in the map")
+	}
+	str := object.GoStringFromStringObject(node.FieldTable["value"].Fvalue.(*object.Object))
+	if str != "new" {
+		t.Errorf("TestHashMapReplaceExistingKey: expected value 'new', got '%s'", str)
+	}
+}
+
+func TestHashMapReplaceAbsentKey(t *testing.T) {
+	globals.InitGlobals("test")
+	value := object.StringObjectFromGoString("unchanged")
+	hashMap := makeHashMapWithOneEntry(object.StringObjectFromGoString("alpha"), value)
+
+	absentKey := object.StringObjectFromGoString("beta")
+	ret := hashMapReplace([]interface{}{hashMap, absentKey, object.StringObjectFromGoString("new")})
+	if !object.IsNull(ret) {
+		t.Errorf("TestHashMapReplaceAbsentKey: expected null return, got %v", ret)
+	}
+
+	if hashMapFindNode(hashMap, absentKey) != nil {
+		t.Errorf("TestHashMapReplaceAbsentKey: absent key was unexpectedly added to the map")
+	}
+
+	node := hashMapFindNode(hashMap, object.StringObjectFromGoString("alpha"))
+	if node == nil || node.FieldTable["value"].Fvalue != value {
+		t.Errorf("TestHashMapReplaceAbsentKey: existing entry was modified")
+	}
+}
+
+func TestHashMapReplaceOnEmptyMap(t *testing.T) {
+	globals.InitGlobals("test")
+	className := "java/util/HashMap"
+	hashMap := object.MakeEmptyObjectWithClassName(&className)
+	hashMap.FieldTable["table"] = object.Field{Ftype: "[Ljava/util/HashMap$Node;", Fvalue: nil}
+
+	key := object.StringObjectFromGoString("alpha")
+	ret := hashMapReplace([]interface{}{hashMap, key, object.StringObjectFromGoString("new")})
+	if !object.IsNull(ret) {
+		t.Errorf("TestHashMapReplaceOnEmptyMap: expected null return, got %v", ret)
+	}
+}
+
+// a boxed value is stored and returned as the very object that was put, so it can be
+// unboxed after it is retrieved from the map
+func TestHashMapBoxedValueUnboxes(t *testing.T) {
+	globals.InitGlobals("test")
+	oldValue := integerValueOf([]interface{}{int64(42)}).(*object.Object)
+	hashMap := makeHashMapWithOneEntry(object.StringObjectFromGoString("answer"), oldValue)
+
+	newValue := integerValueOf([]interface{}{int64(43)}).(*object.Object)
+	ret := hashMapReplace([]interface{}{hashMap, object.StringObjectFromGoString("answer"), newValue})
+	if ret != oldValue {
+		t.Fatalf("TestHashMapBoxedValueUnboxes: expected the Integer that was put, got %v", ret)
+	}
+	if intValue := integerIntLongValue([]interface{}{ret}); intValue != int64(42) {
+		t.Errorf("TestHashMapBoxedValueUnboxes: expected intValue() of 42, got %v", intValue)
+	}
+
+	node := hashMapFindNode(hashMap, object.StringObjectFromGoString("answer"))
+	if node == nil {
+		t.Fatalf("TestHashMapBoxedValueUnboxes: key is no longer in the map")
+	}
+	got, ok := node.FieldTable["value"].Fvalue.(*object.Object)
+	if !ok || got != newValue {
+		t.Fatalf("TestHashMapBoxedValueUnboxes: expected the replacing Integer in the map, got %v",
+			node.FieldTable["value"].Fvalue)
+	}
+	if intValue := integerIntLongValue([]interface{}{got}); intValue != int64(43) {
+		t.Errorf("TestHashMapBoxedValueUnboxes: expected intValue() of 43, got %v", intValue)
+	}
+}
+
+// boxed keys hash by their values, so equal boxed keys find the same node and keys with
+// different values are spread across the table
+func TestHashMapHashOfBoxedKeys(t *testing.T) {
+	globals.InitGlobals("test")
+	hash := func(key *object.Object) int64 { return hashMapHash([]interface{}{key}).(int64) }
+
+	one := populator("java/lang/Integer", types.Int, int64(1)).(*object.Object)
+	otherOne := populator("java/lang/Integer", types.Int, int64(1)).(*object.Object)
+	two := populator("java/lang/Integer", types.Int, int64(2)).(*object.Object)
+	if hash(one) != hash(otherOne) {
+		t.Errorf("TestHashMapHashOfBoxedKeys: equal Integers hash differently")
+	}
+	if hash(one) == hash(two) {
+		t.Errorf("TestHashMapHashOfBoxedKeys: Integers 1 and 2 have the same hash")
+	}
+
+	half := populator("java/lang/Double", types.Double, 0.5).(*object.Object)
+	quarter := populator("java/lang/Double", types.Double, 0.25).(*object.Object)
+	if hash(half) == hash(quarter) {
+		t.Errorf("TestHashMapHashOfBoxedKeys: Doubles 0.5 and 0.25 have the same hash")
+	}
+
+	hashMap := makeHashMapWithOneEntry(two, object.StringObjectFromGoString("two"))
+	if hashMapFindNode(hashMap, populator("java/lang/Integer", types.Int, int64(2)).(*object.Object)) == nil {
+		t.Errorf("TestHashMapHashOfBoxedKeys: an equal Integer key did not find its entry")
+	}
+	if hashMapFindNode(hashMap, one) != nil {
+		t.Errorf("TestHashMapHashOfBoxedKeys: a different Integer key found an entry")
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/object"
+	"jacobin/types"
+)
+
+// Implementation of some of the functions in java/util/Optional.
+// Strategy: as in the JDK, an Optional holds its value in the "value" field,
+// which is null when the Optional is empty.
+
+var classNameOptional = "java/util/Optional"
+
+func Load_Util_Optional() {
+
+	MethodSignatures["java/util/Optional.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/util/Optional.empty()Ljava/util/Optional;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  optionalEmpty,
+		}
+
+	MethodSignatures["java/util/Optional.get()Ljava/lang/Object;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  optionalGet,
+		}
+
+	MethodSignatures["java/util/Optional.isEmpty()Z"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  optionalIsEmpty,
+		}
+
+	MethodSignatures["java/util/Optional.isPresent()Z"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  optionalIsPresent,
+		}
+
+	MethodSignatures["java/util/Optional.of(Ljava/lang/Object;)Ljava/util/Optional;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  optionalOf,
+		}
+
+	MethodSignatures["java/util/Optional.ofNullable(Ljava/lang/Object;)Ljava/util/Optional;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  optionalOfNullable,
+		}
+
+	MethodSignatures["java/util/Optional.orElse(Ljava/lang/Object;)Ljava/lang/Object;"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  optionalOrElse,
+		}
+
+}
+
+// makeOptional creates an Optional holding value, which is null for an empty Optional
+func makeOptional(value *object.Object) *object.Object {
+	optional := object.MakeEmptyObjectWithClassName(&classNameOptional)
+	optional.FieldTable["value"] = object.Field{Ftype: "Ljava/lang/Object;", Fvalue: value}
+	return optional
+}
+
+// optionalValue returns the value held by an Optional, or null if it's empty
+func optionalValue(optional *object.Object) *object.Object {
+	value, ok := optional.FieldTable["value"].Fvalue.(*object.Object)
+	if !ok {
+		return object.Null
+	}
+	return value
+}
+
+// "java/util/Optional.empty()Ljava/util/Optional;"
+func optionalEmpty([]interface{}) interface{} {
+	return makeOptional(object.Null)
+}
+
+// "java/util/Optional.of(Ljava/lang/Object;)Ljava/util/Optional;"
+func optionalOf(params []interface{}) interface{} {
+	value, ok := params[0].(*object.Object)
+	if !ok || object.IsNull(value) {
+		return getGErrBlk(excNames.NullPointerException, "Optional.of(): value is null")
+	}
+	return makeOptional(value)
+}
+
+// "java/util/Optional.ofNullable(Ljava/lang/Object;)Ljava/util/Optional;"
+func optionalOfNullable(params []interface{}) interface{} {
+	value, ok := params[0].(*object.Object)
+	if !ok {
+		value = object.Null
+	}
+	return makeOptional(value)
+}
+
+// "java/util/Optional.isPresent()Z"
+func optionalIsPresent(params []interface{}) interface{} {
+	if object.IsNull(optionalValue(params[0].(*object.Object))) {
+		return types.JavaBoolFalse
+	}
+	return types.JavaBoolTrue
+}
+
+// "java/util/Optional.isEmpty()Z"
+func optionalIsEmpty(params []interface{}) interface{} {
+	if object.IsNull(optionalValue(params[0].(*object.Object))) {
+		return types.JavaBoolTrue
+	}
+	return types.JavaBoolFalse
+}
+
+// "java/util/Optional.get()Ljava/lang/Object;"
+func optionalGet(params []interface{}) interface{} {
+	value := optionalValue(params[0].(*object.Object))
+	if object.IsNull(value) {
+		return getGErrBlk(excNames.NoSuchElementException, "No value present")
+	}
+	return value
+}
+
+// "java/util/Optional.orElse(Ljava/lang/Object;)Ljava/lang/Object;"
+func optionalOrElse(params []interface{}) interface{} {
+	value := optionalValue(params[0].(*object.Object))
+	if object.IsNull(value) {
+		return params[1]
+	}
+	return value
+}
@@ -0,0 +1,93 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+func TestOptionalPresent(t *testing.T) {
+	globals.InitGlobals("test")
+	value := object.StringObjectFromGoString("hello")
+
+	for name, factory := range map[string]func([]interface{}) interface{}{
+		"of": optionalOf, "ofNullable": optionalOfNullable} {
+		optional, ok := factory([]interface{}{value}).(*object.Object)
+		if !ok {
+			t.Fatalf("Optional.%s(): expected an Optional, got %T", name, optional)
+		}
+		if optionalIsPresent([]interface{}{optional}) != types.JavaBoolTrue {
+			t.Errorf("Optional.%s(): expected isPresent() to be true", name)
+		}
+		if optionalIsEmpty([]interface{}{optional}) != types.JavaBoolFalse {
+			t.Errorf("Optional.%s(): expected isEmpty() to be false", name)
+		}
+		if got := optionalGet([]interface{}{optional}); got != value {
+			t.Errorf("Optional.%s(): expected get() to return the value, got %v", name, got)
+		}
+		other := object.StringObjectFromGoString("other")
+		if got := optionalOrElse([]interface{}{optional, other}); got != value {
+			t.Errorf("Optional.%s(): expected orElse() to return the value, got %v", name, got)
+		}
+	}
+}
+
+func TestOptionalEmpty(t *testing.T) {
+	globals.InitGlobals("test")
+	empty := optionalEmpty(nil).(*object.Object)
+	nullable := optionalOfNullable([]interface{}{object.Null}).(*object.Object)
+
+	for name, optional := range map[string]*object.Object{"empty": empty, "ofNullable(null)": nullable} {
+		if optionalIsPresent([]interface{}{optional}) != types.JavaBoolFalse {
+			t.Errorf("Optional.%s: expected isPresent() to be false", name)
+		}
+		if optionalIsEmpty([]interface{}{optional}) != types.JavaBoolTrue {
+			t.Errorf("Optional.%s: expected isEmpty() to be true", name)
+		}
+	}
+}
+
+func TestOptionalGetOnEmptyThrows(t *testing.T) {
+	globals.InitGlobals("test")
+	empty := optionalEmpty(nil).(*object.Object)
+	ret := optionalGet([]interface{}{empty})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok {
+		t.Fatalf("Optional.get() on empty: expected an error, got %T", ret)
+	}
+	if gerr.ExceptionType != excNames.NoSuchElementException {
+		t.Errorf("Optional.get() on empty: expected NoSuchElementException, got %d", gerr.ExceptionType)
+	}
+	if gerr.ErrMsg != "No value present" {
+		t.Errorf("Optional.get() on empty: unexpected message %q", gerr.ErrMsg)
+	}
+}
+
+func TestOptionalOfNullThrows(t *testing.T) {
+	globals.InitGlobals("test")
+	ret := optionalOf([]interface{}{object.Null})
+	gerr, ok := ret.(*GErrBlk)
+	if !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("Optional.of(null): expected NullPointerException, got %v", ret)
+	}
+}
+
+func TestOptionalOrElseFallback(t *testing.T) {
+	globals.InitGlobals("test")
+	empty := optionalEmpty(nil).(*object.Object)
+	fallback := object.StringObjectFromGoString("fallback")
+	if got := optionalOrElse([]interface{}{empty, fallback}); got != fallback {
+		t.Errorf("Optional.orElse() on empty: expected the fallback, got %v", got)
+	}
+	if got := optionalOrElse([]interface{}{empty, object.Null}); !object.IsNull(got) {
+		t.Errorf("Optional.orElse(null) on empty: expected null, got %v", got)
+	}
+}
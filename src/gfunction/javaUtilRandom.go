@@ -7,7 +7,7 @@ import (
 	"jacobin/object"
 	"jacobin/types"
 	"math"
-	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
@@ -64,7 +64,7 @@ func Load_Util_Random() {
 	MethodSignatures["java/util/Random.nextInt()I"] =
 		GMeth{
 			ParamSlots: 0,
-			GFunction:  randomNextIntLong,
+			GFunction:  randomNextInt,
 		}
 
 	MethodSignatures["java/util/Random.nextInt(I)I"] =
@@ -76,7 +76,7 @@ func Load_Util_Random() {
 	MethodSignatures["java/util/Random.nextLong()J"] =
 		GMeth{
 			ParamSlots: 0,
-			GFunction:  randomNextIntLong,
+			GFunction:  randomNextLong,
 		}
 
 	MethodSignatures["java/util/Random.setSeed(J)V"] =
@@ -88,20 +88,25 @@ func Load_Util_Random() {
 }
 
 /*
-ChatGPT key Points:
-* Initialization: The NewRandom() function initializes a Random instance using the current time as a seed.
-* Seed Setting: The SetSeed(seed int64) method allows setting a specific seed for the random number generator.
-* Random Number Generation: Methods like NextInt(), NextIntBound(bound int), NextFloat32(), NextFloat64(),
-                                         NextBoolean(), and NextGaussian()
-                            mimic the behavior of their Java counterparts using Go's math/rand package.
-* Concurrency: A sync.Mutex is used to ensure thread safety when accessing shared state like haveNextNextGaussian
-               and nextNextGaussian in NextGaussian().
+Random implements java.util.Random's algorithm exactly, so that a Random created with a
+given seed produces the same sequence of values as it does on the JDK. The generator is
+the 48-bit linear congruential generator described in the Random Javadoc and in Knuth,
+TAOCP vol. 2, section 3.2.1. Every value is derived from next(bits), as in the JDK.
 
 * object.Object Ftype = types.Struct
 */
 
+const (
+	randomMultiplier = 0x5DEECE66D
+	randomAddend     = 0xB
+	randomMask       = (1 << 48) - 1
+)
+
+// the JDK's seedUniquifier, which makes Randoms created at the same instant differ
+var randomSeedUniquifier int64 = 8682522807148012
+
 type Random struct {
-	rand                 *rand.Rand
+	seed                 int64 // only the low-order 48 bits are used
 	nextNextGaussian     float64
 	haveNextNextGaussian bool
 }
@@ -118,18 +123,66 @@ func GetStructFromRandomObject(objPtr *object.Object) Random {
 	return randStruct
 }
 
+// newRandom returns a Random whose seed is set as by Random.setSeed(seed)
+func newRandom(seed int64) Random {
+	return Random{seed: (seed ^ randomMultiplier) & randomMask}
+}
+
+// next advances the generator and returns its next bits pseudorandom bits (at most 32),
+// as in the JDK's Random.next(int)
+func (r *Random) next(bits uint) int32 {
+	r.seed = (r.seed*randomMultiplier + randomAddend) & randomMask
+	return int32(r.seed >> (48 - bits))
+}
+
+// nextInt returns a pseudorandom int between 0 (inclusive) and bound (exclusive), which
+// must be positive. Values that would make the distribution uneven are rejected.
+func (r *Random) nextInt(bound int32) int32 {
+	if bound&(-bound) == bound { // bound is a power of 2
+		return int32((int64(bound) * int64(r.next(31))) >> 31)
+	}
+	for {
+		bits := r.next(31)
+		val := bits % bound
+		if bits-val+(bound-1) >= 0 { // int32 overflow means bits fell in the uneven last range
+			return val
+		}
+	}
+}
+
+// nextDouble returns a pseudorandom double between 0.0 (inclusive) and 1.0 (exclusive),
+// made from 53 random bits
+func (r *Random) nextDouble() float64 {
+	return float64(int64(r.next(26))<<27+int64(r.next(27))) * (1.0 / (1 << 53))
+}
+
+// withRandom runs fn on the Random in the object obj and saves the Random's new state.
+// The Random is locked while fn runs, since a Random can be shared by threads.
+func withRandom(obj *object.Object, fn func(r *Random) interface{}) interface{} {
+	global := globals.GetGlobalRef()
+	global.RandomLock.Lock()
+	defer global.RandomLock.Unlock()
+	r := GetStructFromRandomObject(obj)
+	ret := fn(&r)
+	UpdateRandomObjectFromStruct(obj, r)
+	return ret
+}
+
 // "java/util/Random.<init>()V"
-// NewRandom creates a new Random instance initialized with the current time as seed.
-// chatGPT generated: func NewRandom() *Random
+// Creates a Random with a seed that's very likely to differ from that of any other
+// Random. As in the JDK, this is the nanosecond clock mixed with a seed uniquifier.
 func randomInitVoid(params []interface{}) interface{} {
-	source := rand.NewSource(time.Now().UnixNano())
-	randStruct := Random{
-		rand:                 rand.New(source),
-		nextNextGaussian:     0.0,
-		haveNextNextGaussian: false,
+	uniquifier := atomic.LoadInt64(&randomSeedUniquifier) // the JDK updates it with a CAS loop
+	for {
+		next := uniquifier * 1181783497276652981
+		if atomic.CompareAndSwapInt64(&randomSeedUniquifier, uniquifier, next) {
+			uniquifier = next
+			break
+		}
+		uniquifier = atomic.LoadInt64(&randomSeedUniquifier)
 	}
 	obj := params[0].(*object.Object)
-	UpdateRandomObjectFromStruct(obj, randStruct)
+	UpdateRandomObjectFromStruct(obj, newRandom(uniquifier^time.Now().UnixNano()))
 	return nil
 }
 
@@ -137,125 +190,124 @@ func randomInitVoid(params []interface{}) interface{} {
 // Same as randomInitVoid except a seed is supplied.
 func randomInitLong(params []interface{}) interface{} {
 	seed := params[1].(int64)
-	source := rand.NewSource(seed)
-	randStruct := Random{
-		rand:                 rand.New(source),
-		nextNextGaussian:     0.0,
-		haveNextNextGaussian: false,
-	}
 	obj := params[0].(*object.Object)
-	UpdateRandomObjectFromStruct(obj, randStruct)
+	UpdateRandomObjectFromStruct(obj, newRandom(seed))
 	return nil
 }
 
 // randomSetSeed sets the seed of the random number generator.
-// ChatGPT: func (r *Random) SetSeed(seed int64)
 func randomSetSeed(params []interface{}) interface{} {
-	global := globals.GetGlobalRef()
-	global.RandomLock.Lock() // <-------------------
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
 	seed := params[1].(int64)
-	r.rand.Seed(seed)
-	r.haveNextNextGaussian = false
-	UpdateRandomObjectFromStruct(obj, r)
-	global.RandomLock.Unlock() // <-------------------
-	return nil
+	return withRandom(obj, func(r *Random) interface{} {
+		*r = newRandom(seed)
+		return nil
+	})
 }
 
-// randomNextIntLong returns the next pseudorandom, uniformly distributed int64 value.
-// ChatGPT: func (r *Random) NextInt() int {
-func randomNextIntLong(params []interface{}) interface{} {
+// randomNextInt returns the next pseudorandom, uniformly distributed int value.
+func randomNextInt(params []interface{}) interface{} {
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
-	output := r.rand.Int63()
-	return output
+	return withRandom(obj, func(r *Random) interface{} {
+		return int64(r.next(32))
+	})
 }
 
 // randomNextIntBound returns a pseudorandom, uniformly distributed int value between 0 (inclusive) and bound (exclusive).
-// ChatGPT: func (r *Random) NextIntBound(bound int) int, error {
 func randomNextIntBound(params []interface{}) interface{} {
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
 	bound := params[1].(int64)
 	if bound < 1 {
 		errMsg := fmt.Sprintf("Bound must be positive, observed: %d", bound)
 		return getGErrBlk(excNames.IllegalArgumentException, errMsg)
 	}
-	output := r.rand.Int63n(bound)
-	return output
+	return withRandom(obj, func(r *Random) interface{} {
+		return int64(r.nextInt(int32(bound)))
+	})
+}
+
+// randomNextLong returns the next pseudorandom, uniformly distributed long value.
+func randomNextLong(params []interface{}) interface{} {
+	obj := params[0].(*object.Object)
+	return withRandom(obj, func(r *Random) interface{} {
+		return int64(r.next(32))<<32 + int64(r.next(32))
+	})
 }
 
 // randomNextBoolean returns the next pseudorandom, uniformly distributed boolean value.
-// ChatGPT: func (r *Random) NextBoolean() bool
 func randomNextBoolean(params []interface{}) interface{} {
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
-	if r.rand.Int63n(2) == 0 {
-		return int64(1)
-	} else {
-		return int64(0)
-	}
+	return withRandom(obj, func(r *Random) interface{} {
+		if r.next(1) != 0 {
+			return types.JavaBoolTrue
+		}
+		return types.JavaBoolFalse
+	})
 }
 
-// Given an array of bytes, fill each element with a random number between 0 and 255.
+// Given an array of bytes, fill each element with a random byte. As in the JDK, each
+// random int supplies four bytes, low-order byte first.
 func randomNextBytes(params []interface{}) interface{} {
 	robj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(robj)
 	bobj := params[1].(*object.Object)
 	bytes := bobj.FieldTable["value"].Fvalue.([]byte)
-	r.rand.Read(bytes)
+	withRandom(robj, func(r *Random) interface{} {
+		for i := 0; i < len(bytes); {
+			rnd := r.next(32)
+			for n := min(len(bytes)-i, 4); n > 0; n-- {
+				bytes[i] = byte(rnd)
+				rnd >>= 8
+				i++
+			}
+		}
+		return nil
+	})
 	bobj.FieldTable["value"] = object.Field{Ftype: types.ByteArray, Fvalue: bytes}
 	return nil
 }
 
-// randomNextFloat returns the next pseudorandom, uniformly distributed float64 value between 0.0 (inclusive) and 1.0 (exclusive).
-// ChatGPT: func (r *Random) NextFloat32() float32
+// randomNextFloat returns the next pseudorandom, uniformly distributed float value between
+// 0.0 (inclusive) and 1.0 (exclusive), made from 24 random bits.
 func randomNextFloat(params []interface{}) interface{} {
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
-	return r.rand.Float64()
+	return withRandom(obj, func(r *Random) interface{} {
+		return float64(float32(r.next(24)) / (1 << 24))
+	})
 }
 
-// randomNextDouble is identical to randomNextFloat.
-// ChatGPT: func (r *Random) NextFloat64() float64
+// randomNextDouble returns the next pseudorandom, uniformly distributed double value between
+// 0.0 (inclusive) and 1.0 (exclusive).
 func randomNextDouble(params []interface{}) interface{} {
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
-	return r.rand.Float64()
+	return withRandom(obj, func(r *Random) interface{} {
+		return r.nextDouble()
+	})
 }
 
-// NextGaussian returns the next pseudorandom, Gaussian ("normally") distributed float64 value with mean 0.0 and standard deviation 1.0.
-// ChatGPT: func (r *Random) NextGaussian() float64
+// randomNextGaussian returns the next pseudorandom, Gaussian ("normally") distributed double value
+// with mean 0.0 and standard deviation 1.0. As in the JDK, it uses the polar method, which
+// produces two values at a time; the second is saved for the next call.
 func randomNextGaussian(params []interface{}) interface{} {
-	global := globals.GetGlobalRef()
-	global.RandomLock.Lock() // <-------------------
 	obj := params[0].(*object.Object)
-	r := GetStructFromRandomObject(obj)
-
-	if r.haveNextNextGaussian {
-		r.haveNextNextGaussian = false
-		UpdateRandomObjectFromStruct(obj, r)
-		global.RandomLock.Unlock() // <-------------------
-		return r.nextNextGaussian
-	}
-
-	var v1, v2, s float64
-	for {
-		v1 = 2*r.rand.Float64() - 1 // between -1.0 and 1.0
-		v2 = 2*r.rand.Float64() - 1 // between -1.0 and 1.0
-		s = v1*v1 + v2*v2
-		if s < 1.0 && s != 0.0 {
-			break
+	return withRandom(obj, func(r *Random) interface{} {
+		if r.haveNextNextGaussian {
+			r.haveNextNextGaussian = false
+			return r.nextNextGaussian
 		}
-	}
 
-	multiplier := math.Sqrt(-2 * math.Log(s) / s)
-	r.nextNextGaussian = v2 * multiplier
-	r.haveNextNextGaussian = true
-	UpdateRandomObjectFromStruct(obj, r)
-
-	global.RandomLock.Unlock() // <-------------------
+		var v1, v2, s float64
+		for {
+			v1 = 2*r.nextDouble() - 1 // between -1.0 and 1.0
+			v2 = 2*r.nextDouble() - 1 // between -1.0 and 1.0
+			s = v1*v1 + v2*v2
+			if s < 1.0 && s != 0.0 {
+				break
+			}
+		}
 
-	return v1 * multiplier
+		multiplier := math.Sqrt(-2 * math.Log(s) / s)
+		r.nextNextGaussian = v2 * multiplier
+		r.haveNextNextGaussian = true
+		return v1 * multiplier
+	})
 }
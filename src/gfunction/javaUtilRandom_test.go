@@ -0,0 +1,119 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+// newSeededRandom returns a java/util/Random object created with new Random(seed)
+func newSeededRandom(seed int64) *object.Object {
+	className := "java/util/Random"
+	obj := object.MakeEmptyObjectWithClassName(&className)
+	randomInitLong([]interface{}{obj, seed})
+	return obj
+}
+
+// the expected values are those returned by the JDK for new Random(42)
+
+func TestRandomNextIntMatchesJDK(t *testing.T) {
+	globals.InitGlobals("test")
+	r := newSeededRandom(42)
+	expected := []int64{-1170105035, 234785527, -1360544799, 205897768, 1325939940}
+	for i, want := range expected {
+		if got := randomNextInt([]interface{}{r}).(int64); got != want {
+			t.Errorf("nextInt() #%d: expected %d, got %d", i+1, want, got)
+		}
+	}
+}
+
+func TestRandomNextLongMatchesJDK(t *testing.T) {
+	globals.InitGlobals("test")
+	r := newSeededRandom(42)
+	expected := []int64{-5025562857975149833, -5843495416241995736, 5694868678511409995}
+	for i, want := range expected {
+		if got := randomNextLong([]interface{}{r}).(int64); got != want {
+			t.Errorf("nextLong() #%d: expected %d, got %d", i+1, want, got)
+		}
+	}
+}
+
+func TestRandomNextDoubleMatchesJDK(t *testing.T) {
+	globals.InitGlobals("test")
+	r := newSeededRandom(42)
+	expected := []float64{0.7275636800328681, 0.6832234717598454, 0.30871945533265976, 0.27707849007413665}
+	for i, want := range expected {
+		if got := randomNextDouble([]interface{}{r}).(float64); got != want {
+			t.Errorf("nextDouble() #%d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+// nextInt(bound) uses a different algorithm for powers of 2 than for other bounds
+func TestRandomNextIntBoundMatchesJDK(t *testing.T) {
+	globals.InitGlobals("test")
+	r := newSeededRandom(42)
+	expected := []int64{0, 3, 8, 4, 0, 5, 5, 8, 9, 3}
+	for i, want := range expected {
+		if got := randomNextIntBound([]interface{}{r, int64(10)}).(int64); got != want {
+			t.Errorf("nextInt(10) #%d: expected %d, got %d", i+1, want, got)
+		}
+	}
+
+	r = newSeededRandom(42)
+	expected = []int64{11, 0, 10, 0, 4}
+	for i, want := range expected {
+		if got := randomNextIntBound([]interface{}{r, int64(16)}).(int64); got != want {
+			t.Errorf("nextInt(16) #%d: expected %d, got %d", i+1, want, got)
+		}
+	}
+
+	if _, ok := randomNextIntBound([]interface{}{r, int64(0)}).(*GErrBlk); !ok {
+		t.Errorf("nextInt(0): expected an IllegalArgumentException")
+	}
+}
+
+// setSeed() restarts the sequence, and two Randoms with the same seed agree
+func TestRandomSetSeedRestartsSequence(t *testing.T) {
+	globals.InitGlobals("test")
+	r1 := newSeededRandom(42)
+	r2 := newSeededRandom(7)
+	randomSetSeed([]interface{}{r2, int64(42)})
+
+	for i := 0; i < 5; i++ {
+		v1 := randomNextLong([]interface{}{r1}).(int64)
+		v2 := randomNextLong([]interface{}{r2}).(int64)
+		if v1 != v2 {
+			t.Errorf("value #%d: expected both Randoms to return the same value, got %d and %d", i+1, v1, v2)
+		}
+	}
+}
+
+func TestRandomNextBytesAndBoolean(t *testing.T) {
+	globals.InitGlobals("test")
+	r := newSeededRandom(42)
+	bytes := object.MakePrimitiveObject(types.ByteArray, types.ByteArray, make([]byte, 5))
+	randomNextBytes([]interface{}{r, bytes})
+
+	// the first two ints for seed 42 are 0xBA419D35 and 0x0DFE8AF7, used low-order byte first
+	expected := []byte{0x35, 0x9D, 0x41, 0xBA, 0xF7}
+	got := bytes.FieldTable["value"].Fvalue.([]byte)
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("nextBytes(): expected %v, got %v", expected, got)
+			break
+		}
+	}
+
+	r = newSeededRandom(42)
+	if b := randomNextBoolean([]interface{}{r}); b != types.JavaBoolTrue {
+		t.Errorf("nextBoolean(): expected true, got %v", b)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/object"
+	"jacobin/types"
+	"strings"
+)
+
+// Implementation of some of the functions in java/util/StringTokenizer.
+// Strategy: the "str" field holds the part of the input that has not yet been tokenized,
+// and the "delimiters" field holds the set of delimiter characters, both as String objects.
+// A token is a maximal run of characters that are not delimiters. Each call to nextToken()
+// removes the token, and the delimiters before it, from the front of "str".
+
+// the delimiters used when none are specified: space, tab, newline, carriage return, and form feed
+const stringTokenizerDefaultDelims = " \t\n\r\f"
+
+func Load_Util_StringTokenizer() {
+
+	MethodSignatures["java/util/StringTokenizer.<clinit>()V"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  justReturn,
+		}
+
+	MethodSignatures["java/util/StringTokenizer.<init>(Ljava/lang/String;)V"] =
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  stringTokenizerInit,
+		}
+
+	MethodSignatures["java/util/StringTokenizer.<init>(Ljava/lang/String;Ljava/lang/String;)V"] =
+		GMeth{
+			ParamSlots: 2,
+			GFunction:  stringTokenizerInitDelims,
+		}
+
+	MethodSignatures["java/util/StringTokenizer.countTokens()I"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringTokenizerCountTokens,
+		}
+
+	MethodSignatures["java/util/StringTokenizer.hasMoreTokens()Z"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringTokenizerHasMoreTokens,
+		}
+
+	MethodSignatures["java/util/StringTokenizer.nextToken()Ljava/lang/String;"] =
+		GMeth{
+			ParamSlots: 0,
+			GFunction:  stringTokenizerNextToken,
+		}
+
+}
+
+// "java/util/StringTokenizer.<init>(Ljava/lang/String;)V"
+func stringTokenizerInit(params []interface{}) interface{} {
+	delims := object.StringObjectFromGoString(stringTokenizerDefaultDelims)
+	return stringTokenizerInitDelims([]interface{}{params[0], params[1], delims})
+}
+
+// "java/util/StringTokenizer.<init>(Ljava/lang/String;Ljava/lang/String;)V"
+// As in the JDK, a null delimiter set is accepted here, but the tokenizer's other methods
+// then throw a NullPointerException.
+func stringTokenizerInitDelims(params []interface{}) interface{} {
+	tokenizer := params[0].(*object.Object)
+	str, ok := params[1].(*object.Object)
+	if !ok || object.IsNull(str) {
+		return getGErrBlk(excNames.NullPointerException, "StringTokenizer: string to tokenize is null")
+	}
+	delims, ok := params[2].(*object.Object)
+	if !ok {
+		delims = object.Null
+	}
+
+	tokenizer.FieldTable["str"] = object.Field{Ftype: types.StringClassRef, Fvalue: str}
+	tokenizer.FieldTable["delimiters"] = object.Field{Ftype: types.StringClassRef, Fvalue: delims}
+	return nil
+}
+
+// stringTokenizerState returns the untokenized remainder of the tokenizer's input and a
+// function that reports whether a character is a delimiter
+func stringTokenizerState(tokenizer *object.Object) (string, func(rune) bool, *GErrBlk) {
+	delims, ok := tokenizer.FieldTable["delimiters"].Fvalue.(*object.Object)
+	if !ok || object.IsNull(delims) {
+		return "", nil, getGErrBlk(excNames.NullPointerException, "StringTokenizer: delimiters are null")
+	}
+	delimSet := object.GoStringFromStringObject(delims)
+	isDelim := func(r rune) bool { return strings.ContainsRune(delimSet, r) }
+
+	str := object.GoStringFromStringObject(tokenizer.FieldTable["str"].Fvalue.(*object.Object))
+	return str, isDelim, nil
+}
+
+// "java/util/StringTokenizer.hasMoreTokens()Z"
+func stringTokenizerHasMoreTokens(params []interface{}) interface{} {
+	str, isDelim, gerr := stringTokenizerState(params[0].(*object.Object))
+	if gerr != nil {
+		return gerr
+	}
+	if strings.TrimLeftFunc(str, isDelim) == "" {
+		return types.JavaBoolFalse
+	}
+	return types.JavaBoolTrue
+}
+
+// "java/util/StringTokenizer.nextToken()Ljava/lang/String;"
+func stringTokenizerNextToken(params []interface{}) interface{} {
+	tokenizer := params[0].(*object.Object)
+	str, isDelim, gerr := stringTokenizerState(tokenizer)
+	if gerr != nil {
+		return gerr
+	}
+
+	rest := strings.TrimLeftFunc(str, isDelim)
+	if rest == "" {
+		return getGErrBlk(excNames.NoSuchElementException, "StringTokenizer.nextToken(): no more tokens")
+	}
+
+	token := rest
+	if end := strings.IndexFunc(rest, isDelim); end >= 0 {
+		token, rest = rest[:end], rest[end:]
+	} else {
+		rest = ""
+	}
+
+	tokenizer.FieldTable["str"] = object.Field{Ftype: types.StringClassRef,
+		Fvalue: object.StringObjectFromGoString(rest)}
+	return object.StringObjectFromGoString(token)
+}
+
+// "java/util/StringTokenizer.countTokens()I"
+// Returns the number of times nextToken() can be called before it throws an exception.
+func stringTokenizerCountTokens(params []interface{}) interface{} {
+	str, isDelim, gerr := stringTokenizerState(params[0].(*object.Object))
+	if gerr != nil {
+		return gerr
+	}
+	return int64(len(strings.FieldsFunc(str, isDelim)))
+}
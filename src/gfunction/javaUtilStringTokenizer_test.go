@@ -0,0 +1,99 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"testing"
+)
+
+// newTokenizer returns a StringTokenizer for str, using the default delimiters if
+// delims is nil
+func newTokenizer(str string, delims *string) *object.Object {
+	className := "java/util/StringTokenizer"
+	tokenizer := object.MakeEmptyObjectWithClassName(&className)
+	if delims == nil {
+		stringTokenizerInit([]interface{}{tokenizer, object.StringObjectFromGoString(str)})
+	} else {
+		stringTokenizerInitDelims([]interface{}{tokenizer, object.StringObjectFromGoString(str),
+			object.StringObjectFromGoString(*delims)})
+	}
+	return tokenizer
+}
+
+// checkTokens reads all the tokens from tokenizer and checks them against expected,
+// then checks that the tokenizer is exhausted
+func checkTokens(t *testing.T, tokenizer *object.Object, expected []string) {
+	if count := stringTokenizerCountTokens([]interface{}{tokenizer}).(int64); count != int64(len(expected)) {
+		t.Errorf("countTokens(): expected %d, got %d", len(expected), count)
+	}
+
+	for i, want := range expected {
+		if stringTokenizerHasMoreTokens([]interface{}{tokenizer}) != types.JavaBoolTrue {
+			t.Fatalf("hasMoreTokens(): expected true before token %d", i)
+		}
+		token := stringTokenizerNextToken([]interface{}{tokenizer}).(*object.Object)
+		if got := object.GoStringFromStringObject(token); got != want {
+			t.Errorf("nextToken() #%d: expected %q, got %q", i, want, got)
+		}
+		remaining := int64(len(expected) - i - 1)
+		if count := stringTokenizerCountTokens([]interface{}{tokenizer}).(int64); count != remaining {
+			t.Errorf("countTokens() after token %d: expected %d, got %d", i, remaining, count)
+		}
+	}
+
+	if stringTokenizerHasMoreTokens([]interface{}{tokenizer}) != types.JavaBoolFalse {
+		t.Errorf("hasMoreTokens(): expected false once all tokens are read")
+	}
+	ret := stringTokenizerNextToken([]interface{}{tokenizer})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NoSuchElementException {
+		t.Errorf("nextToken(): expected NoSuchElementException when exhausted, got %v", ret)
+	}
+}
+
+func TestStringTokenizerDefaultDelimiters(t *testing.T) {
+	globals.InitGlobals("test")
+	tokenizer := newTokenizer("  the quick\tbrown\n\nfox\r\f", nil)
+	checkTokens(t, tokenizer, []string{"the", "quick", "brown", "fox"})
+}
+
+func TestStringTokenizerCustomDelimiters(t *testing.T) {
+	globals.InitGlobals("test")
+	delims := ",;"
+	tokenizer := newTokenizer("a,b;;c d,,", &delims)
+	checkTokens(t, tokenizer, []string{"a", "b", "c d"})
+}
+
+func TestStringTokenizerNoTokens(t *testing.T) {
+	globals.InitGlobals("test")
+	checkTokens(t, newTokenizer("", nil), []string{})
+	checkTokens(t, newTokenizer(" \t ", nil), []string{})
+}
+
+func TestStringTokenizerNullArguments(t *testing.T) {
+	globals.InitGlobals("test")
+	className := "java/util/StringTokenizer"
+	tokenizer := object.MakeEmptyObjectWithClassName(&className)
+
+	ret := stringTokenizerInit([]interface{}{tokenizer, object.Null})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("<init>(null): expected NullPointerException, got %v", ret)
+	}
+
+	// null delimiters are accepted by the constructor, but not when tokenizing
+	ret = stringTokenizerInitDelims([]interface{}{tokenizer, object.StringObjectFromGoString("a b"), object.Null})
+	if ret != nil {
+		t.Fatalf("<init>(str, null): unexpected error: %v", ret)
+	}
+	ret = stringTokenizerNextToken([]interface{}{tokenizer})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("nextToken() with null delimiters: expected NullPointerException, got %v", ret)
+	}
+}
@@ -7,8 +7,11 @@
 package gfunction
 
 import (
+	"fmt"
 	"jacobin/excNames"
 	"jacobin/object"
+	"jacobin/types"
+	"sync"
 )
 
 /*
@@ -27,6 +30,19 @@ import (
  could mean an empty slice).
 */
 
+/*
+ The base classes use jdk.internal.misc.Unsafe to read and update fields atomically. In the
+ JDK, an offset is the distance in memory of a field from the start of its object, or of an
+ array element from the start of its array. Jacobin's objects hold their fields by name,
+ so here objectFieldOffset1() gives each field name an offset, which the methods that take
+ an offset map back to the field name. For an array, the offset is simply the index of the
+ element: the base offset is 0 and the index scale is 1.
+
+ Implemented: arrayBaseOffset, arrayIndexScale, compareAndSetInt, getAndAddInt,
+ getIntVolatile, getUnsafe, and objectFieldOffset1, for int fields and int arrays.
+ Stubbed: <clinit>, which in the JDK registers the native methods, does nothing.
+*/
+
 func Load_Jdk_Internal_Misc_Unsafe() {
 
 	MethodSignatures["jdk/internal/misc/Unsafe.<clinit>()V"] =
@@ -41,6 +57,12 @@ func Load_Jdk_Internal_Misc_Unsafe() {
 			GFunction:  arrayBaseOffset,
 		}
 
+	MethodSignatures["jdk/internal/misc/Unsafe.arrayIndexScale(Ljava/lang/Class;)I"] = // offset between items in an array
+		GMeth{
+			ParamSlots: 1,
+			GFunction:  arrayIndexScale,
+		}
+
 	MethodSignatures["jdk/internal/misc/Unsafe.getIntVolatile(Ljava/lang/Object;J)I"] =
 		GMeth{
 			ParamSlots: 3,
@@ -56,7 +78,7 @@ func Load_Jdk_Internal_Misc_Unsafe() {
 	MethodSignatures["jdk/internal/misc/Unsafe.getAndAddInt(Ljava/lang/Object;JI)I"] =
 		GMeth{
 			ParamSlots: 4,
-			GFunction:  unsafeCompareAndSetInt,
+			GFunction:  unsafeGetAndAddInt,
 		}
 
 	MethodSignatures["jdk/internal/misc/Unsafe.getUnsafe()Ljdk/internal/misc/Unsafe;"] =
@@ -67,7 +89,7 @@ func Load_Jdk_Internal_Misc_Unsafe() {
 
 	MethodSignatures["jdk/internal/misc/Unsafe.objectFieldOffset1(Ljava/lang/Class;Ljava/lang/String;)J"] =
 		GMeth{
-			ParamSlots: 0,
+			ParamSlots: 2,
 			GFunction:  unsafeObjectFieldOffset1,
 		}
 
@@ -75,62 +97,177 @@ func Load_Jdk_Internal_Misc_Unsafe() {
 
 var classUnsafeName = "jdk/internal/misc/Unsafe"
 
+// the offsets given out by objectFieldOffset1(), by field name, and the field names by offset.
+// Offsets start at unsafeFirstFieldOffset, as the first field follows the object header.
+const unsafeFirstFieldOffset = 16
+
+var unsafeFieldOffsets = make(map[string]int64)
+var unsafeOffsetFields = make(map[int64]string)
+
+// unsafeLock makes reading and updating a value through Unsafe atomic.
+var unsafeLock sync.Mutex
+
 // Return the number of bytes between the beginning of the object and the first element.
 // This is used in computing the pointer to a given element
 // "jdk/internal/misc/Unsafe.arrayBaseOffset(Ljava/lang/Class;)I"
 func arrayBaseOffset(params []interface{}) interface{} {
-	p := params[0]
+	p := params[1]
+	if p == nil || p == object.Null {
+		errMsg := "Object is a null pointer"
+		return getGErrBlk(excNames.NullPointerException, errMsg)
+	}
+	return int64(0)
+}
+
+// Return the difference between the offsets of consecutive elements of an array. As the
+// offset of an element is its index, this is 1.
+// "jdk/internal/misc/Unsafe.arrayIndexScale(Ljava/lang/Class;)I"
+func arrayIndexScale(params []interface{}) interface{} {
+	p := params[1]
 	if p == nil || p == object.Null {
 		errMsg := "Object is a null pointer"
 		return getGErrBlk(excNames.NullPointerException, errMsg)
 	}
-	return int64(0) // this should work...
+	return int64(1)
+}
+
+// getUnsafeInt returns the int at the offset in obj, which is either an int field or, if obj
+// is an int array, an element. The caller must hold unsafeLock.
+func getUnsafeInt(obj *object.Object, offset int64) (int64, *GErrBlk) {
+	if object.IsNull(obj) {
+		return 0, getGErrBlk(excNames.NullPointerException, "Unsafe: object is null")
+	}
+
+	if arr, ok := obj.FieldTable["value"].Fvalue.([]int64); ok && obj.FieldTable["value"].Ftype == types.IntArray {
+		if offset < 0 || offset >= int64(len(arr)) {
+			errMsg := fmt.Sprintf("Unsafe: offset %d is outside the array of length %d", offset, len(arr))
+			return 0, getGErrBlk(excNames.ArrayIndexOutOfBoundsException, errMsg)
+		}
+		return arr[offset], nil
+	}
+
+	fieldName, ok := unsafeOffsetFields[offset]
+	if !ok {
+		errMsg := fmt.Sprintf("Unsafe: %d is not the offset of a field", offset)
+		return 0, getGErrBlk(excNames.InternalError, errMsg)
+	}
+	field, ok := obj.FieldTable[fieldName]
+	if !ok {
+		errMsg := fmt.Sprintf("Unsafe: object of class %s has no field %s",
+			object.GoStringFromStringPoolIndex(obj.KlassName), fieldName)
+		return 0, getGErrBlk(excNames.InternalError, errMsg)
+	}
+	value, ok := field.Fvalue.(int64)
+	if !ok {
+		errMsg := fmt.Sprintf("Unsafe: field %s is not an int", fieldName)
+		return 0, getGErrBlk(excNames.InternalError, errMsg)
+	}
+	return value, nil
+}
+
+// setUnsafeInt sets the int at the offset in obj, which getUnsafeInt() has already read
+// without error. The caller must hold unsafeLock.
+func setUnsafeInt(obj *object.Object, offset int64, value int64) {
+	if arr, ok := obj.FieldTable["value"].Fvalue.([]int64); ok && obj.FieldTable["value"].Ftype == types.IntArray {
+		arr[offset] = value
+		return
+	}
+	fieldName := unsafeOffsetFields[offset]
+	field := obj.FieldTable[fieldName]
+	field.Fvalue = value
+	obj.FieldTable[fieldName] = field
 }
 
-// SWAG
 // "jdk/internal/misc/Unsafe.getIntVolatile(Ljava/lang/Object;J)I"
 func unsafeGetIntVolatile(params []interface{}) interface{} {
-	var hash int64
-	switch params[1].(type) {
-	case nil:
-		hash = 0
-	case *object.Object:
-		obj := params[0].(*object.Object)
-		hash = int64(obj.Mark.Hash)
-	}
+	// params[0]: the Unsafe object
+	// params[1]: the object holding the int
+	// params[2]: the offset (a long, so it occupies params[2] and params[3])
+	obj, _ := params[1].(*object.Object)
 	offset := params[2].(int64)
-	wint := hash + offset
-	return wint
+
+	unsafeLock.Lock()
+	defer unsafeLock.Unlock()
+	value, gerr := getUnsafeInt(obj, offset)
+	if gerr != nil {
+		return gerr
+	}
+	return value
 }
 
-// SWAG
+// Sets the int to x if it is expected, as a single atomic operation, and returns whether it did.
 // "jdk/internal/misc/Unsafe.compareAndSetInt(Ljava/lang/Object;JII)Z"
 func unsafeCompareAndSetInt(params []interface{}) interface{} {
-	return int64(1) // SWAG
+	// params[0]: the Unsafe object
+	// params[1]: the object holding the int
+	// params[2]: the offset (a long, so it occupies params[2] and params[3])
+	// params[4]: the expected value
+	// params[5]: the new value
+	obj, _ := params[1].(*object.Object)
+	offset := params[2].(int64)
+	expected := params[4].(int64)
+	x := params[5].(int64)
+
+	unsafeLock.Lock()
+	defer unsafeLock.Unlock()
+	value, gerr := getUnsafeInt(obj, offset)
+	if gerr != nil {
+		return gerr
+	}
+	if value != expected {
+		return types.JavaBoolFalse
+	}
+	setUnsafeInt(obj, offset, x)
+	return types.JavaBoolTrue
 }
 
-// SWAG
+// Adds delta to the int, as a single atomic operation, and returns the int's previous value.
 // "jdk/internal/misc/Unsafe.getAndAddInt(Ljava/lang/Object;JI)I"
 func unsafeGetAndAddInt(params []interface{}) interface{} {
-	var hash int64
-	switch params[1].(type) {
-	case nil:
-		hash = 0
-	case *object.Object:
-		obj := params[0].(*object.Object)
-		hash = int64(obj.Mark.Hash)
-	}
+	// params[0]: the Unsafe object
+	// params[1]: the object holding the int
+	// params[2]: the offset (a long, so it occupies params[2] and params[3])
+	// params[4]: delta
+	obj, _ := params[1].(*object.Object)
 	offset := params[2].(int64)
 	delta := params[4].(int64)
-	wint := hash + offset + delta
-	return wint
+
+	unsafeLock.Lock()
+	defer unsafeLock.Unlock()
+	value, gerr := getUnsafeInt(obj, offset)
+	if gerr != nil {
+		return gerr
+	}
+	setUnsafeInt(obj, offset, int64(int32(value+delta))) // wraps as an int does
+	return value
 }
 
+// "jdk/internal/misc/Unsafe.getUnsafe()Ljdk/internal/misc/Unsafe;"
 func unsafeGetUnsafe([]interface{}) interface{} {
 	obj := object.MakeEmptyObjectWithClassName(&classUnsafeName)
 	return obj
 }
 
-func unsafeObjectFieldOffset1([]interface{}) interface{} {
-	return int64(0)
+// Returns the offset of the named field, which is the same for every class that has a
+// field of that name. The class is not consulted, as the field is found by name.
+// "jdk/internal/misc/Unsafe.objectFieldOffset1(Ljava/lang/Class;Ljava/lang/String;)J"
+func unsafeObjectFieldOffset1(params []interface{}) interface{} {
+	// params[0]: the Unsafe object
+	// params[1]: the class
+	// params[2]: the field name
+	name, ok := params[2].(*object.Object)
+	if !ok || object.IsNull(name) {
+		return getGErrBlk(excNames.NullPointerException, "Unsafe.objectFieldOffset1: field name is null")
+	}
+	fieldName := object.GoStringFromStringObject(name)
+
+	unsafeLock.Lock()
+	defer unsafeLock.Unlock()
+	offset, ok := unsafeFieldOffsets[fieldName]
+	if !ok {
+		offset = unsafeFirstFieldOffset + int64(len(unsafeFieldOffsets))*8
+		unsafeFieldOffsets[fieldName] = offset
+		unsafeOffsetFields[offset] = fieldName
+	}
+	return offset
 }
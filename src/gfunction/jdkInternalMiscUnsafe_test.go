@@ -0,0 +1,93 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package gfunction
+
+import (
+	"jacobin/excNames"
+	"jacobin/globals"
+	"jacobin/object"
+	"jacobin/types"
+	"math"
+	"testing"
+)
+
+func TestUnsafeFieldOffsets(t *testing.T) {
+	globals.InitGlobals("test")
+	u := unsafeGetUnsafe(nil)
+
+	offset := unsafeObjectFieldOffset1([]interface{}{u, "Counter", object.StringObjectFromGoString("count")})
+	other := unsafeObjectFieldOffset1([]interface{}{u, "Counter", object.StringObjectFromGoString("other")})
+	again := unsafeObjectFieldOffset1([]interface{}{u, "Other", object.StringObjectFromGoString("count")})
+	if offset == other {
+		t.Errorf("objectFieldOffset1(): expected different fields to have different offsets, both got %v", offset)
+	}
+	if offset != again {
+		t.Errorf("objectFieldOffset1(): expected the same field to have the same offset, got %v and %v", offset, again)
+	}
+
+	ret := unsafeObjectFieldOffset1([]interface{}{u, "Counter", object.Null})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("objectFieldOffset1(null name): expected a NullPointerException, got %v", ret)
+	}
+}
+
+func TestUnsafeIntField(t *testing.T) {
+	globals.InitGlobals("test")
+	u := unsafeGetUnsafe(nil)
+	className := "Counter"
+	counter := object.MakeEmptyObjectWithClassName(&className)
+	counter.FieldTable["count"] = object.Field{Ftype: types.Int, Fvalue: int64(math.MaxInt32)}
+	offset := unsafeObjectFieldOffset1([]interface{}{u, "Counter", object.StringObjectFromGoString("count")}).(int64)
+
+	// getAndAddInt() returns the old value and wraps as an int does
+	if ret := unsafeGetAndAddInt([]interface{}{u, counter, offset, offset, int64(1)}); ret != int64(math.MaxInt32) {
+		t.Errorf("getAndAddInt(): expected %d, got %v", math.MaxInt32, ret)
+	}
+	if ret := unsafeGetIntVolatile([]interface{}{u, counter, offset, offset}); ret != int64(math.MinInt32) {
+		t.Errorf("getIntVolatile(): expected %d, got %v", math.MinInt32, ret)
+	}
+
+	ret := unsafeCompareAndSetInt([]interface{}{u, counter, offset, offset, int64(0), int64(1)})
+	if ret != types.JavaBoolFalse || counter.FieldTable["count"].Fvalue != int64(math.MinInt32) {
+		t.Errorf("compareAndSetInt() with the wrong expected value: expected false and no change, got %v and %v",
+			ret, counter.FieldTable["count"].Fvalue)
+	}
+	ret = unsafeCompareAndSetInt([]interface{}{u, counter, offset, offset, int64(math.MinInt32), int64(1)})
+	if ret != types.JavaBoolTrue || counter.FieldTable["count"].Fvalue != int64(1) {
+		t.Errorf("compareAndSetInt(): expected true and count of 1, got %v and %v",
+			ret, counter.FieldTable["count"].Fvalue)
+	}
+
+	ret = unsafeGetIntVolatile([]interface{}{u, object.Null, offset, offset})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.NullPointerException {
+		t.Errorf("getIntVolatile(null): expected a NullPointerException, got %v", ret)
+	}
+	ret = unsafeGetIntVolatile([]interface{}{u, counter, int64(3), int64(3)})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.InternalError {
+		t.Errorf("getIntVolatile() with an invalid offset: expected an InternalError, got %v", ret)
+	}
+}
+
+func TestUnsafeIntArray(t *testing.T) {
+	globals.InitGlobals("test")
+	u := unsafeGetUnsafe(nil)
+	arr := object.Make1DimArray(object.INT, 3)
+	base := arrayBaseOffset([]interface{}{u, "[I"}).(int64)
+	scale := arrayIndexScale([]interface{}{u, "[I"}).(int64)
+
+	offset := base + 2*scale
+	unsafeGetAndAddInt([]interface{}{u, arr, offset, offset, int64(7)})
+	if got := arr.FieldTable["value"].Fvalue.([]int64); got[2] != 7 || got[0] != 0 || got[1] != 0 {
+		t.Errorf("getAndAddInt() on element 2: expected [0 0 7], got %v", got)
+	}
+
+	offset = base + 3*scale
+	ret := unsafeGetIntVolatile([]interface{}{u, arr, offset, offset})
+	if gerr, ok := ret.(*GErrBlk); !ok || gerr.ExceptionType != excNames.ArrayIndexOutOfBoundsException {
+		t.Errorf("getIntVolatile() past the end of the array: expected an ArrayIndexOutOfBoundsException, got %v", ret)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"jacobin/types"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"sync"
 )
@@ -28,8 +27,9 @@ var StringEnvVarHeadless = "java.awt.headless"
 type Globals struct {
 	// ---- jacobin version number ----
 	// note: all references to version number must come from this literal
-	Version string
-	VmModel string // "client" or "server" (both the same acc. to JVM docs)
+	Version  string
+	VmModel  string // "client" or "server" (both the same acc. to JVM docs)
+	ExecMode string // reported by -version. Jacobin only interprets, so always "interpreted mode"
 
 	// ---- processing stoppage? ----
 	ExitNow bool
@@ -67,7 +67,20 @@ type Globals struct {
 	JacobinBuildData map[string]string
 
 	// ---- special switches ----
-	StrictJDK bool // hew closely to actions and error messages of the JDK
+	StrictJDK         bool // hew closely to actions and error messages of the JDK
+	DisableExplicitGC bool // -XX:+DisableExplicitGC: System.gc() and Runtime.gc() do nothing
+
+	// ---- heap sizes, which are informational only, as Jacobin uses Go's heap ----
+	InitialHeapSize int64 // -Xms, in bytes; 0 if not specified
+	MaxHeapSize     int64 // -Xmx, in bytes; 0 if not specified
+
+	// ---- stack limits ----
+	ThreadStackSize        int64 // -Xss, in bytes; determines how deep the frame stack can grow
+	MaxJavaStackTraceDepth int   // -XX:MaxJavaStackTraceDepth, the most stack trace lines shown; 0 = all
+
+	// ---- profiling, shown at exit ----
+	ProfileOpcodes bool // -Xprof:opcodes: count the executions of each opcode
+	ProfileMethods bool // -Xprof:methods: count the calls of each method
 
 	// ---- list of addresses of arrays, see jvm/arrays.go for info ----
 	ArrayAddressList *list.List
@@ -89,14 +102,22 @@ type Globals struct {
 	AtomicIntegerLock sync.Mutex
 
 	// ---- misc properties
-	FileEncoding string // what file encoding are we using?
-	Headless     bool   // Headless?
+	FileEncoding   string // what file encoding are we using?
+	StdoutEncoding string // the encoding of what System.out writes to the console
+	StderrEncoding string // the encoding of what System.err writes to the console
+	FileSeparator  string // separates the names in a file path (file.separator)
+	PathSeparator  string // separates the entries in a list of paths, such as the classpath (path.separator)
+	ClassPath      string // the classpath given by -cp, -classpath, or --class-path
+	Headless       bool   // Headless?
 
 	// Get around the golang circular dependency. To be set up in jvmStart.go
 	// Enables gfunctions to call these functions through a global variable.
 	FuncInstantiateClass func(string, *list.List) (any, error)
 	FuncThrowException   func(int, string)
 	FuncFillInStackTrace func([]any) any
+	FuncRunThread        func(any) error
+	FuncInvokeMethod     func(any, string, string) (any, error)
+	FuncShowProfile      func()
 }
 
 // ----- String Pool
@@ -111,11 +132,15 @@ var LoaderWg sync.WaitGroup
 
 var global Globals
 
+// DefaultThreadStackSize is the stack size, in bytes, if -Xss is not specified
+const DefaultThreadStackSize = 1 << 20
+
 // InitGlobals initializes the global values that are known at start-up
 func InitGlobals(progName string) Globals {
 	global = Globals{
 		Version:           "0.5.0",
 		VmModel:           "server",
+		ExecMode:          "interpreted mode",
 		ExitNow:           false,
 		JacobinName:       progName,
 		JacobinHome:       "",
@@ -139,6 +164,8 @@ func InitGlobals(progName string) Globals {
 		GoStackShown:         false,
 		FuncInstantiateClass: fakeInstantiateClass,
 		FuncThrowException:   fakeThrowEx,
+		FuncRunThread:        fakeRunThread,
+		FuncInvokeMethod:     fakeInvokeMethod,
 	}
 
 	// ----- String Pool and other values
@@ -154,11 +181,25 @@ func InitGlobals(progName string) Globals {
 	}
 	InitArrayAddressList()
 
-	if runtime.GOOS == "windows" {
-		global.FileEncoding = "windows-1252"
-	} else {
-		global.FileEncoding = "UTF-8"
-	}
+	// As in JDK 18 and later, the default charset is UTF-8 on all platforms. It can be
+	// changed with -Dfile.encoding
+	global.FileEncoding = "UTF-8"
+
+	// The console encodings can be changed with -Dstdout.encoding and -Dstderr.encoding.
+	// They default to UTF-8 on all platforms: on Windows, Go writes console output as
+	// UTF-16 that it converts from UTF-8, so non-ASCII text renders correctly whatever the
+	// console's code page is.
+	global.StdoutEncoding = "UTF-8"
+	global.StderrEncoding = "UTF-8"
+
+	// The separators default to the platform's, and can be changed with -Dfile.separator
+	// and -Dpath.separator
+	global.FileSeparator = string(os.PathSeparator)
+	global.PathSeparator = string(os.PathListSeparator)
+
+	// The stack limits default to HotSpot's on 64-bit platforms
+	global.ThreadStackSize = DefaultThreadStackSize
+	global.MaxJavaStackTraceDepth = 1024
 
 	// Set up headlass boolean.
 	strHeadless := os.Getenv(StringEnvVarHeadless)
@@ -295,6 +336,19 @@ func fakeThrowEx(whichEx int, msg string) {
 	fmt.Fprintf(os.Stderr, errMsg)
 }
 
+// Fake RunThread. Function is set up in jvmStart.go.
+func fakeRunThread(runnable any) error {
+	errMsg := "\n*Attempt to access uninitialized RunThread pointer func\n"
+	fmt.Fprint(os.Stderr, errMsg)
+	return errors.New(errMsg)
+}
+
+// Fake InvokeMethod. Function is set up in jvmStart.go. Unlike the other fakes, it
+// prints nothing, as its callers fall back to default behavior when it fails.
+func fakeInvokeMethod(obj any, methName, methType string) (any, error) {
+	return nil, errors.New("uninitialized InvokeMethod pointer func")
+}
+
 func InitStringPool() {
 
 	StringPoolLock.Lock()
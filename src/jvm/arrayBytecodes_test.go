@@ -7,6 +7,7 @@
 package jvm
 
 import (
+	"fmt"
 	"io"
 	"jacobin/classloader"
 	"jacobin/frames"
@@ -2559,3 +2560,245 @@ func TestSastore(t *testing.T) {
 		t.Errorf("SASTORE: Expected sum of array entries to be 100, got: %d", sum)
 	}
 }
+
+// Every array load and store checks its index: an index of -1 or of the array's length
+// results in an ArrayIndexOutOfBoundsException whose message includes the bad index.
+func TestArrayIndexOutOfBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      byte
+		arrType uint8
+		value   any // nil for loads
+		slots   int // stack slots the stored value takes
+	}{
+		{"IALOAD", opcodes.IALOAD, object.INT, nil, 0},
+		{"LALOAD", opcodes.LALOAD, object.INT, nil, 0},
+		{"FALOAD", opcodes.FALOAD, object.FLOAT, nil, 0},
+		{"DALOAD", opcodes.DALOAD, object.FLOAT, nil, 0},
+		{"AALOAD", opcodes.AALOAD, object.REF, nil, 0},
+		{"BALOAD", opcodes.BALOAD, object.BYTE, nil, 0},
+		{"CALOAD", opcodes.CALOAD, object.INT, nil, 0},
+		{"SALOAD", opcodes.SALOAD, object.INT, nil, 0},
+		{"IASTORE", opcodes.IASTORE, object.INT, int64(1), 1},
+		{"LASTORE", opcodes.LASTORE, object.INT, int64(1), 2},
+		{"FASTORE", opcodes.FASTORE, object.FLOAT, 1.0, 1},
+		{"DASTORE", opcodes.DASTORE, object.FLOAT, 1.0, 2},
+		{"AASTORE", opcodes.AASTORE, object.REF, object.Null, 1},
+		{"BASTORE", opcodes.BASTORE, object.BYTE, int64(1), 1},
+		{"CASTORE", opcodes.CASTORE, object.INT, int64(1), 1},
+		{"SASTORE", opcodes.SASTORE, object.INT, int64(1), 1},
+	}
+
+	globals.InitGlobals("test")
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { _ = w.Close(); os.Stderr = normalStderr }()
+
+	for _, tt := range tests {
+		for _, index := range []int64{-1, 3} {
+			f := newFrame(tt.op)
+			push(&f, object.Make1DimArray(tt.arrType, 3))
+			push(&f, index)
+			for i := 0; i < tt.slots; i++ {
+				push(&f, tt.value)
+			}
+			fs := frames.CreateFrameStack()
+			fs.PushFront(&f)
+
+			err := runFrame(fs)
+			if err == nil {
+				t.Errorf("%s: expected an error for index %d, got none", tt.name, index)
+				continue
+			}
+
+			expected := fmt.Sprintf("Invalid array subscript: %d,", index)
+			if tt.slots > 0 {
+				expected = fmt.Sprintf("array index is %d", index)
+			}
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("%s: expected the error to contain %q, got: %s", tt.name, expected, err.Error())
+			}
+		}
+	}
+}
+
+// IALOAD: an out-of-bounds index throws an ArrayIndexOutOfBoundsException that the
+// program can catch
+func TestIaloadOutOfBoundsIsCaught(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { _ = w.Close(); os.Stderr = normalStderr }()
+
+	aioobeName := "java/lang/ArrayIndexOutOfBoundsException"
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 2)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&aioobeName)}
+
+	classloader.MethAreaInsert(aioobeName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            aioobeName,
+			NameIndex:       stringPool.GetStringIndex(&aioobeName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	f := frames.CreateFrame(2)
+	f.Ftype = 'J'
+	f.ClName = "Reader"
+	f.MethName = "read"
+	f.MethType = "()V"
+	f.CP = &CP
+	f.Meth = []byte{
+		opcodes.ALOAD_0, opcodes.ICONST_3, opcodes.IALOAD, opcodes.ISTORE_1, opcodes.RETURN,
+		opcodes.ASTORE_2, opcodes.ICONST_M1, opcodes.ISTORE_1, opcodes.RETURN,
+	}
+	f.Locals = []interface{}{object.Make1DimArray(object.INT, 3), int64(0), nil}
+	classloader.MTable = make(map[string]classloader.MTentry)
+	classloader.MTable["Reader.read()V"] = classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{
+		MaxStack:   2,
+		MaxLocals:  3,
+		Cp:         &CP,
+		Code:       f.Meth,
+		Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 4, HandlerPc: 5, CatchType: 1}},
+	}}
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("expected the ArrayIndexOutOfBoundsException to be caught, got: %s", err.Error())
+	}
+	if f.Locals[1] != int64(-1) {
+		t.Errorf("expected the catch block to set -1, got: %v", f.Locals[1])
+	}
+	exc, ok := f.Locals[2].(*object.Object)
+	if !ok {
+		t.Fatalf("expected the exception object in local 2, got: %T", f.Locals[2])
+	}
+	if excClass := object.GoStringFromStringPoolIndex(exc.KlassName); excClass != aioobeName {
+		t.Errorf("expected an ArrayIndexOutOfBoundsException, got: %s", excClass)
+	}
+}
+
+// AASTORE: a String or null can be stored in an array of Strings, but an Integer can't:
+// it results in an ArrayStoreException
+func TestAastoreComponentTypeCheck(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { _ = w.Close(); os.Stderr = normalStderr }()
+
+	integerName := "java/lang/Integer"
+	classloader.MethAreaInsert(integerName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            integerName,
+			NameIndex:       stringPool.GetStringIndex(&integerName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	stringName := types.StringClassName
+	arrayPtr := object.Make1DimRefArray(&stringName, 3)
+	str := object.StringObjectFromGoString("test")
+
+	store := func(value *object.Object, index int64) error {
+		f := newFrame(opcodes.AASTORE)
+		push(&f, arrayPtr)
+		push(&f, index)
+		push(&f, value)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f)
+		return runFrame(fs)
+	}
+
+	if err := store(str, 0); err != nil {
+		t.Errorf("AASTORE: expected storing a String in a String array to succeed, got: %s", err.Error())
+	}
+	if err := store(object.Null, 1); err != nil {
+		t.Errorf("AASTORE: expected storing null in a String array to succeed, got: %s", err.Error())
+	}
+
+	err := store(object.MakeEmptyObjectWithClassName(&integerName), 2)
+	if err == nil {
+		t.Fatalf("AASTORE: expected an error storing an Integer in a String array, got none")
+	}
+	if !strings.Contains(err.Error(), "java.lang.Integer cannot be stored in an array of java.lang.String") {
+		t.Errorf("AASTORE: got unexpected error message: %s", err.Error())
+	}
+
+	rawArray := arrayPtr.FieldTable["value"].Fvalue.([]*object.Object)
+	if rawArray[0] != str {
+		t.Errorf("AASTORE: expected the String in array[0], got: %v", rawArray[0])
+	}
+	if rawArray[1] != nil || rawArray[2] != nil {
+		t.Errorf("AASTORE: expected array[1] and array[2] to be null, got: %v, %v", rawArray[1], rawArray[2])
+	}
+}
+
+// AASTORE: a row of a char[][], as in char[][] c = new char[2][]; c[0] = new char[3];, is
+// stored as Jacobin stores char arrays, as [I. It can be stored in the array of char[]s, as
+// can the rows of arrays of other primitive types stored the same way, but not a row of a
+// type Jacobin stores differently.
+func TestAastoreArrayOfPrimitiveArrays(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { _ = w.Close(); os.Stderr = normalStderr }()
+
+	store := func(componentType string, value *object.Object) error {
+		f := newFrame(opcodes.AASTORE)
+		push(&f, object.Make1DimRefArray(&componentType, 2))
+		push(&f, int64(0))
+		push(&f, value)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f)
+		return runFrame(fs)
+	}
+
+	// the arrays that NEWARRAY creates for char[], short[], long[], boolean[], and double[]
+	rows := map[string]*object.Object{
+		"[C": object.Make1DimArray(object.INT, 3),
+		"[S": object.Make1DimArray(object.INT, 3),
+		"[J": object.Make1DimArray(object.INT, 3),
+		"[Z": object.Make1DimArray(object.BYTE, 3),
+		"[D": object.Make1DimArray(object.FLOAT, 3),
+	}
+	for componentType, row := range rows {
+		if err := store(componentType, row); err != nil {
+			t.Errorf("AASTORE: expected storing a %s row to succeed, got: %s", componentType, err.Error())
+		}
+	}
+
+	// a row of a multidimensional array can be stored in an array of such rows
+	charArrayType := "[C"
+	if err := store("[[C", object.Make1DimRefArray(&charArrayType, 2)); err != nil {
+		t.Errorf("AASTORE: expected storing a char[][] row to succeed, got: %s", err.Error())
+	}
+
+	err := store("[C", object.Make1DimArray(object.FLOAT, 3))
+	if err == nil || !strings.Contains(err.Error(), "cannot be stored in an array of") {
+		t.Errorf("AASTORE: expected an error storing a float[] in an array of char[]s, got: %v", err)
+	}
+}
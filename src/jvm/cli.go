@@ -58,6 +58,13 @@ func HandleCli(osArgs []string, Global *globals.Globals) (err error) {
 			continue // skip the arg if there was a problem. (Might want to revisit this.)
 		}
 
+		// a .java file would be run in source-file mode, which Jacobin doesn't support.
+		// So say so, rather than try to load the file as a class
+		if strings.HasSuffix(option, ".java") {
+			sourceFileModeNotSupported(option, Global)
+			break
+		}
+
 		// if the option is the name of the class to execute, note that then get
 		// all successive arguments and store them as app args in globPtr
 		if strings.HasSuffix(option, ".class") {
@@ -85,16 +92,30 @@ func HandleCli(osArgs []string, Global *globals.Globals) (err error) {
 }
 
 // pass in the option potentially with embedded arguments and get back
-// the option name and the embedded argument(s), if any
+// the option name and the embedded argument(s), if any. The argument is found by
+// these rules, in this order of precedence:
+//  1. -Xms, -Xmx, and -Xss: the size follows the option name directly, as in -Xmx512m
+//  2. -D: the property's value follows the first =, so that the property's name
+//     and value can contain a :, as in -Dkey:with=colon and -Dk=a:b
+//  3. all other options: the value follows the first :, as in -verbose:fine and
+//     -XX:Name=value, or, if there is no :, the first =
 func getOptionRootAndArgs(option string) (string, string, error) {
 	if len(option) == 0 {
 		return "", "", errors.New("empty option error")
 	}
 
-	// if the option has an embedded arg value, it'll come after a : or an =
-	argMarker := strings.Index(option, ":")
-	if argMarker == -1 {
+	var argMarker int
+	switch {
+	case strings.HasPrefix(option, "-Xms") || strings.HasPrefix(option, "-Xmx") ||
+		strings.HasPrefix(option, "-Xss"):
+		return option[:4], option[4:], nil
+	case strings.HasPrefix(option, "-D"):
 		argMarker = strings.Index(option, "=")
+	default:
+		argMarker = strings.Index(option, ":")
+		if argMarker == -1 {
+			argMarker = strings.Index(option, "=")
+		}
 	}
 
 	// if there's no embedded : or = then the option doesn't contain an arg value
@@ -103,7 +124,6 @@ func getOptionRootAndArgs(option string) (string, string, error) {
 	}
 
 	return option[:argMarker], option[argMarker+1:], nil
-
 }
 
 // you can can set JVM options using the three environment variables that are
@@ -151,10 +171,20 @@ are passed as the arguments to main class.
 
 where options include:
 	-client       to select the "client" VM
+	-cp <class search path of directories and jar files>
+	-classpath <class search path of directories and jar files>
+	--class-path <class search path of directories and jar files>
+	              A list of directories and JAR archives to search for
+	              class files, separated by the path separator
+	-D<name>=<value>
+	              set a system property. file.encoding, file.separator,
+	              path.separator, stdout.encoding, and stderr.encoding
+	              are supported
 	-verbose:[class|info|fine|finest]  enable verbose output
                   info, fine, finest are Jacobin-specific options providing
                     increasing amounts of detail. The finest level is used
                     primarily for performance analysis.
+	-verbose:[warning|severe]  show only warnings and errors (the default)
 	-? -h -help   print this help message to the error stream
 	--help        print this help message to the output stream
 	-version      print product version to the error stream and exit
@@ -165,7 +195,25 @@ where options include:
 
 Jacobin-specific options:
 	-strictJDK    make user messages conform closely to the JDK's format
-	-trace:inst   display instruction-level tracing data to the console`
+	-trace:inst   display instruction-level tracing data to the console
+
+Extra options:
+	-Xint         interpreted mode execution only (Jacobin's only mode)
+	-Xms<size>    set initial Java heap size (accepted for compatibility;
+	              Jacobin uses Go's heap)
+	-Xmx<size>    set maximum Java heap size, as reported by
+	              Runtime.maxMemory() (Jacobin uses Go's heap)
+	-Xss<size>    set Java thread stack size, which limits the depth
+	              of method calls before a StackOverflowError
+	-Xcomp        not supported: Jacobin warns and runs in interpreted mode
+	-Xprof:opcodes
+	              count the executions of each opcode and show the
+	              counts on the error stream at exit
+	-Xprof:methods
+	              count the calls of each method and show the counts
+	              on the error stream at exit
+	-Xprintcodes <classfile>
+	              print the bytecodes of each method in the class and exit`
 
 	_, _ = fmt.Fprintln(outStream, userMessage)
 }
@@ -181,7 +229,8 @@ func showVersion(outStream *os.File, global *globals.Globals) {
 	}
 
 	ver := fmt.Sprintf(
-		"Jacobin VM v. %s (Java %d) %s\n64-bit %s VM", global.Version, global.MaxJavaVersion, exeDate, global.VmModel)
+		"Jacobin VM v. %s (Java %d) %s\n64-bit %s VM (%s)", global.Version, global.MaxJavaVersion, exeDate,
+		global.VmModel, global.ExecMode)
 	_, _ = fmt.Fprintln(outStream, ver)
 
 	if !strings.Contains(global.CommandLine, "-strictJDK") {
@@ -8,6 +8,8 @@ package jvm
 
 import (
 	"io"
+	"jacobin/classloader"
+	"jacobin/gfunction"
 	"jacobin/globals"
 	"jacobin/log"
 	"os"
@@ -275,6 +277,52 @@ func TestChangeLoggingLevels(t *testing.T) {
 	}
 }
 
+// each -verbose:<level> option sets the corresponding logging level. Warnings are always
+// shown, so -verbose:severe sets the level to WARNING
+func TestVerboseOptionsSetLogLevel(t *testing.T) {
+	tests := []struct {
+		option string
+		level  int
+	}{
+		{"-verbose:severe", log.WARNING},
+		{"-verbose:warning", log.WARNING},
+		{"-verbose:class", log.CLASS},
+		{"-verbose:info", log.INFO},
+		{"-verbose:fine", log.FINE},
+		{"-verbose:finest", log.FINEST},
+	}
+
+	for _, test := range tests {
+		global := globals.InitGlobals("test")
+		LoadOptionsTable(global)
+		_ = log.SetLogLevel(log.FINE) // so that the quieter levels can be seen to take effect
+
+		normalStdout := os.Stdout
+		_, wout, _ := os.Pipe()
+		os.Stdout = wout
+
+		normalStderr := os.Stderr
+		_, w, _ := os.Pipe()
+		os.Stderr = w
+
+		args := []string{"jacobin", test.option, "Hello.class"}
+		_ = HandleCli(args, &global)
+
+		_ = w.Close()
+		_ = wout.Close()
+		os.Stdout = normalStdout
+		os.Stderr = normalStderr
+
+		if log.Level != test.level {
+			t.Errorf("%s: expected logging level %d, got %d", test.option, test.level, log.Level)
+		}
+		if !global.Options["-verbose"].Set {
+			t.Errorf("%s: -verbose was not marked as set", test.option)
+		}
+	}
+	_ = log.SetLogLevel(log.WARNING)
+}
+
 func TestInvalidLoggingLevel(t *testing.T) {
 	global := globals.InitGlobals("test")
 	LoadOptionsTable(global)
@@ -289,7 +337,7 @@ func TestInvalidLoggingLevel(t *testing.T) {
 	_, w, _ := os.Pipe()
 	os.Stderr = w
 
-	_, err := verbosityLevel(0, "severe", &global)
+	_, err := verbosityLevel(0, "loud", &global)
 
 	_ = w.Close()
 	_ = wout.Close()
@@ -297,7 +345,10 @@ func TestInvalidLoggingLevel(t *testing.T) {
 	os.Stderr = normalStderr
 
 	if err == nil {
-		t.Error("Setting log level to SEVERE via command line did not generate expected error")
+		t.Error("Setting an invalid log level via command line did not generate expected error")
+	}
+	if log.Level != log.WARNING {
+		t.Errorf("An invalid log level should leave the level unchanged, got: %d", log.Level)
 	}
 }
 
@@ -535,9 +586,531 @@ func TestMissingJARfilename(t *testing.T) {
 	}
 }
 
+func TestGetOptionRootAndArgs(t *testing.T) {
+	tests := []struct {
+		option, root, arg string
+	}{
+		{"-client", "-client", ""},               // bare option
+		{"--show-version", "--show-version", ""}, // bare option with a double hyphen
+		{"-verbose:fine", "-verbose", "fine"},    // value after a :
+		{"-verbose:", "-verbose", ""},            // empty value after a :
+		{"-trace:inst", "-trace", "inst"},        // value after a :
+		{"-XX:+DisableExplicitGC", "-XX", "+DisableExplicitGC"},
+		{"-XX:MaxRAM=1g", "-XX", "MaxRAM=1g"},           // a : takes precedence over an = ...
+		{"--module-path=mods", "--module-path", "mods"}, // ... but an = is used if there's no :
+		{"-Dk=v", "-Dk", "v"},                           // -D value after the =
+		{"-Dk=a:b", "-Dk", "a:b"},                       // -D value containing a :
+		{"-Dkey:with=colon", "-Dkey:with", "colon"},     // -D name containing a :
+		{"-Dpath.separator=:", "-Dpath.separator", ":"}, // -D value that is a :
+		{"-Dk=a=b", "-Dk", "a=b"},                       // only the first = splits
+		{"-Dk=", "-Dk", ""},                             // -D with an empty value
+		{"-Dflag", "-Dflag", ""},                        // -D with no value
+		{"-Dk:v", "-Dk:v", ""},                          // -D with no =, so no value
+		{"-Xmx512m", "-Xmx", "512m"},                    // value run into the option
+		{"-Xms1g", "-Xms", "1g"},                        // value run into the option
+		{"-Xmx", "-Xmx", ""},                            // missing value
+		{"-Xss512k", "-Xss", "512k"},                    // value run into the option
+		{"-Xint", "-Xint", ""},                          // not a heap size
+		{"Main.class", "Main.class", ""},                // not an option
+	}
+	for _, tt := range tests {
+		root, arg, err := getOptionRootAndArgs(tt.option)
+		if err != nil {
+			t.Errorf("getOptionRootAndArgs(%q): unexpected error: %v", tt.option, err)
+			continue
+		}
+		if root != tt.root || arg != tt.arg {
+			t.Errorf("getOptionRootAndArgs(%q): expected (%q, %q), got (%q, %q)",
+				tt.option, tt.root, tt.arg, root, arg)
+		}
+	}
+}
+
 func TestEmptyOptionForEmbeddedArg(t *testing.T) {
 	_, _, err := getOptionRootAndArgs("")
 	if err == nil {
 		t.Error("Empty option should fail test for embedded args, but did not.")
 	}
 }
+
+// -p and --module-path should be recognized and report that modules are unsupported,
+// rather than treat the module path as the starting class
+func TestModulePathNotSupported(t *testing.T) {
+	for _, flag := range []string{"-p", "--module-path"} {
+		global := globals.InitGlobals("test")
+		_ = log.SetLogLevel(log.WARNING)
+		LoadOptionsTable(global)
+
+		normalStdout := os.Stdout
+		_, wout, _ := os.Pipe()
+		os.Stdout = wout
+
+		normalStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+
+		args := []string{"jacobin", flag, "mods", "com.example.Main.class"}
+		_ = HandleCli(args, &global)
+
+		_ = w.Close()
+		out, _ := io.ReadAll(r)
+
+		_ = wout.Close()
+		os.Stdout = normalStdout
+		os.Stderr = normalStderr
+
+		msg := string(out[:])
+
+		if !strings.Contains(msg, "modules are not yet supported") {
+			t.Errorf("jacobin %s did not report that modules are unsupported. msg was: %s", flag, msg)
+		}
+
+		if strings.Contains(msg, "is not a recognized option") {
+			t.Errorf("jacobin %s was reported as an unrecognized option. msg was: %s", flag, msg)
+		}
+
+		if global.StartingClass != "" {
+			t.Errorf("jacobin %s should not have set a starting class, but got: %s", flag, global.StartingClass)
+		}
+
+		if global.ExitNow != true {
+			t.Errorf("jacobin %s should have set globPtr.exitNow to true", flag)
+		}
+	}
+}
+
+// a .java file, whether given alone or with --source, should be reported as needing a
+// compiler, rather than be loaded as a class
+func TestSourceFileModeNotSupported(t *testing.T) {
+	for _, args := range [][]string{
+		{"jacobin", "Hello.java", "arg1"},
+		{"jacobin", "--source", "11", "Hello.java"},
+	} {
+		global := globals.InitGlobals("test")
+		_ = log.SetLogLevel(log.WARNING)
+		LoadOptionsTable(global)
+
+		normalStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+
+		err := HandleCli(args, &global)
+
+		_ = w.Close()
+		out, _ := io.ReadAll(r)
+		os.Stderr = normalStderr
+
+		msg := string(out[:])
+		cmd := strings.Join(args, " ")
+
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %s", cmd, err.Error())
+		}
+		if !strings.Contains(msg, "Hello.java: source-file mode requires a compiler") {
+			t.Errorf("%s: did not report that source-file mode is unsupported. msg was: %s", cmd, msg)
+		}
+		if strings.Contains(msg, "is not a recognized option") {
+			t.Errorf("%s: was reported as an unrecognized option. msg was: %s", cmd, msg)
+		}
+		if global.StartingClass != "" {
+			t.Errorf("%s: should not have set a starting class, but got: %s", cmd, global.StartingClass)
+		}
+		if global.ExitNow != true {
+			t.Errorf("%s: should have set globPtr.exitNow to true", cmd)
+		}
+	}
+}
+
+func TestFileEncodingOption(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStdout := os.Stdout
+	_, wout, _ := os.Pipe()
+	os.Stdout = wout
+
+	args := []string{"jacobin", "-Dfile.encoding=ISO-8859-1", "a.class"}
+	_ = HandleCli(args, &global)
+
+	_ = wout.Close()
+	os.Stdout = normalStdout
+
+	if global.FileEncoding != "ISO-8859-1" {
+		t.Errorf("-Dfile.encoding=ISO-8859-1 should set the file encoding, but got: %s", global.FileEncoding)
+	}
+
+	if global.StartingClass != "a.class" {
+		t.Errorf("a.class not identified as starting class. Got: %s", global.StartingClass)
+	}
+}
+
+// -Dstdout.encoding and -Dsun.stderr.encoding set the console encodings, not file.encoding
+func TestConsoleEncodingOptions(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStdout := os.Stdout
+	_, wout, _ := os.Pipe()
+	os.Stdout = wout
+
+	args := []string{"jacobin", "-Dstdout.encoding=ISO-8859-1", "-Dsun.stderr.encoding=US-ASCII", "a.class"}
+	_ = HandleCli(args, &global)
+
+	_ = wout.Close()
+	os.Stdout = normalStdout
+
+	if global.StdoutEncoding != "ISO-8859-1" {
+		t.Errorf("-Dstdout.encoding=ISO-8859-1 should set the stdout encoding, but got: %s", global.StdoutEncoding)
+	}
+	if global.StderrEncoding != "US-ASCII" {
+		t.Errorf("-Dsun.stderr.encoding=US-ASCII should set the stderr encoding, but got: %s", global.StderrEncoding)
+	}
+	if global.FileEncoding != "UTF-8" {
+		t.Errorf("the console encodings should not change the file encoding, but got: %s", global.FileEncoding)
+	}
+	if global.StartingClass != "a.class" {
+		t.Errorf("a.class not identified as starting class. Got: %s", global.StartingClass)
+	}
+}
+
+// -Dpath.separator's value can be a :, which must not be taken as the option's arg marker,
+// and -cp's classpath is then split on the new path separator
+func TestSeparatorAndClassPathOptions(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStdout := os.Stdout
+	_, wout, _ := os.Pipe()
+	os.Stdout = wout
+
+	args := []string{"jacobin", "-Dfile.separator=\\", "-Dpath.separator=:", "-cp", "classes:lib/app.jar", "a.class"}
+	_ = HandleCli(args, &global)
+
+	_ = wout.Close()
+	os.Stdout = normalStdout
+
+	if global.FileSeparator != "\\" {
+		t.Errorf("-Dfile.separator=\\ should set the file separator, but got: %s", global.FileSeparator)
+	}
+	if global.PathSeparator != ":" {
+		t.Errorf("-Dpath.separator=: should set the path separator, but got: %s", global.PathSeparator)
+	}
+	if global.ClassPath != "classes:lib/app.jar" {
+		t.Errorf("-cp should set the classpath, but got: %s", global.ClassPath)
+	}
+	if global.StartingClass != "a.class" {
+		t.Errorf("a.class not identified as starting class. Got: %s", global.StartingClass)
+	}
+
+	option, arg, _ := getOptionRootAndArgs("-Dpath.separator=:")
+	if option != "-Dpath.separator" || arg != ":" {
+		t.Errorf("Expected -Dpath.separator and :, got %s and %s", option, arg)
+	}
+}
+
+func TestMissingClassPath(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStderr := os.Stderr
+	_, werr, _ := os.Pipe()
+	os.Stderr = werr
+
+	global.Args = []string{"-classpath"}
+	_, err := setClassPath(0, "", &global)
+
+	_ = werr.Close()
+	os.Stderr = normalStderr
+
+	if err != os.ErrInvalid || !global.ExitNow {
+		t.Errorf("Missing classpath after -classpath should fail and exit, but got: %v", err)
+	}
+}
+
+// -Xms and -Xmx are accepted, and the sizes recorded, although Jacobin uses Go's heap
+func TestHeapSizeOptions(t *testing.T) {
+	globals.InitGlobals("test")
+	global := globals.GetGlobalRef()
+	LoadOptionsTable(*global)
+	mtable := make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&mtable)
+
+	args := []string{"jacobin", "-Xms64m", "-Xmx512m", "a.class"}
+	_ = HandleCli(args, global)
+
+	if global.InitialHeapSize != 64<<20 {
+		t.Errorf("-Xms64m should set the initial heap size to %d, but got: %d", 64<<20, global.InitialHeapSize)
+	}
+	if global.MaxHeapSize != 512<<20 {
+		t.Errorf("-Xmx512m should set the maximum heap size to %d, but got: %d", 512<<20, global.MaxHeapSize)
+	}
+	if global.ExitNow {
+		t.Error("-Xms and -Xmx should not exit the VM")
+	}
+	if global.StartingClass != "a.class" {
+		t.Errorf("a.class not identified as starting class. Got: %s", global.StartingClass)
+	}
+
+	// what Runtime.getRuntime().maxMemory() returns
+	maxMemory := mtable["java/lang/Runtime.maxMemory()J"].Meth.(gfunction.GMeth).GFunction
+	if maxMem := maxMemory([]interface{}{nil}).(int64); maxMem != 512<<20 {
+		t.Errorf("Runtime.maxMemory() should reflect -Xmx512m, but got: %d", maxMem)
+	}
+}
+
+// -Xss sets the thread stack size and -XX:MaxJavaStackTraceDepth the stack trace depth
+func TestStackLimitOptions(t *testing.T) {
+	globals.InitGlobals("test")
+	global := globals.GetGlobalRef()
+	LoadOptionsTable(*global)
+
+	args := []string{"jacobin", "-Xss512k", "-XX:MaxJavaStackTraceDepth=20", "a.class"}
+	_ = HandleCli(args, global)
+
+	if global.ThreadStackSize != 512<<10 {
+		t.Errorf("-Xss512k should set the thread stack size to %d, but got: %d", 512<<10, global.ThreadStackSize)
+	}
+	if global.MaxJavaStackTraceDepth != 20 {
+		t.Errorf("-XX:MaxJavaStackTraceDepth=20 should set the depth to 20, but got: %d",
+			global.MaxJavaStackTraceDepth)
+	}
+	if global.ExitNow {
+		t.Error("-Xss and -XX:MaxJavaStackTraceDepth should not exit the VM")
+	}
+	if maxFrameStackDepth() != (512<<10)/estimatedFrameSize {
+		t.Errorf("-Xss512k: expected a frame stack depth of %d, got: %d",
+			(512<<10)/estimatedFrameSize, maxFrameStackDepth())
+	}
+}
+
+func TestInvalidMaxJavaStackTraceDepth(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStderr := os.Stderr
+	_, werr, _ := os.Pipe()
+	os.Stderr = werr
+
+	_, err := handleXXoption(0, "MaxJavaStackTraceDepth=-5", &global)
+
+	_ = werr.Close()
+	os.Stderr = normalStderr
+
+	if err == nil || !global.ExitNow {
+		t.Error("-XX:MaxJavaStackTraceDepth=-5 should be rejected and exit the VM")
+	}
+}
+
+func TestParseHeapSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		expected int64
+	}{
+		{"4096", 4096},
+		{"64k", 64 << 10},
+		{"64K", 64 << 10},
+		{"512m", 512 << 20},
+		{"2G", 2 << 30},
+	}
+	for _, tt := range tests {
+		if got, err := parseHeapSize(tt.size); err != nil || got != tt.expected {
+			t.Errorf("parseHeapSize(%s): expected %d, got %d (%v)", tt.size, tt.expected, got, err)
+		}
+	}
+
+	for _, size := range []string{"", "m", "512q", "-1m", "0", "99999999999g"} {
+		if _, err := parseHeapSize(size); err == nil {
+			t.Errorf("parseHeapSize(%s): expected an error, but got none", size)
+		}
+	}
+}
+
+func TestInvalidHeapSize(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStderr := os.Stderr
+	rerr, werr, _ := os.Pipe()
+	os.Stderr = werr
+
+	global.Args = []string{"-Xmx512q"}
+	_, err := setHeapSize(0, "512q", &global)
+
+	_ = werr.Close()
+	msg, _ := io.ReadAll(rerr)
+	os.Stderr = normalStderr
+
+	if err == nil || !global.ExitNow {
+		t.Error("-Xmx512q should be rejected and exit the VM")
+	}
+	if !strings.Contains(string(msg), "Invalid maximum heap size: -Xmx512q") {
+		t.Errorf("Expected an invalid maximum heap size message, got: %s", string(msg))
+	}
+}
+
+func TestXintOption(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStdout := os.Stdout
+	_, wout, _ := os.Pipe()
+	os.Stdout = wout
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	args := []string{"jacobin", "-Xint", "-version"}
+	_ = HandleCli(args, &global)
+
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	_ = wout.Close()
+	os.Stdout = normalStdout
+	os.Stderr = normalStderr
+
+	if !global.Options["-Xint"].Set {
+		t.Error("-Xint was not recognized as an option")
+	}
+	if global.ExecMode != "interpreted mode" {
+		t.Errorf("-Xint should leave the execution mode as 'interpreted mode', got: %s", global.ExecMode)
+	}
+
+	msg := string(out)
+	if strings.Contains(msg, "not supported") {
+		t.Errorf("-Xint should not produce a warning. msg was: %s", msg)
+	}
+	if !strings.Contains(msg, "(interpreted mode)") {
+		t.Errorf("-version should report interpreted mode. msg was: %s", msg)
+	}
+}
+
+func TestXXOptionIsIgnored(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStdout := os.Stdout
+	_, wout, _ := os.Pipe()
+	os.Stdout = wout
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	args := []string{"jacobin", "-XX:+PrintGCDetails", "-version"}
+	_ = HandleCli(args, &global)
+
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	_ = wout.Close()
+	os.Stdout = normalStdout
+	os.Stderr = normalStderr
+
+	if !global.Options["-XX"].Set {
+		t.Error("-XX:+PrintGCDetails was not recognized as an option")
+	}
+
+	msg := string(out)
+	if strings.Contains(msg, "not a recognized option") {
+		t.Errorf("-XX:+PrintGCDetails should be accepted. msg was: %s", msg)
+	}
+	if !strings.Contains(msg, "(interpreted mode)") {
+		t.Errorf("-version should still be processed. msg was: %s", msg)
+	}
+}
+
+func TestDisableExplicitGCOption(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+
+	args := []string{"jacobin", "-XX:+DisableExplicitGC", "Hello.class"}
+	_ = HandleCli(args, &global)
+	if !global.DisableExplicitGC {
+		t.Error("-XX:+DisableExplicitGC did not disable explicit GC")
+	}
+
+	args = []string{"jacobin", "-XX:+DisableExplicitGC", "-XX:-DisableExplicitGC", "Hello.class"}
+	_ = HandleCli(args, &global)
+	if global.DisableExplicitGC {
+		t.Error("-XX:-DisableExplicitGC did not re-enable explicit GC")
+	}
+
+	_ = w.Close()
+	os.Stderr = normalStderr
+}
+
+func TestXprofOption(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	args := []string{"jacobin", "-Xprof:opcodes", "a.class"}
+	_ = HandleCli(args, &global)
+
+	if !global.Options["-Xprof"].Set {
+		t.Error("-Xprof was not recognized as an option")
+	}
+	if !global.ProfileOpcodes {
+		t.Error("-Xprof:opcodes should enable the opcode profile")
+	}
+
+	global = globals.InitGlobals("test")
+	LoadOptionsTable(global)
+	_ = HandleCli([]string{"jacobin", "-Xprof:methods", "a.class"}, &global)
+	if !global.ProfileMethods || global.ProfileOpcodes {
+		t.Error("-Xprof:methods should enable only the method profile")
+	}
+
+	global = globals.InitGlobals("test")
+	LoadOptionsTable(global)
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	_ = HandleCli([]string{"jacobin", "-Xprof:everything", "a.class"}, &global)
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if global.Options["-Xprof"].Set || global.ProfileOpcodes {
+		t.Error("-Xprof:everything should be ignored")
+	}
+}
+
+func TestXcompOption(t *testing.T) {
+	global := globals.InitGlobals("test")
+	LoadOptionsTable(global)
+
+	normalStdout := os.Stdout
+	_, wout, _ := os.Pipe()
+	os.Stdout = wout
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	args := []string{"jacobin", "-Xcomp", "-version"}
+	_ = HandleCli(args, &global)
+
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	_ = wout.Close()
+	os.Stdout = normalStdout
+	os.Stderr = normalStderr
+
+	if !global.Options["-Xcomp"].Set {
+		t.Error("-Xcomp was not recognized as an option")
+	}
+	if global.ExecMode != "interpreted mode" {
+		t.Errorf("-Xcomp should leave the execution mode as 'interpreted mode', got: %s", global.ExecMode)
+	}
+
+	msg := string(out)
+	if !strings.Contains(msg, "compilation not supported, running interpreted") {
+		t.Errorf("-Xcomp should warn that compilation is not supported. msg was: %s", msg)
+	}
+	if !strings.Contains(msg, "(interpreted mode)") {
+		t.Errorf("-version should report interpreted mode. msg was: %s", msg)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"jacobin/classloader"
+	"jacobin/frames"
+	"jacobin/gfunction"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/opcodes"
+	"jacobin/statics"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"testing"
+)
+
+// runs code, a method of Color that returns a value, with locals as its local variables,
+// and returns the value the method returned
+func runEnumMethod(t *testing.T, cp *classloader.CPool, code []byte, locals ...interface{}) interface{} {
+	catcher := frames.CreateFrame(1) // receives the return value
+	f := frames.CreateFrame(4)
+	f.ClName = "Color"
+	f.MethName = "test"
+	f.CP = cp
+	f.Meth = code
+	f.Locals = append([]interface{}{nil}, locals...)
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(catcher)
+	fs.PushFront(f)
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("unexpected error running %v: %v", code, err)
+	}
+	return pop(catcher)
+}
+
+// The enum Color { RED, GREEN, BLUE } after its <clinit>() has run: each constant was
+// created by Enum.<init>() and stored in a static field, and $VALUES holds all three.
+// Its values() and valueOf() methods have the bytecode that javac generates for them.
+func TestEnumValuesNameOrdinal(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+	globals.GetGlobalRef().FuncInvokeMethod = InvokeJavaMethod
+
+	colorName := "Color"
+	enumName := "java/lang/Enum"
+	arrayName := "[LColor;"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 24)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.FieldRef, Slot: 0} // Color.$VALUES
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // Color
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0} // [LColor;.clone()
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 1}  // [LColor;
+	CP.CpIndex[8] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 1}
+	CP.CpIndex[9] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}
+	CP.CpIndex[10] = classloader.CpEntry{Type: classloader.UTF8, Slot: 3}
+	CP.CpIndex[11] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 1} // Color.name()
+	CP.CpIndex[12] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 2}
+	CP.CpIndex[13] = classloader.CpEntry{Type: classloader.UTF8, Slot: 4}
+	CP.CpIndex[14] = classloader.CpEntry{Type: classloader.UTF8, Slot: 5}
+	CP.CpIndex[15] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 2} // Color.ordinal()
+	CP.CpIndex[16] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 3}
+	CP.CpIndex[17] = classloader.CpEntry{Type: classloader.UTF8, Slot: 6}
+	CP.CpIndex[18] = classloader.CpEntry{Type: classloader.UTF8, Slot: 7}
+	CP.CpIndex[19] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 3} // Enum.valueOf()
+	CP.CpIndex[20] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 2}  // java/lang/Enum
+	CP.CpIndex[21] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 4}
+	CP.CpIndex[22] = classloader.CpEntry{Type: classloader.UTF8, Slot: 8}
+	CP.CpIndex[23] = classloader.CpEntry{Type: classloader.UTF8, Slot: 9}
+	CP.FieldRefs = []classloader.FieldRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.MethodRefs = []classloader.MethodRefEntry{
+		{ClassIndex: 7, NameAndType: 8}, {ClassIndex: 2, NameAndType: 12},
+		{ClassIndex: 2, NameAndType: 16}, {ClassIndex: 20, NameAndType: 21}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&colorName), stringPool.GetStringIndex(&arrayName),
+		stringPool.GetStringIndex(&enumName)}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{
+		{NameIndex: 4, DescIndex: 5}, {NameIndex: 9, DescIndex: 10}, {NameIndex: 13, DescIndex: 14},
+		{NameIndex: 17, DescIndex: 18}, {NameIndex: 22, DescIndex: 23}}
+	CP.Utf8Refs = []string{"$VALUES", arrayName, "clone", "()Ljava/lang/Object;",
+		"name", "()Ljava/lang/String;", "ordinal", "()I",
+		"valueOf", "(Ljava/lang/Class;Ljava/lang/String;)Ljava/lang/Enum;",
+		"RED", "GREEN", "BLUE"}
+
+	classloader.MethAreaInsert(enumName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            enumName,
+			NameIndex:       stringPool.GetStringIndex(&enumName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			ClInit:          types.ClInitRun,
+		},
+	})
+	colorKlass := &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            colorName,
+			NameIndex:       stringPool.GetStringIndex(&colorName),
+			SuperclassIndex: stringPool.GetStringIndex(&enumName),
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	}
+	classloader.MethAreaInsert(colorName, colorKlass)
+
+	// what Color.<clinit>() does
+	enumInit := classloader.MTable["java/lang/Enum.<init>(Ljava/lang/String;I)V"].Meth.(gfunction.GMeth).GFunction
+	constNames := []string{"RED", "GREEN", "BLUE"}
+	allValues := object.Make1DimRefArray(&colorName, int64(len(constNames)))
+	allValues.KlassName = stringPool.GetStringIndex(&arrayName)
+	for i, name := range constNames {
+		constant := object.MakeEmptyObjectWithClassName(&colorName)
+		enumInit([]interface{}{constant, object.StringObjectFromGoString(name), int64(i)})
+		_ = statics.AddStatic("Color."+name, statics.Static{Type: "LColor;", Value: constant})
+		allValues.FieldTable["value"].Fvalue.([]*object.Object)[i] = constant
+		colorKlass.Data.Fields = append(colorKlass.Data.Fields,
+			classloader.Field{AccessFlags: 0x4019, Name: uint16(10 + i), IsStatic: true})
+	}
+	_ = statics.AddStatic("Color.$VALUES", statics.Static{Type: arrayName, Value: allValues})
+
+	// public static Color[] values() { return (Color[]) $VALUES.clone(); }
+	values := runEnumMethod(t, &CP, []byte{
+		opcodes.GETSTATIC, 0x00, 0x01,
+		opcodes.INVOKEVIRTUAL, 0x00, 0x06,
+		opcodes.CHECKCAST, 0x00, 0x07,
+		opcodes.ARETURN}).(*object.Object)
+	if values == allValues {
+		t.Errorf("values(): expected a copy of $VALUES, got $VALUES itself")
+	}
+
+	// for (Color c : Color.values()) { c.name(); c.ordinal(); }
+	for i, constant := range values.FieldTable["value"].Fvalue.([]*object.Object) {
+		name := runEnumMethod(t, &CP, []byte{
+			opcodes.ALOAD_1, opcodes.INVOKEVIRTUAL, 0x00, 0x0B, opcodes.ARETURN}, constant)
+		if got := object.GoStringFromStringObject(name.(*object.Object)); got != constNames[i] {
+			t.Errorf("values()[%d].name(): expected %s, got %s", i, constNames[i], got)
+		}
+		ordinal := runEnumMethod(t, &CP, []byte{
+			opcodes.ALOAD_1, opcodes.INVOKEVIRTUAL, 0x00, 0x0F, opcodes.IRETURN}, constant)
+		if ordinal != int64(i) {
+			t.Errorf("values()[%d].ordinal(): expected %d, got %v", i, i, ordinal)
+		}
+	}
+
+	// public static Color valueOf(String name) { return (Color) Enum.valueOf(Color.class, name); }
+	green := runEnumMethod(t, &CP, []byte{
+		opcodes.LDC, 0x02,
+		opcodes.ALOAD_1,
+		opcodes.INVOKESTATIC, 0x00, 0x13,
+		opcodes.CHECKCAST, 0x00, 0x02,
+		opcodes.ARETURN}, object.StringObjectFromGoString("GREEN"))
+	if green != values.FieldTable["value"].Fvalue.([]*object.Object)[1] {
+		t.Errorf("valueOf(\"GREEN\"): expected the GREEN constant, got %v", green)
+	}
+
+	// String.valueOf(Color.BLUE), as used by println(), finds Enum.toString()
+	blue := values.FieldTable["value"].Fvalue.([]*object.Object)[2]
+	str, err := InvokeJavaMethod(blue, "toString", "()Ljava/lang/String;")
+	if err != nil || object.GoStringFromStringObject(str.(*object.Object)) != "BLUE" {
+		t.Errorf("BLUE.toString(): expected BLUE, got %v (%v)", str, err)
+	}
+}
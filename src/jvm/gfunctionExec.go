@@ -15,7 +15,10 @@ import (
 	"jacobin/exceptions"
 	"jacobin/frames"
 	"jacobin/gfunction"
+	"jacobin/globals"
 	"jacobin/log"
+	"jacobin/object"
+	"jacobin/types"
 	"slices"
 )
 
@@ -40,6 +43,10 @@ func runGfunction(mt classloader.MTentry, fs *list.List,
 	className, methodName, methodType string,
 	params *[]interface{}, objRef bool) any {
 
+	if globals.GetGlobalRef().ProfileMethods {
+		countMethodCall(className, methodName, methodType)
+	}
+
 	f := fs.Front().Value.(*frames.Frame)
 	var paramCount int
 	if params == nil {
@@ -58,6 +65,7 @@ func runGfunction(mt classloader.MTentry, fs *list.List,
 
 	if paramCount > 0 {
 		slices.Reverse(*params)
+		boxArguments(mt.Meth.(gfunction.GMeth).ParamTypes, *params, objRef)
 	}
 
 	var ret any
@@ -73,6 +81,12 @@ func runGfunction(mt classloader.MTentry, fs *list.List,
 	case *gfunction.GErrBlk:
 		// var errorDetails string
 		errBlk := *ret.(*gfunction.GErrBlk)
+		if errBlk.Exception != nil { // an exception thrown by Java code the gfunction called
+			if rethrowException(fs, f, errBlk.Exception) == exceptions.Caught {
+				return CaughtGfunctionException
+			}
+			return errors.New(errBlk.ErrMsg)
+		}
 
 		var threadName string
 		if f.Thread == 1 {
@@ -82,11 +96,13 @@ func runGfunction(mt classloader.MTentry, fs *list.List,
 		}
 		errMsg := fmt.Sprintf("%s in thread: %s, method: %s",
 			errBlk.ErrMsg, threadName, fullMethName)
-		status := exceptions.ThrowEx(errBlk.ExceptionType, errMsg, f)
+		// create the exception object and search the frame stack for a handler for it
+		status := throwFromBytecode(fs, f, errBlk.ExceptionType, errMsg)
 		if status != exceptions.Caught {
 			return errors.New(errMsg + " " + errBlk.ErrMsg) // applies only if in test
 		} else {
-			// if the exception was caught, tell calling function to execute the catching logic
+			// if the exception was caught, the catch frame is now at the head of the frame
+			// stack, so tell calling function to resume interpretation there
 			return CaughtGfunctionException
 		}
 
@@ -104,3 +120,40 @@ func runGfunction(mt classloader.MTentry, fs *list.List,
 	// return value, so return it.
 	return ret
 }
+
+// boxArguments autoboxes and unboxes the arguments to a gfunction so that they match the
+// parameter types in its descriptor, as parsed when it was loaded into the MTable: a primitive passed for a parameter of a reference type
+// is boxed (an int as an Integer, a float as a Float), and a wrapper object passed for a
+// parameter of a primitive type is unboxed. Only single-slot values are converted, since
+// a long or double occupies two slots and so cannot stand in for a reference or vice versa.
+// params are in the order of the descriptor, preceded by the objectRef if objRef is true.
+func boxArguments(paramTypes []string, params []interface{}, objRef bool) {
+	i := 0
+	if objRef {
+		i = 1
+	}
+	for _, paramType := range paramTypes {
+		if i >= len(params) {
+			return
+		}
+		switch paramType[0] {
+		case 'L', '[':
+			switch value := params[i].(type) {
+			case int64:
+				params[i] = gfunction.Box(value, types.Int)
+			case float64:
+				params[i] = gfunction.Box(value, types.Float)
+			}
+		case 'J', 'D':
+			i += 2
+			continue
+		default:
+			if obj, ok := params[i].(*object.Object); ok {
+				if value, ok := gfunction.Unbox(obj); ok {
+					params[i] = value
+				}
+			}
+		}
+		i += 1
+	}
+}
@@ -7,16 +7,22 @@
 package jvm
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"jacobin/classloader"
 	"jacobin/frames"
 	"jacobin/gfunction"
 	"jacobin/globals"
 	"jacobin/log"
+	"jacobin/object"
 	"jacobin/opcodes"
 	"jacobin/stringPool"
+	"jacobin/types"
+	"jacobin/util"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -216,3 +222,394 @@ func TestGfuncINVOKEVIRTUALwith1stringArgtemplate(t *testing.T) {
 		t.Errorf("gfunctionExec: Did not get expected msg, got: %s", outMsg)
 	}
 }
+
+// a GErrBlk returned by a gfunction becomes an exception that a Java catch block can handle:
+// static void parse() { int r; try { r = Integer.parseInt("x"); } catch (NumberFormatException e) { r = -1; } }
+// (r is in local 0 and the exception is stored in local 1)
+func TestGfunctionExceptionIsCaught(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+
+	integerName := "java/lang/Integer"
+	nfeName := "java/lang/NumberFormatException"
+	integerIndex := stringPool.GetStringIndex(&integerName)
+	nfeIndex := stringPool.GetStringIndex(&nfeName)
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 10)
+	CP.CpIndex[0] = classloader.CpEntry{Type: 0, Slot: 0}
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // java/lang/Integer
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.StringConst, Slot: 7}
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}     // point to UTF8[2]
+	CP.CpIndex[8] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 1} // java/lang/NumberFormatException
+
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{integerIndex, nfeIndex}
+	CP.Utf8Refs = []string{"parseInt", "(Ljava/lang/String;)I", "x"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: uint16(4), DescIndex: uint16(5)}}
+
+	for _, name := range []string{integerName, nfeName} {
+		classloader.MethAreaInsert(name, &classloader.Klass{
+			Status: 'X',
+			Loader: "test",
+			Data: &classloader.ClData{
+				Name:            name,
+				NameIndex:       stringPool.GetStringIndex(&name),
+				SuperclassIndex: types.ObjectPoolStringIndex,
+				MethodTable:     make(map[string]*classloader.Method),
+				CP:              CP,
+				ClInit:          types.ClInitRun,
+			},
+		})
+	}
+
+	meth := classloader.JmEntry{
+		MaxStack:  2,
+		MaxLocals: 2,
+		Cp:        &CP,
+		Code: []byte{
+			opcodes.LDC, 0x06, opcodes.INVOKESTATIC, 0x00, 0x01, opcodes.ISTORE_0, opcodes.RETURN,
+			opcodes.ASTORE_1, opcodes.ICONST_M1, opcodes.ISTORE_0, opcodes.RETURN,
+		},
+		Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 6, HandlerPc: 7, CatchType: 8}},
+	}
+	classloader.MTable["Parser.parse()V"] = classloader.MTentry{MType: 'J', Meth: meth}
+
+	f := frames.CreateFrame(meth.MaxStack)
+	f.Ftype = 'J'
+	f.ClName = "Parser"
+	f.MethName = "parse"
+	f.MethType = "()V"
+	f.CP = &CP
+	f.Meth = meth.Code
+	f.Locals = make([]interface{}, meth.MaxLocals)
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("gfunctionExec: expected the NumberFormatException to be caught, got: %s", err.Error())
+	}
+
+	if f.Locals[0] != int64(-1) {
+		t.Errorf("gfunctionExec: expected the catch block to set -1, got: %v", f.Locals[0])
+	}
+	exc, ok := f.Locals[1].(*object.Object)
+	if !ok {
+		t.Fatalf("gfunctionExec: expected the exception object in local 1, got: %T", f.Locals[1])
+	}
+	excClass := object.GoStringFromStringPoolIndex(exc.KlassName)
+	if excClass != nfeName {
+		t.Errorf("gfunctionExec: expected a NumberFormatException, got: %s", excClass)
+	}
+}
+
+// a primitive passed to a gfunction for a parameter of a reference type is autoboxed, and
+// a wrapper object passed for a primitive parameter is unboxed, but longs, which take two
+// slots, are passed as they are
+func TestGfunctionArgumentsAreBoxed(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	var received []interface{}
+	// the parameter types are those loadlib() parses from each gfunction's descriptor
+	gmeth := func(paramTypes ...string) classloader.MTentry {
+		return classloader.MTentry{MType: 'G', Meth: gfunction.GMeth{
+			GFunction: func(params []interface{}) interface{} {
+				received = params
+				return nil
+			},
+			ParamTypes: paramTypes,
+		}}
+	}
+
+	f := newFrame(opcodes.NOP)
+	fs := frames.CreateFrameStack()
+	_ = frames.PushFrame(fs, &f)
+
+	// the parameters are passed as they are popped off the op stack, so in reverse order
+	params := []interface{}{int64(7)}
+	_ = runGfunction(gmeth("Ljava/lang/Object;"), fs, "app/Box", "take", "(Ljava/lang/Object;)V", &params, false)
+	boxed, ok := received[0].(*object.Object)
+	if !ok {
+		t.Fatalf("TestGfunctionArgumentsAreBoxed: expected an Integer for the int argument, got %T", received[0])
+	}
+	if className := *(stringPool.GetStringPointer(boxed.KlassName)); className != "java/lang/Integer" {
+		t.Errorf("TestGfunctionArgumentsAreBoxed: expected the int to be boxed as java/lang/Integer, got %s", className)
+	}
+	if boxed.FieldTable["value"].Fvalue != int64(7) {
+		t.Errorf("TestGfunctionArgumentsAreBoxed: expected boxed value 7, got %v", boxed.FieldTable["value"].Fvalue)
+	}
+
+	className := "app/Box"
+	this := object.MakeEmptyObjectWithClassName(&className)
+	integer := gfunction.Box(int64(21), types.Int)
+	params = []interface{}{integer, this}
+	_ = runGfunction(gmeth("I"), fs, "app/Box", "twice", "(I)I", &params, true)
+	if received[0] != this || received[1] != int64(21) {
+		t.Errorf("TestGfunctionArgumentsAreBoxed: expected the objectRef and the unboxed int 21, got %v", received)
+	}
+
+	params = []interface{}{int64(5), int64(9), int64(9)}
+	_ = runGfunction(gmeth("J", "Ljava/lang/Object;"), fs, "app/Box", "put", "(JLjava/lang/Object;)V", &params, false)
+	if received[0] != int64(9) || received[1] != int64(9) {
+		t.Errorf("TestGfunctionArgumentsAreBoxed: expected the long to be passed unchanged, got %v", received)
+	}
+	if _, ok := received[2].(*object.Object); !ok {
+		t.Errorf("TestGfunctionArgumentsAreBoxed: expected the int after the long to be boxed, got %T", received[2])
+	}
+}
+
+// the common case, in which every argument already matches its parameter's type, neither
+// parses the descriptor nor boxes anything, and so allocates nothing
+func TestBoxArgumentsDoesNotReparse(t *testing.T) {
+	paramTypes, _, _ := util.ParseMethodDescriptor("(ILjava/lang/String;JD)V")
+	str := object.StringObjectFromGoString("text")
+	params := []interface{}{int64(3), str, int64(4), int64(4), 2.5, 2.5}
+	allocs := testing.AllocsPerRun(100, func() {
+		boxArguments(paramTypes, params, false)
+	})
+	if allocs != 0 {
+		t.Errorf("TestBoxArgumentsDoesNotReparse: expected no allocations, got %v", allocs)
+	}
+	if params[0] != int64(3) || params[1] != str || params[4] != 2.5 {
+		t.Errorf("TestBoxArgumentsDoesNotReparse: expected the arguments to be unchanged, got %v", params)
+	}
+}
+
+// Thread.start() runs the Runnable's run() method in bytecode on a new execution thread and
+// Thread.join() waits for it to finish. Here, Worker.run() calls Recorder.record(), a
+// gfunction that appends to a synchronized collection.
+func TestThreadStartRunsRunnable(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+	globals.GetGlobalRef().FuncRunThread = RunJavaThread
+
+	workerName := "Worker"
+	recorderName := "Recorder"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 6)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // Recorder
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&recorderName)}
+	CP.Utf8Refs = []string{"record", "()V"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}}
+
+	for _, name := range []string{workerName, recorderName} {
+		classloader.MethAreaInsert(name, &classloader.Klass{
+			Status: 'X',
+			Loader: "test",
+			Data: &classloader.ClData{
+				Name:            name,
+				NameIndex:       stringPool.GetStringIndex(&name),
+				SuperclassIndex: types.ObjectPoolStringIndex,
+				MethodTable:     make(map[string]*classloader.Method),
+				CP:              CP,
+				ClInit:          types.ClInitRun,
+			},
+		})
+	}
+
+	var lock sync.Mutex
+	var records []int
+	classloader.MTable["Recorder.record()V"] = classloader.MTentry{MType: 'G', Meth: gfunction.GMeth{
+		ParamSlots: 0,
+		GFunction: func(params []interface{}) interface{} {
+			lock.Lock()
+			defer lock.Unlock()
+			records = append(records, len(records))
+			return nil
+		},
+	}}
+	classloader.MTable["Worker.run()V"] = classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{
+		MaxStack:  1,
+		MaxLocals: 1,
+		Cp:        &CP,
+		Code:      []byte{opcodes.INVOKESTATIC, 0x00, 0x01, opcodes.RETURN},
+	}}
+
+	gfunc := func(name string) func([]interface{}) interface{} {
+		return classloader.MTable[name].Meth.(gfunction.GMeth).GFunction
+	}
+	threadInit := gfunc("java/lang/Thread.<init>(Ljava/lang/Runnable;)V")
+	threadStart := gfunc("java/lang/Thread.start()V")
+	threadJoin := gfunc("java/lang/Thread.join()V")
+
+	threadName := "java/lang/Thread"
+	var threads []*object.Object
+	for i := 0; i < 4; i++ {
+		th := object.MakeEmptyObjectWithClassName(&threadName)
+		threadInit([]interface{}{th, object.MakeEmptyObjectWithClassName(&workerName)})
+		if ret := threadStart([]interface{}{th}); ret != nil {
+			t.Fatalf("Thread.start: unexpected error: %v", ret)
+		}
+		threads = append(threads, th)
+	}
+	for _, th := range threads {
+		if ret := threadJoin([]interface{}{th}); ret != nil {
+			t.Fatalf("Thread.join: unexpected error: %v", ret)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(records) != len(threads) {
+		t.Errorf("expected %d records after joining the threads, got %d", len(threads), len(records))
+	}
+}
+
+// println(Object) prints the object's toString(). Gadget overrides toString() in bytecode,
+// so its string is printed; Plain doesn't, so Object.toString() provides the string.
+func TestPrintlnObjectCallsToString(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+	globals.GetGlobalRef().FuncInvokeMethod = InvokeJavaMethod
+
+	gadgetName := "Gadget"
+	plainName := "Plain"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 3)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.StringConst, Slot: 2}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.Utf8Refs = []string{"a gadget"}
+
+	for _, name := range []string{gadgetName, plainName} {
+		classloader.MethAreaInsert(name, &classloader.Klass{
+			Status: 'X',
+			Loader: "test",
+			Data: &classloader.ClData{
+				Name:            name,
+				NameIndex:       stringPool.GetStringIndex(&name),
+				SuperclassIndex: types.ObjectPoolStringIndex,
+				MethodTable:     make(map[string]*classloader.Method),
+				CP:              CP,
+				ClInit:          types.ClInitRun,
+			},
+		})
+	}
+	classloader.MTable["Gadget.toString()Ljava/lang/String;"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  1,
+			MaxLocals: 1,
+			Cp:        &CP,
+			Code:      []byte{opcodes.LDC, 0x01, opcodes.ARETURN},
+		}}
+
+	println := classloader.MTable["java/io/PrintStream.println(Ljava/lang/Object;)V"].Meth.(gfunction.GMeth).GFunction
+	capture := func(obj *object.Object) string {
+		r, w, _ := os.Pipe()
+		if ret := println([]interface{}{w, obj}); ret != nil {
+			t.Fatalf("println(Object): unexpected error: %v", ret)
+		}
+		_ = w.Close()
+		out, _ := io.ReadAll(r)
+		return string(out)
+	}
+
+	if out := capture(object.MakeEmptyObjectWithClassName(&gadgetName)); out != "a gadget\n" {
+		t.Errorf("println(Object) of an object that overrides toString(): expected %q, got %q",
+			"a gadget\n", out)
+	}
+
+	plain := object.MakeEmptyObjectWithClassName(&plainName)
+	expected := fmt.Sprintf("Plain@%x\n", uint32(object.IdentityHashCode(plain)))
+	if out := capture(plain); out != expected {
+		t.Errorf("println(Object) of an object without toString(): expected %q, got %q", expected, out)
+	}
+}
+
+// An exception thrown by a toString() that a gfunction calls is thrown in the calling thread,
+// where Java code can catch it. Thrower.toString() throws the Thrower itself, and describe()
+// catches what String.valueOf(this) throws, returning "caught".
+func TestToStringExceptionIsThrownInCaller(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+	globals.GetGlobalRef().FuncInvokeMethod = InvokeJavaMethod
+
+	throwerName := "Thrower"
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 8)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // Thrower
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.StringConst, Slot: 7}
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&throwerName)}
+	CP.Utf8Refs = []string{"valueOf", "(Ljava/lang/Object;)Ljava/lang/String;", "caught"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}}
+
+	classloader.MethAreaInsert(throwerName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            throwerName,
+			NameIndex:       stringPool.GetStringIndex(&throwerName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	// Thrower.valueOf(Object) is String.valueOf(Object), so the constant pool needs no other class
+	classloader.MTable["Thrower.valueOf(Ljava/lang/Object;)Ljava/lang/String;"] =
+		classloader.MTable["java/lang/String.valueOf(Ljava/lang/Object;)Ljava/lang/String;"]
+	classloader.MTable["Thrower.toString()Ljava/lang/String;"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  1,
+			MaxLocals: 1,
+			Cp:        &CP,
+			Code:      []byte{opcodes.ALOAD_0, opcodes.ATHROW},
+		}}
+	classloader.MTable["Thrower.describe()Ljava/lang/String;"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  2,
+			MaxLocals: 1,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.ALOAD_0, opcodes.INVOKESTATIC, 0x00, 0x01, opcodes.ARETURN, // try { return valueOf(this) }
+				opcodes.POP, opcodes.LDC, 0x06, opcodes.ARETURN, // catch (Thrower t) { return "caught" }
+			},
+			Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 5, HandlerPc: 5, CatchType: 2}},
+		}}
+
+	thrower := object.MakeEmptyObjectWithClassName(&throwerName)
+	ret, err := InvokeJavaMethod(thrower, "describe", "()Ljava/lang/String;")
+	if err != nil {
+		t.Fatalf("describe(): unexpected error: %v", err)
+	}
+	if str, ok := ret.(*object.Object); !ok || object.GoStringFromStringObject(str) != "caught" {
+		t.Errorf("describe(): expected the exception from toString() to be caught, got %v", ret)
+	}
+
+	// called directly, the exception that toString() doesn't catch is returned
+	_, err = InvokeJavaMethod(thrower, "toString", "()Ljava/lang/String;")
+	var javaExc *gfunction.JavaException
+	if !errors.As(err, &javaExc) || javaExc.Exception != thrower {
+		t.Errorf("toString(): expected a JavaException holding the Thrower, got %v", err)
+	}
+}
@@ -92,63 +92,36 @@ func InstantiateClass(classname string, frameStack *list.List) (any, error) {
 	uintp := uintptr(unsafe.Pointer(&obj))
 	obj.Mark.Hash = uint32(uintp)
 
-	// handle the fields. If the object has no superclass other than Object,
-	// the fields are in an array in the order they're declared in the CP.
-	// If the object has a non-Object superclass, then the superclasses' fields
-	// and the present object's field are stored in a map--indexed by the
-	// field name. Eventually, we might coalesce on a single approach for
-	// both kinds of objects.
-	if len(superclasses) == 0 && len(k.Data.Fields) == 0 {
-		goto runInitializer // check to see if any static initializers
-	}
-
-	// initialize the map of this object's fields
+	// handle the fields. The object's instance fields are those declared in the
+	// class files of the present class and of each of its superclasses, as parsed
+	// by the classloader. We start at the topmost superclass and work our way down
+	// to the present class, adding each instance field with its default value to
+	// FieldTable, which is indexed by field name. Static fields are not part of the
+	// object; createField() places them in the statics table.
+	//
+	// The map is allocated even if there are no fields, so that bytecodes and
+	// G functions can consult it without first checking for nil.
 	obj.FieldTable = make(map[string]object.Field)
 
-	if len(superclasses) == 0 {
-		for i := 0; i < len(k.Data.Fields); i++ {
-			f := k.Data.Fields[i]
-			desc := k.Data.CP.Utf8Refs[f.Desc]
-			name := k.Data.CP.Utf8Refs[f.Name]
-			if log.Level == log.FINE {
-				reciteField := fmt.Sprintf("Class: %s field[%d] name: %s, type: %s", k.Data.Name, i,
-					name, desc)
-				_ = log.Log(reciteField, log.FINE)
-			}
-
-			fieldToAdd, err := createField(f, k, classname)
-			if err != nil {
-				return nil, err
+	classChain := append([]string{classname}, superclasses...)
+	for j := len(classChain) - 1; j >= 0; j-- {
+		c := k
+		if j > 0 {
+			c = classloader.MethAreaFetch(classChain[j])
+			if c == nil {
+				errMsg := fmt.Sprintf("Error in class instantiation, cannot find superclass: %s",
+					classChain[j])
+				_ = log.Log(errMsg, log.SEVERE)
+				return nil, errors.New(errMsg)
 			}
-			obj.FieldTable[name] = *fieldToAdd
-		} // loop through the fields if any
-		// add the field to the field table for this object
-
-		// obj.FieldTable = nil  // removed via JACOBIN-378
-		goto runInitializer
-		// return &obj, nil
-	} // end of handling fields for objects w/ no superclasses
-
-	// in the case of superclasses, we start at the topmost superclass
-	// and work our way down to the present class, adding fields to FieldTable.
-	// so we add the present class into position[0] and then loop through
-	// the slice of class names
-	superclasses = append([]string{classname}, superclasses...)
-	for j := len(superclasses) - 1; j >= 0; j-- {
-		superclassName := superclasses[j]
-		c := classloader.MethAreaFetch(superclassName)
-		if c == nil {
-			errMsg := fmt.Sprintf("Error in class instantiation, cannot find superclass: %s",
-				superclassName)
-			_ = log.Log(errMsg, log.SEVERE)
-			return nil, errors.New(errMsg)
 		}
+
 		for i := 0; i < len(c.Data.Fields); i++ {
 			f := c.Data.Fields[i]
 			desc := c.Data.CP.Utf8Refs[f.Desc]
 			name := c.Data.CP.Utf8Refs[f.Name]
 			if log.Level == log.FINE {
-				reciteField := fmt.Sprintf("Class: %s field[%d] name: %s, type: %s", k.Data.Name, i,
+				reciteField := fmt.Sprintf("Class: %s field[%d] name: %s, type: %s", c.Data.Name, i,
 					name, desc)
 				_ = log.Log(reciteField, log.FINE)
 			}
@@ -158,13 +131,17 @@ func InstantiateClass(classname string, frameStack *list.List) (any, error) {
 				return nil, err
 			}
 
-			// add the field to the field table for this object
-			obj.FieldTable[name] = *fieldToAdd
-		} // end of handling fields for one  class or superclass
-	} // end of handling fields for classes with superclasses other than Object
+			if !f.IsStatic { // add the instance field to the field table for this object
+				obj.FieldTable[name] = *fieldToAdd
+			}
+		} // end of handling fields for one class or superclass
+	} // end of handling fields for the class and its superclasses
 
-runInitializer:
-	// run intialization blocks
+	// run intialization blocks. If there are superclasses other than Object,
+	// runInitializationBlock() expects the present class in position[0].
+	if len(superclasses) > 0 {
+		superclasses = classChain
+	}
 	_, ok := k.Data.MethodTable["<clinit>()V"]
 	if ok && k.Data.ClInit == types.ClInitNotRun {
 		err := runInitializationBlock(k, superclasses, frameStack)
@@ -187,13 +164,18 @@ func createField(f classloader.Field, k *classloader.Klass, classname string) (*
 		_ = log.Log(reciteField, log.FINE)
 	}
 
+	// fields start out with the default values specified in the JVM spec (section 2.3):
+	// 0 for integral types, 0.0 for floating-point types, false for booleans, and
+	// null for references (including arrays)
 	fieldToAdd := new(object.Field)
 	fieldToAdd.Ftype = desc
 	switch string(fieldToAdd.Ftype[0]) {
 	case types.Ref, types.Array: // it's a reference
-		fieldToAdd.Fvalue = nil
-	case types.Byte, types.Char, types.Int, types.Long, types.Short, types.Bool:
+		fieldToAdd.Fvalue = object.Null
+	case types.Byte, types.Char, types.Int, types.Long, types.Short:
 		fieldToAdd.Fvalue = int64(0)
+	case types.Bool:
+		fieldToAdd.Fvalue = types.JavaBoolFalse
 	case types.Double, types.Float:
 		fieldToAdd.Fvalue = 0.0
 	default:
@@ -253,7 +235,7 @@ func createField(f classloader.Field, k *classloader.Klass, classname string) (*
 		fieldName := k.Data.CP.Utf8Refs[f.Name]
 		fullFieldName := classname + "." + fieldName
 
-		_, alreadyPresent := statics.Statics[fullFieldName]
+		_, alreadyPresent := statics.QueryStatic(fullFieldName)
 		if !alreadyPresent { // add only if field has not been pre-loaded
 			_ = statics.AddStatic(fullFieldName, s)
 		}
@@ -269,6 +251,9 @@ func loadThisClass(className string) error {
 	}
 	// Try to load class by name
 	err := classloader.LoadClassFromNameOnly(className)
+	if errors.Is(err, classloader.ErrClassNotFound) {
+		return err // the caller throws a NoClassDefFoundError into the running program
+	}
 	if err != nil {
 		var errClassName = className
 		if className == "" {
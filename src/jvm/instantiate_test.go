@@ -234,3 +234,107 @@ func TestLoadClassJavaLangObject(t *testing.T) {
 		t.Errorf("Got unexpected error from loadThisClass: %s", err.Error())
 	}
 }
+
+// An object's FieldTable is built from the fields the classloader parsed from the
+// class files of the class and its superclass. It should hold exactly the instance
+// fields of both, each with its declared type and the JVM's default value.
+func TestInstantiateFieldsFromParsedClass(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	_ = log.SetLogLevel(log.WARNING)
+	classloader.InitMethodArea()
+
+	superName := "FieldsSuper"
+	subName := "FieldsSub"
+	superIndex := stringPool.GetStringIndex(&superName)
+	subIndex := stringPool.GetStringIndex(&subName)
+
+	// the UTF-8 entries that the parsed fields point to, in the form that
+	// classloader.ParseAndPostClass() leaves them
+	CP := classloader.CPool{}
+	CP.Utf8Refs = []string{
+		"count", "J", "instances", "I", "flag", "Z", "b", "B", "c", "C", "s", "S",
+		"i", "fl", "F", "d", "D", "str", "Ljava/lang/String;", "arr", "[I", "NAME",
+	}
+
+	classloader.MethAreaInsert(superName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            superName,
+			NameIndex:       superIndex,
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			Fields: []classloader.Field{
+				{Name: 0, Desc: 1},                 // long count
+				{Name: 2, Desc: 3, IsStatic: true}, // static int instances
+			},
+			CP: CP,
+		},
+	})
+	classloader.MethAreaInsert(subName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            subName,
+			NameIndex:       subIndex,
+			SuperclassIndex: superIndex,
+			Fields: []classloader.Field{
+				{Name: 4, Desc: 5},                   // boolean flag
+				{Name: 6, Desc: 7},                   // byte b
+				{Name: 8, Desc: 9},                   // char c
+				{Name: 10, Desc: 11},                 // short s
+				{Name: 12, Desc: 3},                  // int i
+				{Name: 13, Desc: 14},                 // float fl
+				{Name: 15, Desc: 16},                 // double d
+				{Name: 17, Desc: 18},                 // String str
+				{Name: 19, Desc: 20},                 // int[] arr
+				{Name: 21, Desc: 18, IsStatic: true}, // static String NAME
+			},
+			CP: CP,
+		},
+	})
+
+	anything, err := InstantiateClass(subName, nil)
+	if err != nil {
+		t.Fatalf("Got unexpected error from instantiating %s: %s", subName, err.Error())
+	}
+	obj := anything.(*object.Object)
+
+	expected := map[string]object.Field{
+		"count": {Ftype: types.Long, Fvalue: int64(0)},
+		"flag":  {Ftype: types.Bool, Fvalue: types.JavaBoolFalse},
+		"b":     {Ftype: types.Byte, Fvalue: int64(0)},
+		"c":     {Ftype: types.Char, Fvalue: int64(0)},
+		"s":     {Ftype: types.Short, Fvalue: int64(0)},
+		"i":     {Ftype: types.Int, Fvalue: int64(0)},
+		"fl":    {Ftype: types.Float, Fvalue: 0.0},
+		"d":     {Ftype: types.Double, Fvalue: 0.0},
+		"str":   {Ftype: "Ljava/lang/String;", Fvalue: object.Null},
+		"arr":   {Ftype: "[I", Fvalue: object.Null},
+	}
+
+	if len(obj.FieldTable) != len(expected) {
+		t.Errorf("Expected %d fields in %s object, got %d fields",
+			len(expected), subName, len(obj.FieldTable))
+	}
+	for name, want := range expected {
+		got, ok := obj.FieldTable[name]
+		if !ok {
+			t.Errorf("Expected field %s in %s object, but it is missing", name, subName)
+			continue
+		}
+		if got.Ftype != want.Ftype {
+			t.Errorf("Field %s: expected type %s, got %s", name, want.Ftype, got.Ftype)
+		}
+		if got.Fvalue != want.Fvalue {
+			t.Errorf("Field %s: expected default value %v, got %v (%T)",
+				name, want.Fvalue, got.Fvalue, got.Fvalue)
+		}
+	}
+
+	for _, static := range []string{"instances", "NAME"} {
+		if _, ok := obj.FieldTable[static]; ok {
+			t.Errorf("Static field %s should not be in the object's FieldTable", static)
+		}
+	}
+}
@@ -62,7 +62,10 @@ func JVMrun() int {
 	// Enable functions call InstantiateClass through a global function variable. (This avoids circularity issues.)
 	globPtr.FuncInstantiateClass = InstantiateClass
 	globPtr.FuncThrowException = exceptions.ThrowExNil
+	globPtr.FuncRunThread = RunJavaThread
+	globPtr.FuncInvokeMethod = InvokeJavaMethod
 	globPtr.FuncFillInStackTrace = gfunction.FillInStackTrace
+	globPtr.FuncShowProfile = showProfile
 
 	_ = log.Log("running program: "+globPtr.JacobinName, log.FINE)
 
@@ -129,6 +132,13 @@ func JVMrun() int {
 	// Likely to be reinstated at some later point
 	// classloader.LoadReferencedClasses(mainClass)
 
+	// the superclasses and interfaces of the main class, however, must be loaded
+	// before it is verified and its methods are run
+	err = classloader.LoadClassHierarchy(*stringPool.GetStringPointer(mainClassNameIndex))
+	if err != nil { // the exception message will already have been shown to user
+		return shutdown.Exit(shutdown.JVM_EXCEPTION)
+	}
+
 	// initialize the MTable (table caching methods)
 	classloader.MTable = make(map[string]classloader.MTentry)
 	gfunction.MTableLoadGFunctions(&classloader.MTable)
@@ -148,5 +158,6 @@ func JVMrun() int {
 	if status != nil {
 		return shutdown.Exit(shutdown.APP_EXCEPTION)
 	}
+	gfunction.WaitForNonDaemonThreads() // the program ends when its last user thread does
 	return shutdown.Exit(shutdown.OK)
 }
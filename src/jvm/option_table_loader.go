@@ -12,9 +12,13 @@ import (
 	"jacobin/execdata"
 	"jacobin/globals"
 	"jacobin/log"
+	"jacobin/shutdown"
 	"jacobin/statics"
 	"jacobin/types"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // This set of routines loads the globPtr.Options table with the various
@@ -31,6 +35,7 @@ import (
 //                              // 0 = no argument      1 = value follows a :
 //                              // 2 = value follows =  4 = value follows a space
 //                              // 8 = option has multiple values separated by a ; (such as -cp)
+//                              // 16 = value follows the option directly (such as -Xmx512m)
 //	        action  func(position int, name string, gl pointer to globasl) error
 //                              // which is the action to perform when this option found.
 //      }
@@ -68,6 +73,26 @@ func LoadOptionsTable(Global globals.Globals) {
 	Global.Options["-client"] = client
 	client.Set = true
 
+	classPath := globals.Option{true, false, 8, setClassPath}
+	Global.Options["-cp"] = classPath
+	Global.Options["-classpath"] = classPath
+	Global.Options["--class-path"] = classPath
+
+	fileEncoding := globals.Option{true, false, 2, setFileEncoding}
+	Global.Options["-Dfile.encoding"] = fileEncoding
+
+	separator := globals.Option{true, false, 2, setSeparator}
+	Global.Options["-Dfile.separator"] = separator
+	Global.Options["-Dpath.separator"] = separator
+
+	stderrEncoding := globals.Option{true, false, 2, setConsoleEncoding}
+	Global.Options["-Dstderr.encoding"] = stderrEncoding
+	Global.Options["-Dsun.stderr.encoding"] = stderrEncoding
+
+	stdoutEncoding := globals.Option{true, false, 2, setConsoleEncoding}
+	Global.Options["-Dstdout.encoding"] = stdoutEncoding
+	Global.Options["-Dsun.stdout.encoding"] = stdoutEncoding
+
 	dryRun := globals.Option{false, false, 0, notSupported}
 	Global.Options["--dry-run"] = dryRun
 	dryRun.Set = true
@@ -87,6 +112,13 @@ func LoadOptionsTable(Global globals.Globals) {
 	Global.Options["-jar"] = jarFile
 	jarFile.Set = true
 
+	modulePath := globals.Option{true, false, 4, modulePathNotSupported}
+	Global.Options["-p"] = modulePath
+	Global.Options["--module-path"] = modulePath
+
+	source := globals.Option{true, false, 4, sourceLauncherNotSupported}
+	Global.Options["--source"] = source
+
 	showversion := globals.Option{true, false, 0, showVersionStderr}
 	Global.Options["-showversion"] = showversion
 
@@ -107,6 +139,28 @@ func LoadOptionsTable(Global globals.Globals) {
 
 	vversion := globals.Option{true, false, 1, versionStdoutThenExit}
 	Global.Options["--version"] = vversion
+
+	xcomp := globals.Option{true, false, 0, compiledMode}
+	Global.Options["-Xcomp"] = xcomp
+
+	xint := globals.Option{true, false, 0, interpretedMode}
+	Global.Options["-Xint"] = xint
+
+	heapSize := globals.Option{true, false, 16, setHeapSize}
+	Global.Options["-Xms"] = heapSize
+	Global.Options["-Xmx"] = heapSize
+
+	threadStackSize := globals.Option{true, false, 16, setThreadStackSize}
+	Global.Options["-Xss"] = threadStackSize
+
+	xprof := globals.Option{true, false, 1, enableProfiling}
+	Global.Options["-Xprof"] = xprof
+
+	xprintcodes := globals.Option{true, false, 4, printCodes}
+	Global.Options["-Xprintcodes"] = xprintcodes
+
+	xxOption := globals.Option{true, false, 1, handleXXoption}
+	Global.Options["-XX"] = xxOption
 }
 
 // ---- the functions for the supported CLI options, in alphabetic order ----
@@ -119,6 +173,113 @@ func clientVM(pos int, name string, gl *globals.Globals) (int, error) {
 	return pos, nil
 }
 
+// for -Xcomp. Jacobin has no compiler, so we say so and continue in interpreted mode.
+func compiledMode(pos int, name string, gl *globals.Globals) (int, error) {
+	setOptionToSeen("-Xcomp", gl)
+	fmt.Fprintln(os.Stderr, "-Xcomp: compilation not supported, running interpreted")
+	gl.ExecMode = "interpreted mode"
+	return pos, nil
+}
+
+// for -XX:<option>, such as -XX:+PrintGCDetails. These options tune HotSpot, principally
+// its garbage collectors and compilers, which Jacobin doesn't have, so they are accepted
+// and ignored. The exceptions are -XX:+DisableExplicitGC (and -XX:-DisableExplicitGC),
+// which determines whether System.gc() runs Go's garbage collector, and
+// -XX:MaxJavaStackTraceDepth=<n>, which limits the lines shown in a stack trace (0 = no limit).
+func handleXXoption(pos int, name string, gl *globals.Globals) (int, error) {
+	setOptionToSeen("-XX", gl)
+	switch name {
+	case "+DisableExplicitGC":
+		gl.DisableExplicitGC = true
+	case "-DisableExplicitGC":
+		gl.DisableExplicitGC = false
+	default:
+		if value, ok := strings.CutPrefix(name, "MaxJavaStackTraceDepth="); ok {
+			depth, err := strconv.Atoi(value)
+			if err != nil || depth < 0 {
+				fmt.Fprintf(os.Stderr, "Improperly specified VM option '%s'\n", name)
+				gl.ExitNow = true
+				return pos, os.ErrInvalid
+			}
+			gl.MaxJavaStackTraceDepth = depth
+			break
+		}
+		_ = log.Log("-XX:"+name+" is not supported by Jacobin and is ignored", log.INFO)
+	}
+	return pos, nil
+}
+
+// for -Xms<size> and -Xmx<size>, which set the initial and maximum heap sizes. Jacobin
+// uses Go's heap, so the sizes are only recorded, and the maximum is reported by
+// Runtime.maxMemory(). As in the JDK, an invalid size is an error that exits the VM.
+func setHeapSize(pos int, argValue string, gl *globals.Globals) (int, error) {
+	option, _, _ := getOptionRootAndArgs(gl.Args[pos])
+	size, err := parseHeapSize(argValue)
+	if err != nil {
+		which := "maximum"
+		if option == "-Xms" {
+			which = "initial"
+		}
+		fmt.Fprintf(os.Stderr, "Invalid %s heap size: %s\n", which, gl.Args[pos])
+		gl.ExitNow = true
+		return pos, err
+	}
+	if option == "-Xms" {
+		gl.InitialHeapSize = size
+	} else {
+		gl.MaxHeapSize = size
+	}
+	setOptionToSeen(option, gl)
+	return pos, nil
+}
+
+// for -Xss<size>, which sets the size of a thread's stack. Jacobin's frames are not laid
+// out in a fixed-size stack, so the size determines how many frames a thread can have
+// before a StackOverflowError is thrown (see maxFrameStackDepth()).
+func setThreadStackSize(pos int, argValue string, gl *globals.Globals) (int, error) {
+	size, err := parseHeapSize(argValue)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid thread stack size: %s\n", gl.Args[pos])
+		gl.ExitNow = true
+		return pos, err
+	}
+	gl.ThreadStackSize = size
+	setOptionToSeen("-Xss", gl)
+	return pos, nil
+}
+
+// parseHeapSize converts a size such as 512m into bytes. The size is a number of bytes,
+// optionally followed by k, m, or g (in either case) for kilobytes, megabytes, or gigabytes.
+func parseHeapSize(sizeStr string) (int64, error) {
+	multiplier := int64(1)
+	if sizeStr != "" {
+		switch sizeStr[len(sizeStr)-1] {
+		case 'k', 'K':
+			multiplier = 1 << 10
+		case 'm', 'M':
+			multiplier = 1 << 20
+		case 'g', 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			sizeStr = sizeStr[:len(sizeStr)-1]
+		}
+	}
+
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil || size <= 0 || size > math.MaxInt64/multiplier {
+		return 0, errors.New("invalid heap size: " + sizeStr)
+	}
+	return size * multiplier, nil
+}
+
+// for -Xint. Jacobin only interprets, so this simply confirms the execution mode.
+func interpretedMode(pos int, name string, gl *globals.Globals) (int, error) {
+	setOptionToSeen("-Xint", gl)
+	gl.ExecMode = "interpreted mode"
+	return pos, nil
+}
+
 // for -jar option. Get the next arg, which must be the JAR filename, and then all remaining args
 // are app args, which are duly added to globPtr.appArgs
 func getJarFilename(pos int, name string, gl *globals.Globals) (int, error) {
@@ -135,6 +296,122 @@ func getJarFilename(pos int, name string, gl *globals.Globals) (int, error) {
 	}
 }
 
+// for -p and --module-path. Jacobin is not yet module-aware, so rather than treat the
+// module path as the name of the starting class, we say so and exit the VM.
+func modulePathNotSupported(pos int, name string, gl *globals.Globals) (int, error) {
+	option, _, _ := getOptionRootAndArgs(gl.Args[pos])
+	setOptionToSeen(option, gl)
+	fmt.Fprintf(os.Stderr, "%s: modules are not yet supported in Jacobin. Exiting.\n", option)
+	gl.ExitNow = true
+	return len(gl.Args), nil
+}
+
+// for -Xprintcodes <classfile>, which prints the bytecodes of each method in the class
+// file as a listing of mnemonics (see printcodes.go), and then exits the VM.
+func printCodes(pos int, name string, gl *globals.Globals) (int, error) {
+	setOptionToSeen("-Xprintcodes", gl)
+	if len(gl.Args) <= pos+1 {
+		fmt.Fprintln(os.Stderr, "-Xprintcodes requires the name of a class file. Exiting.")
+		shutdown.Exit(shutdown.JVM_EXCEPTION)
+		return pos, os.ErrInvalid
+	}
+
+	gl.ExitNow = true
+	if err := printClassCodes(gl.Args[pos+1], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "-Xprintcodes: %s\n", err.Error())
+		shutdown.Exit(shutdown.JVM_EXCEPTION)
+		return len(gl.Args), err
+	}
+	return len(gl.Args), nil
+}
+
+// for --source <version>, which runs a .java file in source-file mode. That requires
+// compiling the file, which Jacobin can't do, so we say so and exit the VM.
+func sourceLauncherNotSupported(pos int, name string, gl *globals.Globals) (int, error) {
+	setOptionToSeen("--source", gl)
+	sourceFile := "--source"
+	for _, arg := range gl.Args[pos+1:] {
+		if strings.HasSuffix(arg, ".java") {
+			sourceFile = arg
+			break
+		}
+	}
+	sourceFileModeNotSupported(sourceFile, gl)
+	return len(gl.Args), nil
+}
+
+// sourceFileModeNotSupported reports that a .java file can't be run in source-file mode,
+// as Java 11 and later can, and marks the VM to exit. Called for --source and when the
+// name of a .java file is given on the command line.
+func sourceFileModeNotSupported(sourceFile string, gl *globals.Globals) {
+	fmt.Fprintf(os.Stderr, "%s: source-file mode requires a compiler, which Jacobin does not have. "+
+		"Compile the file with javac and run the resulting class. Exiting.\n", sourceFile)
+	gl.ExitNow = true
+}
+
+// for -Dfile.encoding=<charset>, which sets the default charset used by readers, writers,
+// and String.getBytes(), and which is reported by the file.encoding system property.
+func setFileEncoding(pos int, argValue string, gl *globals.Globals) (int, error) {
+	if argValue == "" {
+		log.Log("Error: -Dfile.encoding requires a charset name. Ignored.", log.WARNING)
+		return pos, errors.New("missing charset name for -Dfile.encoding")
+	}
+	gl.FileEncoding = argValue
+	setOptionToSeen("-Dfile.encoding", gl)
+	return pos, nil
+}
+
+// for -Dfile.separator=<separator> and -Dpath.separator=<separator>, which set the
+// separators that the File code and the classpath use, and which are reported by the
+// file.separator and path.separator system properties.
+func setSeparator(pos int, argValue string, gl *globals.Globals) (int, error) {
+	option, _, _ := getOptionRootAndArgs(gl.Args[pos])
+	if argValue == "" {
+		log.Log("Error: "+option+" requires a separator. Ignored.", log.WARNING)
+		return pos, errors.New("missing separator for " + option)
+	}
+	if strings.Contains(option, "file") {
+		gl.FileSeparator = argValue
+	} else {
+		gl.PathSeparator = argValue
+	}
+	setOptionToSeen(option, gl)
+	return pos, nil
+}
+
+// for -cp, -classpath, and --class-path, which are followed by the list of directories and
+// jar files in which to look for classes, separated by the path separator.
+func setClassPath(pos int, name string, gl *globals.Globals) (int, error) {
+	option := gl.Args[pos]
+	if len(gl.Args) <= pos+1 {
+		fmt.Fprintf(os.Stderr, "%s requires class path specification\n", option)
+		gl.ExitNow = true
+		return pos, os.ErrInvalid
+	}
+	gl.ClassPath = gl.Args[pos+1]
+	setOptionToSeen(option, gl)
+	log.Log("Class path: "+gl.ClassPath, log.FINE)
+	return pos + 1, nil
+}
+
+// for -Dstdout.encoding=<charset> and -Dstderr.encoding=<charset> (and the older
+// -Dsun.stdout.encoding and -Dsun.stderr.encoding), which set the encoding of what
+// System.out and System.err write, independently of -Dfile.encoding.
+func setConsoleEncoding(pos int, argValue string, gl *globals.Globals) (int, error) {
+	option, _, _ := getOptionRootAndArgs(gl.Args[pos])
+	if argValue == "" {
+		log.Log("Error: "+option+" requires a charset name. Ignored.", log.WARNING)
+		return pos, errors.New("missing charset name for " + option)
+	}
+	if strings.Contains(option, "stderr") {
+		gl.StderrEncoding = argValue
+	} else {
+		gl.StdoutEncoding = argValue
+	}
+	setOptionToSeen(option, gl)
+	return pos, nil
+}
+
 // generic notification function that an option is not supported
 func notSupported(pos int, arg string, gl *globals.Globals) (int, error) {
 	name := gl.Args[pos]
@@ -191,6 +468,23 @@ func enableTraceInstructions(pos int, argValue string, gl *globals.Globals) (int
 	return pos, nil
 }
 
+// for -Xprof:<what>, which counts what the program executes and shows the counts at exit.
+// -Xprof:opcodes counts the executions of each opcode and -Xprof:methods the calls of
+// each method (see profiler.go). Both can be enabled by giving -Xprof twice.
+func enableProfiling(pos int, argValue string, gl *globals.Globals) (int, error) {
+	switch argValue {
+	case "opcodes":
+		gl.ProfileOpcodes = true
+	case "methods":
+		gl.ProfileMethods = true
+	default:
+		log.Log("Error: "+argValue+" is not a valid -Xprof option. Ignored.", log.WARNING)
+		return pos, errors.New("Invalid profiling option specified: " + argValue)
+	}
+	setOptionToSeen("-Xprof", gl)
+	return pos, nil
+}
+
 func enableAssertions(pos int, name string, gl *globals.Globals) (int, error) {
 	setOptionToSeen("-ea", gl)
 	statics.AddStatic("main.$assertionsDisabled",
@@ -198,27 +492,28 @@ func enableAssertions(pos int, name string, gl *globals.Globals) (int, error) {
 	return pos, nil
 }
 
-// set verbosity level. Note Jacobin starts up at WARNING level, so there is no
-// need to set it to that level. You cannot set the level to coarser than WARNING
-// which is why there is no way to set the verbosity to SEVERE only.
+// the logging levels that can be set with -verbose:<level>. You cannot set the level
+// to coarser than WARNING, as warnings must always be shown, so -verbose:severe, which
+// asks for the quietest run, sets the level to WARNING, as -verbose:warning does.
+var verbosityLevels = map[string]int{
+	"severe":  log.WARNING,
+	"warning": log.WARNING,
+	"class":   log.CLASS,
+	"info":    log.INFO,
+	"fine":    log.FINE,
+	"finest":  log.FINEST,
+}
+
+// set verbosity level. Note Jacobin starts up at WARNING level, so -verbose:warning
+// is needed only to undo an earlier -verbose option (such as one from JAVA_TOOL_OPTIONS).
 func verbosityLevel(pos int, argValue string, gl *globals.Globals) (int, error) {
-	switch argValue {
-	case "class":
-		log.Level = log.CLASS
-		log.Log("Logging level set to CLASS", log.INFO)
-	case "info":
-		log.Level = log.INFO
-		log.Log("Logging level set to log.INFO", log.INFO)
-	case "fine":
-		log.Level = log.FINE
-		log.Log("Logging level set to FINE", log.INFO)
-	case "finest":
-		log.Level = log.FINEST
-		log.Log("Logging level set to FINEST", log.INFO)
-	default:
+	level, ok := verbosityLevels[argValue]
+	if !ok {
 		log.Log("Error: "+argValue+" is not a valid verbosity option. Ignored.", log.WARNING)
 		return pos, errors.New("Invalid logging level specified: " + argValue)
 	}
+	_ = log.SetLogLevel(level)
+	log.Log("Logging level set to "+strings.ToUpper(argValue), log.INFO)
 	setOptionToSeen("-verbose", gl) // mark the -verbose option as having been specified
 
 	if log.Level == log.FINEST {
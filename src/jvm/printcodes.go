@@ -0,0 +1,214 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"jacobin/classloader"
+	"jacobin/opcodes"
+	"jacobin/stringPool"
+	"strings"
+)
+
+// This file contains the disassembler used by the -Xprintcodes option, which prints
+// the bytecodes of every method in a class as a listing of mnemonics, one instruction
+// per line: its PC, the opcode name, and its decoded operands. CP references are shown
+// as #index followed by a comment giving what the CP entry refers to.
+
+// names of the primitive array types used by NEWARRAY
+var newarrayTypes = map[byte]string{
+	4: "boolean", 5: "char", 6: "float", 7: "double",
+	8: "byte", 9: "short", 10: "int", 11: "long",
+}
+
+// printClassCodes loads the class in the named class file and prints the bytecodes
+// of each of its methods, in the order they're declared in the class, to out.
+func printClassCodes(filename string, out io.Writer) error {
+	classloader.InitMethodArea()
+	nameIndex, err := classloader.LoadClassFromFile(classloader.BootstrapCL, filename)
+	if err != nil {
+		return err
+	}
+	className := *stringPool.GetStringPointer(nameIndex)
+	k := classloader.MethAreaFetch(className)
+	if k == nil || k.Data == nil {
+		return errors.New("could not load class " + className)
+	}
+
+	_, _ = fmt.Fprintf(out, "class %s\n", className)
+	for _, m := range k.Data.Methods {
+		_, _ = fmt.Fprintf(out, "\n  %s%s\n", k.Data.CP.Utf8Refs[m.Name], k.Data.CP.Utf8Refs[m.Desc])
+		if len(m.CodeAttr.Code) == 0 { // abstract and native methods have no bytecode
+			_, _ = fmt.Fprintln(out, "    (no code)")
+			continue
+		}
+		disassemble(m.CodeAttr.Code, &k.Data.CP, out)
+	}
+	return nil
+}
+
+// disassemble prints a listing of the bytecodes in code to out. CP references are
+// resolved using cp.
+func disassemble(code []byte, cp *classloader.CPool, out io.Writer) {
+	for pc := 0; pc < len(code); {
+		name := opcodes.BytecodeName(code[pc])
+		length := opcodes.InstructionLength(code, pc)
+		if length == 0 { // the instruction's operands run past the end of the code
+			_, _ = fmt.Fprintf(out, "%6d: %s <truncated>\n", pc, name)
+			return
+		}
+
+		operands := decodeOperands(code, pc, cp)
+		if operands == "" {
+			_, _ = fmt.Fprintf(out, "%6d: %s\n", pc, name)
+		} else {
+			_, _ = fmt.Fprintf(out, "%6d: %-16s%s\n", pc, name, operands)
+		}
+		pc += length
+	}
+}
+
+// decodeOperands returns the operands of the instruction at code[pc] in printable form.
+// The caller must have checked, using opcodes.InstructionLength(), that the operands
+// lie within code.
+func decodeOperands(code []byte, pc int, cp *classloader.CPool) string {
+	u1 := func(at int) int { return int(code[at]) }
+	u2 := func(at int) int { return int(code[at])<<8 | int(code[at+1]) }
+	s2 := func(at int) int { return int(int16(u2(at))) }
+	s4 := func(at int) int {
+		return int(int32(uint32(code[at])<<24 | uint32(code[at+1])<<16 | uint32(code[at+2])<<8 | uint32(code[at+3])))
+	}
+
+	opcode := code[pc]
+	switch {
+	case opcode == opcodes.BIPUSH:
+		return fmt.Sprintf("%d", int8(code[pc+1]))
+
+	case opcode == opcodes.SIPUSH:
+		return fmt.Sprintf("%d", s2(pc+1))
+
+	case opcode == opcodes.LDC:
+		return cpOperand(cp, u1(pc+1))
+
+	case opcode == opcodes.LDC_W || opcode == opcodes.LDC2_W ||
+		(opcode >= opcodes.GETSTATIC && opcode <= opcodes.INVOKESTATIC) ||
+		opcode == opcodes.NEW || opcode == opcodes.ANEWARRAY ||
+		opcode == opcodes.CHECKCAST || opcode == opcodes.INSTANCEOF ||
+		opcode == opcodes.INVOKEDYNAMIC:
+		return cpOperand(cp, u2(pc+1))
+
+	case opcode == opcodes.INVOKEINTERFACE:
+		return fmt.Sprintf("%s, count %d", cpOperand(cp, u2(pc+1)), u1(pc+3))
+
+	case opcode == opcodes.MULTIANEWARRAY:
+		return fmt.Sprintf("%s, dimensions %d", cpOperand(cp, u2(pc+1)), u1(pc+3))
+
+	case (opcode >= opcodes.ILOAD && opcode <= opcodes.ALOAD) ||
+		(opcode >= opcodes.ISTORE && opcode <= opcodes.ASTORE) || opcode == opcodes.RET:
+		return fmt.Sprintf("%d", u1(pc+1))
+
+	case opcode == opcodes.IINC:
+		return fmt.Sprintf("%d, %d", u1(pc+1), int8(code[pc+2]))
+
+	case opcode == opcodes.NEWARRAY:
+		typeName, ok := newarrayTypes[code[pc+1]]
+		if !ok {
+			typeName = fmt.Sprintf("<invalid type %d>", code[pc+1])
+		}
+		return typeName
+
+	case (opcode >= opcodes.IFEQ && opcode <= opcodes.JSR) ||
+		opcode == opcodes.IFNULL || opcode == opcodes.IFNONNULL:
+		return fmt.Sprintf("%d", pc+s2(pc+1))
+
+	case opcode == opcodes.GOTO_W || opcode == opcodes.JSR_W:
+		return fmt.Sprintf("%d", pc+s4(pc+1))
+
+	case opcode == opcodes.WIDE:
+		widened := opcodes.BytecodeName(code[pc+1])
+		if code[pc+1] == opcodes.IINC {
+			return fmt.Sprintf("%s %d, %d", widened, u2(pc+2), s2(pc+4))
+		}
+		return fmt.Sprintf("%s %d", widened, u2(pc+2))
+
+	case opcode == opcodes.TABLESWITCH:
+		base := pc + 1 + (3 - pc%4) // the operands are 4-byte aligned
+		low, high := s4(base+4), s4(base+8)
+		var cases []string
+		for i := 0; i <= high-low; i++ {
+			cases = append(cases, fmt.Sprintf("%d: %d", low+i, pc+s4(base+12+4*i)))
+		}
+		cases = append(cases, fmt.Sprintf("default: %d", pc+s4(base)))
+		return "{ " + strings.Join(cases, ", ") + " }"
+
+	case opcode == opcodes.LOOKUPSWITCH:
+		base := pc + 1 + (3 - pc%4) // the operands are 4-byte aligned
+		var cases []string
+		for i := 0; i < s4(base+4); i++ {
+			pair := base + 8 + 8*i
+			cases = append(cases, fmt.Sprintf("%d: %d", s4(pair), pc+s4(pair+4)))
+		}
+		cases = append(cases, fmt.Sprintf("default: %d", pc+s4(base)))
+		return "{ " + strings.Join(cases, ", ") + " }"
+	}
+
+	return "" // all other instructions have no operands
+}
+
+// cpOperand formats a reference to CP entry index as #index, followed by a comment
+// that describes the entry, where it can be resolved
+func cpOperand(cp *classloader.CPool, index int) string {
+	description := describeCPentry(cp, index)
+	if description == "" {
+		return fmt.Sprintf("#%d", index)
+	}
+	return fmt.Sprintf("#%d // %s", index, description)
+}
+
+// describeCPentry returns a description of the CP entry at index, such as
+// "Method java/io/PrintStream.println:(Ljava/lang/String;)V", or an empty string
+// if the entry is not one that the disassembler resolves
+func describeCPentry(cp *classloader.CPool, index int) string {
+	if cp == nil || index < 1 || index >= len(cp.CpIndex) {
+		return ""
+	}
+
+	// memberRef describes the class, name, and type of a field, method, or interface method
+	memberRef := func(classIndex, nameAndTypeIndex uint16) string {
+		className := classloader.GetClassNameFromCPclassref(cp, classIndex)
+		nameAndType := cp.NameAndTypes[cp.CpIndex[nameAndTypeIndex].Slot]
+		name := cp.Utf8Refs[cp.CpIndex[nameAndType.NameIndex].Slot]
+		desc := cp.Utf8Refs[cp.CpIndex[nameAndType.DescIndex].Slot]
+		return fmt.Sprintf("%s.%s:%s", className, name, desc)
+	}
+
+	entry := cp.CpIndex[index]
+	switch entry.Type {
+	case classloader.ClassRef:
+		return "Class " + classloader.GetClassNameFromCPclassref(cp, uint16(index))
+	case classloader.FieldRef:
+		ref := cp.FieldRefs[entry.Slot]
+		return "Field " + memberRef(ref.ClassIndex, ref.NameAndType)
+	case classloader.MethodRef:
+		ref := cp.MethodRefs[entry.Slot]
+		return "Method " + memberRef(ref.ClassIndex, ref.NameAndType)
+	case classloader.Interface:
+		ref := cp.InterfaceRefs[entry.Slot]
+		return "InterfaceMethod " + memberRef(ref.ClassIndex, ref.NameAndType)
+	case classloader.StringConst:
+		if str := classloader.FetchCPentry(cp, index); str.RetType == classloader.IS_STRING_ADDR {
+			return "String " + *str.StringVal
+		}
+	case classloader.IntConst, classloader.LongConst:
+		return fmt.Sprintf("%d", classloader.FetchCPentry(cp, index).IntVal)
+	case classloader.FloatConst, classloader.DoubleConst:
+		return fmt.Sprintf("%g", classloader.FetchCPentry(cp, index).FloatVal)
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"bytes"
+	"jacobin/classloader"
+	"jacobin/globals"
+	"jacobin/opcodes"
+	"jacobin/stringPool"
+	"strings"
+	"testing"
+)
+
+// disassemble a small method that prints a string, counts down a local, and switches
+// on a value, and check the resulting mnemonic listing
+func TestDisassembleMethod(t *testing.T) {
+	globals.InitGlobals("test")
+
+	systemName := "java/lang/System"
+	printStreamName := "java/io/PrintStream"
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 14)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.FieldRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.StringConst, Slot: 9}
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 1}
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 1}
+	CP.CpIndex[9] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[10] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[11] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}
+	CP.CpIndex[12] = classloader.CpEntry{Type: classloader.UTF8, Slot: 3}
+	CP.CpIndex[13] = classloader.CpEntry{Type: classloader.UTF8, Slot: 4}
+	CP.FieldRefs = []classloader.FieldRefEntry{{ClassIndex: 4, NameAndType: 5}}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 6, NameAndType: 7}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&systemName), stringPool.GetStringIndex(&printStreamName)}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 10, DescIndex: 11}, {NameIndex: 12, DescIndex: 13}}
+	CP.Utf8Refs = []string{"hello", "out", "Ljava/io/PrintStream;", "println", "(Ljava/lang/String;)V"}
+
+	code := []byte{
+		opcodes.GETSTATIC, 0x00, 0x01, // 0
+		opcodes.LDC, 0x02, // 3
+		opcodes.INVOKEVIRTUAL, 0x00, 0x03, // 5
+		opcodes.BIPUSH, 0x0A, // 8
+		opcodes.ISTORE_1,         // 10
+		opcodes.IINC, 0x01, 0xFF, // 11
+		opcodes.ILOAD_1,          // 14
+		opcodes.IFNE, 0xFF, 0xFC, // 15: back to 11
+		opcodes.TABLESWITCH, 0x00, // 18, padded to a 4-byte boundary
+		0x00, 0x00, 0x00, 0x16, // default: 18+22
+		0x00, 0x00, 0x00, 0x00, // low
+		0x00, 0x00, 0x00, 0x01, // high
+		0x00, 0x00, 0x00, 0x16, // case 0
+		0x00, 0x00, 0x00, 0x16, // case 1
+		opcodes.RETURN, // 40
+	}
+
+	var out bytes.Buffer
+	disassemble(code, &CP, &out)
+	listing := out.String()
+
+	expected := []string{
+		"     0: GETSTATIC       #1 // Field java/lang/System.out:Ljava/io/PrintStream;\n",
+		"     3: LDC             #2 // String hello\n",
+		"     5: INVOKEVIRTUAL   #3 // Method java/io/PrintStream.println:(Ljava/lang/String;)V\n",
+		"     8: BIPUSH          10\n",
+		"    10: ISTORE_1\n",
+		"    11: IINC            1, -1\n",
+		"    15: IFNE            11\n",
+		"    18: TABLESWITCH     { 0: 40, 1: 40, default: 40 }\n",
+		"    40: RETURN\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(listing, line) {
+			t.Errorf("expected the listing to contain %q, got:\n%s", line, listing)
+		}
+	}
+	if lines := strings.Count(listing, "\n"); lines != 10 {
+		t.Errorf("expected 10 instructions in the listing, got %d:\n%s", lines, listing)
+	}
+}
+
+// an instruction whose operands run past the end of the code is flagged, and the
+// listing ends there
+func TestDisassembleTruncatedCode(t *testing.T) {
+	globals.InitGlobals("test")
+
+	var out bytes.Buffer
+	disassemble([]byte{opcodes.ICONST_0, opcodes.SIPUSH, 0x01}, nil, &out)
+	expected := "     0: ICONST_0\n     1: SIPUSH <truncated>\n"
+	if out.String() != expected {
+		t.Errorf("expected listing:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"fmt"
+	"io"
+	"jacobin/globals"
+	"jacobin/opcodes"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// The profiler counts what the program executes, for performance analysis. It's enabled
+// by -Xprof:opcodes, which counts the executions of each opcode, and by -Xprof:methods,
+// which counts the calls of each method, whether in bytecode or a gfunction. The counts
+// are shown on stderr when the program exits (see showProfile()). As several threads can
+// execute bytecodes at once, the counts are updated atomically or under a mutex.
+
+// the number of times each opcode has been executed, indexed by opcode
+var opcodeCounts [256]atomic.Int64
+
+// the number of times each method has been called, keyed by class.method+signature
+var methodCounts = make(map[string]int64)
+var methodCountsMutex sync.Mutex
+
+// countOpcode records one execution of opcode. Called by runFrame() for each bytecode
+// it executes if -Xprof:opcodes is in effect.
+func countOpcode(opcode byte) {
+	opcodeCounts[opcode].Add(1)
+}
+
+// countMethodCall records one call of className.methodName with the signature methodType.
+// Called for each method invoked, if -Xprof:methods is in effect: by createAndInitNewFrame()
+// for methods in bytecode and by runGfunction() for gfunctions.
+func countMethodCall(className, methodName, methodType string) {
+	methodCountsMutex.Lock()
+	methodCounts[className+"."+methodName+methodType]++
+	methodCountsMutex.Unlock()
+}
+
+// resetProfile clears all the profiling counts
+func resetProfile() {
+	for i := range opcodeCounts {
+		opcodeCounts[i].Store(0)
+	}
+	methodCountsMutex.Lock()
+	methodCounts = make(map[string]int64)
+	methodCountsMutex.Unlock()
+}
+
+// showProfile writes the profiles enabled by -Xprof to stderr. Called at shutdown via
+// globals.FuncShowProfile.
+func showProfile() {
+	glob := globals.GetGlobalRef()
+	if glob.ProfileOpcodes {
+		writeOpcodeProfile(os.Stderr)
+	}
+	if glob.ProfileMethods {
+		writeMethodProfile(os.Stderr)
+	}
+}
+
+// writeOpcodeProfile writes a histogram of the opcodes that have been executed, from the
+// most executed to the least, with the number of executions of each
+func writeOpcodeProfile(w io.Writer) {
+	var executed []int
+	var total int64
+	for opcode := range opcodeCounts {
+		if count := opcodeCounts[opcode].Load(); count > 0 {
+			executed = append(executed, opcode)
+			total += count
+		}
+	}
+	sort.SliceStable(executed, func(i, j int) bool {
+		return opcodeCounts[executed[i]].Load() > opcodeCounts[executed[j]].Load()
+	})
+
+	_, _ = fmt.Fprintf(w, "Opcode profile: %d bytecodes executed\n", total)
+	for _, opcode := range executed {
+		count := opcodeCounts[opcode].Load()
+		_, _ = fmt.Fprintf(w, "  %-16s %12d %6.2f%%\n",
+			opcodes.BytecodeName(byte(opcode)), count, float64(count)*100/float64(total))
+	}
+}
+
+// writeMethodProfile writes the methods that have been called, from the most called to
+// the least, with the number of calls of each. Methods called equally often are listed
+// in alphabetical order.
+func writeMethodProfile(w io.Writer) {
+	methodCountsMutex.Lock()
+	defer methodCountsMutex.Unlock()
+
+	var methods []string
+	var total int64
+	for method, count := range methodCounts {
+		methods = append(methods, method)
+		total += count
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		ci, cj := methodCounts[methods[i]], methodCounts[methods[j]]
+		return ci > cj || (ci == cj && methods[i] < methods[j])
+	})
+
+	_, _ = fmt.Fprintf(w, "Method profile: %d calls\n", total)
+	for _, method := range methods {
+		_, _ = fmt.Fprintf(w, "  %12d  %s\n", methodCounts[method], method)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"bytes"
+	"jacobin/classloader"
+	"jacobin/gfunction"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/opcodes"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"strings"
+	"testing"
+)
+
+// with -Xprof:opcodes, each bytecode runFrame() executes is counted
+func TestOpcodeProfileCounts(t *testing.T) {
+	globals.InitGlobals("test")
+	globals.GetGlobalRef().ProfileOpcodes = true
+	resetProfile()
+	defer resetProfile()
+
+	runIntLoop() // sum += i for i from 0 to 999
+
+	expected := map[byte]int64{
+		opcodes.IADD:      1000,
+		opcodes.IINC:      1000,
+		opcodes.IF_ICMPGE: 1001, // the last comparison ends the loop
+		opcodes.ISTORE_0:  1001,
+		opcodes.RETURN:    1,
+	}
+	for opcode, count := range expected {
+		if got := opcodeCounts[opcode].Load(); got != count {
+			t.Errorf("-Xprof:opcodes: expected %s to be executed %d times, got: %d",
+				opcodes.BytecodeName(opcode), count, got)
+		}
+	}
+	if got := opcodeCounts[opcodes.LADD].Load(); got != 0 {
+		t.Errorf("-Xprof:opcodes: expected LADD not to be executed, got: %d", got)
+	}
+
+	var out bytes.Buffer
+	writeOpcodeProfile(&out)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if lines[0] != "Opcode profile: 9008 bytecodes executed" {
+		t.Errorf("-Xprof:opcodes: unexpected profile heading: %s", lines[0])
+	}
+	if !strings.HasPrefix(strings.TrimSpace(lines[len(lines)-1]), "RETURN") {
+		t.Errorf("-Xprof:opcodes: expected the least executed opcode, RETURN, last, got: %s",
+			lines[len(lines)-1])
+	}
+}
+
+// without -Xprof:opcodes, nothing is counted
+func TestOpcodeProfileDisabled(t *testing.T) {
+	globals.InitGlobals("test")
+	resetProfile()
+
+	runIntLoop()
+
+	if got := opcodeCounts[opcodes.IADD].Load(); got != 0 {
+		t.Errorf("expected no opcodes to be counted without -Xprof:opcodes, got IADD: %d", got)
+	}
+}
+
+// with -Xprof:methods, each call of a method, whether in bytecode or a gfunction, is
+// counted. Looper.loop() calls Counter.inc(), a method in bytecode, and Recorder.record(),
+// a gfunction, ten times each.
+func TestMethodProfileCounts(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	globals.GetGlobalRef().ProfileMethods = true
+	resetProfile()
+	defer resetProfile()
+
+	counterName := "Counter"
+	recorderName := "Recorder"
+	looperName := "Looper"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = []classloader.CpEntry{
+		{Type: 0, Slot: 0},
+		{Type: classloader.MethodRef, Slot: 0},   // 1 Counter.inc()V
+		{Type: classloader.ClassRef, Slot: 0},    // 2 Counter
+		{Type: classloader.NameAndType, Slot: 0}, // 3 inc()V
+		{Type: classloader.UTF8, Slot: 0},        // 4 inc
+		{Type: classloader.UTF8, Slot: 1},        // 5 ()V
+		{Type: classloader.MethodRef, Slot: 1},   // 6 Recorder.record()V
+		{Type: classloader.ClassRef, Slot: 1},    // 7 Recorder
+		{Type: classloader.NameAndType, Slot: 1}, // 8 record()V
+		{Type: classloader.UTF8, Slot: 2},        // 9 record
+	}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}, {ClassIndex: 7, NameAndType: 8}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&counterName), stringPool.GetStringIndex(&recorderName)}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}, {NameIndex: 9, DescIndex: 5}}
+	CP.Utf8Refs = []string{"inc", "()V", "record"}
+
+	for _, name := range []string{counterName, recorderName, looperName} {
+		classloader.MethAreaInsert(name, &classloader.Klass{
+			Status: 'X',
+			Loader: "test",
+			Data: &classloader.ClData{
+				Name:            name,
+				NameIndex:       stringPool.GetStringIndex(&name),
+				SuperclassIndex: types.ObjectPoolStringIndex,
+				MethodTable:     make(map[string]*classloader.Method),
+				CP:              CP,
+				ClInit:          types.ClInitRun,
+			},
+		})
+	}
+
+	classloader.MTable["Counter.inc()V"] = classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{
+		MaxStack: 1, MaxLocals: 1, Cp: &CP, Code: []byte{opcodes.RETURN},
+	}}
+	classloader.MTable["Recorder.record()V"] = classloader.MTentry{MType: 'G', Meth: gfunction.GMeth{
+		ParamSlots: 0,
+		GFunction:  func(params []interface{}) interface{} { return nil },
+	}}
+
+	// void loop() { for (int i = 0; i < 10; i++) { Counter.inc(); Recorder.record(); } }
+	classloader.MTable["Looper.loop()V"] = classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{
+		MaxStack:  2,
+		MaxLocals: 2,
+		Cp:        &CP,
+		Code: []byte{
+			opcodes.ICONST_0, opcodes.ISTORE_1, // 0: i = 0
+			opcodes.ILOAD_1, opcodes.BIPUSH, 10, // 2: i < 10
+			opcodes.IF_ICMPGE, 0x00, 0x0F, // 5: to 20
+			opcodes.INVOKESTATIC, 0x00, 0x01, // 8: Counter.inc()
+			opcodes.INVOKESTATIC, 0x00, 0x06, // 11: Recorder.record()
+			opcodes.IINC, 0x01, 0x01, // 14: i++
+			opcodes.GOTO, 0xFF, 0xF1, // 17: to 2
+			opcodes.RETURN, // 20
+		},
+	}}
+
+	if _, err := InvokeJavaMethod(object.MakeEmptyObjectWithClassName(&looperName), "loop", "()V"); err != nil {
+		t.Fatalf("-Xprof:methods: unexpected error running Looper.loop(): %v", err)
+	}
+
+	if count := methodCounts["Counter.inc()V"]; count != 10 {
+		t.Errorf("-Xprof:methods: expected Counter.inc()V to be called 10 times, got: %d", count)
+	}
+	if count := methodCounts["Recorder.record()V"]; count != 10 {
+		t.Errorf("-Xprof:methods: expected Recorder.record()V to be called 10 times, got: %d", count)
+	}
+
+	var out bytes.Buffer
+	writeMethodProfile(&out)
+	expected := "Method profile: 20 calls\n" +
+		"            10  Counter.inc()V\n" +
+		"            10  Recorder.record()V\n"
+	if out.String() != expected {
+		t.Errorf("-Xprof:methods: expected the profile:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
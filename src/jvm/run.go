@@ -29,6 +29,8 @@ import (
 	"jacobin/util"
 	"math"
 	"runtime/debug"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"unsafe"
@@ -139,6 +141,9 @@ func runThread(t *thread.ExecThread) error {
 	for t.Stack.Len() > 0 {
 		err := runFrame(t.Stack)
 		if err != nil {
+			if invokedThreadException(t.ID) != nil { // the exception is passed to the invoker
+				return err
+			}
 			exceptions.ShowFrameStack(t)
 			if globals.GetGlobalRef().GoStackShown == false {
 				exceptions.ShowGoStackTrace(nil)
@@ -150,16 +155,149 @@ func runThread(t *thread.ExecThread) error {
 		if t.Stack.Len() == 1 { // true when the last executed frame was main()
 			return nil
 		} else {
-			t.Stack.Remove(t.Stack.Front()) // pop the frame off
+			// pop the frame off; as nothing refers to it any longer, it can be reused
+			frames.ReleaseFrame(t.Stack.Remove(t.Stack.Front()).(*frames.Frame))
 		}
 	}
 	return nil
 }
 
+// RunJavaThread runs the run() method of runnable, an object implementing
+// java/lang/Runnable, on a new execution thread. It returns when run() does.
+// Called by the java/lang/Thread gfunctions via globals.FuncRunThread
+func RunJavaThread(runnable any) error {
+	obj, ok := runnable.(*object.Object)
+	if !ok || object.IsNull(obj) {
+		return errors.New("RunJavaThread: runnable is not a valid object")
+	}
+
+	className := object.GoStringFromStringPoolIndex(obj.KlassName)
+	me, err := classloader.FetchMethodAndCP(className, "run", "()V")
+	if err != nil {
+		return fmt.Errorf("RunJavaThread: run() not found in %s: %w", className, err)
+	}
+	if me.MType == 'G' { // such as Thread.run(), in a Thread subclass that doesn't override it
+		ret := me.Meth.(gfunction.GMeth).GFunction([]interface{}{obj})
+		switch ret := ret.(type) {
+		case *gfunction.GErrBlk:
+			return fmt.Errorf("RunJavaThread: %s.run(): %s", className, ret.ErrMsg)
+		case error:
+			return ret
+		}
+		return nil
+	}
+	if me.MType != 'J' {
+		return fmt.Errorf("RunJavaThread: run() in %s is not a Java method", className)
+	}
+
+	t := thread.CreateThread()
+	t.Stack = frames.CreateFrameStack()
+	t.Trace = MainThread.Trace
+	t.AddThreadToTable(globals.GetGlobalRef())
+
+	f := createMethodFrame(&t, className, "run", "()V", me.Meth.(classloader.JmEntry), obj)
+	if frames.PushFrame(t.Stack, f) != nil {
+		errMsg := "Memory error allocating frame on thread: " + strconv.Itoa(t.ID)
+		_ = log.Log(errMsg, log.SEVERE)
+		return errors.New(errMsg)
+	}
+	return runThread(&t)
+}
+
+// InvokeJavaMethod calls the no-argument instance method methName, whose descriptor is
+// methType, on obj and returns the method's return value (nil for a void method). As with
+// invokevirtual, the method is looked up starting at obj's class, so overrides are found.
+// A Java method runs to completion on a temporary execution thread. If it throws an
+// exception that it doesn't catch, the exception is returned in a *gfunction.JavaException,
+// so that the gfunction can pass it back to be thrown in the calling thread. Called by
+// gfunctions, such as those that need an object's toString(), via globals.FuncInvokeMethod
+func InvokeJavaMethod(receiver any, methName, methType string) (any, error) {
+	obj, ok := receiver.(*object.Object)
+	if !ok || object.IsNull(obj) {
+		return nil, errors.New("InvokeJavaMethod: not a valid object")
+	}
+
+	className := object.GoStringFromStringPoolIndex(obj.KlassName)
+	me, err := classloader.FetchMethodAndCP(className, methName, methType)
+	if err != nil {
+		return nil, fmt.Errorf("InvokeJavaMethod: %s%s not found in %s: %w", methName, methType, className, err)
+	}
+
+	if me.MType == 'G' {
+		ret := me.Meth.(gfunction.GMeth).GFunction([]interface{}{obj})
+		if errBlk, isErr := ret.(*gfunction.GErrBlk); isErr {
+			if errBlk.Exception != nil {
+				return nil, &gfunction.JavaException{Exception: errBlk.Exception}
+			}
+			return nil, errors.New(errBlk.ErrMsg)
+		}
+		return ret, nil
+	}
+
+	t := thread.CreateThread()
+	t.Stack = frames.CreateFrameStack()
+	t.Trace = MainThread.Trace
+	glob := globals.GetGlobalRef()
+	t.AddThreadToTable(glob)
+	invokedThreads.Store(t.ID, (*object.Object)(nil))
+	defer func() {
+		invokedThreads.Delete(t.ID)
+		glob.ThreadLock.Lock()
+		delete(glob.Threads, t.ID)
+		glob.ThreadLock.Unlock()
+	}()
+
+	// the method returns its value onto the stack of the frame beneath it, so a frame
+	// is placed underneath the method's frame to receive that value
+	catcher := frames.CreateFrame(1)
+	catcher.Thread = t.ID
+	f := createMethodFrame(&t, className, methName, methType, me.Meth.(classloader.JmEntry), obj)
+	if frames.PushFrame(t.Stack, catcher) != nil || frames.PushFrame(t.Stack, f) != nil {
+		errMsg := "Memory error allocating frame on thread: " + strconv.Itoa(t.ID)
+		_ = log.Log(errMsg, log.SEVERE)
+		return nil, errors.New(errMsg)
+	}
+	if err = runThread(&t); err != nil {
+		if exc := invokedThreadException(t.ID); exc != nil {
+			return nil, &gfunction.JavaException{Exception: exc}
+		}
+		return nil, err
+	}
+
+	if catcher.TOS < 0 { // a void method
+		return nil, nil
+	}
+	return pop(catcher), nil
+}
+
+// createMethodFrame creates the frame for executing the Java method m, an instance method
+// of className, on thread t, with obj as this
+func createMethodFrame(t *thread.ExecThread, className, methName, methType string,
+	m classloader.JmEntry, obj *object.Object) *frames.Frame {
+	f := frames.CreateFrame(m.MaxStack + 2) // the +2 is arbitrary, as in StartExec()
+	f.Thread = t.ID
+	f.ClName = className
+	f.MethName = methName
+	f.MethType = methType
+	f.CP = m.Cp
+	f.Meth = append(f.Meth, m.Code...)
+	f.Locals = make([]interface{}, max(m.MaxLocals, 1))
+	f.Locals[0] = obj // this
+	return f
+}
+
 // runFrame() is the principal execution function in Jacobin. It first tests for a
 // golang function in the present frame. If it is a golang function, it's sent to
 // a different function for execution. Otherwise, bytecode interpretation takes
 // place through a giant switch statement.
+//
+// The switch is preferred to a table of functions indexed by opcode. The Go compiler
+// turns a dense switch on a byte into a jump table, so dispatch is already an indexed
+// jump, without the call and the return that a function table would add to every
+// bytecode. And the switch lets a case do what a function can't easily do: update
+// runFrame's own state (wideInEffect, and f on a call or a caught exception) and
+// resume interpretation with the new head frame via goto frameInterpreter.
+// BenchmarkRunFrameIntLoop measures the dispatch over a tight loop of int arithmetic.
 func runFrame(fs *list.List) error {
 	glob := globals.GetGlobalRef()
 	wideInEffect := false
@@ -178,6 +316,9 @@ frameInterpreter:
 		}
 
 		opcode := f.Meth[f.PC]
+		if glob.ProfileOpcodes {
+			countOpcode(opcode)
+		}
 		switch opcode { // cases listed in numerical value of opcode
 		case opcodes.NOP:
 			break
@@ -424,14 +565,15 @@ frameInterpreter:
 				}
 			}
 
-			if index >= int64(len(array)) {
+			size := int64(len(array))
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
-				errMsg := fmt.Sprintf("in %s.%s, I/C/S/LALOAD: Invalid array subscript",
-					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				errMsg := fmt.Sprintf("in %s.%s, I/C/S/LALOAD: Invalid array subscript: %d, array size is %d",
+					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, index, size)
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 			var value = array[index]
 			push(f, value)
@@ -469,14 +611,15 @@ frameInterpreter:
 				}
 			}
 
-			if index >= int64(len(array)) {
+			size := int64(len(array))
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
-				errMsg := fmt.Sprintf("in %s.%s, D/FALOAD: Invalid array subscript",
-					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				errMsg := fmt.Sprintf("in %s.%s, D/FALOAD: Invalid array subscript: %d, array size is %d",
+					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, index, size)
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 
 			var value = array[index]
@@ -501,14 +644,14 @@ frameInterpreter:
 			array := fvalue.([]*object.Object)
 
 			size := int64(len(array))
-			if index >= size {
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
-				errMsg := fmt.Sprintf("in %s.%s, AALOAD: Invalid array subscript: %d",
-					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, index)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				errMsg := fmt.Sprintf("in %s.%s, AALOAD: Invalid array subscript: %d, array size is %d",
+					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, index, size)
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 
 			var value = array[index]
@@ -547,15 +690,14 @@ frameInterpreter:
 				}
 			}
 			size := int64(len(array))
-
-			if index >= size {
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
-				errMsg := fmt.Sprintf("in %s.%s, BALOAD: Invalid array subscript: %d",
-					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, index)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				errMsg := fmt.Sprintf("in %s.%s, BALOAD: Invalid array subscript: %d, array size is %d",
+					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, index, size)
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 			var value = array[index]
 			push(f, int64(value))
@@ -771,14 +913,14 @@ frameInterpreter:
 			}
 
 			size := int64(len(array))
-			if index >= size {
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
 				errMsg := fmt.Sprintf("in %s.%s, I/C/S/LASTORE: array size is %d but array index is %d",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, size, index)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 			array[index] = value
 
@@ -827,20 +969,20 @@ frameInterpreter:
 			}
 
 			size := int64(len(array))
-			if index >= size {
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
 				errMsg := fmt.Sprintf("in %s.%s, D/FASTORE: array size is %d but array index is %d",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, size, index)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 
 			array[index] = value
 
 		case opcodes.AASTORE: // 0x53   (store a reference in a reference array)
-			value := pop(f).(*object.Object)    // reference we're inserting
+			value, _ := pop(f).(*object.Object) // reference we're inserting (can be null)
 			index := pop(f).(int64)             // index into the array
 			arrayRef := pop(f).(*object.Object) // ptr to the array object
 
@@ -870,12 +1012,30 @@ frameInterpreter:
 			// get pointer to the actual array
 			rawArray := rawArrayObj.Fvalue.([]*object.Object)
 			size := int64(len(rawArray))
-			if index >= size {
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
 				errMsg := fmt.Sprintf("in %s.%s, AASTORE: array size is %d but array index is %d",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, size, index)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
+				}
+				return errors.New(errMsg) // applies only if in test
+			}
+
+			// null can be stored in any array of references. Otherwise, the object's class
+			// must be assignable to the array's component type.
+			if !object.IsNull(value) && value.KlassName != types.InvalidStringIndex {
+				componentType := strings.TrimSuffix(strings.TrimPrefix(rawArrayObj.Ftype, types.RefArray), ";")
+				valueType := object.GoStringFromStringPoolIndex(value.KlassName)
+				if componentType != "" && valueType != "" && !isAssignableTo(valueType, componentType) {
+					glob.ErrorGoStack = string(debug.Stack())
+					errMsg := fmt.Sprintf("in %s.%s, AASTORE: %s cannot be stored in an array of %s",
+						util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName,
+						util.ConvertInternalClassNameToUserFormat(valueType),
+						util.ConvertInternalClassNameToUserFormat(componentType))
+					if throwFromBytecode(fs, f, excNames.ArrayStoreException, errMsg) == exceptions.Caught {
+						goto frameInterpreter
+					}
 					return errors.New(errMsg) // applies only if in test
 				}
 			}
@@ -909,14 +1069,14 @@ frameInterpreter:
 
 			rawArray := o.Fvalue.([]byte)
 			size := int64(len(rawArray))
-			if index >= size {
+			if index < 0 || index >= size {
 				glob.ErrorGoStack = string(debug.Stack())
 				errMsg := fmt.Sprintf("in %s.%s, BASTORE: array size is %d but array index is %d",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, size, index)
-				status := exceptions.ThrowEx(excNames.ArrayIndexOutOfBoundsException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArrayIndexOutOfBoundsException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 			rawArray[index] = value
 
@@ -994,7 +1154,7 @@ frameInterpreter:
 			i2 := pop(f).(int64)
 			i1 := pop(f).(int64)
 			sum := add(i1, i2)
-			push(f, sum)
+			push(f, int64(int32(sum))) // ints wrap at 32 bits, although they're held in int64s
 		case opcodes.LADD: //  0x61     (add top 2 longs on operand stack, push result)
 			l2 := pop(f).(int64) //    longs occupy two slots, hence double pushes and pops
 			pop(f)
@@ -1019,7 +1179,7 @@ frameInterpreter:
 			i2 := pop(f).(int64)
 			i1 := pop(f).(int64)
 			diff := subtract(i1, i2)
-			push(f, diff)
+			push(f, int64(int32(diff)))
 		case opcodes.LSUB: //  0x65 (subtract top 2 longs on operand stack, push result)
 			i2 := pop(f).(int64) //    longs occupy two slots, hence double pushes and pops
 			pop(f)
@@ -1045,7 +1205,7 @@ frameInterpreter:
 			i2 := pop(f).(int64)
 			i1 := pop(f).(int64)
 			product := multiply(i1, i2)
-			push(f, product)
+			push(f, int64(int32(product)))
 		case opcodes.LMUL: //  0x69     (multiply 2 longs on operand stack, push result)
 			l2 := pop(f).(int64) //    longs occupy two slots, hence double pushes and pops
 			pop(f)
@@ -1077,15 +1237,12 @@ frameInterpreter:
 				}
 				errMsg := fmt.Sprintf("in %s.%s %s",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, errInfo)
-				status := exceptions.ThrowEx(excNames.ArithmeticException, errMsg, f)
-				if status == exceptions.Caught {
-					// f.PC += 1
-					goto frameInterpreter // execute the frame with the exception
-				} else {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArithmeticException, errMsg) == exceptions.Caught {
+					goto frameInterpreter // execute the catch block
 				}
+				return errors.New(errMsg) // applies only if in test
 			} else {
-				push(f, val2/val1)
+				push(f, int64(int32(val2/val1))) // MIN_VALUE / -1 overflows to MIN_VALUE
 			}
 		case opcodes.LDIV: //  0x6D   (long divide tos-2 by tos)
 			val1 := pop(f).(int64)
@@ -1100,10 +1257,10 @@ frameInterpreter:
 				}
 				errMsg := fmt.Sprintf("in %s.%s %s",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, errInfo)
-				status := exceptions.ThrowEx(excNames.ArithmeticException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArithmeticException, errMsg) == exceptions.Caught {
+					goto frameInterpreter // execute the catch block
 				}
+				return errors.New(errMsg) // applies only if in test
 			} else {
 				res := val2 / val1
 				push(f, res)
@@ -1154,10 +1311,10 @@ frameInterpreter:
 				}
 				errMsg := fmt.Sprintf("in %s.%s %s",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, errInfo)
-				status := exceptions.ThrowEx(excNames.ArithmeticException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArithmeticException, errMsg) == exceptions.Caught {
+					goto frameInterpreter // execute the catch block
 				}
+				return errors.New(errMsg) // applies only if in test
 			} else {
 				res := val1 % val2
 				push(f, res)
@@ -1170,10 +1327,10 @@ frameInterpreter:
 				errInfo := "LREM: Arithmetic Exception: divide by zero"
 				errMsg := fmt.Sprintf("in %s.%s %s",
 					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName, errInfo)
-				status := exceptions.ThrowEx(excNames.ArithmeticException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.ArithmeticException, errMsg) == exceptions.Caught {
+					goto frameInterpreter // execute the catch block
 				}
+				return errors.New(errMsg) // applies only if in test
 			} else {
 				val1 := pop(f).(int64)
 				pop(f)
@@ -1195,7 +1352,7 @@ frameInterpreter:
 			push(f, drem)
 		case opcodes.INEG: //	0x74 	(negate an int)
 			val := pop(f).(int64)
-			push(f, -val)
+			push(f, int64(int32(-val))) // -MIN_VALUE overflows to MIN_VALUE
 		case opcodes.LNEG: //   0x75	(negate a long)
 			val := pop(f).(int64)
 			pop(f) // pop a second time because it's a long, which occupies 2 slots
@@ -1213,13 +1370,7 @@ frameInterpreter:
 		case opcodes.ISHL: //	0x78 	(shift int left)
 			shiftBy := pop(f).(int64)
 			val1 := pop(f).(int64)
-			var val2 int64
-			if val1 < 0 { // if neg, shift as pos, then make neg
-				val2 = (-val1) << (shiftBy & 0x1F) // only the bottom five bits are used
-				push(f, -val2)
-			} else {
-				push(f, val1<<(shiftBy&0x1F))
-			}
+			push(f, int64(int32(val1)<<(shiftBy&0x1F))) // only the bottom five bits are used
 		case opcodes.LSHL: // 	0x79	(shift value1 (long) left by value2 (int) bits)
 			shiftBy := pop(f).(int64)
 			ushiftBy := uint64(shiftBy) & 0x3f // must be unsigned in golang; 0-63 bits per JVM
@@ -1231,15 +1382,8 @@ frameInterpreter:
 		case opcodes.ISHR: //  0x7A	(shift int value right)
 			shiftBy := pop(f).(int64)
 			val1 := pop(f).(int64)
-			var val2 int64
-			if val1 < 0 { // if neg, shift as pos, then make neg
-				val2 = (-val1) >> (shiftBy & 0x1F) // only the bottom five bits are used
-				push(f, -val2)
-			} else {
-				push(f, val1>>(shiftBy&0x1F))
-			}
-		case opcodes.LSHR, // 	0x7B	(shift value1 (long) right by value2 (int) bits)
-			opcodes.LUSHR: // 	0x70
+			push(f, int64(int32(val1)>>(shiftBy&0x1F))) // arithmetic shift; only the bottom five bits are used
+		case opcodes.LSHR: // 	0x7B	(shift value1 (long) right by value2 (int) bits)
 			shiftBy := pop(f).(int64)
 			ushiftBy := uint64(shiftBy) & 0x3f // must be unsigned in golang; 0-63 bits per JVM
 			val1 := pop(f).(int64)
@@ -1247,13 +1391,18 @@ frameInterpreter:
 			val3 := val1 >> ushiftBy
 			push(f, val3)
 			push(f, val3)
+		case opcodes.LUSHR: // 	0x7D	(unsigned shift of value1 (long) right by value2 (int) bits)
+			shiftBy := pop(f).(int64)
+			ushiftBy := uint64(shiftBy) & 0x3f // must be unsigned in golang; 0-63 bits per JVM
+			val1 := pop(f).(int64)
+			pop(f)
+			val3 := int64(uint64(val1) >> ushiftBy) // zeros are shifted in, whatever the sign
+			push(f, val3)
+			push(f, val3)
 		case opcodes.IUSHR: // 0x7C (unsigned shift right of int)
-			shiftBy := pop(f).(int64) // TODO: verify the result against JDK
+			shiftBy := pop(f).(int64)
 			val1 := pop(f).(int64)
-			if val1 < 0 {
-				val1 = -val1
-			}
-			push(f, val1>>(shiftBy&0x1F)) // only the bottom five bits are used
+			push(f, int64(int32(uint32(val1)>>(shiftBy&0x1F)))) // only the bottom five bits are used
 		case opcodes.IAND: //	0x7E	(logical and of two ints, push result)
 			val1 := pop(f).(int64)
 			val2 := pop(f).(int64)
@@ -1296,7 +1445,7 @@ frameInterpreter:
 			if wideInEffect { // if wide is in effect, index  and increment are two bytes wide, otherwise one byte each
 				index = (int(f.Meth[f.PC+1]) * 256) + int(f.Meth[f.PC+2])
 				f.PC += 2
-				increment = int64(f.Meth[f.PC+1])*256 + int64(f.Meth[f.PC+2])
+				increment = int64(int16(uint16(f.Meth[f.PC+1])<<8 | uint16(f.Meth[f.PC+2]))) // signed
 				f.PC += 2
 				wideInEffect = false
 			} else {
@@ -1304,8 +1453,9 @@ frameInterpreter:
 				increment = byteToInt64(f.Meth[f.PC+2])
 				f.PC += 2
 			}
+			// locals hold 32-bit ints in int64s, so the sum wraps as an int does
 			orig := f.Locals[index].(int64)
-			f.Locals[index] = orig + increment
+			f.Locals[index] = int64(int32(orig + increment))
 
 		case opcodes.I2F: //	0x86 	( convert int to float)
 			intVal := pop(f).(int64)
@@ -1639,9 +1789,9 @@ frameInterpreter:
 			}
 			f.PC += paddingBytes
 
-			// get the jump size for the default branch
-			defaultJump := int64(binary.BigEndian.Uint32(
-				[]byte{f.Meth[f.PC+1], f.Meth[f.PC+2], f.Meth[f.PC+3], f.Meth[f.PC+4]}))
+			// get the jump size for the default branch, which, like the other offsets, is
+			// signed, as the default can branch backward
+			defaultJump := fourBytesToInt64(f.Meth[f.PC+1], f.Meth[f.PC+2], f.Meth[f.PC+3], f.Meth[f.PC+4])
 			f.PC += 4
 
 			// how many branches in this switch (other than default)
@@ -1649,22 +1799,23 @@ frameInterpreter:
 				[]byte{f.Meth[f.PC+1], f.Meth[f.PC+2], f.Meth[f.PC+3], f.Meth[f.PC+4]})
 			f.PC += 4
 
-			jumpTable := make(map[int64]int)
-			for i := 0; i < int(npairs); i++ {
-				// get the jump size for each case branch
-				caseValue := fourBytesToInt64(
-					f.Meth[f.PC+1], f.Meth[f.PC+2], f.Meth[f.PC+3], f.Meth[f.PC+4])
-				f.PC += 4
-				jumpOffset := fourBytesToInt64(f.Meth[f.PC+1], f.Meth[f.PC+2], f.Meth[f.PC+3], f.Meth[f.PC+4])
-				f.PC += 4
-				jumpTable[caseValue] = int(jumpOffset)
+			// the match-offset pairs are sorted by their match value, so the key is found by
+			// a binary search, as the JVM spec allows. Each pair is 8 bytes: the match value,
+			// then the jump offset. pairsPC points to the byte before the first pair.
+			pairsPC := f.PC
+			pairValue := func(i int) int64 {
+				at := pairsPC + 1 + i*8
+				return fourBytesToInt64(f.Meth[at], f.Meth[at+1], f.Meth[at+2], f.Meth[at+3])
 			}
+			f.PC += int(npairs) * 8
 
 			// now get the value we're switching on and find the distance to jump
 			key := pop(f).(int64)
-			jumpDistance, present := jumpTable[key]
-			if present {
-				f.PC = basePC + jumpDistance - 1
+			i := sort.Search(int(npairs), func(i int) bool { return pairValue(i) >= key })
+			if i < int(npairs) && pairValue(i) == key {
+				at := pairsPC + 5 + i*8
+				jumpDistance := fourBytesToInt64(f.Meth[at], f.Meth[at+1], f.Meth[at+2], f.Meth[at+3])
+				f.PC = basePC + int(jumpDistance) - 1
 			} else {
 				f.PC = basePC + int(defaultJump) - 1
 			}
@@ -1680,7 +1831,7 @@ frameInterpreter:
 			push(f, valToReturn) // pushed twice b/c a long uses two slots
 			push(f, valToReturn)
 			return nil
-		case opcodes.FRETURN: // 0xAE
+		case opcodes.FRETURN: // 0xAE (return a float, which uses one slot, and exit current frame)
 			valToReturn := pop(f).(float64)
 			f = fs.Front().Next().Value.(*frames.Frame)
 			push(f, valToReturn)
@@ -1688,15 +1839,15 @@ frameInterpreter:
 		case opcodes.DRETURN: // 0xAF (return a double and exit current frame)
 			valToReturn := pop(f).(float64)
 			f = fs.Front().Next().Value.(*frames.Frame)
-			push(f, valToReturn) // pushed twice b/c a float uses two slots
+			push(f, valToReturn) // pushed twice b/c a double uses two slots
 			push(f, valToReturn)
 			return nil
 		case opcodes.ARETURN: // 0xB0	(return a reference)
+			// the reference, which can be null, is pushed as is, so the caller gets the
+			// same object. As with the other returns, runThread() pops this frame.
 			valToReturn := pop(f)
-			// prevFrame := f
 			f = fs.Front().Next().Value.(*frames.Frame)
 			push(f, valToReturn)
-			// fs.PushFront(prevFrame) //
 			return nil
 		case opcodes.RETURN: // 0xB1    (return from void function)
 			f.TOS = -1 // empty the stack
@@ -1715,32 +1866,26 @@ frameInterpreter:
 				return errors.New(errMsg)
 			}
 
-			// get the field entry
-			field := CP.FieldRefs[CPentry.Slot]
-
-			// get the class entry from the field entry for this field. It's the class name.
-			classRef := field.ClassIndex
-			classNameIndex := CP.ClassRefs[CP.CpIndex[classRef].Slot]
-			classNamePtr := stringPool.GetStringPointer(uint32(classNameIndex))
-			className := *classNamePtr
-
-			// process the name and type entry for this field
-			nAndTindex := field.NameAndType
-			nAndTentry := CP.CpIndex[nAndTindex]
-			nAndTslot := nAndTentry.Slot
-			nAndT := CP.NameAndTypes[nAndTslot]
-			fieldNameIndex := nAndT.NameIndex
-			fieldName := classloader.FetchUTF8stringFromCPEntryNumber(CP, fieldNameIndex)
-			fieldName = className + "." + fieldName
+			// get the class and the field name the field entry resolves to
+			fieldRef, ok := classloader.ResolveFieldRef(CP, CPslot)
+			if !ok {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("GETSTATIC: CP entry %d in %s.%s is not a valid field reference",
+					CPslot, f.ClName, f.MethName)
+				_ = log.Log(errMsg, log.SEVERE)
+				return errors.New(errMsg)
+			}
+			className := fieldRef.ClassName
+			fieldName := className + "." + fieldRef.FieldName
 
 			// was this static field previously loaded? Is so, get its location and move on.
-			prevLoaded, ok := statics.Statics[fieldName]
+			prevLoaded, ok := statics.QueryStatic(fieldName)
 			if !ok { // if field is not already loaded, then
 				// the class has not been instantiated, so
 				// instantiate the class
 				_, err := InstantiateClass(className, fs)
 				if err == nil {
-					prevLoaded, ok = statics.Statics[fieldName]
+					prevLoaded, ok = statics.QueryStatic(fieldName)
 				} else {
 					glob.ErrorGoStack = string(debug.Stack())
 					errMsg := fmt.Sprintf("GETSTATIC: could not load class %s", className)
@@ -1799,32 +1944,26 @@ frameInterpreter:
 				return errors.New(errMsg)
 			}
 
-			// get the field entry
-			field := CP.FieldRefs[CPentry.Slot]
-
-			// get the class entry from the field entry for this field. It's the class name.
-			classRef := field.ClassIndex
-			classNameIndex := CP.ClassRefs[CP.CpIndex[classRef].Slot]
-			classNamePtr := stringPool.GetStringPointer(uint32(classNameIndex))
-			className := *classNamePtr
-
-			// process the name and type entry for this field
-			nAndTindex := field.NameAndType
-			nAndTentry := CP.CpIndex[nAndTindex]
-			nAndTslot := nAndTentry.Slot
-			nAndT := CP.NameAndTypes[nAndTslot]
-			fieldNameIndex := nAndT.NameIndex
-			fieldName := classloader.FetchUTF8stringFromCPEntryNumber(CP, fieldNameIndex)
-			fieldName = className + "." + fieldName
+			// get the class and the field name the field entry resolves to
+			fieldRef, ok := classloader.ResolveFieldRef(CP, CPslot)
+			if !ok {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("PUTSTATIC: CP entry %d in %s.%s is not a valid field reference",
+					CPslot, f.ClName, f.MethName)
+				_ = log.Log(errMsg, log.SEVERE)
+				return errors.New(errMsg)
+			}
+			className := fieldRef.ClassName
+			fieldName := className + "." + fieldRef.FieldName
 
 			// was this static field previously loaded? Is so, get its location and move on.
-			prevLoaded, ok := statics.Statics[fieldName]
+			prevLoaded, ok := statics.QueryStatic(fieldName)
 			if !ok { // if field is not already loaded, then
 				// the class has not been instantiated, so
 				// instantiate the class
 				_, err := InstantiateClass(className, fs)
 				if err == nil {
-					prevLoaded, ok = statics.Statics[fieldName]
+					prevLoaded, ok = statics.QueryStatic(fieldName)
 				} else {
 					glob.ErrorGoStack = string(debug.Stack())
 					errMsg := fmt.Sprintf("PUTSTATIC: could not load class %s", className)
@@ -1849,16 +1988,16 @@ frameInterpreter:
 				// be stored as a boolean, a byte (in an array), or int64
 				// We want all forms normalized to int64
 				value = pop(f).(int64) & 0x01
-				statics.Statics[fieldName] = statics.Static{
+				_ = statics.AddStatic(fieldName, statics.Static{
 					Type:  prevLoaded.Type,
 					Value: value,
-				}
+				})
 			case types.Char, types.Short, types.Int, types.Long:
 				value = pop(f).(int64)
-				statics.Statics[fieldName] = statics.Static{
+				_ = statics.AddStatic(fieldName, statics.Static{
 					Type:  prevLoaded.Type,
 					Value: value,
-				}
+				})
 			case types.Byte:
 				var val byte
 				v := pop(f)
@@ -1869,16 +2008,16 @@ frameInterpreter:
 				case byte:
 					val = v.(byte)
 				}
-				statics.Statics[fieldName] = statics.Static{
+				_ = statics.AddStatic(fieldName, statics.Static{
 					Type:  prevLoaded.Type,
 					Value: val,
-				}
+				})
 			case types.Float, types.Double:
 				value = pop(f).(float64)
-				statics.Statics[fieldName] = statics.Static{
+				_ = statics.AddStatic(fieldName, statics.Static{
 					Type:  prevLoaded.Type,
 					Value: value,
-				}
+				})
 
 			default:
 				// if it's not a primitive or a pointer to a class,
@@ -1887,10 +2026,10 @@ frameInterpreter:
 				value = pop(f)
 				switch value.(type) {
 				case *object.Object:
-					statics.Statics[fieldName] = statics.Static{
+					_ = statics.AddStatic(fieldName, statics.Static{
 						Type:  prevLoaded.Type,
 						Value: value,
-					}
+					})
 				case *classloader.Klass:
 					// convert to an *object.Object
 					kPtr := value.(*classloader.Klass)
@@ -1903,10 +2042,10 @@ frameInterpreter:
 
 					obj.FieldTable[fieldName] = objField
 
-					statics.Statics[fieldName] = statics.Static{
+					_ = statics.AddStatic(fieldName, statics.Static{
 						Type:  objField.Ftype,
 						Value: value,
-					}
+					})
 				default:
 					glob.ErrorGoStack = string(debug.Stack())
 					errMsg := fmt.Sprintf("PUTSTATIC: field %s, type unrecognized: %v", fieldName, value)
@@ -1936,13 +2075,15 @@ frameInterpreter:
 			}
 
 			// Get field name.
-			fullFieldEntry := CP.FieldRefs[fieldEntry.Slot]
-			nameAndTypeCPIndex := fullFieldEntry.NameAndType
-			nameAndTypeIndex := CP.CpIndex[nameAndTypeCPIndex]
-			nameAndType := CP.NameAndTypes[nameAndTypeIndex.Slot]
-			nameCPIndex := nameAndType.NameIndex
-			nameCPentry := CP.CpIndex[nameCPIndex]
-			fieldName := CP.Utf8Refs[nameCPentry.Slot]
+			fieldRef, ok := classloader.ResolveFieldRef(CP, CPslot)
+			if !ok {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("GETFIELD: CP entry %d in %s.%s is not a valid field reference",
+					CPslot, f.ClName, f.MethName)
+				_ = log.Log(errMsg, log.SEVERE)
+				return errors.New(errMsg)
+			}
+			fieldName := fieldRef.FieldName
 			if MainThread.Trace {
 				traceInfo := fmt.Sprintf("GETFIELD: fieldName = %s", fieldName)
 				_ = log.Log(traceInfo, log.TRACE_INST)
@@ -2048,13 +2189,15 @@ frameInterpreter:
 
 			// otherwise look up the field name in the CP and find it in the FieldTable, then do the update
 			if len(obj.FieldTable) != 0 {
-				fullFieldEntry := CP.FieldRefs[fieldEntry.Slot]
-				nameAndTypeCPIndex := fullFieldEntry.NameAndType
-				nameAndTypeIndex := CP.CpIndex[nameAndTypeCPIndex]
-				nameAndType := CP.NameAndTypes[nameAndTypeIndex.Slot]
-				nameCPIndex := nameAndType.NameIndex
-				nameCPentry := CP.CpIndex[nameCPIndex]
-				fieldName := CP.Utf8Refs[nameCPentry.Slot]
+				fieldRef, ok := classloader.ResolveFieldRef(CP, CPslot)
+				if !ok {
+					glob.ErrorGoStack = string(debug.Stack())
+					errMsg := fmt.Sprintf("PUTFIELD: CP entry %d in %s.%s is not a valid field reference",
+						CPslot, f.ClName, f.MethName)
+					_ = log.Log(errMsg, log.SEVERE)
+					return errors.New(errMsg)
+				}
+				fieldName := fieldRef.FieldName
 
 				objField, ok := obj.FieldTable[fieldName]
 				if !ok {
@@ -2097,26 +2240,22 @@ frameInterpreter:
 				}
 			}
 
-			// get the methodRef entry
-			method := CP.MethodRefs[CPentry.Slot]
-
-			// get the class entry from this method
-			classRef := method.ClassIndex
-			classNameIndex := CP.ClassRefs[CP.CpIndex[classRef].Slot]
-			classNamePtr := stringPool.GetStringPointer(classNameIndex)
-			className := *classNamePtr
-
-			// get the method name for this method
-			nAndTindex := method.NameAndType
-			nAndTentry := CP.CpIndex[nAndTindex]
-			nAndTslot := nAndTentry.Slot
-			nAndT := CP.NameAndTypes[nAndTslot]
-			methodNameIndex := nAndT.NameIndex
-			methodName := classloader.FetchUTF8stringFromCPEntryNumber(CP, methodNameIndex)
-
-			// get the signature for this method
-			methodSigIndex := nAndT.DescIndex
-			methodType := classloader.FetchUTF8stringFromCPEntryNumber(CP, methodSigIndex)
+			// get the class, method name, and signature the methodRef entry resolves to
+			methodRef, ok := classloader.ResolveMethodRef(CP, CPslot)
+			if !ok {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("INVOKEVIRTUAL: CP entry %d in %s.%s is not a valid method reference",
+					CPslot, f.ClName, f.MethName)
+				_ = log.Log(errMsg, log.SEVERE)
+				return errors.New(errMsg)
+			}
+			className := methodRef.ClassName
+			if strings.HasPrefix(className, types.Array) {
+				// an array type has the methods of Object, such as clone(), so look them up there
+				className = types.ObjectClassName
+			}
+			methodName := methodRef.MethodName
+			methodType := methodRef.MethodType
 
 			if native.IsUnsupportedNativeMethod(className + "." + methodName) {
 				errMsg := fmt.Sprintf("%s() in %s is an unsupported native function",
@@ -2130,6 +2269,12 @@ frameInterpreter:
 			mtEntry := classloader.MTable[className+"."+methodName+methodType]
 			if mtEntry.Meth == nil { // if the method is not in the method table, find it
 				mtEntry, err = classloader.FetchMethodAndCP(className, methodName, methodType)
+				if errors.Is(err, classloader.ErrClassNotFound) {
+					if throwFromBytecode(fs, f, excNames.NoClassDefFoundError, className) == exceptions.Caught {
+						goto frameInterpreter
+					}
+					return err // applies only if in test
+				}
 				if err != nil || mtEntry.Meth == nil {
 					// TODO: search the superclasses, then the classpath and retry
 					glob.ErrorGoStack = string(debug.Stack())
@@ -2161,13 +2306,13 @@ frameInterpreter:
 				// if err != nil {
 				if ret != nil {
 					switch ret.(type) {
-					case error: // only occurs in testing
-						if glob.JacobinName == "test" {
+					case error:
+						if errors.Is(ret.(error), CaughtGfunctionException) {
+							goto frameInterpreter // the handler's PC has already been set
+						} else if glob.JacobinName == "test" || invokedThreadException(f.Thread) != nil {
+							// only occurs in testing, or if the exception is passed to InvokeJavaMethod's caller
 							errRet := ret.(error)
 							return errRet
-						} else if errors.Is(ret.(error), CaughtGfunctionException) {
-							f.PC += 1
-							goto frameInterpreter
 						}
 					default: // if it's not an error, then it's a legitimate return value, which we simply push
 						push(f, ret)
@@ -2189,6 +2334,13 @@ frameInterpreter:
 					_ = log.Log(errMsg, log.SEVERE)
 					return errors.New(errMsg)
 				}
+				if fs.Len() >= maxFrameStackDepth() { // too deep a call, such as from runaway recursion
+					errMsg := "INVOKEVIRTUAL: frame stack overflow calling " + className + "." + methodName + methodType
+					if throwFromBytecode(fs, f, excNames.StackOverflowError, errMsg) == exceptions.Caught {
+						goto frameInterpreter
+					}
+					return errors.New(errMsg) // applies only if in test
+				}
 				fram, err := createAndInitNewFrame(
 					className, methodName, methodType, &m, true, f)
 				if err != nil {
@@ -2208,7 +2360,15 @@ frameInterpreter:
 			CPslot := (int(f.Meth[f.PC+1]) * 256) + int(f.Meth[f.PC+2]) // next 2 bytes point to CP entry
 			f.PC += 2
 			CP := f.CP.(*classloader.CPool)
-			className, methodName, methodType := classloader.GetMethInfoFromCPmethref(CP, CPslot)
+			methodRef, ok := classloader.ResolveMethodRef(CP, CPslot)
+			if !ok {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("INVOKESPECIAL: CP entry %d in %s.%s is not a valid method reference",
+					CPslot, f.ClName, f.MethName)
+				_ = log.Log(errMsg, log.SEVERE)
+				return errors.New(errMsg)
+			}
+			className, methodName, methodType := methodRef.ClassName, methodRef.MethodName, methodRef.MethodType
 
 			// if it's a call to java/lang/Object."<init>":()V, which happens frequently,
 			// that function simply returns. So test for it here and if it is, skip the rest
@@ -2218,6 +2378,12 @@ frameInterpreter:
 			}
 
 			mtEntry, err := classloader.FetchMethodAndCP(className, methodName, methodType)
+			if errors.Is(err, classloader.ErrClassNotFound) {
+				if throwFromBytecode(fs, f, excNames.NoClassDefFoundError, className) == exceptions.Caught {
+					goto frameInterpreter
+				}
+				return err // applies only if in test
+			}
 			if err != nil || mtEntry.Meth == nil {
 				// TODO: search the classpath and retry
 				glob.ErrorGoStack = string(debug.Stack())
@@ -2244,12 +2410,12 @@ frameInterpreter:
 				if ret != nil {
 					switch ret.(type) {
 					case error:
-						if glob.JacobinName == "test" {
+						if errors.Is(ret.(error), CaughtGfunctionException) {
+							goto frameInterpreter // the handler's PC has already been set
+						} else if glob.JacobinName == "test" || invokedThreadException(f.Thread) != nil {
+							// only occurs in testing, or if the exception is passed to InvokeJavaMethod's caller
 							errRet := ret.(error)
 							return errRet
-						} else if errors.Is(ret.(error), CaughtGfunctionException) {
-							f.PC += 1
-							goto frameInterpreter
 						}
 					default: // if it's not an error, then it's a legitimate return value, which we simply push
 						push(f, ret)
@@ -2269,6 +2435,13 @@ frameInterpreter:
 					_ = log.Log(errMsg, log.SEVERE)
 					return errors.New(errMsg)
 				}
+				if fs.Len() >= maxFrameStackDepth() { // too deep a call, such as from runaway recursion
+					errMsg := "INVOKESPECIAL: frame stack overflow calling " + className + "." + methodName + methodType
+					if throwFromBytecode(fs, f, excNames.StackOverflowError, errMsg) == exceptions.Caught {
+						goto frameInterpreter
+					}
+					return errors.New(errMsg) // applies only if in test
+				}
 				fram, err := createAndInitNewFrame(className, methodName, methodType, &m, true, f)
 				if err != nil {
 					glob.ErrorGoStack = string(debug.Stack())
@@ -2292,38 +2465,40 @@ frameInterpreter:
 			CPslot := (int(f.Meth[f.PC+1]) * 256) + int(f.Meth[f.PC+2]) // next 2 bytes point to CP entry
 			// f.PC += 2
 			CP := f.CP.(*classloader.CPool)
-			CPentry := CP.CpIndex[CPslot]
-			// get the methodRef entry
-			method := CP.MethodRefs[CPentry.Slot]
-
-			// get the class entry from this method
-			classRef := method.ClassIndex
-			classNameIndex := CP.ClassRefs[CP.CpIndex[classRef].Slot]
-			classNamePtr := stringPool.GetStringPointer(uint32(classNameIndex))
-			className := *classNamePtr
-
-			// get the method name for this method
-			nAndTindex := method.NameAndType
-			nAndTentry := CP.CpIndex[nAndTindex]
-			nAndTslot := nAndTentry.Slot
-			nAndT := CP.NameAndTypes[nAndTslot]
-			methodNameIndex := nAndT.NameIndex
-			methodName := classloader.FetchUTF8stringFromCPEntryNumber(CP, methodNameIndex)
-
-			// get the signature for this method
-			methodSigIndex := nAndT.DescIndex
-			methodType := classloader.FetchUTF8stringFromCPEntryNumber(
-				CP, methodSigIndex)
-
-			mtEntry, err := classloader.FetchMethodAndCP(className, methodName, methodType)
-			if err != nil || mtEntry.Meth == nil {
-				// TODO: search the classpath and retry
+			// get the class, method name, and signature the methodRef entry resolves to and,
+			// if this call has been made before, the method itself
+			methodRef, ok := classloader.ResolveMethodRef(CP, CPslot)
+			if !ok {
 				glob.ErrorGoStack = string(debug.Stack())
-				errMsg := "INVOKESTATIC: Class method not found: " + className + "." + methodName + methodType
-				status := exceptions.ThrowEx(excNames.ClassNotLoadedException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				errMsg := fmt.Sprintf("INVOKESTATIC: CP entry %d in %s.%s is not a valid method reference",
+					CPslot, f.ClName, f.MethName)
+				_ = log.Log(errMsg, log.SEVERE)
+				return errors.New(errMsg)
+			}
+			className := methodRef.ClassName
+			methodName := methodRef.MethodName
+			methodType := methodRef.MethodType
+
+			mtEntry := methodRef.Method
+			var err error
+			if mtEntry.Meth == nil {
+				mtEntry, err = classloader.FetchMethodAndCP(className, methodName, methodType)
+				if errors.Is(err, classloader.ErrClassNotFound) {
+					if throwFromBytecode(fs, f, excNames.NoClassDefFoundError, className) == exceptions.Caught {
+						goto frameInterpreter
+					}
+					return err // applies only if in test
+				}
+				if err != nil || mtEntry.Meth == nil {
+					// TODO: search the classpath and retry
+					glob.ErrorGoStack = string(debug.Stack())
+					errMsg := "INVOKESTATIC: Class method not found: " + className + "." + methodName + methodType
+					status := exceptions.ThrowEx(excNames.ClassNotLoadedException, errMsg, f)
+					if status != exceptions.Caught {
+						return errors.New(errMsg) // applies only if in test
+					}
 				}
+				classloader.CacheResolvedMethod(CP, CPslot, mtEntry)
 			}
 
 			// before we can run the method, we need to either instantiate the class and/or
@@ -2359,12 +2534,12 @@ frameInterpreter:
 				if ret != nil {
 					switch ret.(type) {
 					case error:
-						if glob.JacobinName == "test" {
+						if errors.Is(ret.(error), CaughtGfunctionException) {
+							goto frameInterpreter // the handler's PC has already been set
+						} else if glob.JacobinName == "test" || invokedThreadException(f.Thread) != nil {
+							// only occurs in testing, or if the exception is passed to InvokeJavaMethod's caller
 							errRet := ret.(error)
 							return errRet
-						} else if errors.Is(ret.(error), CaughtGfunctionException) {
-							f.PC += 1
-							goto frameInterpreter
 						}
 					default: // if it's not an error, then it's a legitimate return value, which we simply push
 						push(f, ret)
@@ -2383,6 +2558,13 @@ frameInterpreter:
 					_ = log.Log(errMsg, log.SEVERE)
 					return errors.New(errMsg)
 				}
+				if fs.Len() >= maxFrameStackDepth() { // too deep a call, such as from runaway recursion
+					errMsg := "INVOKESTATIC: frame stack overflow calling " + className + "." + methodName + methodType
+					if throwFromBytecode(fs, f, excNames.StackOverflowError, errMsg) == exceptions.Caught {
+						goto frameInterpreter
+					}
+					return errors.New(errMsg) // applies only if in test
+				}
 				fram, err := createAndInitNewFrame(
 					className, methodName, methodType, &m, false, f)
 				if err != nil {
@@ -2451,41 +2633,30 @@ frameInterpreter:
 			interfaceMethodType := classloader.FetchUTF8stringFromCPEntryNumber(
 				CP, interfaceMethodSigIndex)
 
-			var args []any
-			for i := 0; i < int(count)-1; i++ {
-				args = append(args, pop(f))
-			}
-			// now get the objRef pointing to the class containing the call to the method described just previously
-			// The objRef object has previously been instantiated and its constructor called.
-			objRef := pop(f)
-			if objRef == nil {
+			// the objRef, the object whose method is invoked, is on the op stack below the
+			// arguments, which take count-1 slots. It's left in place for the invocation.
+			objRef := f.OpStack[f.TOS-int(count)+1]
+			if object.IsNull(objRef) {
 				errMsg := fmt.Sprintf("INVOKEINTERFACE: object whose method, %s, is invoked is null",
-					interfaceName+interfaceMethodName+interfaceMethodType)
-				status := exceptions.ThrowEx(excNames.NullPointerException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+					interfaceName+"."+interfaceMethodName+interfaceMethodType)
+				if throwFromBytecode(fs, f, excNames.NullPointerException, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 
 			// get the name of the objectRef's class, and make sure it's loaded
 			objRefClassName := *(stringPool.GetStringPointer(objRef.(*object.Object).KlassName))
-			if err := classloader.LoadClassFromNameOnly(objRefClassName); err != nil {
-				// in this case, LoadClassFromNameOnly() will have already thrown the exception
-				if globals.JacobinHome() == "test" {
+			if classloader.MethAreaFetch(objRefClassName) == nil {
+				if err := classloader.LoadClassFromNameOnly(objRefClassName); err != nil {
+					if errors.Is(err, classloader.ErrClassNotFound) &&
+						throwFromBytecode(fs, f, excNames.NoClassDefFoundError, objRefClassName) == exceptions.Caught {
+						goto frameInterpreter
+					}
 					return err // applies only if in test
 				}
 			}
 
-			class := classloader.MethAreaFetch(objRefClassName)
-			if class == nil {
-				// in theory, this can't happen due to immediately previous loading, but making sure
-				errMsg := fmt.Sprintf("INVOKEINTERFACE: class %s not found", objRefClassName)
-				status := exceptions.ThrowEx(excNames.ClassNotLoadedException, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
-				}
-			}
-
 			// Now find the interface method. Section 5.4.3.4 of the JVM spec lists the order in which
 			// the steps are taken, where C is the interface:
 			//
@@ -2510,61 +2681,88 @@ frameInterpreter:
 			//
 			// For more info: https://docs.oracle.com/javase/specs/jvms/se17/html/jvms-5.html#jvms-5.4.3.4
 
-			clData := *class.Data
-			if len(clData.Interfaces) == 0 {
+			// Here, the method that is executed is the one selected by the class of the objRef
+			// (or its nearest superclass declaring it); failing that, a default method of the
+			// interface.
+			if !implementsInterface(objRefClassName, interfaceName, make(map[string]bool)) {
 				errMsg := fmt.Sprintf("INVOKEINTERFACE: class %s does not implement interface %s",
 					objRefClassName, interfaceName)
-				status := exceptions.ThrowEx(excNames.IncompatibleClassChangeError, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+				if throwFromBytecode(fs, f, excNames.IncompatibleClassChangeError, errMsg) == exceptions.Caught {
+					goto frameInterpreter
+				}
+				return errors.New(errMsg) // applies only if in test
+			}
+
+			methodClassName := objRefClassName
+			mtEntry, err := classloader.FetchMethodAndCP(objRefClassName, interfaceMethodName, interfaceMethodType)
+			if err != nil || mtEntry.Meth == nil {
+				methodClassName = interfaceName
+				mtEntry, err = classloader.FetchMethodAndCP(interfaceName, interfaceMethodName, interfaceMethodType)
+			}
+			if err == nil && mtEntry.MType == 'J' &&
+				mtEntry.Meth.(classloader.JmEntry).AccessFlags&0x0400 > 0 { // ACC_ABSTRACT
+				err = errors.New("abstract method")
+			}
+			if err != nil || mtEntry.Meth == nil {
+				errMsg := fmt.Sprintf("INVOKEINTERFACE: class %s has no implementation of %s.%s%s",
+					objRefClassName, interfaceName, interfaceMethodName, interfaceMethodType)
+				if throwFromBytecode(fs, f, excNames.AbstractMethodError, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
 
-			var foundIntfaceName = ""
-			for i := 0; i < len(clData.Interfaces); i++ {
-				index := uint32(clData.Interfaces[i])
-				foundIntfaceName = *stringPool.GetStringPointer(index)
-				if foundIntfaceName == interfaceName {
-					if err := classloader.LoadClassFromNameOnly(interfaceName); err != nil {
-						// in this case, LoadClassFromNameOnly() will have already thrown the exception
-						if globals.JacobinHome() == "test" {
-							return err // applies only if in test
+			if mtEntry.MType == 'G' { // a native golang function: the args and objRef are its params
+				gmethData := mtEntry.Meth.(gfunction.GMeth)
+				var params []interface{}
+				for i := 0; i < gmethData.ParamSlots; i++ {
+					params = append(params, pop(f))
+				}
+				params = append(params, pop(f)) // the objRef
+
+				ret := runGfunction(mtEntry, fs, methodClassName, interfaceMethodName, interfaceMethodType, &params, true)
+				if ret != nil {
+					switch ret.(type) {
+					case error:
+						if errors.Is(ret.(error), CaughtGfunctionException) {
+							goto frameInterpreter // the handler's PC has already been set
+						} else if glob.JacobinName == "test" || invokedThreadException(f.Thread) != nil {
+							// only occurs in testing, or if the exception is passed to InvokeJavaMethod's caller
+							return ret.(error)
 						}
-					}
-					mtEntry, err := classloader.FetchMethodAndCP(
-						interfaceName, interfaceMethodName, interfaceMethodType)
-					if err != nil || mtEntry.Meth == nil {
-						glob.ErrorGoStack = string(debug.Stack())
-						errMsg := fmt.Sprintf("INVOKEINTERFACE: Interface method not found: %s.%s%s"+
-							interfaceName, interfaceMethodName, interfaceMethodType)
-						status := exceptions.ThrowEx(excNames.ClassNotLoadedException, errMsg, f)
-						if status != exceptions.Caught {
-							return errors.New(errMsg) // applies only if in test
+					default: // a legitimate return value, which we simply push
+						push(f, ret)
+						if strings.HasSuffix(interfaceMethodType, "D") || strings.HasSuffix(interfaceMethodType, "J") {
+							push(f, ret) // push twice if long or double
 						}
 					}
-					goto executeInterfaceMethod // method found, move on to execution
-				} else { // TODO: check for superclasses, after checking Object
-					foundIntfaceName = ""
 				}
+				break
 			}
 
-			if foundIntfaceName == "" { // no interface was found, check java.lang.Object()
-				errMsg := fmt.Sprintf("INVOKEINTERFACE: class %s does not implement interface %s",
-					objRefClassName, interfaceName)
-				status := exceptions.ThrowEx(excNames.IncompatibleClassChangeError, errMsg, f)
-				if status != exceptions.Caught {
-					return errors.New(errMsg) // applies only if in test
+			m := mtEntry.Meth.(classloader.JmEntry)
+			if fs.Len() >= maxFrameStackDepth() { // too deep a call, such as from runaway recursion
+				errMsg := "INVOKEINTERFACE: frame stack overflow calling " + methodClassName + "." + interfaceMethodName + interfaceMethodType
+				if throwFromBytecode(fs, f, excNames.StackOverflowError, errMsg) == exceptions.Caught {
+					goto frameInterpreter
 				}
+				return errors.New(errMsg) // applies only if in test
 			}
-
-			// CURR: get a pointer to the method area
-			// CURR: extract the parameters to the function
-			// CURR: execute the function in the G and J variants
-
-		executeInterfaceMethod:
-			// for the nonce
-			errMsg := "INVOKEINTERFACE: WIP, forcing an error, for the nonce"
-			exceptions.ThrowEx(excNames.WrongMethodTypeException, errMsg, f)
+			fram, err := createAndInitNewFrame(
+				methodClassName, interfaceMethodName, interfaceMethodType, &m, true, f)
+			if err != nil {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := "INVOKEINTERFACE: Error creating frame in: " +
+					methodClassName + "." + interfaceMethodName + interfaceMethodType
+				return errors.New(errMsg)
+			}
+			if f.ExceptionPC != -1 {
+				f.ExceptionPC = f.PC // in the event of an exception, here's where we were
+			}
+			f.PC += 1                            // move to next bytecode before exiting
+			fs.PushFront(fram)                   // push the new frame
+			f = fs.Front().Value.(*frames.Frame) // point f to the new head
+			return runFrame(fs)
 
 		case opcodes.NEW: // 0xBB 	new: create and instantiate a new object
 			CPslot := (int(f.Meth[f.PC+1]) * 256) + int(f.Meth[f.PC+2]) // next 2 bytes point to CP entry
@@ -2586,6 +2784,12 @@ frameInterpreter:
 			}
 
 			ref, err := InstantiateClass(className, fs)
+			if errors.Is(err, classloader.ErrClassNotFound) {
+				if throwFromBytecode(fs, f, excNames.NoClassDefFoundError, className) == exceptions.Caught {
+					goto frameInterpreter
+				}
+				return err // applies only if in test
+			}
 			if err != nil {
 				glob.ErrorGoStack = string(debug.Stack())
 				errMsg := fmt.Sprintf("NEW: could not load class %s", className)
@@ -2742,64 +2946,18 @@ frameInterpreter:
 			// with whether we want the standard JDK info as elected with the -strictJDK
 			// command-line option)
 			if catchFrame == nil {
-				// if the exception is not caught, then print the data from the stackTraceElements (STEs)
-				// in the Throwable object or subclass (which is generally the specific exception class).
-
-				// start by printing out the name of the exception/error and the thread it occurred on
-				msg := ""
-				if f.Thread == 1 { // if it's thread #1, use its name, "main"
-					msg = fmt.Sprintf("Exception in thread \"main\" %s", exceptionName)
-				} else {
-					msg = fmt.Sprintf("Exception in thread %d %s", f.Thread, exceptionName)
-				}
-
-				appMsg := objectRef.FieldTable["detailMessage"].Fvalue
-				if appMsg != nil {
-					switch appMsg.(type) {
-					case []uint8:
-						st := appMsg.([]uint8)
-						msg += fmt.Sprintf(": %s", string(st))
-					case *object.Object:
-						st := appMsg.(*object.Object)
-						value := st.FieldTable["value"].Fvalue
-						switch value.(type) {
-						case []byte:
-							msg += fmt.Sprintf(": %s", string(st.FieldTable["value"].Fvalue.([]byte)))
-						case uint32:
-							str := stringPool.GetStringPointer(value.(uint32))
-							msg += fmt.Sprintf(": %s", *str)
-						}
-
-					}
+				// if the method was called by InvokeJavaMethod, the exception goes back to its caller
+				if isInvokedThread(f.Thread) {
+					invokedThreads.Store(f.Thread, objectRef)
+					return errors.New("ATHROW: uncaught exception " + exceptionName)
 				}
-				_ = log.Log(msg, log.SEVERE)
 
-				steArrayPtr := objectRef.FieldTable["stackTrace"].Fvalue.(*object.Object)
-				rawSteArray := steArrayPtr.FieldTable["value"].Fvalue.([]*object.Object) // []*object.Object (each of which is an STE)
-				for i := 0; i < len(rawSteArray); i++ {
-					ste := rawSteArray[i]
-					methodName := ste.FieldTable["methodName"].Fvalue.(string)
-					if methodName == "<init>" { // don't show constructors
-						continue
-					}
-					rawClassName := ste.FieldTable["declaringClass"].Fvalue.(string)
-					if rawClassName == "java/lang/Throwable" { // don't show Throwable methods
-						continue
-					}
-					className := strings.Replace(rawClassName, "/", ".", -1)
+				// if the exception is not caught, then print the data from the stackTraceElements (STEs)
+				// in the Throwable object or subclass (which is generally the specific exception class).
 
-					sourceLine := ste.FieldTable["sourceLine"].Fvalue.(string)
-
-					var s string
-					if sourceLine != "" {
-						s = fmt.Sprintf("\tat %s.%s(%s:%s)", className,
-							methodName, ste.FieldTable["fileName"].Fvalue, sourceLine)
-					} else {
-						s = fmt.Sprintf("\tat %s.%s(%s)", className,
-							methodName, ste.FieldTable["fileName"].Fvalue)
-					}
-					_ = log.Log(s, log.SEVERE)
-				}
+				// this prints the name of the exception/error, the thread it occurred on, the
+				// stack trace, and the chain of exceptions that caused it, if any
+				showUncaughtException(f.Thread, objectRef)
 
 				// show Jacobin's JVM stack info if -strictJDK is not set
 				if glob.StrictJDK == false {
@@ -2815,20 +2973,9 @@ frameInterpreter:
 				shutdown.Exit(shutdown.APP_EXCEPTION)
 
 			} else { // perform the catch operation. We know the frame and the starting bytecode for the handler
-				for fr := fs.Front(); fr != nil; fr = fr.Next() {
-					var frm = fr.Value.(*frames.Frame)
-					// f.ExceptionTable = &m.Exceptions
-					if frm == catchFrame {
-						// frm.Meth = f.Meth[handlerBytecode:]
-						frm.TOS = -1
-						push(frm, objectRef)
-						// frm.PC = 0
-						frm.PC = handlerBytecode
-						// make the frame with the catch block active
-						fs.Front().Value = frm
-						goto frameInterpreter
-					}
-				}
+				// pop the frames above the catch frame, so the handler's frame is the active one
+				catchException(fs, f, catchFrame, handlerBytecode, objectRef)
+				goto frameInterpreter
 			}
 		case opcodes.CHECKCAST: // 0xC0 same as INSTANCEOF but throws exception on null
 			// because this uses the same logic as INSTANCEOF, any change here should
@@ -2915,7 +3062,13 @@ frameInterpreter:
 				} else { // the object being checked is a class
 					classPtr := classloader.MethAreaFetch(className)
 					if classPtr == nil { // class wasn't loaded, so load it now
-						if classloader.LoadClassFromNameOnly(className) != nil {
+						if err := classloader.LoadClassFromNameOnly(className); err != nil {
+							if errors.Is(err, classloader.ErrClassNotFound) {
+								if throwFromBytecode(fs, f, excNames.NoClassDefFoundError, className) == exceptions.Caught {
+									goto frameInterpreter
+								}
+								return err // applies only if in test
+							}
 							glob.ErrorGoStack = string(debug.Stack())
 							return errors.New("CHECKCAST: Could not load class: " + className)
 						}
@@ -2975,7 +3128,13 @@ frameInterpreter:
 						}
 						classPtr := classloader.MethAreaFetch(className)
 						if classPtr == nil { // class wasn't loaded, so load it now
-							if classloader.LoadClassFromNameOnly(className) != nil {
+							if err := classloader.LoadClassFromNameOnly(className); err != nil {
+								if errors.Is(err, classloader.ErrClassNotFound) {
+									if throwFromBytecode(fs, f, excNames.NoClassDefFoundError, className) == exceptions.Caught {
+										goto frameInterpreter
+									}
+									return err // applies only if in test
+								}
 								glob.ErrorGoStack = string(debug.Stack())
 								errMsg := "INSTANCEOF: Could not load class: " + className
 								_ = log.Log(errMsg, log.SEVERE)
@@ -2992,8 +3151,39 @@ frameInterpreter:
 				}
 			}
 
-		case opcodes.MONITORENTER, opcodes.MONITOREXIT: // OxC2 and OxC3. These  are not implemented in the JDK JVM
-			_ = pop(f) // so just pop off the reference on the stack
+		case opcodes.MONITORENTER, opcodes.MONITOREXIT: // OxC2 and OxC3 (enter and exit the monitor of a synchronized block)
+			// Entering a monitor owned by another thread waits until that thread exits it. Monitors
+			// are reentrant, and an exit by a thread that does not own the monitor is caught.
+			// TODO: enter and exit the implicit monitor of a synchronized method, which has no bytecodes.
+			opName := "MONITORENTER"
+			if opcode == opcodes.MONITOREXIT {
+				opName = "MONITOREXIT"
+			}
+			ref := pop(f)
+			if ref == nil || ref == object.Null {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("%s: null monitor in %s.%s",
+					opName, util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName)
+				if throwFromBytecode(fs, f, excNames.NullPointerException, errMsg) == exceptions.Caught {
+					goto frameInterpreter // execute the catch block
+				}
+				return errors.New(errMsg) // applies only if in test
+			}
+			obj, ok := ref.(*object.Object)
+			if !ok {
+				break // not a Java object, so there's no monitor to track
+			}
+			if opcode == opcodes.MONITORENTER {
+				object.MonitorEnter(obj, f.Thread)
+			} else if !object.MonitorExit(obj, f.Thread) {
+				glob.ErrorGoStack = string(debug.Stack())
+				errMsg := fmt.Sprintf("MONITOREXIT: monitor not entered in %s.%s",
+					util.ConvertInternalClassNameToUserFormat(f.ClName), f.MethName)
+				if throwFromBytecode(fs, f, excNames.IllegalMonitorStateException, errMsg) == exceptions.Caught {
+					goto frameInterpreter // execute the catch block
+				}
+				return errors.New(errMsg) // applies only if in test
+			}
 
 		case opcodes.WIDE: // 0xC4 Make some bytecodes operate on larger sized operands
 			// https://docs.oracle.com/javase/specs/jvms/se17/html/jvms-6.html#jvms-6.5.wide
@@ -3184,6 +3374,10 @@ func createAndInitNewFrame(
 		_ = log.Log(traceInfo, log.TRACE_INST)
 	}
 
+	if globals.GetGlobalRef().ProfileMethods {
+		countMethodCall(className, methodName, methodType)
+	}
+
 	f := currFrame
 
 	stackSize := m.MaxStack
@@ -3298,9 +3492,11 @@ func createAndInitNewFrame(
 		lenLocals = 1
 	}
 
-	// allocate the local variables
-	for k := 0; k < lenLocals; k++ {
-		fram.Locals = append(fram.Locals, int64(0))
+	// allocate the local variables all at once, with room for the objectRef's extra slot
+	// (see below), so that they're never reallocated
+	fram.Locals = slices.Grow(fram.Locals[:0], lenLocals+1)[:lenLocals]
+	for k := range fram.Locals {
+		fram.Locals[k] = int64(0)
 	}
 
 	// if includeObjectRef is true then objectRef != nil.
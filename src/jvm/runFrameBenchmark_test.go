@@ -0,0 +1,154 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"jacobin/classloader"
+	"jacobin/frames"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/opcodes"
+	"jacobin/stringPool"
+	"jacobin/thread"
+	"jacobin/types"
+	"testing"
+)
+
+// a tight loop of int arithmetic, which is dominated by the dispatch of bytecodes:
+//
+//	int sum = 0; for (int i = 0; i < 1000; i++) { sum += i; }
+var intLoopCode = []byte{
+	opcodes.ICONST_0, opcodes.ISTORE_0, // 0: sum = 0
+	opcodes.ICONST_0, opcodes.ISTORE_1, // 2: i = 0
+	opcodes.ILOAD_1,            // 4
+	opcodes.SIPUSH, 0x03, 0xE8, // 5: 1000
+	opcodes.IF_ICMPGE, 0x00, 0x0D, // 8: to 21
+	opcodes.ILOAD_0, opcodes.ILOAD_1, opcodes.IADD, opcodes.ISTORE_0, // 11: sum += i
+	opcodes.IINC, 0x01, 0x01, // 15: i++
+	opcodes.GOTO, 0xFF, 0xF2, // 18: to 4
+	opcodes.RETURN, // 21
+}
+
+// runs the int loop in a new frame and returns the frame
+func runIntLoop() *frames.Frame {
+	f := frames.CreateFrame(2)
+	f.Ftype = 'J'
+	f.Meth = intLoopCode
+	f.Locals = make([]interface{}, 2)
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+	_ = runFrame(fs)
+	return f
+}
+
+func TestRunFrameIntLoop(t *testing.T) {
+	globals.InitGlobals("test")
+	if sum := runIntLoop().Locals[0]; sum != int64(499500) {
+		t.Errorf("int loop: expected a sum of 499500, got: %v", sum)
+	}
+}
+
+func BenchmarkRunFrameIntLoop(b *testing.B) {
+	globals.InitGlobals("test")
+	for i := 0; i < b.N; i++ {
+		runIntLoop()
+	}
+}
+
+// sets up Summer.sum(), a recursive method, and returns a function that runs it, as the
+// thread's bottom frame would, and returns its result:
+//
+//	static int sum(int n) { return n == 0 ? 0 : n + sum(n - 1); }
+func setUpRecursiveSum() func(n int64) int64 {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	summerName := "Summer"
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 6)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // Summer
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&summerName)}
+	CP.Utf8Refs = []string{"sum", "(I)I"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}}
+
+	classloader.MethAreaInsert(summerName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            summerName,
+			NameIndex:       stringPool.GetStringIndex(&summerName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	sum := classloader.JmEntry{
+		MaxStack:  3,
+		MaxLocals: 1,
+		Cp:        &CP,
+		Code: []byte{
+			opcodes.ILOAD_0, opcodes.IFNE, 0x00, 0x05, // 0: if n != 0, go to 6
+			opcodes.ICONST_0, opcodes.IRETURN, // 4: return 0
+			opcodes.ILOAD_0, opcodes.ILOAD_0, opcodes.ICONST_1, opcodes.ISUB, // 6: n, n - 1
+			opcodes.INVOKESTATIC, 0x00, 0x01, // 10: sum(n - 1)
+			opcodes.IADD, opcodes.IRETURN, // 13: return n + sum(n - 1)
+		},
+	}
+	classloader.MTable["Summer.sum(I)I"] = classloader.MTentry{MType: 'J', Meth: sum}
+
+	return func(n int64) int64 {
+		th := thread.CreateThread()
+		th.Stack = frames.CreateFrameStack()
+		catcher := frames.CreateFrame(1) // receives the value sum() returns
+		f := frames.CreateFrame(sum.MaxStack + 2)
+		f.Ftype = 'J'
+		f.ClName = summerName
+		f.MethName = "sum"
+		f.MethType = "(I)I"
+		f.CP = &CP
+		f.Meth = append(f.Meth, sum.Code...)
+		f.Locals = append(f.Locals, n)
+		th.Stack.PushFront(catcher)
+		th.Stack.PushFront(f)
+		if err := runThread(&th); err != nil {
+			return -1
+		}
+		return pop(catcher).(int64)
+	}
+}
+
+// frames released when their methods return are reused by later calls without any
+// of their earlier state, so repeated runs of a recursive method get the same result
+func TestRecursionWithReusedFrames(t *testing.T) {
+	runSum := setUpRecursiveSum()
+	for i := 0; i < 3; i++ {
+		if ret := runSum(100); ret != 5050 {
+			t.Errorf("sum(100), run %d: expected 5050, got: %d", i+1, ret)
+		}
+	}
+	if ret := runSum(10); ret != 55 {
+		t.Errorf("sum(10): expected 55, got: %d", ret)
+	}
+}
+
+// the allocations per run (see -benchmem) show the effect of reusing frames
+func BenchmarkRecursiveCalls(b *testing.B) {
+	runSum := setUpRecursiveSum()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		runSum(100)
+	}
+}
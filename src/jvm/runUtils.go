@@ -7,17 +7,24 @@
 package jvm
 
 import (
+	"container/list"
 	"encoding/binary"
 	"fmt"
+	"jacobin/classloader"
 	"jacobin/excNames"
 	"jacobin/exceptions"
 	"jacobin/frames"
+	"jacobin/globals"
 	"jacobin/log"
 	"jacobin/object"
 	"jacobin/opcodes"
+	"jacobin/shutdown"
+	"jacobin/stringPool"
 	"jacobin/types"
 	"jacobin/util"
 	"math"
+	"strings"
+	"sync"
 	"unsafe"
 )
 
@@ -192,7 +199,7 @@ func emitTraceData(f *frames.Frame) string {
 		"class: " + fmt.Sprintf("%-22s", f.ClName) +
 			" meth: " + fmt.Sprintf("%-10s", f.MethName) +
 			" PC: " + fmt.Sprintf("% 3d", f.PC) +
-			", " + fmt.Sprintf("%-13s", opcodes.BytecodeNames[int(f.Meth[f.PC])]) +
+			", " + fmt.Sprintf("%-13s", opcodes.BytecodeName(f.Meth[f.PC])) +
 			" TOS: " + tos +
 			" " + stackTop +
 			" "
@@ -389,3 +396,310 @@ func push(f *frames.Frame, x interface{}) {
 	} // trace the resultant stack
 
 }
+
+// estimatedFrameSize is roughly the number of bytes HotSpot uses for the frame of a
+// simple method. The thread stack size (-Xss) is divided by it to get a frame count.
+const estimatedFrameSize = 128
+
+// maxFrameStackDepth returns the number of frames a thread's frame stack can hold. A call
+// that would push the stack past this depth throws a StackOverflowError. With the default
+// 1MB thread stack, the limit is 8192 frames.
+func maxFrameStackDepth() int {
+	depth := int(globals.GetGlobalRef().ThreadStackSize / estimatedFrameSize)
+	if depth < 1 {
+		depth = 1
+	}
+	return depth
+}
+
+// throwFromBytecode throws a Java exception for a condition detected while executing a
+// bytecode, such as division by zero, so that the application can catch it just as it
+// can an exception thrown by ATHROW. The frame stack is searched for a handler and, if one
+// is found, an exception object is created with msg as its detail message, the frames above
+// the catch frame are popped, and the catch frame is set to run the handler with the
+// exception on its op stack. The caller must then resume interpretation with the frame at
+// the head of fs. If there is no handler, the exception is reported by exceptions.ThrowEx().
+// Returns exceptions.Caught or exceptions.NotCaught.
+func throwFromBytecode(fs *list.List, f *frames.Frame, which int, msg string) bool {
+	exceptionCPname := util.ConvertClassFilenameToInternalFormat(excNames.JVMexceptionNames[which])
+
+	// capture the PC where the exception was thrown, if it hasn't been captured yet
+	if f.ExceptionPC == -1 {
+		f.ExceptionPC = f.PC
+	}
+
+	catchFrame, handlerPC := exceptions.FindCatchFrame(fs, exceptionCPname, f.ExceptionPC)
+	if catchFrame == nil && !isInvokedThread(f.Thread) {
+		return exceptions.ThrowEx(which, msg, f)
+	}
+
+	objRef, err := InstantiateClass(exceptionCPname, fs)
+	if err != nil {
+		return exceptions.ThrowEx(which, msg, f)
+	}
+	exc := objRef.(*object.Object)
+	exc.FieldTable["detailMessage"] =
+		object.Field{Ftype: "Ljava/lang/String;", Fvalue: object.StringObjectFromGoString(msg)}
+
+	glob := globals.GetGlobalRef()
+	if glob.FuncFillInStackTrace != nil { // the stack trace is taken before any frames are popped
+		glob.FuncFillInStackTrace([]any{fs, exc})
+	}
+
+	if catchFrame == nil { // the method was called by InvokeJavaMethod, which passes exc to its caller
+		invokedThreads.Store(f.Thread, exc)
+		return exceptions.NotCaught
+	}
+	catchException(fs, f, catchFrame, handlerPC, exc)
+	return exceptions.Caught
+}
+
+// rethrowException throws exc, an exception object that Java code run by a gfunction threw
+// and didn't catch (see InvokeJavaMethod()), in the frame stack fs, whose current frame is f.
+// As with ATHROW, the frame that catches it resumes at its handler or, if none does, the
+// exception is shown and the program ends. Returns exceptions.Caught if exc was caught.
+func rethrowException(fs *list.List, f *frames.Frame, exc *object.Object) bool {
+	if f.ExceptionPC == -1 {
+		f.ExceptionPC = f.PC
+	}
+
+	excName := object.GoStringFromStringPoolIndex(exc.KlassName)
+	catchFrame, handlerPC := exceptions.FindCatchFrame(fs, excName, f.ExceptionPC)
+	if catchFrame == nil {
+		if isInvokedThread(f.Thread) { // pass it on to the next caller
+			invokedThreads.Store(f.Thread, exc)
+			return exceptions.NotCaught
+		}
+		showUncaughtException(f.Thread, exc)
+		shutdown.Exit(shutdown.APP_EXCEPTION)
+		return exceptions.NotCaught // applies only if in test
+	}
+	catchException(fs, f, catchFrame, handlerPC, exc)
+	return exceptions.Caught
+}
+
+// catchException pops the frames above catchFrame, in which exc is caught, off the frame
+// stack fs, and sets up catchFrame to resume at handlerPC with exc on its op stack.
+// f is the frame in which exc was thrown.
+func catchException(fs *list.List, f, catchFrame *frames.Frame, handlerPC int, exc *object.Object) {
+	for fs.Len() > 0 && fs.Front().Value.(*frames.Frame) != catchFrame {
+		fs.Remove(fs.Front())
+	}
+	catchFrame.TOS = -1
+	push(catchFrame, exc)
+	catchFrame.PC = handlerPC
+	catchFrame.ExceptionPC = -1
+	f.ExceptionPC = -1
+}
+
+// invokedThreads holds the IDs of the temporary threads on which InvokeJavaMethod runs
+// methods. An exception that a method run this way doesn't catch doesn't end the program:
+// it's stored here as the thread's value, for InvokeJavaMethod to return to its caller.
+var invokedThreads sync.Map
+
+// isInvokedThread returns true if thread was created by InvokeJavaMethod
+func isInvokedThread(thread int) bool {
+	_, ok := invokedThreads.Load(thread)
+	return ok
+}
+
+// invokedThreadException returns the uncaught exception that ended thread, a thread
+// created by InvokeJavaMethod, or nil if there is none
+func invokedThreadException(thread int) *object.Object {
+	exc, _ := invokedThreads.Load(thread)
+	excObj, _ := exc.(*object.Object)
+	return excObj
+}
+
+// showUncaughtException logs the exception that ended a thread in the format of the JDK's
+// Throwable.printStackTrace(): the exception and its stack trace, then a "Caused by:"
+// section for each exception in its chain of causes. As in the JDK, the frames a cause
+// shares with the exception it caused are not repeated, but counted in a "... N more" line.
+// Each trace is cut off after -XX:MaxJavaStackTraceDepth lines (see TruncateStackTrace()).
+func showUncaughtException(thread int, exc *object.Object) {
+	var msg string
+	if thread == 1 { // if it's thread #1, use its name, "main"
+		msg = "Exception in thread \"main\" " + throwableString(exc)
+	} else {
+		msg = fmt.Sprintf("Exception in thread %d %s", thread, throwableString(exc))
+	}
+	_ = log.Log(msg, log.SEVERE)
+
+	trace := stackTraceLines(exc)
+	for _, line := range exceptions.TruncateStackTrace(trace) {
+		_ = log.Log(line, log.SEVERE)
+	}
+
+	seen := map[*object.Object]bool{exc: true}
+	for cause := throwableCause(exc); cause != nil; cause = throwableCause(cause) {
+		if seen[cause] {
+			_ = log.Log("\t[CIRCULAR REFERENCE: "+throwableString(cause)+"]", log.SEVERE)
+			break
+		}
+		seen[cause] = true
+
+		causeTrace := stackTraceLines(cause)
+		m, n := len(causeTrace)-1, len(trace)-1
+		for m >= 0 && n >= 0 && causeTrace[m] == trace[n] {
+			m--
+			n--
+		}
+		framesInCommon := len(causeTrace) - 1 - m
+
+		_ = log.Log("Caused by: "+throwableString(cause), log.SEVERE)
+		for _, line := range exceptions.TruncateStackTrace(causeTrace[:m+1]) {
+			_ = log.Log(line, log.SEVERE)
+		}
+		if framesInCommon != 0 {
+			_ = log.Log(fmt.Sprintf("\t... %d more", framesInCommon), log.SEVERE)
+		}
+		trace = causeTrace
+	}
+}
+
+// throwableString returns the exception's class name in the format used by HotSpot,
+// followed by its detail message, if it has one, as Throwable.toString() does
+func throwableString(exc *object.Object) string {
+	exceptionClass := *(stringPool.GetStringPointer(exc.KlassName))
+	str := strings.Replace(exceptionClass, "/", ".", -1)
+
+	appMsg := exc.FieldTable["detailMessage"].Fvalue
+	switch appMsg.(type) {
+	case []uint8:
+		str += fmt.Sprintf(": %s", string(appMsg.([]uint8)))
+	case *object.Object:
+		st := appMsg.(*object.Object)
+		if object.IsNull(st) {
+			break
+		}
+		value := st.FieldTable["value"].Fvalue
+		switch value.(type) {
+		case []byte:
+			str += fmt.Sprintf(": %s", string(value.([]byte)))
+		case uint32:
+			str += fmt.Sprintf(": %s", *stringPool.GetStringPointer(value.(uint32)))
+		}
+	}
+	return str
+}
+
+// stackTraceLines returns the "at" lines of the stack trace held in the exception's
+// stackTrace field, leaving out constructors and the methods of Throwable itself
+func stackTraceLines(exc *object.Object) []string {
+	var lines []string
+	steArrayPtr, ok := exc.FieldTable["stackTrace"].Fvalue.(*object.Object)
+	if !ok || object.IsNull(steArrayPtr) {
+		return lines
+	}
+
+	rawSteArray, _ := steArrayPtr.FieldTable["value"].Fvalue.([]*object.Object) // each of which is an STE
+	for _, ste := range rawSteArray {
+		if ste == nil {
+			continue
+		}
+		methodName := ste.FieldTable["methodName"].Fvalue.(string)
+		if methodName == "<init>" { // don't show constructors
+			continue
+		}
+		rawClassName := ste.FieldTable["declaringClass"].Fvalue.(string)
+		if rawClassName == "java/lang/Throwable" { // don't show Throwable methods
+			continue
+		}
+		className := strings.Replace(rawClassName, "/", ".", -1)
+		lines = append(lines, fmt.Sprintf("\tat %s.%s(%s)", className,
+			methodName, exceptions.SourceLocation(ste)))
+	}
+	return lines
+}
+
+// throwableCause returns the exception's cause, or nil if it has none. Throwable's
+// cause field refers to the Throwable itself until a cause is set.
+func throwableCause(exc *object.Object) *object.Object {
+	cause, ok := exc.FieldTable["cause"].Fvalue.(*object.Object)
+	if !ok || object.IsNull(cause) || cause == exc {
+		return nil
+	}
+	return cause
+}
+
+// implementsInterface reports whether the class className, or any of its superclasses,
+// implements the interface interfaceName, whether directly or via a superinterface.
+// (An interface is considered to implement itself.) Classes are loaded as needed; those
+// that can't be loaded are treated as not implementing the interface. visited holds the
+// names of the classes and interfaces already checked, so that none is checked twice.
+func implementsInterface(className, interfaceName string, visited map[string]bool) bool {
+	if className == interfaceName {
+		return true
+	}
+	if className == "" || visited[className] {
+		return false
+	}
+	visited[className] = true
+
+	if classloader.MethAreaFetch(className) == nil {
+		if err := classloader.LoadClassFromNameOnly(className); err != nil {
+			return false
+		}
+	}
+	class := classloader.MethAreaFetch(className)
+	if class == nil || class.Data == nil {
+		return false
+	}
+
+	for _, index := range class.Data.Interfaces {
+		superinterface := *stringPool.GetStringPointer(uint32(index))
+		if implementsInterface(superinterface, interfaceName, visited) {
+			return true
+		}
+	}
+
+	if class.Data.SuperclassIndex == types.ObjectPoolStringIndex {
+		return false
+	}
+	superclass := *stringPool.GetStringPointer(class.Data.SuperclassIndex)
+	return implementsInterface(superclass, interfaceName, visited)
+}
+
+// isAssignableTo reports whether an object of class className can be stored where a
+// typeName is expected: that is, whether className is typeName or one of its
+// subclasses or implementations. Every class is assignable to java/lang/Object.
+//
+// Arrays are checked only as far as Jacobin's array class names allow. An array of a
+// primitive type is named for how Jacobin stores it (see jacobinArrayType()), so a
+// char[] is named [I, for example. The class names of arrays of references and of
+// arrays of arrays depend on how the arrays were created, so any array can be stored
+// where such an array is expected.
+func isAssignableTo(className, typeName string) bool {
+	if typeName == types.ObjectClassName || className == typeName {
+		return true
+	}
+	if strings.HasPrefix(typeName, types.Array) {
+		if !strings.HasPrefix(className, types.Array) {
+			return false
+		}
+		if len(typeName) == 2 && typeName != types.RefArray { // a 1-dimensional array of a primitive type
+			return jacobinArrayType(className) == jacobinArrayType(typeName)
+		}
+		return true
+	}
+	if strings.HasPrefix(className, types.Array) {
+		return false
+	}
+	return implementsInterface(className, typeName, make(map[string]bool))
+}
+
+// jacobinArrayType returns the type of array in which Jacobin stores an array of the
+// given type. Boolean and byte arrays are stored as arrays of bytes ([B), char, short,
+// int, and long arrays as arrays of int64s ([I), and float and double arrays as arrays of
+// float64s ([F). Other array types are returned unchanged.
+func jacobinArrayType(arrayType string) string {
+	switch arrayType {
+	case types.BoolArray, types.ByteArray:
+		return types.ByteArray
+	case "[C", "[S", types.IntArray, types.LongArray:
+		return types.IntArray
+	case types.FloatArray, types.DoubleArray:
+		return types.FloatArray
+	}
+	return arrayType
+}
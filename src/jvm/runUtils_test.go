@@ -6,7 +6,21 @@
 
 package jvm
 
-import "testing"
+import (
+	"io"
+	"jacobin/classloader"
+	"jacobin/exceptions"
+	"jacobin/frames"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/opcodes"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"os"
+	"strings"
+	"testing"
+)
 
 // tests for runUtils.go. Note that most functions are tested inside the tests for run.go,
 // but several benefit from standalone testing. Those are tested here
@@ -23,3 +37,435 @@ func TestConvertBoolByteToInt64(t *testing.T) {
 		t.Errorf("convertBoolByteToInt64(bool) != 1 (true), got %d", res)
 	}
 }
+
+// loads a minimal java/lang/ArithmeticException and a class, Catcher, whose methods have
+// try/catch blocks around divisions by zero. Returns Catcher's CP.
+func loadCatcherClass() *classloader.CPool {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	arithName := "java/lang/ArithmeticException"
+	excName := "java/lang/Exception"
+	catcherName := "Catcher"
+	arithIndex := stringPool.GetStringIndex(&arithName)
+	excIndex := stringPool.GetStringIndex(&excName)
+	catcherIndex := stringPool.GetStringIndex(&catcherName)
+
+	CP := classloader.CPool{}
+	CP.ClassRefs = []uint32{arithIndex, excIndex, catcherIndex}
+	CP.CpIndex = []classloader.CpEntry{
+		{Type: 0, Slot: 0},
+		{Type: classloader.ClassRef, Slot: 0}, // 1 java/lang/ArithmeticException
+		{Type: classloader.ClassRef, Slot: 1}, // 2 java/lang/Exception
+		{Type: classloader.ClassRef, Slot: 2}, // 3 Catcher
+	}
+
+	classloader.MethAreaInsert(arithName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            arithName,
+			NameIndex:       arithIndex,
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+		},
+	})
+
+	// static void idiv() { int r; try { r = 5 / 0; } catch (ArithmeticException e) { r = -1; } }
+	// (r is in local 0 and the exception is stored in local 1)
+	classloader.MTable["Catcher.idiv()V"] = classloader.MTentry{
+		MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  2,
+			MaxLocals: 2,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.ICONST_5, opcodes.ICONST_0, opcodes.IDIV, opcodes.ISTORE_0, opcodes.RETURN,
+				opcodes.ASTORE_1, opcodes.ICONST_M1, opcodes.ISTORE_0, opcodes.RETURN,
+			},
+			Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 4, HandlerPc: 5, CatchType: 1}},
+		},
+	}
+
+	// static void lrem() { long r; try { r = 5L % 0L; } catch (Exception e) { r = -1L; } }
+	classloader.MTable["Catcher.lrem()V"] = classloader.MTentry{
+		MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  4,
+			MaxLocals: 3,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.LCONST_1, opcodes.LCONST_0, opcodes.LREM, opcodes.LSTORE_0, opcodes.RETURN,
+				opcodes.ASTORE_2, opcodes.ICONST_M1, opcodes.I2L, opcodes.LSTORE_0, opcodes.RETURN,
+			},
+			Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 4, HandlerPc: 5, CatchType: 2}},
+		},
+	}
+	return &CP
+}
+
+// creates the frame for a method of Catcher loaded by loadCatcherClass()
+func catcherFrame(CP *classloader.CPool, methName string) *frames.Frame {
+	meth := classloader.MTable["Catcher."+methName+"()V"].Meth.(classloader.JmEntry)
+	f := frames.CreateFrame(meth.MaxStack)
+	f.Ftype = 'J'
+	f.ClName = "Catcher"
+	f.MethName = methName
+	f.MethType = "()V"
+	f.CP = CP
+	f.Meth = meth.Code
+	f.Locals = make([]interface{}, meth.MaxLocals)
+	return f
+}
+
+// a division by zero inside a try block is caught by the catch block for ArithmeticException
+func TestIdivByZeroIsCaught(t *testing.T) {
+	CP := loadCatcherClass()
+	f := catcherFrame(CP, "idiv")
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("IDIV by zero in a try block: expected the exception to be caught, got: %s", err.Error())
+	}
+	if f.Locals[0] != int64(-1) {
+		t.Errorf("IDIV by zero in a try block: expected the catch block to set -1, got: %v", f.Locals[0])
+	}
+
+	exc, ok := f.Locals[1].(*object.Object)
+	if !ok {
+		t.Fatalf("IDIV by zero in a try block: expected the exception object in local 1, got: %T", f.Locals[1])
+	}
+	excClass := object.GoStringFromStringPoolIndex(exc.KlassName)
+	if excClass != "java/lang/ArithmeticException" {
+		t.Errorf("IDIV by zero in a try block: expected an ArithmeticException, got: %s", excClass)
+	}
+	msg := object.GoStringFromStringObject(exc.FieldTable["detailMessage"].Fvalue.(*object.Object))
+	if !strings.Contains(msg, "division by zero") {
+		t.Errorf("IDIV by zero in a try block: unexpected detail message: %s", msg)
+	}
+}
+
+// the handler may be for a superclass, here Exception, and LREM must run it from its first bytecode
+func TestLremByZeroIsCaught(t *testing.T) {
+	CP := loadCatcherClass()
+	f := catcherFrame(CP, "lrem")
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("LREM by zero in a try block: expected the exception to be caught, got: %s", err.Error())
+	}
+	if f.Locals[0] != int64(-1) {
+		t.Errorf("LREM by zero in a try block: expected the catch block to set -1, got: %v", f.Locals[0])
+	}
+	if _, ok := f.Locals[2].(*object.Object); !ok {
+		t.Errorf("LREM by zero in a try block: expected the exception object in local 2, got: %T", f.Locals[2])
+	}
+}
+
+// a division by zero in a method without a handler is caught by the caller's handler
+func TestDivideByZeroCaughtInCaller(t *testing.T) {
+	CP := loadCatcherClass()
+	caller := catcherFrame(CP, "idiv")
+	caller.PC = 2 // as if the IDIV were a call that is in progress inside the try block
+
+	callee := frames.CreateFrame(2)
+	callee.Ftype = 'J'
+	callee.ClName = "Catcher"
+	callee.MethName = "noHandler"
+	callee.MethType = "()V"
+	callee.CP = CP
+	callee.Meth = []byte{opcodes.ICONST_1, opcodes.ICONST_0, opcodes.IDIV, opcodes.RETURN}
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(caller)
+	fs.PushFront(callee)
+
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("IDIV by zero in a callee: expected the caller to catch it, got: %s", err.Error())
+	}
+	if fs.Len() != 1 || fs.Front().Value.(*frames.Frame) != caller {
+		t.Errorf("IDIV by zero in a callee: expected the callee's frame to be popped, frame count: %d", fs.Len())
+	}
+	if caller.Locals[0] != int64(-1) {
+		t.Errorf("IDIV by zero in a callee: expected the caller's catch block to set -1, got: %v", caller.Locals[0])
+	}
+}
+
+// with no handler anywhere, the exception is not caught and, in tests, an error is returned
+func TestIdivByZeroNotCaught(t *testing.T) {
+	CP := loadCatcherClass()
+	f := catcherFrame(CP, "idiv")
+	f.PC = 4 // past the end of the try block
+	f.Meth = append([]byte{}, f.Meth...)
+	f.Meth[4] = opcodes.IDIV
+	push(f, int64(5))
+	push(f, int64(0))
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	err := runFrame(fs)
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Errorf("IDIV by zero outside a try block: expected a division by zero error, got: %v", err)
+	}
+}
+
+// makeTestThrowable creates an exception of the given class with a detail message and a
+// stack trace of the given methods, each of which is a class name and a method name
+func makeTestThrowable(className, msg string, methods ...[2]string) *object.Object {
+	exc := object.MakeEmptyObjectWithClassName(&className)
+	exc.FieldTable["detailMessage"] =
+		object.Field{Ftype: "Ljava/lang/String;", Fvalue: object.StringObjectFromGoString(msg)}
+	exc.FieldTable["cause"] = object.Field{Ftype: "Ljava/lang/Throwable;", Fvalue: exc}
+
+	steClassName := "java/lang/StackTraceElement"
+	steArray := object.Make1DimRefArray(&steClassName, int64(len(methods)))
+	rawSteArray := steArray.FieldTable["value"].Fvalue.([]*object.Object)
+	for i, method := range methods {
+		ste := object.MakeEmptyObjectWithClassName(&steClassName)
+		ste.FieldTable["declaringClass"] = object.Field{Fvalue: method[0]}
+		ste.FieldTable["methodName"] = object.Field{Fvalue: method[1]}
+		ste.FieldTable["fileName"] = object.Field{Fvalue: method[0][strings.LastIndex(method[0], "/")+1:] + ".java"}
+		ste.FieldTable["sourceLine"] = object.Field{Fvalue: ""}
+		rawSteArray[i] = ste
+	}
+	exc.FieldTable["stackTrace"] = object.Field{Ftype: "[Ljava/lang/StackTraceElement;", Fvalue: steArray}
+	return exc
+}
+
+// an uncaught exception that wraps another is shown with a Caused by: section for the
+// wrapped exception, in which the frames it shares with the wrapping exception are elided
+func TestShowUncaughtExceptionWithCause(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	cause := makeTestThrowable("java/lang/NumberFormatException", "For input string: \"x\"",
+		[2]string{"java/lang/Integer", "parseInt"},
+		[2]string{"app/Config", "load"},
+		[2]string{"app/Main", "main"})
+	exc := makeTestThrowable("java/lang/IllegalStateException", "bad config",
+		[2]string{"app/Config", "load"},
+		[2]string{"app/Main", "main"})
+	exc.FieldTable["cause"] = object.Field{Ftype: "Ljava/lang/Throwable;", Fvalue: cause}
+
+	showUncaughtException(1, exc)
+
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stderr = normalStderr
+
+	expected := "Exception in thread \"main\" java.lang.IllegalStateException: bad config\n" +
+		"\tat app.Config.load(Config.java)\n" +
+		"\tat app.Main.main(Main.java)\n" +
+		"Caused by: java.lang.NumberFormatException: For input string: \"x\"\n" +
+		"\tat java.lang.Integer.parseInt(Integer.java)\n" +
+		"\t... 2 more\n"
+	if string(out) != expected {
+		t.Errorf("TestShowUncaughtExceptionWithCause: expected:\n%s\ngot:\n%s", expected, string(out))
+	}
+}
+
+// a chain of causes that loops back on itself is cut off where the loop begins
+func TestShowUncaughtExceptionCircularCause(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	exc := makeTestThrowable("java/lang/RuntimeException", "outer", [2]string{"app/Main", "main"})
+	cause := makeTestThrowable("java/lang/RuntimeException", "inner", [2]string{"app/Main", "main"})
+	exc.FieldTable["cause"] = object.Field{Ftype: "Ljava/lang/Throwable;", Fvalue: cause}
+	cause.FieldTable["cause"] = object.Field{Ftype: "Ljava/lang/Throwable;", Fvalue: exc}
+
+	showUncaughtException(1, exc)
+
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stderr = normalStderr
+
+	if !strings.Contains(string(out), "Caused by: java.lang.RuntimeException: inner\n") {
+		t.Errorf("TestShowUncaughtExceptionCircularCause: missing Caused by line in:\n%s", string(out))
+	}
+	if !strings.HasSuffix(string(out), "\t[CIRCULAR REFERENCE: java.lang.RuntimeException: outer]\n") {
+		t.Errorf("TestShowUncaughtExceptionCircularCause: missing circular reference line in:\n%s", string(out))
+	}
+}
+
+// runaway recursion stops with a StackOverflowError once the frame stack reaches the depth
+// allowed by -Xss, and the frame stack listing is cut off at -XX:MaxJavaStackTraceDepth
+func TestDeepRecursionOverflowsFrameStack(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	glob := globals.GetGlobalRef()
+	glob.ThreadStackSize = 64 * estimatedFrameSize // room for 64 frames
+	glob.MaxJavaStackTraceDepth = 10
+
+	recurserName := "Recurser"
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 6)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // Recurser
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&recurserName)}
+	CP.Utf8Refs = []string{"recurse", "()V"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}}
+	classloader.InitResolvedRefs(&CP)
+
+	classloader.MethAreaInsert(recurserName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            recurserName,
+			NameIndex:       stringPool.GetStringIndex(&recurserName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	// static void recurse() { recurse(); }
+	classloader.MTable["Recurser.recurse()V"] = classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{
+		MaxStack:  1,
+		MaxLocals: 1,
+		Cp:        &CP,
+		Code:      []byte{opcodes.INVOKESTATIC, 0x00, 0x01, opcodes.RETURN},
+	}}
+
+	f := frames.CreateFrame(1)
+	f.Ftype = 'J'
+	f.ClName = recurserName
+	f.MethName = "recurse"
+	f.MethType = "()V"
+	f.CP = &CP
+	f.Meth = classloader.MTable["Recurser.recurse()V"].Meth.(classloader.JmEntry).Code
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	err := runFrame(fs)
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil || !strings.Contains(err.Error(), "frame stack overflow") {
+		t.Fatalf("deep recursion: expected a frame stack overflow, got: %v", err)
+	}
+	if fs.Len() != 64 {
+		t.Errorf("deep recursion: expected the overflow at 64 frames, got: %d", fs.Len())
+	}
+
+	// the first call cached the method, which each of the recursive calls then reused
+	if ref, _ := classloader.ResolveMethodRef(&CP, 1); ref.Method.Meth == nil {
+		t.Error("deep recursion: expected INVOKESTATIC to cache Recurser.recurse()V")
+	}
+
+	listing := *exceptions.GrabFrameStack(fs)
+	if len(listing) != 11 {
+		t.Fatalf("deep recursion: expected 10 frames and an omission line, got %d lines", len(listing))
+	}
+	if !strings.HasPrefix(listing[0], "Method: Recurser.recurse") {
+		t.Errorf("deep recursion: unexpected frame in the listing: %s", listing[0])
+	}
+	if listing[10] != "... 54 frames omitted" {
+		t.Errorf("deep recursion: expected \"... 54 frames omitted\", got: %s", listing[10])
+	}
+}
+
+// an uncaught exception's stack trace is cut off at -XX:MaxJavaStackTraceDepth lines,
+// followed by a line that counts the frames left out
+func TestShowUncaughtExceptionTruncatesTrace(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	globals.GetGlobalRef().MaxJavaStackTraceDepth = 3
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	var methods [][2]string
+	for i := 0; i < 5; i++ {
+		methods = append(methods, [2]string{"app/Main", "recurse"})
+	}
+	exc := makeTestThrowable("java/lang/StackOverflowError", "too deep", methods...)
+
+	showUncaughtException(1, exc)
+
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	os.Stderr = normalStderr
+
+	expected := "Exception in thread \"main\" java.lang.StackOverflowError: too deep\n" +
+		"\tat app.Main.recurse(Main.java)\n" +
+		"\tat app.Main.recurse(Main.java)\n" +
+		"\tat app.Main.recurse(Main.java)\n" +
+		"\t... 2 frames omitted\n"
+	if string(out) != expected {
+		t.Errorf("TestShowUncaughtExceptionTruncatesTrace: expected:\n%s\ngot:\n%s", expected, string(out))
+	}
+}
+
+// a method's frame is created with its op stack and locals preallocated to the method's
+// max_stack and max_locals, and a push beyond the op stack's size is a stack overflow
+func TestNewFramePreallocatedToMaxStack(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+
+	m := classloader.JmEntry{MaxStack: 3, MaxLocals: 4, Code: []byte{opcodes.RETURN}}
+	caller := frames.CreateFrame(2)
+	caller.ClName = "Caller"
+	fram, err := createAndInitNewFrame("Callee", "m", "()V", &m, false, caller)
+	if err != nil {
+		t.Fatalf("createAndInitNewFrame: unexpected error: %v", err)
+	}
+	if len(fram.OpStack) < m.MaxStack || cap(fram.OpStack) < m.MaxStack {
+		t.Errorf("expected an op stack of at least max_stack (3), got length %d, capacity %d",
+			len(fram.OpStack), cap(fram.OpStack))
+	}
+	if len(fram.Locals) != m.MaxLocals {
+		t.Errorf("expected max_locals (4) locals, got: %d", len(fram.Locals))
+	}
+
+	for i := 0; i < len(fram.OpStack); i++ {
+		push(fram, int64(i))
+	}
+
+	normalStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	push(fram, int64(99))
+	_ = w.Close()
+	msg, _ := io.ReadAll(r)
+	os.Stderr = normalStderr
+
+	if !strings.Contains(string(msg), "StackOverflowError") ||
+		!strings.Contains(string(msg), "exceeded op stack size") {
+		t.Errorf("expected a push onto a full op stack to overflow, got: %s", string(msg))
+	}
+	if fram.TOS != len(fram.OpStack)-1 {
+		t.Errorf("expected the overflowing push to leave TOS at %d, got: %d", len(fram.OpStack)-1, fram.TOS)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"jacobin/log"
 	"jacobin/object"
 	"jacobin/opcodes"
+	"jacobin/stringPool"
 	"jacobin/types"
 	"math"
 	"os"
@@ -64,6 +65,65 @@ func TestAconstNull(t *testing.T) {
 	}
 }
 
+// ACONST_NULL: the null pushed is object.Null--a typed *object.Object--rather than
+// a Go nil, so that it can be type-asserted as an object reference when popped.
+func TestAconstNullIsObjectNull(t *testing.T) {
+	f := newFrame(opcodes.ACONST_NULL)
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	_ = runFrame(fs)
+
+	x := pop(&f)
+	ref, ok := x.(*object.Object)
+	if !ok {
+		t.Fatalf("ACONST_NULL: Expecting a *object.Object on stack, got: %T", x)
+	}
+	if ref != object.Null || !object.IsNull(ref) {
+		t.Errorf("ACONST_NULL: Expecting object.Null on stack, got: %v", ref)
+	}
+}
+
+// ACONST_NULL followed by IFNULL: the null pushed by ACONST_NULL must be recognized
+// as null when popped by IFNULL, so the branch is taken
+func TestAconstNullThenIfnull(t *testing.T) {
+	f := newFrame(opcodes.ACONST_NULL)
+	f.Meth = append(f.Meth, opcodes.IFNULL)
+	f.Meth = append(f.Meth, 0) // jump to byte 5 = ICONST_2
+	f.Meth = append(f.Meth, 4)
+	f.Meth = append(f.Meth, opcodes.ICONST_1)
+	f.Meth = append(f.Meth, opcodes.ICONST_2)
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	_ = runFrame(fs)
+
+	if f.TOS != 0 {
+		t.Fatalf("ACONST_NULL/IFNULL: Expecting TOS = 0, but tos is: %d", f.TOS)
+	}
+	if value := pop(&f).(int64); value != 2 {
+		t.Errorf("ACONST_NULL/IFNULL: Expecting the branch to ICONST_2 to be taken, but got: %d", value)
+	}
+}
+
+// ACONST_NULL followed by IFNONNULL: the null pushed by ACONST_NULL must not be taken
+// for a non-null reference, so IFNONNULL falls through
+func TestAconstNullThenIfnonnull(t *testing.T) {
+	f := newFrame(opcodes.ACONST_NULL)
+	f.Meth = append(f.Meth, opcodes.IFNONNULL)
+	f.Meth = append(f.Meth, 0) // jump to byte 6 = ICONST_2, if the jump is (wrongly) made
+	f.Meth = append(f.Meth, 5)
+	f.Meth = append(f.Meth, opcodes.ICONST_1)
+	f.Meth = append(f.Meth, opcodes.RETURN)
+	f.Meth = append(f.Meth, opcodes.ICONST_2)
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	_ = runFrame(fs)
+
+	if f.Meth[f.PC] != opcodes.RETURN {
+		t.Errorf("ACONST_NULL/IFNONNULL: Expecting fall-through to RETURN, but stopped at: %s",
+			opcodes.BytecodeNames[f.Meth[f.PC]])
+	}
+}
+
 // ALOAD: test load of reference in locals[index] on to stack
 func TestAload(t *testing.T) {
 	f := newFrame(opcodes.ALOAD)
@@ -163,7 +223,7 @@ func TestAload3(t *testing.T) {
 	}
 }
 
-// ARETURN: Return a long from a function
+// ARETURN: Return a reference from a function
 func TestAreturn(t *testing.T) {
 	f0 := newFrame(0)
 	push(&f0, unsafe.Pointer(&f0))
@@ -188,6 +248,59 @@ func TestAreturn(t *testing.T) {
 	}
 }
 
+// ARETURN: Return a String object to the previous frame, which gets the same object
+func TestAreturnString(t *testing.T) {
+	globals.InitGlobals("test")
+	f0 := newFrame(0)
+	push(&f0, int64(20))
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f0)
+
+	str := object.StringObjectFromGoString("returned string")
+	f1 := newFrame(opcodes.ARETURN)
+	push(&f1, str)
+	fs.PushFront(&f1)
+	_ = runFrame(fs)
+
+	if f1.TOS != -1 {
+		t.Errorf("ARETURN: expected the returning frame's stack to be empty, got TOS of %d", f1.TOS)
+	}
+	_ = frames.PopFrame(fs)
+	f2 := fs.Front().Value.(*frames.Frame)
+	newVal, ok := pop(f2).(*object.Object)
+	if !ok || newVal != str {
+		t.Errorf("ARETURN: expected the same String object in the previous frame, got: %v", newVal)
+	} else if object.GoStringFromStringObject(newVal) != "returned string" {
+		t.Errorf("ARETURN: expected 'returned string', got: %s", object.GoStringFromStringObject(newVal))
+	}
+	prevVal := pop(f2).(int64)
+	if prevVal != 20 {
+		t.Errorf("ARETURN: expected a value of 20 in 2nd place of previous frame, got: %d", prevVal)
+	}
+}
+
+// ARETURN: Return null to the previous frame
+func TestAreturnNull(t *testing.T) {
+	f0 := newFrame(0)
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f0)
+
+	f1 := newFrame(opcodes.ARETURN)
+	push(&f1, object.Null)
+	fs.PushFront(&f1)
+	_ = runFrame(fs)
+	_ = frames.PopFrame(fs)
+
+	f2 := fs.Front().Value.(*frames.Frame)
+	if f2.TOS != 0 {
+		t.Fatalf("ARETURN: expected one item on the previous frame's stack, got TOS of %d", f2.TOS)
+	}
+	newVal := pop(f2)
+	if newVal != object.Null {
+		t.Errorf("ARETURN: expected null in the previous frame, got: %v", newVal)
+	}
+}
+
 // ASTORE: Store reference in local var specified by following byte.
 func TestAstore(t *testing.T) {
 	f := newFrame(opcodes.ASTORE)
@@ -292,6 +405,76 @@ func TestAstore3(t *testing.T) {
 // BASTORE is tested in arrayBytecodes_test.go
 
 // BIPUSH
+// ATHROW: an exception thrown in a callee and caught in its caller pops the callee's frame,
+// so the caller's handler runs in the caller's frame and returns to the caller's caller.
+// Catcher.tryIt() calls the static Catcher.fail(this), which throws its argument, and
+// returns "caught" from its handler.
+func TestAthrowCaughtByCaller(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	catcherName := "Catcher"
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 10)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // Catcher
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.StringConst, Slot: 7}
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}
+	CP.CpIndex[8] = classloader.CpEntry{Type: classloader.StringConst, Slot: 9}
+	CP.CpIndex[9] = classloader.CpEntry{Type: classloader.UTF8, Slot: 3}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&catcherName)}
+	CP.Utf8Refs = []string{"fail", "(Ljava/lang/Object;)V", "not caught", "caught"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}}
+
+	classloader.MethAreaInsert(catcherName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            catcherName,
+			NameIndex:       stringPool.GetStringIndex(&catcherName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	// static void fail(Object o) { throw o; }
+	classloader.MTable["Catcher.fail(Ljava/lang/Object;)V"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  1,
+			MaxLocals: 1,
+			Cp:        &CP,
+			Code:      []byte{opcodes.ALOAD_0, opcodes.ATHROW},
+		}}
+	classloader.MTable["Catcher.tryIt()Ljava/lang/String;"] = classloader.MTentry{MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  2,
+			MaxLocals: 1,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.ALOAD_0, opcodes.INVOKESTATIC, 0x00, 0x01, // try { fail(this);
+				opcodes.LDC, 0x06, opcodes.ARETURN, //                    return "not caught" }
+				opcodes.POP, opcodes.LDC, 0x08, opcodes.ARETURN, //   catch (Catcher c) { return "caught" }
+			},
+			Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 7, HandlerPc: 7, CatchType: 2}},
+		}}
+
+	ret, err := InvokeJavaMethod(object.MakeEmptyObjectWithClassName(&catcherName), "tryIt", "()Ljava/lang/String;")
+	if err != nil {
+		t.Fatalf("tryIt(): unexpected error: %v", err)
+	}
+	if str, ok := ret.(*object.Object); !ok || object.GoStringFromStringObject(str) != "caught" {
+		t.Errorf("tryIt(): expected \"caught\" from the handler, got %v", ret)
+	}
+}
+
 func TestBipush(t *testing.T) {
 	f := newFrame(opcodes.BIPUSH)
 	f.Meth = append(f.Meth, 0x05)
@@ -324,6 +507,41 @@ func TestBipushNeg(t *testing.T) {
 	}
 }
 
+// BIPUSH: the operand is a signed byte, which must be sign-extended to an int.
+// The PC must advance past both the opcode and its one-byte operand.
+func TestBipushSignExtension(t *testing.T) {
+	tests := []struct {
+		operand  byte
+		expected int64
+	}{
+		{0x7F, 127},  // largest positive byte
+		{0x01, 1},    // smallest positive byte
+		{0xFF, -1},   // all bits set
+		{0x80, -128}, // most negative byte
+	}
+
+	for _, test := range tests {
+		f := newFrame(opcodes.BIPUSH)
+		f.Meth = append(f.Meth, test.operand)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		_ = runFrame(fs)
+
+		if f.PC != 2 {
+			t.Errorf("BIPUSH 0x%02X: expected PC of 2, got: %d", test.operand, f.PC)
+		}
+		if f.TOS != 0 {
+			t.Errorf("BIPUSH 0x%02X: Top of stack, expected 0, got: %d", test.operand, f.TOS)
+			continue
+		}
+		value := pop(&f).(int64)
+		if value != test.expected {
+			t.Errorf("BIPUSH 0x%02X: Expected popped value to be %d, got: %d",
+				test.operand, test.expected, value)
+		}
+	}
+}
+
 // CHECKCAST: This bytecode uses similar logic to INSTANCEOF, except how
 // it handles exceptional conditions.
 func TestCheckcastOfString(t *testing.T) {
@@ -776,6 +994,60 @@ func TestDconst1(t *testing.T) {
 	}
 }
 
+// The whole family of small-constant pushes (ICONST_*, LCONST_*, FCONST_*, DCONST_*).
+// Longs and doubles take two slots, so they must be pushed twice.
+func TestConstPushFamily(t *testing.T) {
+	tests := []struct {
+		name     string
+		opcode   byte
+		expected interface{}
+		slots    int
+	}{
+		{"ICONST_M1", opcodes.ICONST_M1, int64(-1), 1},
+		{"ICONST_0", opcodes.ICONST_0, int64(0), 1},
+		{"ICONST_1", opcodes.ICONST_1, int64(1), 1},
+		{"ICONST_2", opcodes.ICONST_2, int64(2), 1},
+		{"ICONST_3", opcodes.ICONST_3, int64(3), 1},
+		{"ICONST_4", opcodes.ICONST_4, int64(4), 1},
+		{"ICONST_5", opcodes.ICONST_5, int64(5), 1},
+		{"LCONST_0", opcodes.LCONST_0, int64(0), 2},
+		{"LCONST_1", opcodes.LCONST_1, int64(1), 2},
+		{"FCONST_0", opcodes.FCONST_0, float64(0.0), 1},
+		{"FCONST_1", opcodes.FCONST_1, float64(1.0), 1},
+		{"FCONST_2", opcodes.FCONST_2, float64(2.0), 1},
+		{"DCONST_0", opcodes.DCONST_0, float64(0.0), 2},
+		{"DCONST_1", opcodes.DCONST_1, float64(1.0), 2},
+	}
+
+	for _, test := range tests {
+		f := newFrame(test.opcode)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		err := runFrame(fs)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err.Error())
+			continue
+		}
+
+		if f.TOS != test.slots-1 {
+			t.Errorf("%s: expected TOS of %d, got: %d", test.name, test.slots-1, f.TOS)
+			continue
+		}
+
+		if f.PC != 1 {
+			t.Errorf("%s: expected PC of 1, got: %d", test.name, f.PC)
+		}
+
+		for slot := 0; slot < test.slots; slot++ {
+			value := pop(&f)
+			if value != test.expected { // compares both the dynamic type and the value
+				t.Errorf("%s: expected slot %d to hold %v (%T), got: %v (%T)",
+					test.name, slot, test.expected, test.expected, value, value)
+			}
+		}
+	}
+}
+
 // DDIV: double divide of.TOS-1 by tos, push result
 func TestDdiv(t *testing.T) {
 	f := newFrame(opcodes.DDIV)
@@ -1020,24 +1292,30 @@ func TestDrem(t *testing.T) {
 	}
 }
 
-// DRETURN: Return a long from a function
+// DRETURN: Return a double from a function
 func TestDreturn(t *testing.T) {
 	f0 := newFrame(0)
 	push(&f0, float64(20))
 	fs := frames.CreateFrameStack()
 	fs.PushFront(&f0)
 	f1 := newFrame(opcodes.DRETURN)
-	push(&f1, float64(21))
-	push(&f1, float64(21))
+	push(&f1, float64(21.25))
+	push(&f1, float64(21.25))
 	fs.PushFront(&f1)
 	_ = runFrame(fs)
 	_ = frames.PopFrame(fs)
 	f3 := fs.Front().Value.(*frames.Frame)
+	if f3.TOS != 2 { // the 20 plus the two slots of the returned double
+		t.Errorf("After DRETURN, expected TOS of 2 in previous frame, got: %d", f3.TOS)
+	}
 	newVal := pop(f3).(float64)
-	if newVal != 21.0 {
-		t.Errorf("After DRETURN, expected a value of 21 in previous frame, got: %f", newVal)
+	if newVal != 21.25 {
+		t.Errorf("After DRETURN, expected a value of 21.25 in previous frame, got: %f", newVal)
+	}
+	newVal = pop(f3).(float64) // popped a second time due to doubles taking two slots
+	if newVal != 21.25 {
+		t.Errorf("After DRETURN, expected a value of 21.25 in both slots of the double, got: %f", newVal)
 	}
-	pop(f3) // popped a second time due to longs taking two slots
 
 	prevVal := pop(f3).(float64)
 	if prevVal != 20 {
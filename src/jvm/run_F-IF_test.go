@@ -462,6 +462,32 @@ func TestFrem(t *testing.T) {
 	}
 }
 
+// FRETURN: Return a float from a function
+func TestFreturn(t *testing.T) {
+	f0 := newFrame(0)
+	push(&f0, float64(20))
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f0)
+	f1 := newFrame(opcodes.FRETURN)
+	push(&f1, float64(float32(3.1))) // floats take one slot
+	fs.PushFront(&f1)
+	_ = runFrame(fs)
+	_ = frames.PopFrame(fs)
+	f3 := fs.Front().Value.(*frames.Frame)
+	if f3.TOS != 1 { // the 20 plus the one slot of the returned float
+		t.Errorf("After FRETURN, expected TOS of 1 in previous frame, got: %d", f3.TOS)
+	}
+	newVal := pop(f3).(float64)
+	if newVal != float64(float32(3.1)) {
+		t.Errorf("After FRETURN, expected a value of 3.1 in previous frame, got: %f", newVal)
+	}
+
+	prevVal := pop(f3).(float64)
+	if prevVal != 20 {
+		t.Errorf("After FRETURN, expected a value of 20 in 2nd place of previous frame, got: %f", prevVal)
+	}
+}
+
 // FSTORE: Store float from stack into local specified by following byte.
 func TestFstore(t *testing.T) {
 	f := newFrame(opcodes.FSTORE)
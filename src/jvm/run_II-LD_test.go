@@ -15,6 +15,7 @@ import (
 	"jacobin/opcodes"
 	"jacobin/stringPool"
 	"jacobin/types"
+	"math"
 	"os"
 	"strings"
 	"testing"
@@ -65,6 +66,31 @@ func TestIincNeg(t *testing.T) {
 	}
 }
 
+// IINC: the result wraps to 32 bits, as int arithmetic does, so MAX_VALUE + 1 is MIN_VALUE
+func TestIincOverflow(t *testing.T) {
+	tests := []struct {
+		local     int64
+		increment byte
+		expected  int64
+	}{
+		{math.MaxInt32, 1, math.MinInt32},
+		{math.MaxInt32 - 1, 5, math.MinInt32 + 3},
+		{math.MinInt32, 0xFF, math.MaxInt32}, // an increment of -1
+	}
+	for _, tt := range tests {
+		f := newFrame(opcodes.IINC)
+		f.Locals = append(f.Locals, zero)
+		f.Locals = append(f.Locals, tt.local)
+		f.Meth = append(f.Meth, 1, tt.increment)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		_ = runFrame(fs)
+		if f.Locals[1] != tt.expected {
+			t.Errorf("IINC %d by %d: expected %d, got: %d", tt.local, int8(tt.increment), tt.expected, f.Locals[1])
+		}
+	}
+}
+
 // ILOAD: test load of int in locals[index] on to stack
 func TestIload(t *testing.T) {
 	f := newFrame(opcodes.ILOAD)
@@ -197,6 +223,81 @@ func TestIneg(t *testing.T) {
 }
 
 // INSTANCEOF: Is the TOS item an instance of a particular class?
+// the shift amounts of the int shifts are masked to their bottom five bits, so a shift by
+// 32 leaves the value as is, and a shift by 33 is a shift by 1. IUSHR shifts in zeros.
+func TestIntShiftMasking(t *testing.T) {
+	tests := []struct {
+		name     string
+		opcode   byte
+		value    int64
+		shiftBy  int64
+		expected int64
+	}{
+		{"ISHL by 32", opcodes.ISHL, 22, 32, 22},
+		{"ISHL by 33", opcodes.ISHL, 22, 33, 44},
+		{"ISHL by -1", opcodes.ISHL, 1, -1, math.MinInt32}, // -1 & 0x1F is 31
+		{"ISHR by 32", opcodes.ISHR, -200, 32, -200},
+		{"ISHR by 33", opcodes.ISHR, -200, 33, -100},
+		{"IUSHR by 32", opcodes.IUSHR, -200, 32, -200},
+		{"IUSHR by 33", opcodes.IUSHR, 200, 33, 100},
+		{"IUSHR -1 by 33", opcodes.IUSHR, -1, 33, math.MaxInt32},
+		{"IUSHR MIN_VALUE by 31", opcodes.IUSHR, math.MinInt32, 31, 1},
+	}
+	for _, tt := range tests {
+		f := newFrame(tt.opcode)
+		push(&f, tt.value)
+		push(&f, tt.shiftBy)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		_ = runFrame(fs)
+		if value := pop(&f).(int64); value != tt.expected {
+			t.Errorf("%s: expected %d, got: %d", tt.name, tt.expected, value)
+		}
+	}
+}
+
+// int arithmetic wraps at 32 bits, as in Java, although ints are held in int64s
+func TestIntArithmeticOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		opcode   int
+		operands []int64
+		expected int64
+	}{
+		{"IADD MAX_VALUE + 1", opcodes.IADD, []int64{math.MaxInt32, 1}, math.MinInt32},
+		{"IADD MIN_VALUE + -1", opcodes.IADD, []int64{math.MinInt32, -1}, math.MaxInt32},
+		{"ISUB MIN_VALUE - 1", opcodes.ISUB, []int64{math.MinInt32, 1}, math.MaxInt32},
+		{"IMUL 123456789 * 1000", opcodes.IMUL, []int64{123456789, 1000}, -1097262584},
+		{"IMUL MAX_VALUE * MAX_VALUE", opcodes.IMUL, []int64{math.MaxInt32, math.MaxInt32}, 1},
+		{"IMUL 65536 * 65536", opcodes.IMUL, []int64{65536, 65536}, 0},
+		{"IDIV MIN_VALUE / -1", opcodes.IDIV, []int64{math.MinInt32, -1}, math.MinInt32},
+		{"INEG MIN_VALUE", opcodes.INEG, []int64{math.MinInt32}, math.MinInt32},
+		{"ISHL 1 << 31", opcodes.ISHL, []int64{1, 31}, math.MinInt32},
+		{"ISHL 3 << 31", opcodes.ISHL, []int64{3, 31}, math.MinInt32},
+		{"ISHL 1 << 32", opcodes.ISHL, []int64{1, 32}, 1}, // only the bottom five bits of the shift are used
+		{"ISHL -1 << 4", opcodes.ISHL, []int64{-1, 4}, -16},
+		{"ISHR -1 >> 1", opcodes.ISHR, []int64{-1, 1}, -1},
+		{"ISHR -7 >> 1", opcodes.ISHR, []int64{-7, 1}, -4}, // rounds toward negative infinity
+		{"IUSHR -1 >>> 28", opcodes.IUSHR, []int64{-1, 28}, 15},
+		{"IUSHR -1 >>> 0", opcodes.IUSHR, []int64{-1, 0}, -1},
+	}
+	for _, tt := range tests {
+		f := newFrame(byte(tt.opcode))
+		for _, operand := range tt.operands {
+			push(&f, operand)
+		}
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		if err := runFrame(fs); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if value := pop(&f).(int64); value != tt.expected {
+			t.Errorf("%s: expected %d, got: %d", tt.name, tt.expected, value)
+		}
+	}
+}
+
 func TestInstanceofNilAndNull(t *testing.T) {
 	f := newFrame(opcodes.INSTANCEOF)
 	push(&f, nil)
@@ -266,6 +367,147 @@ func TestInstanceofString(t *testing.T) {
 	}
 }
 
+// runInvokeinterfaceRun calls Runnable.run() via INVOKEINTERFACE on a new object of class
+// className, whose run() sets its field "ran" to 1. If implementsRunnable is false, the
+// class does not declare that it implements java/lang/Runnable. Returns the object and
+// the error, if any, returned by runFrame().
+func runInvokeinterfaceRun(t *testing.T, className string, implementsRunnable bool) (*object.Object, error) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	runnableName := "java/lang/Runnable"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 11)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.Interface, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // java/lang/Runnable
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0} // run
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1} // ()V
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.FieldRef, Slot: 0}
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 1} // className
+	CP.CpIndex[8] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 1}
+	CP.CpIndex[9] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}  // ran
+	CP.CpIndex[10] = classloader.CpEntry{Type: classloader.UTF8, Slot: 3} // I
+	CP.InterfaceRefs = []classloader.InterfaceRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.FieldRefs = []classloader.FieldRefEntry{{ClassIndex: 7, NameAndType: 8}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&runnableName), stringPool.GetStringIndex(&className)}
+	CP.Utf8Refs = []string{"run", "()V", "ran", "I"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}, {NameIndex: 9, DescIndex: 10}}
+
+	var interfaces []uint16
+	if implementsRunnable {
+		interfaces = []uint16{uint16(stringPool.GetStringIndex(&runnableName))}
+	}
+	classloader.MethAreaInsert(className, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            className,
+			NameIndex:       stringPool.GetStringIndex(&className),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			Interfaces:      interfaces,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+	classloader.MTable[className+".run()V"] = classloader.MTentry{MType: 'J', Meth: classloader.JmEntry{
+		MaxStack:  2,
+		MaxLocals: 1,
+		Cp:        &CP,
+		Code:      []byte{opcodes.ALOAD_0, opcodes.ICONST_1, opcodes.PUTFIELD, 0x00, 0x06, opcodes.RETURN},
+	}}
+
+	obj := object.MakeEmptyObjectWithClassName(&className)
+	obj.FieldTable["ran"] = object.Field{Ftype: types.Int, Fvalue: int64(0)}
+
+	// the caller holds the object in a Runnable-typed local: ((Runnable) obj).run()
+	f := frames.CreateFrame(2)
+	f.Ftype = 'J'
+	f.ClName = "Caller"
+	f.MethName = "call"
+	f.MethType = "()V"
+	f.CP = &CP
+	f.Meth = []byte{opcodes.ALOAD_0, opcodes.INVOKEINTERFACE, 0x00, 0x01, 0x01, 0x00, opcodes.RETURN}
+	f.Locals = []interface{}{obj}
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+	return obj, runFrame(fs)
+}
+
+// INVOKEINTERFACE: Runnable.run() is dispatched to the implementation in the object's class
+func TestInvokeinterfaceRunnableRun(t *testing.T) {
+	obj, err := runInvokeinterfaceRun(t, "Greeter", true)
+	if err != nil {
+		t.Fatalf("INVOKEINTERFACE: unexpected error: %s", err.Error())
+	}
+	if ran := obj.FieldTable["ran"].Fvalue; ran != int64(1) {
+		t.Errorf("INVOKEINTERFACE: expected run() to set the field to 1, got: %v", ran)
+	}
+}
+
+// INVOKEINTERFACE: calling run() on an object whose class doesn't implement Runnable
+// results in an IncompatibleClassChangeError
+func TestInvokeinterfaceClassNotImplementingInterface(t *testing.T) {
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { _ = w.Close(); os.Stderr = normalStderr }()
+
+	obj, err := runInvokeinterfaceRun(t, "NotARunnable", false)
+	if err == nil {
+		t.Fatalf("INVOKEINTERFACE: expected an error, got none")
+	}
+	if !strings.Contains(err.Error(), "does not implement interface java/lang/Runnable") {
+		t.Errorf("INVOKEINTERFACE: did not get expected error message, got: %s", err.Error())
+	}
+	if ran := obj.FieldTable["ran"].Fvalue; ran != int64(0) {
+		t.Errorf("INVOKEINTERFACE: expected run() not to be called, but the field is: %v", ran)
+	}
+}
+
+// INVOKESTATIC: calling a method of a class that can't be found results in a
+// NoClassDefFoundError, which the program can catch
+func TestInvokestaticMissingClassIsCaught(t *testing.T) {
+	f := runWithMissingClass(t, opcodes.INVOKESTATIC, 0x01)
+	checkMissingClassCaught(t, f)
+}
+
+// INVOKESTATIC: a CP entry that doesn't resolve to a method is an error
+func TestInvokestaticInvalidMethodRef(t *testing.T) {
+	globals.InitGlobals("test")
+
+	// redirect stderr so as not to pollute the test output with the expected error message
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+
+	f := newFrame(opcodes.INVOKESTATIC)
+	f.Meth = append(f.Meth, 0x00)
+	f.Meth = append(f.Meth, 0x01) // Go to slot 0x0001 in the CP
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 2)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // should be a method ref
+	classloader.InitResolvedRefs(&CP)
+	f.CP = &CP
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	err := runFrame(fs)
+
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil || !strings.Contains(err.Error(), "is not a valid method reference") {
+		t.Errorf("INVOKESTATIC: expected an invalid method reference error, got: %v", err)
+	}
+}
+
 // INVOKEVIRTUAL : invoke method -- here testing for error
 func TestInvokevirtualInvalid(t *testing.T) {
 
@@ -561,8 +803,8 @@ func TestIushr(t *testing.T) {
 
 	value := pop(&f).(int64) // longs require two slots, so popped twice
 
-	if value != 25 { // 200 >> 3 = 25
-		t.Errorf("IUSHR: expected a result of 25, but got: %d", value)
+	if value != 536870887 { // 0xFFFFFF38 >>> 3 = 0x1FFFFFE7
+		t.Errorf("IUSHR: expected a result of 536870887, but got: %d", value)
 	}
 	if f.TOS != -1 {
 		t.Errorf("IUSHR: Expected an empty stack, but got a tos of: %d", f.TOS)
@@ -7,6 +7,7 @@
 package jvm
 
 import (
+	"encoding/binary"
 	"io"
 	"jacobin/classloader"
 	"jacobin/exceptions"
@@ -19,6 +20,7 @@ import (
 	"jacobin/stringPool"
 	"jacobin/thread"
 	"jacobin/types"
+	"math"
 	"os"
 	"strings"
 	"testing"
@@ -157,6 +159,76 @@ func TestLload3(t *testing.T) {
 	}
 }
 
+// LOOKUPSWITCH: the default offset is signed, so the default can branch backward
+func TestLookupswitchBackwardDefault(t *testing.T) {
+	f := newFrame(opcodes.GOTO)
+	f.Meth = append(f.Meth,
+		0x00, 0x06, // 0: GOTO 6
+		opcodes.ICONST_5, opcodes.ISTORE_0, opcodes.RETURN, // 3: the default branch
+		opcodes.ICONST_1,       // 6: the key, which is not in the table
+		opcodes.LOOKUPSWITCH,   // 7: needs no padding, as its operands start at 8
+		0xFF, 0xFF, 0xFF, 0xFC, // default: 7 - 4 = 3
+		0x00, 0x00, 0x00, 0x01, // npairs
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x11, // 0: 7 + 17 = 24
+		opcodes.RETURN) // 24
+	f.Locals = append(f.Locals, int64(0))
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("LOOKUPSWITCH: unexpected error: %v", err)
+	}
+	if f.Locals[0] != int64(5) {
+		t.Errorf("LOOKUPSWITCH: expected the backward default branch to store 5, got %v", f.Locals[0])
+	}
+}
+
+// LOOKUPSWITCH: in a 64-case switch, each key, including negative ones, branches to its
+// own case, and keys not in the table, whether between, below, or above the cases'
+// keys, branch to the default
+func TestLookupswitchLargeTable(t *testing.T) {
+	const npairs = 64
+	const casesPC = 12 + npairs*8 // after the opcode, padding, default, npairs, and pairs
+	const defaultPC = casesPC + npairs*4
+
+	code := []byte{opcodes.LOOKUPSWITCH, 0x00, 0x00, 0x00} // operands start at 4
+	code = binary.BigEndian.AppendUint32(code, defaultPC)
+	code = binary.BigEndian.AppendUint32(code, npairs)
+	for i := 0; i < npairs; i++ {
+		code = binary.BigEndian.AppendUint32(code, uint32(int32(i*10-320))) // sorted keys
+		code = binary.BigEndian.AppendUint32(code, uint32(casesPC+i*4))
+	}
+	for i := 0; i < npairs; i++ { // each case stores its number
+		code = append(code, opcodes.BIPUSH, byte(i), opcodes.ISTORE_0, opcodes.RETURN)
+	}
+	code = append(code, opcodes.ICONST_M1, opcodes.ISTORE_0, opcodes.RETURN) // the default stores -1
+
+	runSwitch := func(key int64) int64 {
+		f := newFrame(opcodes.NOP)
+		f.Meth = code
+		f.Locals = append(f.Locals, int64(0))
+		push(&f, key)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		if err := runFrame(fs); err != nil {
+			t.Fatalf("LOOKUPSWITCH on %d: unexpected error: %v", key, err)
+		}
+		return f.Locals[0].(int64)
+	}
+
+	for i := 0; i < npairs; i++ {
+		key := int64(i*10 - 320)
+		if ret := runSwitch(key); ret != int64(i) {
+			t.Errorf("LOOKUPSWITCH on %d: expected case %d, got %d", key, i, ret)
+		}
+	}
+	for _, key := range []int64{-321, -315, 5, 311, math.MaxInt32, math.MinInt32} {
+		if ret := runSwitch(key); ret != -1 {
+			t.Errorf("LOOKUPSWITCH on %d: expected the default, got case %d", key, ret)
+		}
+	}
+}
+
 // LMUL: pop 2 longs, multiply them, push result
 func TestLmul(t *testing.T) {
 	f := newFrame(opcodes.LMUL)
@@ -520,6 +592,42 @@ func TestLushr(t *testing.T) {
 	}
 }
 
+// the shift amounts of the long shifts are masked to their bottom six bits, so a shift
+// by 64 leaves the value as is, and a shift by 65 is a shift by 1. LUSHR shifts in zeros.
+func TestLongShiftMasking(t *testing.T) {
+	tests := []struct {
+		name     string
+		opcode   byte
+		value    int64
+		shiftBy  int64
+		expected int64
+	}{
+		{"LSHL by 64", opcodes.LSHL, 22, 64, 22},
+		{"LSHL by 65", opcodes.LSHL, 22, 65, 44},
+		{"LSHL by 63", opcodes.LSHL, 1, 63, math.MinInt64},
+		{"LSHR by 64", opcodes.LSHR, -200, 64, -200},
+		{"LSHR by 65", opcodes.LSHR, -200, 65, -100},
+		{"LSHR -1 by 63", opcodes.LSHR, -1, 63, -1},
+		{"LUSHR by 64", opcodes.LUSHR, -200, 64, -200},
+		{"LUSHR by 65", opcodes.LUSHR, 200, 65, 100},
+		{"LUSHR -1 by 1", opcodes.LUSHR, -1, 1, math.MaxInt64},
+		{"LUSHR -1 by 60", opcodes.LUSHR, -1, 60, 15},
+		{"LUSHR MIN_VALUE by 63", opcodes.LUSHR, math.MinInt64, 63, 1},
+	}
+	for _, tt := range tests {
+		f := newFrame(tt.opcode)
+		push(&f, tt.value) // longs require two slots, so pushed twice
+		push(&f, tt.value)
+		push(&f, tt.shiftBy)
+		fs := frames.CreateFrameStack()
+		fs.PushFront(&f) // push the new frame
+		_ = runFrame(fs)
+		if value := pop(&f).(int64); value != tt.expected {
+			t.Errorf("%s: expected %d, got: %d", tt.name, tt.expected, value)
+		}
+	}
+}
+
 // LXOR: Logical XOR of two longs
 func TestLxor(t *testing.T) {
 	f := newFrame(opcodes.LXOR)
@@ -544,7 +652,7 @@ func TestLxor(t *testing.T) {
 	}
 }
 
-// MONITORENTER: The JDK JVM does not implement this, nor do we. So just pop the ref off stack
+// MONITORENTER: For a ref that is not a Java object, this just pops the ref off the stack
 func TestMonitorEnter(t *testing.T) {
 	f := newFrame(opcodes.MONITORENTER)
 	push(&f, &f) // push any value and make sure it gets popped off
@@ -558,7 +666,7 @@ func TestMonitorEnter(t *testing.T) {
 	}
 }
 
-// MONITOREXIT: The JDK JVM does not implement this, nor do we. So just pop the ref off stack
+// MONITOREXIT: For a ref that is not a Java object, this just pops the ref off the stack
 func TestMonitorExit(t *testing.T) {
 	f := newFrame(opcodes.MONITOREXIT)
 	push(&f, &f) // push any value and make sure it gets popped off
@@ -572,6 +680,89 @@ func TestMonitorExit(t *testing.T) {
 	}
 }
 
+// MONITORENTER: A null monitor throws a NullPointerException
+func TestMonitorEnterNull(t *testing.T) {
+	globals.InitGlobals("test")
+	f := newFrame(opcodes.MONITORENTER)
+	f.ClName = "Sync"
+	f.MethName = "run"
+	push(&f, object.Null)
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	err := runFrame(fs)
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil || !strings.Contains(err.Error(), "null monitor") {
+		t.Errorf("MONITORENTER: expected a null monitor error, got: %v", err)
+	}
+	if f.TOS != -1 {
+		t.Errorf("MONITORENTER: Expected an empty stack, but got a tos of: %d", f.TOS)
+	}
+}
+
+// MONITOREXIT: Exiting a monitor that was not entered throws an IllegalMonitorStateException
+func TestMonitorExitNotEntered(t *testing.T) {
+	globals.InitGlobals("test")
+	f := newFrame(opcodes.MONITOREXIT)
+	f.ClName = "Sync"
+	f.MethName = "run"
+	push(&f, object.MakeEmptyObject())
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	err := runFrame(fs)
+	_ = w.Close()
+	os.Stderr = normalStderr
+
+	if err == nil || !strings.Contains(err.Error(), "monitor not entered") {
+		t.Errorf("MONITOREXIT: expected a monitor not entered error, got: %v", err)
+	}
+}
+
+// MONITORENTER/MONITOREXIT: A synchronized block, nested in another one on the same object,
+// as javac compiles it: synchronized (lock) { synchronized (lock) { x = 5; } }
+// (lock is in local 0, the monitors' copies of it in locals 1 and 2, and x in local 3)
+func TestSynchronizedBlockNormalExit(t *testing.T) {
+	globals.InitGlobals("test")
+	f := newFrame(opcodes.ALOAD_0)
+	f.Meth = append(f.Meth, []byte{
+		opcodes.DUP, opcodes.ASTORE_1, opcodes.MONITORENTER,
+		opcodes.ALOAD_0, opcodes.DUP, opcodes.ASTORE_2, opcodes.MONITORENTER,
+		opcodes.ICONST_5, opcodes.ISTORE_3,
+		opcodes.ALOAD_2, opcodes.MONITOREXIT,
+		opcodes.ALOAD_1, opcodes.MONITOREXIT,
+		opcodes.RETURN,
+	}...)
+	lock := object.MakeEmptyObject()
+	f.Locals = []interface{}{lock, nil, nil, int64(0)}
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("synchronized block: unexpected error: %s", err.Error())
+	}
+
+	if f.Locals[3] != int64(5) {
+		t.Errorf("synchronized block: expected the block to set x to 5, got: %v", f.Locals[3])
+	}
+	if f.TOS != -1 {
+		t.Errorf("synchronized block: Expected an empty stack, but got a tos of: %d", f.TOS)
+	}
+	if count := object.MonitorEntryCount(lock); count != 0 {
+		t.Errorf("synchronized block: expected the monitor to be exited, but its entry count is: %d", count)
+	}
+}
+
 // NEW: Instantiate object -- here with an error
 func TestNewWithError(t *testing.T) {
 	f := newFrame(opcodes.NEW)
@@ -601,6 +792,335 @@ func TestNewWithError(t *testing.T) {
 	}
 }
 
+// runWithMissingClass runs Loader.load()V, whose code begins with a bytecode, either NEW
+// or INVOKESTATIC, that is given in op and refers to app/Missing, a class that can't be
+// found. The bytecode is covered by a handler for NoClassDefFoundError:
+//
+//	try { <op> app/Missing; result = 1; } catch (NoClassDefFoundError e) { result = -1; }
+//
+// Returns the frame of load(), whose local 0 holds the result and local 1 the error caught.
+func runWithMissingClass(t *testing.T, op byte, operand byte) *frames.Frame {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	normalStderr := os.Stderr
+	_, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { _ = w.Close(); os.Stderr = normalStderr }()
+
+	missingName := "app/Missing"
+	ncdfeName := "java/lang/NoClassDefFoundError"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 7)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0}
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // app/Missing
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 1} // java/lang/NoClassDefFoundError
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 2, NameAndType: 3}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&missingName), stringPool.GetStringIndex(&ncdfeName)}
+	CP.Utf8Refs = []string{"run", "()V"}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 4, DescIndex: 5}}
+
+	classloader.MethAreaInsert(ncdfeName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            ncdfeName,
+			NameIndex:       stringPool.GetStringIndex(&ncdfeName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitRun,
+		},
+	})
+
+	meth := classloader.JmEntry{
+		MaxStack:  2,
+		MaxLocals: 2,
+		Cp:        &CP,
+		Code: []byte{
+			op, 0x00, operand, opcodes.NOP, opcodes.ICONST_1, opcodes.ISTORE_0, opcodes.RETURN,
+			opcodes.ASTORE_1, opcodes.ICONST_M1, opcodes.ISTORE_0, opcodes.RETURN,
+		},
+		Exceptions: []classloader.CodeException{{StartPc: 0, EndPc: 4, HandlerPc: 7, CatchType: 6}},
+	}
+	if op == opcodes.NEW {
+		meth.Code[3] = opcodes.POP // discard the object, if one were created
+	}
+	classloader.MTable["Loader.load()V"] = classloader.MTentry{MType: 'J', Meth: meth}
+
+	f := frames.CreateFrame(meth.MaxStack)
+	f.Ftype = 'J'
+	f.ClName = "Loader"
+	f.MethName = "load"
+	f.MethType = "()V"
+	f.CP = &CP
+	f.Meth = meth.Code
+	f.Locals = make([]interface{}, meth.MaxLocals)
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(f)
+	if err := runFrame(fs); err != nil {
+		t.Fatalf("expected the NoClassDefFoundError to be caught, got: %s", err.Error())
+	}
+	return f
+}
+
+// checkMissingClassCaught checks that the program run by runWithMissingClass() caught a
+// NoClassDefFoundError naming the missing class
+func checkMissingClassCaught(t *testing.T, f *frames.Frame) {
+	if f.Locals[0] != int64(-1) {
+		t.Errorf("expected the catch block to set -1, got: %v", f.Locals[0])
+	}
+	exc, ok := f.Locals[1].(*object.Object)
+	if !ok {
+		t.Fatalf("expected the error object in local 1, got: %T", f.Locals[1])
+	}
+	if excClass := object.GoStringFromStringPoolIndex(exc.KlassName); excClass != "java/lang/NoClassDefFoundError" {
+		t.Errorf("expected a NoClassDefFoundError, got: %s", excClass)
+	}
+	msg := object.GoStringFromStringObject(exc.FieldTable["detailMessage"].Fvalue.(*object.Object))
+	if msg != "app/Missing" {
+		t.Errorf("expected the error message to name app/Missing, got: %s", msg)
+	}
+}
+
+// NEW: a class that can't be found results in a NoClassDefFoundError, which the program can catch
+func TestNewMissingClassIsCaught(t *testing.T) {
+	f := runWithMissingClass(t, opcodes.NEW, 0x02)
+	checkMissingClassCaught(t, f)
+}
+
+// loadConstructorTestClasses puts two small classes into the method area:
+//
+//	class Base { int id; Base() { super(); id = 7; } }
+//	class Point extends Base { int x; Object name; Point(Object n) { super(); x = 42; name = n; } }
+//
+// Both classes and their constructors share the returned CP. Point's constructor is
+// referenced by CP entry 7, and Point's class ref by CP entry 1.
+func loadConstructorTestClasses() *classloader.CPool {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+
+	pointName := "Point"
+	baseName := "Base"
+	pointIndex := stringPool.GetStringIndex(&pointName)
+	baseIndex := stringPool.GetStringIndex(&baseName)
+
+	CP := classloader.CPool{}
+	CP.Utf8Refs = []string{"<init>", "()V", "id", "I", "x", "name", "Ljava/lang/Object;",
+		"(Ljava/lang/Object;)V"}
+	CP.ClassRefs = []uint32{pointIndex, baseIndex, types.ObjectPoolStringIndex}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{
+		{NameIndex: 4, DescIndex: 5},   // <init>()V
+		{NameIndex: 10, DescIndex: 11}, // id I
+		{NameIndex: 14, DescIndex: 11}, // x I
+		{NameIndex: 15, DescIndex: 16}, // name Ljava/lang/Object;
+		{NameIndex: 4, DescIndex: 21},  // <init>(Ljava/lang/Object;)V
+	}
+	CP.MethodRefs = []classloader.MethodRefEntry{
+		{ClassIndex: 1, NameAndType: 22}, // Point.<init>(Ljava/lang/Object;)V
+		{ClassIndex: 2, NameAndType: 6},  // Base.<init>()V
+		{ClassIndex: 3, NameAndType: 6},  // java/lang/Object.<init>()V
+	}
+	CP.FieldRefs = []classloader.FieldRefEntry{
+		{ClassIndex: 2, NameAndType: 12}, // Base.id
+		{ClassIndex: 1, NameAndType: 13}, // Point.x
+		{ClassIndex: 1, NameAndType: 17}, // Point.name
+	}
+	CP.CpIndex = []classloader.CpEntry{
+		{Type: 0, Slot: 0},
+		{Type: classloader.ClassRef, Slot: 0},    // 1 Point
+		{Type: classloader.ClassRef, Slot: 1},    // 2 Base
+		{Type: classloader.ClassRef, Slot: 2},    // 3 java/lang/Object
+		{Type: classloader.UTF8, Slot: 0},        // 4 <init>
+		{Type: classloader.UTF8, Slot: 1},        // 5 ()V
+		{Type: classloader.NameAndType, Slot: 0}, // 6
+		{Type: classloader.MethodRef, Slot: 0},   // 7 Point.<init>
+		{Type: classloader.MethodRef, Slot: 1},   // 8 Base.<init>
+		{Type: classloader.MethodRef, Slot: 2},   // 9 java/lang/Object.<init>
+		{Type: classloader.UTF8, Slot: 2},        // 10 id
+		{Type: classloader.UTF8, Slot: 3},        // 11 I
+		{Type: classloader.NameAndType, Slot: 1}, // 12
+		{Type: classloader.NameAndType, Slot: 2}, // 13
+		{Type: classloader.UTF8, Slot: 4},        // 14 x
+		{Type: classloader.UTF8, Slot: 5},        // 15 name
+		{Type: classloader.UTF8, Slot: 6},        // 16 Ljava/lang/Object;
+		{Type: classloader.NameAndType, Slot: 3}, // 17
+		{Type: classloader.FieldRef, Slot: 0},    // 18 Base.id
+		{Type: classloader.FieldRef, Slot: 1},    // 19 Point.x
+		{Type: classloader.FieldRef, Slot: 2},    // 20 Point.name
+		{Type: classloader.UTF8, Slot: 7},        // 21 (Ljava/lang/Object;)V
+		{Type: classloader.NameAndType, Slot: 4}, // 22
+	}
+
+	classloader.MethAreaInsert(baseName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            baseName,
+			NameIndex:       baseIndex,
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			Fields:          []classloader.Field{{Name: 2, Desc: 3}},
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+		},
+	})
+	classloader.MethAreaInsert(pointName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            pointName,
+			NameIndex:       pointIndex,
+			SuperclassIndex: baseIndex,
+			Fields:          []classloader.Field{{Name: 4, Desc: 3}, {Name: 5, Desc: 6}},
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+		},
+	})
+
+	classloader.MTable["Base.<init>()V"] = classloader.MTentry{
+		MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  2,
+			MaxLocals: 1,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.ALOAD_0,
+				opcodes.INVOKESPECIAL, 0x00, 0x09, // super()
+				opcodes.ALOAD_0,
+				opcodes.BIPUSH, 0x07,
+				opcodes.PUTFIELD, 0x00, 0x12, // id = 7
+				opcodes.RETURN,
+			},
+		},
+	}
+	classloader.MTable["Point.<init>(Ljava/lang/Object;)V"] = classloader.MTentry{
+		MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  2,
+			MaxLocals: 2,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.ALOAD_0,
+				opcodes.INVOKESPECIAL, 0x00, 0x08, // super()
+				opcodes.ALOAD_0,
+				opcodes.BIPUSH, 0x2A,
+				opcodes.PUTFIELD, 0x00, 0x13, // x = 42
+				opcodes.ALOAD_0,
+				opcodes.ALOAD_1,
+				opcodes.PUTFIELD, 0x00, 0x14, // name = n
+				opcodes.RETURN,
+			},
+		},
+	}
+	return &CP
+}
+
+// NEW: a freshly allocated object of a user class has its own and its superclass's
+// instance fields, all set to their default values.
+func TestNewUserClassDefaults(t *testing.T) {
+	CP := loadConstructorTestClasses()
+
+	f := newFrame(opcodes.NEW)
+	f.Meth = append(f.Meth, 0x00)
+	f.Meth = append(f.Meth, 0x01) // Go to slot 0x0001 in the CP, the class ref for Point
+	f.CP = CP
+
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	err := runFrame(fs)
+	if err != nil {
+		t.Fatalf("NEW: unexpected error: %s", err.Error())
+	}
+
+	obj := pop(&f).(*object.Object)
+	if object.GoStringFromStringPoolIndex(obj.KlassName) != "Point" {
+		t.Errorf("NEW: expected an object of class Point, got %s",
+			object.GoStringFromStringPoolIndex(obj.KlassName))
+	}
+	if obj.FieldTable["id"].Fvalue != int64(0) {
+		t.Errorf("NEW: expected inherited field id to default to 0, got %v", obj.FieldTable["id"].Fvalue)
+	}
+	if obj.FieldTable["x"].Fvalue != int64(0) {
+		t.Errorf("NEW: expected field x to default to 0, got %v", obj.FieldTable["x"].Fvalue)
+	}
+	if !object.IsNull(obj.FieldTable["name"].Fvalue) {
+		t.Errorf("NEW: expected field name to default to null, got %v", obj.FieldTable["name"].Fvalue)
+	}
+}
+
+// NEW + INVOKESPECIAL: new Point(arg) runs Point's constructor, which chains to Base's
+// constructor and then to Object's. Each constructor gets the new object in local 0.
+func TestNewAndInvokespecialConstructorChain(t *testing.T) {
+	CP := loadConstructorTestClasses()
+
+	arg := object.MakeEmptyObject()
+	f := newFrame(opcodes.NEW)
+	f.Meth = append(f.Meth, []byte{
+		0x00, 0x01, // new Point
+		opcodes.DUP,
+		opcodes.ALOAD_0,
+		opcodes.INVOKESPECIAL, 0x00, 0x07, // Point.<init>(Ljava/lang/Object;)V
+		opcodes.ASTORE_1,
+	}...)
+	f.CP = CP
+	f.Locals = []interface{}{arg, int64(0)}
+
+	th := thread.CreateThread()
+	th.Stack = frames.CreateFrameStack()
+	th.Stack.PushFront(&f)
+	err := runThread(&th)
+	if err != nil {
+		t.Fatalf("NEW/INVOKESPECIAL: unexpected error: %s", err.Error())
+	}
+
+	if f.TOS != -1 {
+		t.Errorf("NEW/INVOKESPECIAL: expected an empty stack, got TOS of %d", f.TOS)
+	}
+	obj, ok := f.Locals[1].(*object.Object)
+	if !ok || object.IsNull(obj) {
+		t.Fatalf("NEW/INVOKESPECIAL: expected the new object in local 1, got %v", f.Locals[1])
+	}
+	if obj.FieldTable["id"].Fvalue != int64(7) {
+		t.Errorf("NEW/INVOKESPECIAL: expected id set by Base's constructor to be 7, got %v",
+			obj.FieldTable["id"].Fvalue)
+	}
+	if obj.FieldTable["x"].Fvalue != int64(42) {
+		t.Errorf("NEW/INVOKESPECIAL: expected x to be 42, got %v", obj.FieldTable["x"].Fvalue)
+	}
+	if obj.FieldTable["name"].Fvalue != arg {
+		t.Errorf("NEW/INVOKESPECIAL: expected name to be the constructor's argument, got %v",
+			obj.FieldTable["name"].Fvalue)
+	}
+}
+
+// NOP: no operation. The PC advances to the next bytecode and the stack is untouched.
+func TestNop(t *testing.T) {
+	f := newFrame(opcodes.NOP)
+	push(&f, int64(42))
+	fs := frames.CreateFrameStack()
+	fs.PushFront(&f) // push the new frame
+	_ = runFrame(fs)
+
+	if f.PC != 1 {
+		t.Errorf("NOP: Expecting PC = 1, got: %d", f.PC)
+	}
+	if f.TOS != 0 {
+		t.Fatalf("NOP: Expecting TOS to be unchanged at 0, but tos is: %d", f.TOS)
+	}
+	if value := pop(&f).(int64); value != 42 {
+		t.Errorf("NOP: Expecting the value on the stack to be unchanged at 42, got: %d", value)
+	}
+}
+
 // PEEK: test peek, stack underflow
 func TestPeekWithStackUnderflow(t *testing.T) {
 	normalStderr := os.Stderr
@@ -1287,6 +1807,22 @@ func TestWideIINC(t *testing.T) {
 	}
 }
 
+// WIDE version of IINC with a negative increment, which is a signed 16-bit value
+func TestWideIINCNegative(t *testing.T) {
+	globals.InitGlobals("test")
+	_ = log.SetLogLevel(log.WARNING)
+
+	f := newFrame(opcodes.WIDE)
+	f.Meth = append(f.Meth, opcodes.IINC, 0x00, 0x01, 0xFE, 0x0C) // increment local 1 by -500
+	fs := frames.CreateFrameStack()
+	f.Locals = append(f.Locals, int64(0), int64(math.MinInt32+100))
+	fs.PushFront(&f) // push the new frame
+	_ = runFrame(fs)
+	if f.Locals[1] != int64(math.MaxInt32-399) {
+		t.Errorf("WIDE,IINC: expected result of %d, got: %d", math.MaxInt32-399, f.Locals[1])
+	}
+}
+
 // WIDE version of ILOAD (covers FLOAD AND ALOAD as well b/c they use the same logic)
 func TestWideILOAD(t *testing.T) {
 	globals.InitGlobals("test")
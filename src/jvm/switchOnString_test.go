@@ -0,0 +1,182 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"encoding/binary"
+	"jacobin/classloader"
+	"jacobin/frames"
+	"jacobin/gfunction"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/opcodes"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"testing"
+)
+
+// switchOnStringCode returns the bytecode that javac generates for this method, in which
+// "Aa" and "BB" have the same hash code, so they share a case of the LOOKUPSWITCH:
+//
+//	static int pick(String s) {
+//	    switch (s) {
+//	        case "apple":  return 1;
+//	        case "banana": return 2;
+//	        case "Aa":     return 3;
+//	        case "BB":     return 4;
+//	        default:       return 0;
+//	    }
+//	}
+//
+// The CP entries it uses are: #1 String.hashCode(), #2 String.equals(), and
+// #3-#6 the strings "apple", "banana", "Aa", and "BB".
+func switchOnStringCode() []byte {
+	var code []byte
+	labels := make(map[string]int)
+	type fixup struct {
+		at, from int // where the offset goes and the PC of the instruction it's relative to
+		label    string
+		wide     bool // a 4-byte switch offset, rather than a 2-byte branch offset
+	}
+	var fixups []fixup
+
+	emit := func(b ...byte) { code = append(code, b...) }
+	mark := func(label string) { labels[label] = len(code) }
+	branch := func(opcode byte, label string) {
+		fixups = append(fixups, fixup{len(code) + 1, len(code), label, false})
+		emit(opcode, 0, 0)
+	}
+	// a switch's default offset and its case offsets are relative to the switch's opcode
+	switchTarget := func(from int, label string) {
+		fixups = append(fixups, fixup{len(code), from, label, true})
+		emit(0, 0, 0, 0)
+	}
+	int32Bytes := func(i int32) []byte { return binary.BigEndian.AppendUint32(nil, uint32(i)) }
+	// if (s.equals(<CP string>)) { index = value; } and on to the second switch
+	matchCase := func(label string, cpString byte, value byte, noMatch string) {
+		mark(label)
+		emit(opcodes.ALOAD_1, opcodes.LDC, cpString, opcodes.INVOKEVIRTUAL, 0x00, 0x02)
+		branch(opcodes.IFEQ, noMatch)
+		emit(value, opcodes.ISTORE_2)
+		branch(opcodes.GOTO, "switch2")
+	}
+
+	// String tmp = s; int index = -1; switch (tmp.hashCode()) { ... }
+	emit(opcodes.ALOAD_0, opcodes.ASTORE_1, opcodes.ICONST_M1, opcodes.ISTORE_2,
+		opcodes.ALOAD_1, opcodes.INVOKEVIRTUAL, 0x00, 0x01)
+	switch1 := len(code)
+	emit(opcodes.LOOKUPSWITCH)
+	for len(code)%4 != 0 {
+		emit(0)
+	}
+	switchTarget(switch1, "switch2") // default
+	emit(int32Bytes(3)...)           // npairs, in ascending order of hash code
+	emit(int32Bytes(-1396355227)...) // "banana".hashCode()
+	switchTarget(switch1, "banana")
+	emit(int32Bytes(2112)...) // "Aa".hashCode() and "BB".hashCode()
+	switchTarget(switch1, "Aa")
+	emit(int32Bytes(93029210)...) // "apple".hashCode()
+	switchTarget(switch1, "apple")
+
+	matchCase("banana", 4, opcodes.ICONST_1, "switch2")
+	matchCase("Aa", 5, opcodes.ICONST_2, "BB") // on to the next string with the same hash
+	matchCase("BB", 6, opcodes.ICONST_3, "switch2")
+	matchCase("apple", 3, opcodes.ICONST_0, "switch2")
+
+	// switch (index) { case 0: return 1; ... default: return 0; }
+	mark("switch2")
+	emit(opcodes.ILOAD_2)
+	switch2 := len(code)
+	emit(opcodes.TABLESWITCH)
+	for len(code)%4 != 0 {
+		emit(0)
+	}
+	switchTarget(switch2, "return0") // default
+	emit(int32Bytes(0)...)           // low
+	emit(int32Bytes(3)...)           // high
+	for _, label := range []string{"return1", "return2", "return3", "return4"} {
+		switchTarget(switch2, label)
+	}
+	for i, label := range []string{"return0", "return1", "return2", "return3", "return4"} {
+		mark(label)
+		emit(opcodes.ICONST_0+byte(i), opcodes.IRETURN)
+	}
+
+	for _, fix := range fixups {
+		offset := labels[fix.label] - fix.from
+		if fix.wide {
+			binary.BigEndian.PutUint32(code[fix.at:], uint32(int32(offset)))
+		} else {
+			binary.BigEndian.PutUint16(code[fix.at:], uint16(int16(offset)))
+		}
+	}
+	return code
+}
+
+// javac compiles switch on a string to a LOOKUPSWITCH on the string's hashCode(), with
+// an equals() check for each string that has that hash code, which sets the index used by
+// a second switch, on which the cases' code is executed.
+func TestSwitchOnString(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 18)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0} // String.hashCode()
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 1} // String.equals()
+	// #3-#6: the strings "apple", "banana", "Aa", and "BB"
+	for i := 0; i < 4; i++ {
+		CP.CpIndex[3+i] = classloader.CpEntry{Type: classloader.StringConst, Slot: uint16(14 + i)}
+	}
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0} // java/lang/String
+	CP.CpIndex[8] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[9] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 1}
+	for i := 0; i < 8; i++ { // #10-#17: the UTF-8 strings
+		CP.CpIndex[10+i] = classloader.CpEntry{Type: classloader.UTF8, Slot: uint16(i)}
+	}
+	CP.MethodRefs = []classloader.MethodRefEntry{{ClassIndex: 7, NameAndType: 8}, {ClassIndex: 7, NameAndType: 9}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&types.StringClassName)}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{{NameIndex: 10, DescIndex: 11}, {NameIndex: 12, DescIndex: 13}}
+	CP.Utf8Refs = []string{"hashCode", "()I", "equals", "(Ljava/lang/Object;)Z", "apple", "banana", "Aa", "BB"}
+
+	code := switchOnStringCode()
+	tests := []struct {
+		str      string
+		expected int64
+	}{
+		{"apple", 1},
+		{"banana", 2},
+		{"Aa", 3},
+		{"BB", 4},
+		{"cherry", 0}, // a hash code that's not in the LOOKUPSWITCH
+		{"C#", 0},     // the hash code of "Aa" and "BB", but equal to neither
+		{"", 0},
+	}
+	for _, tt := range tests {
+		catcher := frames.CreateFrame(1) // receives the return value
+		f := frames.CreateFrame(4)
+		f.ClName = "Fruit"
+		f.MethName = "pick"
+		f.MethType = "(Ljava/lang/String;)I"
+		f.CP = &CP
+		f.Meth = code
+		f.Locals = []interface{}{object.StringObjectFromGoString(tt.str), nil, nil}
+
+		fs := frames.CreateFrameStack()
+		fs.PushFront(catcher)
+		fs.PushFront(f)
+		if err := runFrame(fs); err != nil {
+			t.Fatalf("pick(%q): unexpected error: %v", tt.str, err)
+		}
+		if got := pop(catcher); got != tt.expected {
+			t.Errorf("pick(%q): expected %d, got %v", tt.str, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package jvm
+
+import (
+	"jacobin/classloader"
+	"jacobin/frames"
+	"jacobin/gfunction"
+	"jacobin/globals"
+	"jacobin/log"
+	"jacobin/object"
+	"jacobin/opcodes"
+	"jacobin/statics"
+	"jacobin/stringPool"
+	"jacobin/types"
+	"testing"
+)
+
+// Counter is a class like the base classes that update a field atomically:
+//
+//	class Counter {
+//	    private static final Unsafe U = Unsafe.getUnsafe();
+//	    private static final long COUNT_OFFSET = U.objectFieldOffset(Counter.class, "count");
+//	    private volatile int count;
+//	    boolean start() { return U.compareAndSetInt(this, COUNT_OFFSET, 0, 5); }
+//	}
+//
+// Its <clinit>() must run to completion, and start() must then update count.
+func TestClinitUsingUnsafe(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	classloader.MTable = make(map[string]classloader.MTentry)
+	gfunction.MTableLoadGFunctions(&classloader.MTable)
+	statics.Statics = make(map[string]statics.Static)
+
+	counterName := "Counter"
+	unsafeName := "jdk/internal/misc/Unsafe"
+
+	CP := classloader.CPool{}
+	CP.CpIndex = make([]classloader.CpEntry, 24)
+	CP.CpIndex[1] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 0} // Unsafe.getUnsafe()
+	CP.CpIndex[2] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 0}  // Unsafe
+	CP.CpIndex[3] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 0}
+	CP.CpIndex[4] = classloader.CpEntry{Type: classloader.UTF8, Slot: 0}
+	CP.CpIndex[5] = classloader.CpEntry{Type: classloader.UTF8, Slot: 1}
+	CP.CpIndex[6] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 1} // Unsafe.objectFieldOffset1()
+	CP.CpIndex[7] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 1}
+	CP.CpIndex[8] = classloader.CpEntry{Type: classloader.UTF8, Slot: 2}
+	CP.CpIndex[9] = classloader.CpEntry{Type: classloader.UTF8, Slot: 3}
+	CP.CpIndex[10] = classloader.CpEntry{Type: classloader.ClassRef, Slot: 1} // Counter
+	CP.CpIndex[11] = classloader.CpEntry{Type: classloader.UTF8, Slot: 4}     // "count"
+	CP.CpIndex[12] = classloader.CpEntry{Type: classloader.FieldRef, Slot: 0} // Counter.COUNT_OFFSET
+	CP.CpIndex[13] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 2}
+	CP.CpIndex[14] = classloader.CpEntry{Type: classloader.UTF8, Slot: 5}
+	CP.CpIndex[15] = classloader.CpEntry{Type: classloader.UTF8, Slot: 6}
+	CP.CpIndex[16] = classloader.CpEntry{Type: classloader.FieldRef, Slot: 1} // Counter.U
+	CP.CpIndex[17] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 3}
+	CP.CpIndex[18] = classloader.CpEntry{Type: classloader.UTF8, Slot: 7}
+	CP.CpIndex[19] = classloader.CpEntry{Type: classloader.UTF8, Slot: 8}
+	CP.CpIndex[20] = classloader.CpEntry{Type: classloader.MethodRef, Slot: 2} // Unsafe.compareAndSetInt()
+	CP.CpIndex[21] = classloader.CpEntry{Type: classloader.NameAndType, Slot: 4}
+	CP.CpIndex[22] = classloader.CpEntry{Type: classloader.UTF8, Slot: 9}
+	CP.CpIndex[23] = classloader.CpEntry{Type: classloader.UTF8, Slot: 10}
+	CP.MethodRefs = []classloader.MethodRefEntry{
+		{ClassIndex: 2, NameAndType: 3}, {ClassIndex: 2, NameAndType: 7}, {ClassIndex: 2, NameAndType: 21}}
+	CP.FieldRefs = []classloader.FieldRefEntry{{ClassIndex: 10, NameAndType: 13}, {ClassIndex: 10, NameAndType: 17}}
+	CP.ClassRefs = []uint32{stringPool.GetStringIndex(&unsafeName), stringPool.GetStringIndex(&counterName)}
+	CP.NameAndTypes = []classloader.NameAndTypeEntry{
+		{NameIndex: 4, DescIndex: 5}, {NameIndex: 8, DescIndex: 9}, {NameIndex: 14, DescIndex: 15},
+		{NameIndex: 18, DescIndex: 19}, {NameIndex: 22, DescIndex: 23}}
+	CP.Utf8Refs = []string{"getUnsafe", "()Ljdk/internal/misc/Unsafe;",
+		"objectFieldOffset1", "(Ljava/lang/Class;Ljava/lang/String;)J", "count",
+		"COUNT_OFFSET", types.Long, "U", "Ljdk/internal/misc/Unsafe;",
+		"compareAndSetInt", "(Ljava/lang/Object;JII)Z"}
+
+	classloader.MethAreaInsert(unsafeName, &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            unsafeName,
+			NameIndex:       stringPool.GetStringIndex(&unsafeName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			ClInit:          types.ClInitRun,
+		},
+	})
+	counterKlass := &classloader.Klass{
+		Status: 'X',
+		Loader: "test",
+		Data: &classloader.ClData{
+			Name:            counterName,
+			NameIndex:       stringPool.GetStringIndex(&counterName),
+			SuperclassIndex: types.ObjectPoolStringIndex,
+			MethodTable:     make(map[string]*classloader.Method),
+			CP:              CP,
+			ClInit:          types.ClInitNotRun,
+		},
+	}
+	classloader.MethAreaInsert(counterName, counterKlass)
+
+	// the static fields, as loading Counter leaves them
+	_ = statics.AddStatic("Counter.U", statics.Static{Type: "Ljdk/internal/misc/Unsafe;", Value: object.Null})
+	_ = statics.AddStatic("Counter.COUNT_OFFSET", statics.Static{Type: types.Long, Value: int64(0)})
+
+	classloader.MTable["Counter.<clinit>()V"] = classloader.MTentry{
+		MType: 'J',
+		Meth: classloader.JmEntry{
+			MaxStack:  6,
+			MaxLocals: 0,
+			Cp:        &CP,
+			Code: []byte{
+				opcodes.INVOKESTATIC, 0x00, 0x01, // U = Unsafe.getUnsafe()
+				opcodes.PUTSTATIC, 0x00, 0x10,
+				opcodes.GETSTATIC, 0x00, 0x10, // COUNT_OFFSET = U.objectFieldOffset(Counter.class, "count")
+				opcodes.LDC, 0x0A,
+				opcodes.LDC, 0x0B,
+				opcodes.INVOKEVIRTUAL, 0x00, 0x06,
+				opcodes.PUTSTATIC, 0x00, 0x0C,
+				opcodes.RETURN},
+		},
+	}
+
+	fs := frames.CreateFrameStack()
+	if err := runInitializationBlock(counterKlass, nil, fs); err != nil {
+		t.Fatalf("Counter.<clinit>(): unexpected error: %v", err)
+	}
+	if counterKlass.Data.ClInit != types.ClInitRun {
+		t.Errorf("Counter.<clinit>(): expected the class to be initialized, got status %d", counterKlass.Data.ClInit)
+	}
+	if u := statics.GetStaticValue("Counter", "U"); object.IsNull(u) {
+		t.Errorf("Counter.U: expected the Unsafe instance, got null")
+	}
+
+	// boolean start() { return U.compareAndSetInt(this, COUNT_OFFSET, 0, 5); }
+	counter := object.MakeEmptyObjectWithClassName(&counterName)
+	counter.FieldTable["count"] = object.Field{Ftype: types.Int, Fvalue: int64(0)}
+	start := []byte{
+		opcodes.GETSTATIC, 0x00, 0x10,
+		opcodes.ALOAD_1,
+		opcodes.GETSTATIC, 0x00, 0x0C,
+		opcodes.ICONST_0,
+		opcodes.ICONST_5,
+		opcodes.INVOKEVIRTUAL, 0x00, 0x14,
+		opcodes.IRETURN}
+	runStart := func() interface{} {
+		catcher := frames.CreateFrame(1) // receives the return value
+		f := frames.CreateFrame(6)
+		f.ClName = counterName
+		f.MethName = "start"
+		f.CP = &CP
+		f.Meth = start
+		f.Locals = []interface{}{nil, counter}
+
+		fs := frames.CreateFrameStack()
+		fs.PushFront(catcher)
+		fs.PushFront(f)
+		if err := runFrame(fs); err != nil {
+			t.Fatalf("start(): unexpected error: %v", err)
+		}
+		return pop(catcher)
+	}
+
+	if ret := runStart(); ret != types.JavaBoolTrue {
+		t.Errorf("first start(): expected true, got %v", ret)
+	}
+	if got := counter.FieldTable["count"].Fvalue; got != int64(5) {
+		t.Errorf("after start(): expected count to be 5, got %v", got)
+	}
+	if ret := runStart(); ret != types.JavaBoolFalse {
+		t.Errorf("second start(): expected false, as count is no longer 0, got %v", ret)
+	}
+}
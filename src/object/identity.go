@@ -0,0 +1,51 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package object
+
+import "sync/atomic"
+
+// The identity registry gives every object a stable 64-bit id, for use by the identity
+// hash code and, eventually, by identity-based structures such as IdentityHashMap and
+// WeakHashMap. Ids are handed out from a counter the first time an object's identity is
+// requested, so unlike the object's address they are never reused. The id is cached in
+// the object's mark word rather than in a table, so the registry holds no references to
+// objects and does not keep them from being garbage collected.
+
+// lastObjectId is the most recently assigned id. It's accessed only atomically.
+var lastObjectId uint64
+
+// ObjectId returns the object's id, assigning one if the object doesn't have one yet.
+// The id of null is 0; all other objects have ids greater than 0. It's safe for
+// concurrent use: if two threads request the id of a new object at the same time,
+// both get the same id.
+func ObjectId(obj *Object) uint64 {
+	if obj == nil {
+		return 0
+	}
+
+	id := atomic.LoadUint64(&obj.Mark.Id)
+	if id != 0 {
+		return id
+	}
+
+	id = atomic.AddUint64(&lastObjectId, 1)
+	if atomic.CompareAndSwapUint64(&obj.Mark.Id, 0, id) {
+		return id
+	}
+	return atomic.LoadUint64(&obj.Mark.Id) // another thread assigned the id first
+}
+
+// IdentityHashCode returns the value of System.identityHashCode() for the object: a
+// Java int derived from the object's id. The id is multiplied by an odd constant, so
+// that consecutive objects get well-spread hash codes, which remain distinct for the
+// first 2^32 objects. As in the JDK, the hash code of null is 0.
+func IdentityHashCode(obj *Object) int64 {
+	if IsNull(obj) {
+		return 0
+	}
+	return int64(int32(uint32(ObjectId(obj)) * 0x9E3779B9))
+}
@@ -0,0 +1,86 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package object
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestObjectIdIsStable(t *testing.T) {
+	obj := MakeEmptyObject()
+	id := ObjectId(obj)
+	if id == 0 {
+		t.Errorf("Expected a non-zero id for an object, got 0")
+	}
+	for i := 0; i < 3; i++ {
+		if ObjectId(obj) != id {
+			t.Errorf("Expected id %d on every call, got %d", id, ObjectId(obj))
+		}
+	}
+
+	hash := IdentityHashCode(obj)
+	for i := 0; i < 3; i++ {
+		if IdentityHashCode(obj) != hash {
+			t.Errorf("Expected hash code %d on every call, got %d", hash, IdentityHashCode(obj))
+		}
+	}
+}
+
+func TestObjectIdOfNull(t *testing.T) {
+	if ObjectId(Null) != 0 {
+		t.Errorf("Expected id of null to be 0, got %d", ObjectId(Null))
+	}
+	if IdentityHashCode(Null) != 0 {
+		t.Errorf("Expected identity hash code of null to be 0, got %d", IdentityHashCode(Null))
+	}
+}
+
+func TestObjectIdIsUniqueAcrossObjects(t *testing.T) {
+	ids := make(map[uint64]bool)
+	hashes := make(map[int64]bool)
+	for i := 0; i < 10000; i++ {
+		obj := MakeEmptyObject()
+		id := ObjectId(obj)
+		if ids[id] {
+			t.Fatalf("Id %d was assigned to two distinct objects", id)
+		}
+		ids[id] = true
+
+		hash := IdentityHashCode(obj)
+		if hash < -2147483648 || hash > 2147483647 {
+			t.Errorf("Identity hash code %d is not a Java int", hash)
+		}
+		if hashes[hash] {
+			t.Fatalf("Identity hash code %d was given to two distinct objects", hash)
+		}
+		hashes[hash] = true
+	}
+}
+
+// When many goroutines ask for the id of the same new object at once, all must get the same id.
+func TestObjectIdIsConcurrencySafe(t *testing.T) {
+	obj := MakeEmptyObject()
+	const goroutines = 16
+	results := make([]uint64, goroutines)
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = ObjectId(obj)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < goroutines; i++ {
+		if results[i] != results[0] {
+			t.Errorf("Expected all goroutines to get id %d, but one got %d", results[0], results[i])
+		}
+	}
+}
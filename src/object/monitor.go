@@ -0,0 +1,66 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package object
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Every object has a monitor, which the MONITORENTER and MONITOREXIT bytecodes of a
+// synchronized block enter and exit. A monitor is owned by one thread at a time: a thread
+// that enters a monitor owned by another thread waits until that thread has exited it.
+// Monitors are reentrant, so a thread can enter a monitor it already owns. The number of
+// entries not yet exited is counted in the object's mark word, and the monitor is released
+// when that count drops back to 0.
+//
+// The owners of monitors are kept in a table, rather than in the objects, so that all
+// waiting threads can wait on a single condition. An object is in the table only while
+// its monitor is owned, so the table does not keep objects from being garbage collected.
+
+var monitorLock sync.Mutex
+var monitorReleased = sync.NewCond(&monitorLock)
+var monitorOwners = make(map[*Object]int) // object -> ID of the thread owning its monitor
+
+// MonitorEnter enters the object's monitor on behalf of the given thread, waiting until
+// no other thread owns the monitor. It returns the number of times the thread has now
+// entered the monitor without exiting it.
+func MonitorEnter(obj *Object, thread int) uint32 {
+	monitorLock.Lock()
+	defer monitorLock.Unlock()
+	for {
+		owner, owned := monitorOwners[obj]
+		if !owned || owner == thread {
+			break
+		}
+		monitorReleased.Wait()
+	}
+	monitorOwners[obj] = thread
+	return atomic.AddUint32(&obj.Mark.Misc, 1)
+}
+
+// MonitorExit exits the object's monitor on behalf of the given thread. It returns false,
+// leaving the monitor as is, if the thread does not own the monitor, in which case the
+// JVM throws an IllegalMonitorStateException.
+func MonitorExit(obj *Object, thread int) bool {
+	monitorLock.Lock()
+	defer monitorLock.Unlock()
+	if owner, owned := monitorOwners[obj]; !owned || owner != thread {
+		return false
+	}
+	if atomic.AddUint32(&obj.Mark.Misc, ^uint32(0)) == 0 { // decrements the count
+		delete(monitorOwners, obj)
+		monitorReleased.Broadcast()
+	}
+	return true
+}
+
+// MonitorEntryCount returns the number of times the object's monitor has been entered
+// without being exited.
+func MonitorEntryCount(obj *Object) uint32 {
+	return atomic.LoadUint32(&obj.Mark.Misc)
+}
@@ -0,0 +1,82 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by the Jacobin Authors. All rights reserved.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0)  Consult jacobin.org.
+ */
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitorReentrantEntries(t *testing.T) {
+	obj := MakeEmptyObject()
+	if MonitorEnter(obj, 1) != 1 || MonitorEnter(obj, 1) != 2 {
+		t.Fatalf("Expected entry counts of 1 and 2, got %d", MonitorEntryCount(obj))
+	}
+
+	if !MonitorExit(obj, 1) || !MonitorExit(obj, 1) {
+		t.Fatalf("Expected both exits to succeed")
+	}
+	if MonitorEntryCount(obj) != 0 {
+		t.Errorf("Expected the monitor to be exited, but its entry count is %d", MonitorEntryCount(obj))
+	}
+}
+
+func TestMonitorExitWithoutEntry(t *testing.T) {
+	obj := MakeEmptyObject()
+	if MonitorExit(obj, 1) {
+		t.Errorf("Expected an exit without an entry to fail")
+	}
+	if MonitorEntryCount(obj) != 0 {
+		t.Errorf("Expected an entry count of 0 after a failed exit, got %d", MonitorEntryCount(obj))
+	}
+}
+
+// only the thread that owns a monitor can exit it
+func TestMonitorExitByOtherThread(t *testing.T) {
+	obj := MakeEmptyObject()
+	MonitorEnter(obj, 1)
+	if MonitorExit(obj, 2) {
+		t.Errorf("Expected an exit by a thread that doesn't own the monitor to fail")
+	}
+	if MonitorEntryCount(obj) != 1 {
+		t.Errorf("Expected an entry count of 1 after a failed exit, got %d", MonitorEntryCount(obj))
+	}
+	MonitorExit(obj, 1)
+}
+
+// a thread entering a monitor owned by another thread waits until the owner has exited
+// it as many times as it entered it
+func TestMonitorEnterWaitsForOwner(t *testing.T) {
+	obj := MakeEmptyObject()
+	MonitorEnter(obj, 1)
+	MonitorEnter(obj, 1)
+
+	entered := make(chan uint32)
+	go func() {
+		entered <- MonitorEnter(obj, 2)
+	}()
+
+	MonitorExit(obj, 1)
+	select {
+	case <-entered:
+		t.Fatal("Expected thread 2 to wait while thread 1 still owns the monitor")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	MonitorExit(obj, 1)
+	select {
+	case count := <-entered:
+		if count != 1 {
+			t.Errorf("Expected thread 2 to have entered the monitor once, got %d", count)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected thread 2 to enter the monitor once thread 1 released it")
+	}
+	if !MonitorExit(obj, 2) {
+		t.Error("Expected thread 2 to be able to exit the monitor it entered")
+	}
+}
@@ -27,12 +27,15 @@ type Object struct {
 }
 
 // These mark word contains values for different purposes. Here,
-// we use the first four bytes for a hash value, which is taken
-// from the address of the object. The 'misc' field will eventually
-// contain other values, such as locking and monitoring items.
+// we use the first eight bytes for the object's identity, which is
+// assigned on first use by ObjectId() (see identity.go), and the next
+// four bytes for a hash value, which is taken from the address of the
+// object. The 'misc' field counts the entries into the object's monitor
+// (see monitor.go).
 type MarkWord struct {
+	Id   uint64 // the object's identity; first, so it's 64-bit aligned for atomic access
 	Hash uint32 // contains hash code which is the lower 32 bits of the address
-	Misc uint32 // at present unused
+	Misc uint32 // the number of times the object's monitor is entered but not yet exited
 }
 
 // We need to know the type of the field only to tell whether
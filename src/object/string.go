@@ -21,6 +21,7 @@ package object
 import (
 	"jacobin/stringPool"
 	"jacobin/types"
+	"unicode/utf16"
 )
 
 // NewStringObject creates an empty string object (aka Java String)
@@ -160,3 +161,26 @@ func UpdateStringObjectFromBytes(objPtr *Object, argBytes []byte) {
 	fld := Field{Ftype: types.ByteArray, Fvalue: argBytes}
 	objPtr.FieldTable["value"] = fld
 }
+
+// JavaCharArrayFromGoString: convenience method to convert a Go string into the contents of a
+// Java char array. Java chars are UTF-16 code units, so characters outside the Basic Multilingual
+// Plane become a surrogate pair of two chars. (Jacobin stores char array elements as int64.)
+func JavaCharArrayFromGoString(str string) []int64 {
+	units := utf16.Encode([]rune(str))
+	chars := make([]int64, len(units))
+	for i, unit := range units {
+		chars[i] = int64(unit)
+	}
+	return chars
+}
+
+// GoStringFromJavaCharArray: convenience method to convert the contents of a Java char array
+// (UTF-16 code units stored as int64) into a Go string. Surrogate pairs are combined into the
+// code point they represent; unpaired surrogates become the Unicode replacement character.
+func GoStringFromJavaCharArray(chars []int64) string {
+	units := make([]uint16, len(chars))
+	for i, ch := range chars {
+		units[i] = uint16(ch)
+	}
+	return string(utf16.Decode(units))
+}
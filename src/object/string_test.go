@@ -210,3 +210,18 @@ func TestIsStringObject(t *testing.T) {
 		t.Errorf("expected IsStringObject(emptyObj) to be false, got true")
 	}
 }
+
+func TestJavaCharArrayRoundTrip(t *testing.T) {
+	globals.InitGlobals("test")
+
+	original := "plain, ñ, 日本, 𝄞"
+	chars := JavaCharArrayFromGoString(original)
+	if len(chars) != 16 { // the G clef is outside the BMP and so takes two chars
+		t.Errorf("expected 16 UTF-16 chars, got %d", len(chars))
+	}
+
+	str := GoStringFromJavaCharArray(chars)
+	if str != original {
+		t.Errorf("expected round trip to return '%s', got '%s'", original, str)
+	}
+}
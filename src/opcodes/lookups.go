@@ -0,0 +1,97 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by  the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package opcodes
+
+import "fmt"
+
+// VariableLength is the operand length of the instructions whose length depends on
+// their operands: TABLESWITCH, LOOKUPSWITCH, and WIDE. Use InstructionLength() for these.
+const VariableLength = -1
+
+// the number of operand bytes that follow each opcode. Opcodes not listed take none.
+var operandLengths = [256]int{
+	BIPUSH: 1, SIPUSH: 2, LDC: 1, LDC_W: 2, LDC2_W: 2,
+	ILOAD: 1, LLOAD: 1, FLOAD: 1, DLOAD: 1, ALOAD: 1,
+	ISTORE: 1, LSTORE: 1, FSTORE: 1, DSTORE: 1, ASTORE: 1,
+	IINC: 2,
+	IFEQ: 2, IFNE: 2, IFLT: 2, IFGE: 2, IFGT: 2, IFLE: 2,
+	IF_ICMPEQ: 2, IF_ICMPNE: 2, IF_ICMPLT: 2, IF_ICMPGE: 2, IF_ICMPGT: 2, IF_ICMPLE: 2,
+	IF_ACMPEQ: 2, IF_ACMPNE: 2, GOTO: 2, JSR: 2, RET: 1,
+	TABLESWITCH: VariableLength, LOOKUPSWITCH: VariableLength,
+	GETSTATIC: 2, PUTSTATIC: 2, GETFIELD: 2, PUTFIELD: 2,
+	INVOKEVIRTUAL: 2, INVOKESPECIAL: 2, INVOKESTATIC: 2, INVOKEINTERFACE: 4, INVOKEDYNAMIC: 4,
+	NEW: 2, NEWARRAY: 1, ANEWARRAY: 2, CHECKCAST: 2, INSTANCEOF: 2,
+	WIDE: VariableLength, MULTIANEWARRAY: 3, IFNULL: 2, IFNONNULL: 2, GOTO_W: 4, JSR_W: 4,
+}
+
+// BytecodeName returns the mnemonic for opcode, such as "IADD", or UNKNOWN(0xNN)
+// if opcode is not a valid bytecode.
+func BytecodeName(opcode byte) string {
+	if int(opcode) < len(BytecodeNames) {
+		return BytecodeNames[opcode]
+	}
+	return fmt.Sprintf("UNKNOWN(0x%02X)", opcode)
+}
+
+// OperandLength returns the number of operand bytes that follow opcode in the
+// bytecode, or VariableLength for TABLESWITCH, LOOKUPSWITCH, and WIDE.
+func OperandLength(opcode byte) int {
+	return operandLengths[opcode]
+}
+
+// InstructionLength returns the length in bytes, including the opcode, of the
+// instruction at code[pc]. Unlike OperandLength(), it works out the length of
+// TABLESWITCH, LOOKUPSWITCH, and WIDE instructions from their operands. It returns 0
+// if the instruction extends past the end of code.
+func InstructionLength(code []byte, pc int) int {
+	if pc < 0 || pc >= len(code) {
+		return 0
+	}
+
+	length := 1 + operandLengths[code[pc]]
+	switch code[pc] {
+	case WIDE: // WIDE IINC has a two-byte index and a two-byte constant; the others a two-byte index
+		if pc+1 < len(code) && code[pc+1] == IINC {
+			length = 6
+		} else {
+			length = 4
+		}
+	case TABLESWITCH, LOOKUPSWITCH:
+		// after the opcode come 0-3 bytes of padding, so that the operands begin on a
+		// 4-byte boundary, then the default offset. TABLESWITCH then has the low and high
+		// values and an offset for each value in between; LOOKUPSWITCH a count of pairs and
+		// the pairs of values and offsets.
+		base := pc + 1 + (3 - pc%4)
+		if base+8 > len(code) || (code[pc] == TABLESWITCH && base+12 > len(code)) {
+			return 0
+		}
+		if code[pc] == TABLESWITCH {
+			// the count of offsets is computed in 64 bits, as high-low+1 can overflow 32 bits
+			low, high := int64(fourBytes(code, base+4)), int64(fourBytes(code, base+8))
+			if high < low || high-low+1 > int64(len(code)) {
+				return 0
+			}
+			length = base - pc + 12 + 4*int(high-low+1)
+		} else {
+			npairs := fourBytes(code, base+4)
+			if npairs < 0 {
+				return 0
+			}
+			length = base - pc + 8 + 8*int(npairs)
+		}
+	}
+
+	if pc+length > len(code) {
+		return 0
+	}
+	return length
+}
+
+// fourBytes returns the signed 32-bit value stored big-endian in code[at:at+4]
+func fourBytes(code []byte, at int) int32 {
+	return int32(uint32(code[at])<<24 | uint32(code[at+1])<<16 | uint32(code[at+2])<<8 | uint32(code[at+3]))
+}
@@ -0,0 +1,85 @@
+/*
+ * Jacobin VM - A Java virtual machine
+ * Copyright (c) 2024 by  the Jacobin authors. Consult jacobin.org.
+ * Licensed under Mozilla Public License 2.0 (MPL 2.0) All rights reserved.
+ */
+
+package opcodes
+
+import "testing"
+
+func TestBytecodeNameAndOperandLength(t *testing.T) {
+	tests := []struct {
+		opcode byte
+		name   string
+		length int
+	}{
+		{NOP, "NOP", 0},
+		{IADD, "IADD", 0},
+		{BIPUSH, "BIPUSH", 1},
+		{SIPUSH, "SIPUSH", 2},
+		{LDC, "LDC", 1},
+		{ALOAD, "ALOAD", 1},
+		{IINC, "IINC", 2},
+		{IF_ACMPNE, "IF_ACMPNE", 2},
+		{INVOKEINTERFACE, "INVOKEINTERFACE", 4},
+		{MULTIANEWARRAY, "MULTIANEWARRAY", 3},
+		{GOTO_W, "GOTO_W", 4},
+		{TABLESWITCH, "TABLESWITCH", VariableLength},
+		{LOOKUPSWITCH, "LOOKUPSWITCH", VariableLength},
+		{WIDE, "WIDE", VariableLength},
+		{0xFE, "UNKNOWN(0xFE)", 0},
+	}
+
+	for _, tt := range tests {
+		if name := BytecodeName(tt.opcode); name != tt.name {
+			t.Errorf("BytecodeName(0x%02X): expected %s, got %s", tt.opcode, tt.name, name)
+		}
+		if length := OperandLength(tt.opcode); length != tt.length {
+			t.Errorf("OperandLength(%s): expected %d, got %d", tt.name, tt.length, length)
+		}
+	}
+}
+
+func TestInstructionLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   []byte
+		pc     int
+		length int
+	}{
+		{"IADD", []byte{IADD}, 0, 1},
+		{"SIPUSH", []byte{SIPUSH, 0x01, 0x00}, 0, 3},
+		{"truncated SIPUSH", []byte{SIPUSH, 0x01}, 0, 0},
+		{"WIDE ILOAD", []byte{WIDE, ILOAD, 0x01, 0x00}, 0, 4},
+		{"WIDE IINC", []byte{WIDE, IINC, 0x01, 0x00, 0x00, 0x05}, 0, 6},
+		{"TABLESWITCH at 1, with 2 cases", []byte{NOP, TABLESWITCH, 0x00, 0x00, // opcode and padding
+			0x00, 0x00, 0x00, 0x10, // default
+			0x00, 0x00, 0x00, 0x01, // low
+			0x00, 0x00, 0x00, 0x02, // high
+			0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x10}, 1, 23},
+		{"TABLESWITCH with high < low", []byte{TABLESWITCH, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x10, // default
+			0x00, 0x00, 0x00, 0x02, // low
+			0x00, 0x00, 0x00, 0x01}, 0, 0}, // high
+		{"TABLESWITCH whose high-low+1 overflows an int32", []byte{TABLESWITCH, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x10, // default
+			0x80, 0x00, 0x00, 0x00, // low: MinInt32
+			0x7F, 0xFF, 0xFF, 0xFF}, 0, 0}, // high: MaxInt32
+		{"LOOKUPSWITCH at 0, with 1 pair", []byte{LOOKUPSWITCH, 0x00, 0x00, 0x00, // opcode and padding
+			0x00, 0x00, 0x00, 0x10, // default
+			0x00, 0x00, 0x00, 0x01, // npairs
+			0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x10}, 0, 20},
+		{"LOOKUPSWITCH with no pairs", []byte{LOOKUPSWITCH, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00}, 0, 12},
+		{"truncated LOOKUPSWITCH", []byte{LOOKUPSWITCH, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01}, 0, 0},
+		{"PC past the end", []byte{NOP}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		if length := InstructionLength(tt.code, tt.pc); length != tt.length {
+			t.Errorf("InstructionLength(%s): expected %d, got %d", tt.name, tt.length, length)
+		}
+	}
+}
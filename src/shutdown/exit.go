@@ -31,6 +31,10 @@ const (
 func Exit(errorCondition ExitStatus) int {
 	globals.LoaderWg.Wait()
 	g := globals.GetGlobalRef()
+	if g.FuncShowProfile != nil { // show the counts gathered by -Xprof, if any
+		g.FuncShowProfile()
+	}
+
 	if g.JacobinName == "test" || g.JacobinName == "testWithoutShutdown" {
 		if errorCondition == OK {
 			errorCondition = TEST_OK
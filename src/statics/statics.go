@@ -22,6 +22,8 @@ import (
 // Statics is a fast-lookup map of static variables and functions. The int64 value
 // contains the index into the statics array where the entry is stored.
 // Statics are placed into this map only when they are first referenced and resolved.
+// Because threads can access statics concurrently, the map should be accessed only
+// through AddStatic() and QueryStatic(), which lock it.
 var Statics = make(map[string]Static)
 
 // Static contains all the various items needed for a static variable or function.
@@ -49,19 +51,28 @@ type Static struct {
 
 var staticsMutex = sync.RWMutex{}
 
-// AddStatic adds a static field to the Statics table using a mutex
+// AddStatic adds a static field to the Statics table, or updates it, using a mutex
 func AddStatic(name string, s Static) error {
 	if name == "" {
 		errMsg := fmt.Sprintf("AddStatic: Attempting to add static entry with a nil name, type=%s, value=%v", s.Type, s.Value)
 		_ = log.Log(errMsg, log.SEVERE)
 		return errors.New(errMsg)
 	}
-	staticsMutex.RLock()
+	staticsMutex.Lock()
 	Statics[name] = s
-	staticsMutex.RUnlock()
+	staticsMutex.Unlock()
 	return nil
 }
 
+// QueryStatic returns the entry in the Statics table for the static field name, which
+// is in the form className.fieldName, and whether it was found
+func QueryStatic(name string) (Static, bool) {
+	staticsMutex.RLock()
+	s, ok := Statics[name]
+	staticsMutex.RUnlock()
+	return s, ok
+}
+
 // PreloadStatics preloads static fields from java.lang.String and other
 // immediately necessary statics. It's called in jvmStart.go
 func PreloadStatics() {
@@ -122,7 +133,7 @@ func GetStaticValue(className string, fieldName string) any {
 	staticName := className + "." + fieldName
 
 	// was this static field previously loaded? Is so, get its location and move on.
-	prevLoaded, ok := Statics[staticName]
+	prevLoaded, ok := QueryStatic(staticName)
 	if !ok {
 		glob := globals.GetGlobalRef()
 		glob.ErrorGoStack = string(debug.Stack())
@@ -153,11 +164,15 @@ func GetStaticValue(className string, fieldName string) any {
 // DumpStatics dumps the contents of the statics table in sorted order to stderr
 func DumpStatics() {
 	_, _ = fmt.Fprintln(os.Stderr, "\n===== DumpStatics BEGIN")
-	// Create an array of keys.
+	// Create an array of keys, and a copy of the entries, so the table isn't locked while printing.
+	staticsMutex.RLock()
 	keys := make([]string, 0, len(Statics))
-	for key := range Statics {
+	entries := make(map[string]Static, len(Statics))
+	for key, entry := range Statics {
 		keys = append(keys, key)
+		entries[key] = entry
 	}
+	staticsMutex.RUnlock()
 	// Sort the keys.
 	// All the upper case entries precede all the lower case entries.
 	sort.Strings(keys)
@@ -165,7 +180,7 @@ func DumpStatics() {
 	for _, key := range keys {
 		if !strings.HasPrefix(key, "java/") && !strings.HasPrefix(key, "jdk/") &&
 			!strings.HasPrefix(key, "javax/") && !strings.HasPrefix(key, "sun") {
-			_, _ = fmt.Fprintf(os.Stderr, "%s     %v\n", key, Statics[key])
+			_, _ = fmt.Fprintf(os.Stderr, "%s     %v\n", key, entries[key])
 		}
 	}
 	_, _ = fmt.Fprintln(os.Stderr, "===== DumpStatics END")
@@ -247,3 +247,52 @@ func TestDumpStatics(t *testing.T) {
 		t.Errorf("TestIntConversions: got unexpected error in DumpStatics: %s", contents)
 	}
 }
+
+// Threads can load classes and access statics at the same time. This test does that from
+// several goroutines; run it with -race to check that the accesses are synchronized.
+func TestConcurrentClassLoadingAndStaticsAccess(t *testing.T) {
+	globals.InitGlobals("test")
+	log.Init()
+	classloader.InitMethodArea()
+	Statics = make(map[string]Static)
+	PreloadStatics()
+
+	initialSize := classloader.MethAreaSize()
+	const goroutines = 8
+	const classesPerGoroutine = 25
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < classesPerGoroutine; i++ {
+				// every goroutine loads the same classes, as threads using the same class do
+				className := fmt.Sprintf("app/Class%d", i)
+				if classloader.MethAreaFetch(className) == nil {
+					classloader.MethAreaInsert(className,
+						&classloader.Klass{Status: 'X', Loader: "test", Data: &classloader.ClData{Name: className}})
+				}
+				if k := classloader.MethAreaFetch(className); k == nil || k.Data.Name != className {
+					t.Errorf("MethAreaFetch(%s) returned the wrong class", className)
+				}
+
+				_ = AddStatic(fmt.Sprintf("%s.count%d", className, g), Static{Type: types.Int, Value: int64(i)})
+				if s, ok := QueryStatic(fmt.Sprintf("%s.count%d", className, g)); !ok || s.Value != int64(i) {
+					t.Errorf("QueryStatic(%s.count%d): expected %d, got %v", className, g, i, s.Value)
+				}
+				if v := GetStaticValue(types.StringClassName, "LATIN1"); v != int64(0) {
+					t.Errorf("GetStaticValue(String.LATIN1): expected 0, got %v", v)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if size := classloader.MethAreaSize() - initialSize; size != classesPerGoroutine {
+		t.Errorf("expected %d classes in the method area, got %d", classesPerGoroutine, size)
+	}
+	if _, ok := QueryStatic(fmt.Sprintf("app/Class%d.count%d", classesPerGoroutine-1, goroutines-1)); !ok {
+		t.Errorf("expected the statics added by every goroutine to be present")
+	}
+}
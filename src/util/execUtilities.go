@@ -7,9 +7,11 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"jacobin/log"
 	"jacobin/types"
+	"strings"
 )
 
 // ParseIncomingParamsFromMethTypeString takes a type string from a CP
@@ -97,3 +99,75 @@ func ParseIncomingParamsFromMethTypeString(s string) []string {
 	}
 	return params
 }
+
+// ParseMethodDescriptor parses a method descriptor, such as (I[Ljava/lang/String;)V, and
+// returns its parameter types and its return type as field descriptors (unlike
+// ParseIncomingParamsFromMethTypeString(), the types are not reduced). Returns an error
+// if the descriptor is malformed. See JVM spec section 4.3.3.
+func ParseMethodDescriptor(desc string) ([]string, string, error) {
+	params := make([]string, 0)
+	if !strings.HasPrefix(desc, "(") {
+		return nil, "", fmt.Errorf("method descriptor %q does not start with '('", desc)
+	}
+
+	i := 1
+	for i < len(desc) && desc[i] != ')' {
+		end, err := parseFieldType(desc, i)
+		if err != nil {
+			return nil, "", fmt.Errorf("method descriptor %q: %s", desc, err.Error())
+		}
+		params = append(params, desc[i:end])
+		i = end
+	}
+	if i >= len(desc) {
+		return nil, "", fmt.Errorf("method descriptor %q has no closing ')'", desc)
+	}
+
+	i++ // skip the ')'
+	if i >= len(desc) {
+		return nil, "", fmt.Errorf("method descriptor %q has no return type", desc)
+	}
+	retType := desc[i:]
+	if retType != "V" {
+		end, err := parseFieldType(desc, i)
+		if err != nil {
+			return nil, "", fmt.Errorf("method descriptor %q: %s", desc, err.Error())
+		}
+		if end != len(desc) {
+			return nil, "", fmt.Errorf("method descriptor %q has more than one return type", desc)
+		}
+	}
+	return params, retType, nil
+}
+
+// parseFieldType parses the field descriptor that starts at desc[start] and returns
+// the index just past its end.
+func parseFieldType(desc string, start int) (int, error) {
+	i := start
+	for i < len(desc) && desc[i] == '[' {
+		i++
+	}
+	if i >= len(desc) {
+		return 0, errors.New("array type without an element type")
+	}
+
+	switch desc[i] {
+	case 'B', 'C', 'D', 'F', 'I', 'J', 'S', 'Z':
+		return i + 1, nil
+	case 'L':
+		semi := strings.IndexByte(desc[i:], ';')
+		if semi < 0 {
+			return 0, errors.New("class type without a terminating ';'")
+		}
+		if semi == 1 {
+			return 0, errors.New("class type without a class name")
+		}
+		className := desc[i+1 : i+semi]
+		if strings.ContainsAny(className, ".[()") {
+			return 0, fmt.Errorf("invalid class name %q", className)
+		}
+		return i + semi + 1, nil
+	default:
+		return 0, fmt.Errorf("illegal type character '%c'", desc[i])
+	}
+}
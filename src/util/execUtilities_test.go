@@ -136,3 +136,30 @@ func TestParseIncomingReferenceParamsFromMethType17(t *testing.T) {
 func TestParseIncomingReferenceParamsFromMethType18(t *testing.T) {
 	checker(t, 18, "(JD[I[F[[[Ljava/lang/String;[[[J)V", 6, "JD[I[F[[[L[[[J")
 }
+
+func TestParseMethodDescriptor(t *testing.T) {
+	params, ret, err := ParseMethodDescriptor("(I[[JLjava/lang/String;[Ljava/lang/Object;Z)[B")
+	if err != nil {
+		t.Fatalf("Expected the descriptor to parse, got: %s", err.Error())
+	}
+	expected := []string{"I", "[[J", "Ljava/lang/String;", "[Ljava/lang/Object;", "Z"}
+	if fmt.Sprint(params) != fmt.Sprint(expected) {
+		t.Errorf("Expected parameters %v, got: %v", expected, params)
+	}
+	if ret != "[B" {
+		t.Errorf("Expected return type [B, got: %s", ret)
+	}
+
+	params, ret, err = ParseMethodDescriptor("()V")
+	if err != nil || len(params) != 0 || ret != "V" {
+		t.Errorf("Expected ()V to parse to no parameters and V, got: %v %s %v", params, ret, err)
+	}
+}
+
+func TestParseMethodDescriptorMalformed(t *testing.T) {
+	for _, desc := range []string{"", "I)V", "(I", "(I)", "(V)V", "(L;)V", "(Ljava/lang/String)V", "([)V", "()VV", "()[V"} {
+		if _, _, err := ParseMethodDescriptor(desc); err == nil {
+			t.Errorf("Expected descriptor %q to be rejected, but it parsed", desc)
+		}
+	}
+}